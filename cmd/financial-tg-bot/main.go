@@ -16,10 +16,13 @@ import (
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/config"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/env"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/kafka/sync_producer"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/chart_drawing"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/db"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/exchange_rates"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/messages"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/reminders"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
 	logswrapper "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/wrappers/financial-tg-bot/logs"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/wrappers/financial-tg-bot/metrics"
@@ -31,6 +34,8 @@ import (
 const (
 	port        = 8080
 	serviceName = "tg_bot"
+	// defaultReplicaID имя реплики, если в конфиге оно не задано
+	defaultReplicaID = "default"
 )
 
 func main() {
@@ -63,7 +68,7 @@ func main() {
 	}
 
 	// CLIENTS
-	msgHandler := initTgMsgHandler(config)
+	tgClient, msgHandler := initTgMsgHandler(config, redis)
 	fixerClient := fixer.New(ctx, config)
 
 	// INFRA
@@ -79,7 +84,11 @@ func main() {
 	exchangesRatesModel := exchange_rates.New(fixerClient)
 	purchasesModel := purchases.New(db, exchangesRatesModel, redis, producer)
 
-	msgModel := messages.New(msgHandler, purchasesModel, redis)
+	chartDrawingModel := chart_drawing.New()
+
+	limiter := ratelimit.New(redis, config)
+	msgModel := messages.New(msgHandler, purchasesModel, chartDrawingModel, redis, limiter)
+	remindersScheduler := reminders.New(purchasesModel, redis, msgHandler)
 
 	// ПОЕХАЛИ!!
 	errG, ctx := errgroup.WithContext(ctx)
@@ -95,12 +104,36 @@ func main() {
 		return nil
 	})
 
+	errG.Go(func() error {
+		logs.Info("outbox started")
+		if err := tgClient.RunOutbox(ctx); err != nil {
+			log.Fatal("outbox failed:", err)
+			return err
+		}
+		return nil
+	})
+
+	errG.Go(func() error {
+		logs.Info("reminders started")
+		remindersScheduler.Run(ctx)
+		return nil
+	})
+
 	errG.Go(func() error {
 		listener, err := tg.New(config, msgHandler)
 		if err != nil {
 			log.Fatal("tg listener init failed")
 			return err
 		}
+		if config.TgMode() == tg.ModeWebhook {
+			logs.Info("messages webhook started")
+			if err := listener.ListenWebhook(ctx, msgModel, redis, config); err != nil {
+				log.Fatal("tg webhook failed:", err)
+				return err
+			}
+			return nil
+		}
+
 		logs.Info("messages listen started")
 		listener.ListenUpdates(ctx, msgModel)
 		return nil
@@ -119,10 +152,16 @@ func main() {
 	}
 }
 
-func initTgMsgHandler(conf *config.Service) *tracing.Wrapper {
-	msgHandler, err := tgmsghandler.New(conf)
+func initTgMsgHandler(conf *config.Service, store *redis.Client) (*tgmsghandler.MsgHandler, *tracing.Wrapper) {
+	// очередь исходящих сообщений у каждой реплики своя
+	replicaID := conf.ReplicaID()
+	if replicaID == "" {
+		replicaID = defaultReplicaID
+	}
+
+	msgHandler, err := tgmsghandler.New(conf, store, replicaID)
 	if err != nil {
 		log.Fatal("tg msg handler init failed:", err)
 	}
-	return tracing.NewWrapper(metrics.NewWrapper(logswrapper.NewWrapper(msgHandler)))
+	return msgHandler, tracing.NewWrapper(metrics.NewWrapper(logswrapper.NewWrapper(msgHandler)))
 }
@@ -8,8 +8,20 @@ import (
 )
 
 func (s *server) SendReport(ctx context.Context, req *pkg.SendReportRequest) (*pkg.SendReportResponse, error) {
-	if err := s.sender.SendReport(ctx, req.GetUserId(), req.GetReportMessage(), req.GetReportImage()); err != nil {
-		return nil, errors.Wrap(err, "sender.SendReport")
+	switch {
+	case req.GetDigest():
+		if err := s.sender.SendDigest(ctx, req.GetUserId(), req.GetReportMessage(), req.GetReportImage()); err != nil {
+			return nil, errors.Wrap(err, "sender.SendDigest")
+		}
+	case len(req.GetReportDocument()) != 0:
+		err := s.sender.SendReportDocument(ctx, req.GetUserId(), req.GetReportMessage(), req.GetReportDocument(), req.GetDocumentName())
+		if err != nil {
+			return nil, errors.Wrap(err, "sender.SendReportDocument")
+		}
+	default:
+		if err := s.sender.SendReport(ctx, req.GetUserId(), req.GetReportMessage(), req.GetReportImage()); err != nil {
+			return nil, errors.Wrap(err, "sender.SendReport")
+		}
 	}
 
 	return &pkg.SendReportResponse{Response: &pkg.DefaultResponse{
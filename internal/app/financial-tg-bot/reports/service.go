@@ -6,6 +6,7 @@ import (
 	"net"
 
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/report"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
 	pkg "gitlab.ozon.dev/apetrichuk/financial-tg-bot/pkg/api/financial-tg-bot"
 	"go.uber.org/zap"
@@ -18,6 +19,8 @@ type configGetter interface {
 
 type Sender interface {
 	SendReport(ctx context.Context, userID int64, text string, img []byte) error
+	SendDigest(ctx context.Context, userID int64, text string, img []byte) error
+	SendReportDocument(ctx context.Context, userID int64, text string, doc []byte, name string) error
 }
 
 type server struct {
@@ -35,7 +38,8 @@ func Register(conf configGetter, sender Sender) error {
 		zap.Any("addr", lis.Addr()),
 	)
 
-	s := grpc.NewServer()
+	// PDF-документ отчета больше ограничения gRPC по умолчанию в 4 МБ
+	s := grpc.NewServer(grpc.MaxRecvMsgSize(report.MaxSendReportSize))
 
 	pkg.RegisterMessagesServiceServer(s, &server{sender: sender})
 	err = s.Serve(lis)
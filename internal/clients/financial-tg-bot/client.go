@@ -22,7 +22,11 @@ type configGetter interface {
 
 func New(confService configGetter) (*service, error) {
 	address := fmt.Sprintf("%s:%d", confService.GRPCHostMessages(), confService.GRPCPortMessages())
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// PDF-документ отчета больше ограничения gRPC по умолчанию в 4 МБ
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(report.MaxSendReportSize)),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -32,9 +36,12 @@ func New(confService configGetter) (*service, error) {
 
 func (s *service) SendReport(ctx context.Context, req report.SendReportRequest) (report.SendReportResponse, error) {
 	res, err := s.cl.SendReport(ctx, &pb.SendReportRequest{
-		UserId:        req.UserID,
-		ReportMessage: req.ReportMessage,
-		ReportImage:   req.ReportIMG,
+		UserId:         req.UserID,
+		ReportMessage:  req.ReportMessage,
+		ReportImage:    req.ReportIMG,
+		Digest:         req.Digest,
+		ReportDocument: req.ReportDocument,
+		DocumentName:   req.DocumentName,
 	})
 	if err != nil {
 		return report.SendReportResponse{}, errors.Wrap(err, "MessagesServiceClient.SendReport")
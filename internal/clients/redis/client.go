@@ -13,6 +13,13 @@ import (
 var (
 	timeout     = time.Second * 15
 	statusesTTL = time.Minute * 30
+	// телеграм повторяет доставку апдейта в вебхук не дольше суток
+	updatesTTL = time.Hour * 24
+	// дольше двух суток сообщения бота не редактируются: пользователь успевает уйти от них далеко вверх
+	messagesTTL = time.Hour * 48
+	// запланированные сообщения отправляются в течение одного дня пользователя,
+	// а он во всех часовых поясах заканчивается не позже, чем через двое суток по UTC
+	scheduleTTL = time.Hour * 48
 )
 
 type configGetter interface {
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+// tokenBucket корзина токенов в хеше: токены, время последнего пересчета и число отказов подряд.
+// Время берется у редиса, чтобы у всех реплик бота были одни часы. Ключ живет, пока корзина не наполнится
+var tokenBucket = redis.NewScript(`
+redis.replicate_commands()
+
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts', 'denials')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+local denials = tonumber(state[3]) or 0
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+	denials = 0
+else
+	retry = math.ceil((1 - tokens) / rate * 1000)
+	denials = denials + 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now), 'denials', denials)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+
+return {allowed, retry, denials}
+`)
+
+// TakeToken забирает токен из корзины key: rate токенов в секунду, не больше burst
+func (c *Client) TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, int, error) {
+	res, err := tokenBucket.Run(ctx, c.rdb, []string{key}, rate, burst).Int64Slice()
+	if err != nil {
+		logs.Error("take token error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return false, 0, 0, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, int(res[2]), nil
+}
@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const lockKeySuffix = "lock"
+
+// Lock берет блокировку name на время ttl, общую для всех реплик бота. Блокировка не снимается явно,
+// а истекает сама: так упавшая реплика не удержит ее навсегда. false - блокировку держит кто-то другой
+func (c *Client) Lock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	ok, err := c.rdb.SetNX(ctx, name+lockKeySuffix, 1, ttl).Result()
+	if err != nil {
+		logs.Error("lock error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return false, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return ok, nil
+}
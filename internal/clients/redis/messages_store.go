@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const messageKeySuffix = "msg"
+
+// SaveMessageID запоминает id сообщения бота в чате chatID под ключом key, чтобы потом его изменить
+func (c *Client) SaveMessageID(ctx context.Context, chatID int64, key string, messageID int) error {
+	err := c.rdb.Set(ctx, messageKey(chatID, key), messageID, messagesTTL).Err()
+	if err != nil {
+		logs.Error("save message id error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return nil
+}
+
+// GetMessageID id сообщения, запомненного под ключом key, или 0, если такого нет
+func (c *Client) GetMessageID(ctx context.Context, chatID int64, key string) (int, error) {
+	res, err := c.rdb.Get(ctx, messageKey(chatID, key)).Int()
+	if errors.Is(err, redis.Nil) {
+		metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+		return 0, nil
+	}
+	if err != nil {
+		logs.Error("get message id error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return 0, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return res, nil
+}
+
+func messageKey(chatID int64, key string) string {
+	return strconv.FormatInt(chatID, 10) + key + messageKeySuffix
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const outboxKeySuffix = "outbox"
+
+// SaveOutgoing сохраняет исходящее сообщение до его доставки. Сообщения очереди хранятся в одном хеше
+func (c *Client) SaveOutgoing(ctx context.Context, outbox, id string, msg []byte) error {
+	err := c.rdb.HSet(ctx, outbox+outboxKeySuffix, id, msg).Err()
+	if err != nil {
+		logs.Error("save outgoing error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return nil
+}
+
+// DeleteOutgoing удаляет доставленное (или отброшенное) сообщение
+func (c *Client) DeleteOutgoing(ctx context.Context, outbox, id string) error {
+	err := c.rdb.HDel(ctx, outbox+outboxKeySuffix, id).Err()
+	if err != nil {
+		logs.Error("delete outgoing error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return nil
+}
+
+// LoadOutgoing все недоставленные сообщения очереди
+func (c *Client) LoadOutgoing(ctx context.Context, outbox string) (map[string][]byte, error) {
+	res, err := c.rdb.HGetAll(ctx, outbox+outboxKeySuffix).Result()
+	if err != nil {
+		logs.Error("load outgoing error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return nil, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	msgs := make(map[string][]byte, len(res))
+	for id, msg := range res {
+		msgs[id] = []byte(msg)
+	}
+	return msgs, nil
+}
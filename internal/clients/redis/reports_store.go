@@ -7,19 +7,50 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/report"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
 	"go.uber.org/zap"
 )
 
+// reportVersion версия отчета в кеше, увеличивается при каждом изменении Report: отчеты другой версии
+// считаются отсутствующими и пересобираются. 1 - статистика по тратам, 2 - суммы по валютам оплаты,
+// 3 - траты с неизвестной валютой оплаты не попадают в суммы по валютам
+const reportVersion = 3
+
 type ReportItem struct {
 	PurchaseCategory string  `json:"purchaseCategory"`
 	Summa            float64 `json:"summa"`
+	Count            int     `json:"count"`
+}
+
+type TopPurchase struct {
+	Date        time.Time `json:"date"`
+	Category    string    `json:"category"`
+	Description string    `json:"description,omitempty"`
+	Summa       float64   `json:"summa"`
+}
+
+type CurrencyTotal struct {
+	Currency currency.Currency `json:"currency"`
+	Paid     float64           `json:"paid"`
+	Summa    float64           `json:"summa"`
+}
+
+type Stats struct {
+	Total           float64         `json:"total"`
+	Count           int             `json:"count"`
+	Top             []TopPurchase   `json:"top"`
+	Weekdays        [7]float64      `json:"weekdays"`
+	Currencies      []CurrencyTotal `json:"currencies"`
+	AtPurchaseRates float64         `json:"atPurchaseRates,omitempty"`
 }
 
 type Report struct {
+	Version  int          `json:"version,omitempty"` // в отчетах, закешированных до появления версий, ее нет
 	Items    []ReportItem `json:"items"`
+	Stats    Stats        `json:"stats"`
 	FromDate time.Time    `json:"fromDate"` // дата начала выборки данных в отчете
 }
 
@@ -34,8 +65,10 @@ func (r *Report) UnmarshalBinary(data []byte) error {
 		return errors.Wrap(err, "unmarshalling error")
 	}
 
+	r.Version = v.Version
 	r.FromDate = v.FromDate
 	r.Items = v.Items
+	r.Stats = v.Stats
 
 	return nil
 }
@@ -46,8 +79,27 @@ func (c *Client) SetReport(ctx context.Context, key string, value report.Report)
 		items[i] = ReportItem(value.Items[i])
 	}
 
+	top := make([]TopPurchase, len(value.Stats.Top))
+	for i := range value.Stats.Top {
+		top[i] = TopPurchase(value.Stats.Top[i])
+	}
+
+	currencies := make([]CurrencyTotal, len(value.Stats.Currencies))
+	for i := range value.Stats.Currencies {
+		currencies[i] = CurrencyTotal(value.Stats.Currencies[i])
+	}
+
 	r := Report{
-		Items:    items,
+		Version: reportVersion,
+		Items:   items,
+		Stats: Stats{
+			Total:           value.Stats.Total,
+			Count:           value.Stats.Count,
+			Top:             top,
+			Weekdays:        value.Stats.Weekdays,
+			Currencies:      currencies,
+			AtPurchaseRates: value.Stats.AtPurchaseRates,
+		},
 		FromDate: value.FromDate,
 	}
 
@@ -80,14 +132,36 @@ func (c *Client) GetReport(ctx context.Context, key string) (report.Report, erro
 		return report.Report{}, errors.Wrap(err, "scanning err")
 	}
 	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+	if r.Version != reportVersion {
+		// отчет закеширован до изменения Report: в нем нет новых полей
+		return report.Report{}, nil
+	}
 
 	items := make([]report.ReportItem, len(r.Items))
 	for i := range r.Items {
 		items[i] = report.ReportItem(r.Items[i])
 	}
 
+	top := make([]report.TopPurchase, len(r.Stats.Top))
+	for i := range r.Stats.Top {
+		top[i] = report.TopPurchase(r.Stats.Top[i])
+	}
+
+	currencies := make([]report.CurrencyTotal, len(r.Stats.Currencies))
+	for i := range r.Stats.Currencies {
+		currencies[i] = report.CurrencyTotal(r.Stats.Currencies[i])
+	}
+
 	return report.Report{
-		Items:    items,
+		Items: items,
+		Stats: report.Stats{
+			Total:           r.Stats.Total,
+			Count:           r.Stats.Count,
+			Top:             top,
+			Weekdays:        r.Stats.Weekdays,
+			Currencies:      currencies,
+			AtPurchaseRates: r.Stats.AtPurchaseRates,
+		},
 		FromDate: r.FromDate,
 	}, nil
 }
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	sentKeySuffix     = "sent"
+	lastTickKeySuffix = "lasttick"
+)
+
+// MarkSent отмечает, что запланированное сообщение key (напоминание за день, отчет за неделю) пользователю обработано.
+// false - отметка уже была: сообщение отправила другая реплика или этот же бот до перезапуска
+func (c *Client) MarkSent(ctx context.Context, userID int64, key string) (bool, error) {
+	ok, err := c.rdb.SetNX(ctx, sentKey(userID, key), 1, scheduleTTL).Result()
+	if err != nil {
+		logs.Error("mark sent error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return false, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return ok, nil
+}
+
+// UnmarkSent снимает отметку, если сообщение так и не удалось отправить
+func (c *Client) UnmarkSent(ctx context.Context, userID int64, key string) error {
+	return c.Delete(ctx, sentKey(userID, key))
+}
+
+func sentKey(userID int64, key string) string {
+	return strconv.FormatInt(userID, 10) + key + sentKeySuffix
+}
+
+// LastTick время последней полностью обработанной проверки запланированных сообщений name. Нулевое время -
+// проверок еще не было или последняя была так давно, что отметка истекла
+func (c *Client) LastTick(ctx context.Context, name string) (time.Time, error) {
+	res, err := c.rdb.Get(ctx, name+lastTickKeySuffix).Int64()
+	if errors.Is(err, redis.Nil) {
+		metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+		return time.Time{}, nil
+	}
+	if err != nil {
+		logs.Error("get last tick error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return time.Time{}, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return time.Unix(0, res), nil
+}
+
+// SetLastTick запоминает время проверки запланированных сообщений name
+func (c *Client) SetLastTick(ctx context.Context, name string, t time.Time) error {
+	if err := c.rdb.Set(ctx, name+lastTickKeySuffix, t.UnixNano(), scheduleTTL).Err(); err != nil {
+		logs.Error("set last tick error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return nil
+}
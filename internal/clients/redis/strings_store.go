@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
@@ -42,3 +43,8 @@ func (c *Client) GetString(ctx context.Context, key string) (string, error) {
 
 	return res.Val(), nil
 }
+
+// StringTTL сколько хранятся значения, записанные SetString
+func (c *Client) StringTTL() time.Duration {
+	return statusesTTL
+}
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const updateKeySuffix = "update"
+
+// MarkUpdateProcessed отмечает апдейт телеграма обработанным. Возвращает false, если его уже обработала
+// эта или другая реплика бота
+func (c *Client) MarkUpdateProcessed(ctx context.Context, updateID int) (bool, error) {
+	res := c.rdb.SetNX(ctx, strconv.Itoa(updateID)+updateKeySuffix, 1, updatesTTL)
+
+	err := res.Err()
+	if err != nil {
+		logs.Error("mark update processed error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return false, err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return res.Val(), nil
+}
+
+// UnmarkUpdate снимает отметку с апдейта, который не удалось поставить в очередь: телеграм пришлет его повторно,
+// и повтор должен обработаться
+func (c *Client) UnmarkUpdate(ctx context.Context, updateID int) error {
+	err := c.rdb.Del(ctx, strconv.Itoa(updateID)+updateKeySuffix).Err()
+	if err != nil {
+		logs.Error("unmark update error", zap.Error(err))
+		metrics.InFlightCache.WithLabelValues(metrics.StatusErr).Inc()
+		return err
+	}
+	metrics.InFlightCache.WithLabelValues(metrics.StatusOk).Inc()
+
+	return nil
+}
@@ -0,0 +1,438 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/clients/tg/listener.go
+
+// Package mock_tg is a generated GoMock package.
+package mock_tg
+
+import (
+	context "context"
+	reflect "reflect"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	gomock "github.com/golang/mock/gomock"
+	tg "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+)
+
+// MockTokenGetter is a mock of TokenGetter interface.
+type MockTokenGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenGetterMockRecorder
+}
+
+// MockTokenGetterMockRecorder is the mock recorder for MockTokenGetter.
+type MockTokenGetterMockRecorder struct {
+	mock *MockTokenGetter
+}
+
+// NewMockTokenGetter creates a new mock instance.
+func NewMockTokenGetter(ctrl *gomock.Controller) *MockTokenGetter {
+	mock := &MockTokenGetter{ctrl: ctrl}
+	mock.recorder = &MockTokenGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenGetter) EXPECT() *MockTokenGetterMockRecorder {
+	return m.recorder
+}
+
+// Token mocks base method.
+func (m *MockTokenGetter) Token() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockTokenGetterMockRecorder) Token() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockTokenGetter)(nil).Token))
+}
+
+// MockConfigGetter is a mock of ConfigGetter interface.
+type MockConfigGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigGetterMockRecorder
+}
+
+// MockConfigGetterMockRecorder is the mock recorder for MockConfigGetter.
+type MockConfigGetterMockRecorder struct {
+	mock *MockConfigGetter
+}
+
+// NewMockConfigGetter creates a new mock instance.
+func NewMockConfigGetter(ctrl *gomock.Controller) *MockConfigGetter {
+	mock := &MockConfigGetter{ctrl: ctrl}
+	mock.recorder = &MockConfigGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConfigGetter) EXPECT() *MockConfigGetterMockRecorder {
+	return m.recorder
+}
+
+// TgQueueSize mocks base method.
+func (m *MockConfigGetter) TgQueueSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TgQueueSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// TgQueueSize indicates an expected call of TgQueueSize.
+func (mr *MockConfigGetterMockRecorder) TgQueueSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TgQueueSize", reflect.TypeOf((*MockConfigGetter)(nil).TgQueueSize))
+}
+
+// TgWorkers mocks base method.
+func (m *MockConfigGetter) TgWorkers() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TgWorkers")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// TgWorkers indicates an expected call of TgWorkers.
+func (mr *MockConfigGetterMockRecorder) TgWorkers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TgWorkers", reflect.TypeOf((*MockConfigGetter)(nil).TgWorkers))
+}
+
+// Token mocks base method.
+func (m *MockConfigGetter) Token() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockConfigGetterMockRecorder) Token() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockConfigGetter)(nil).Token))
+}
+
+// MockMsgModel is a mock of MsgModel interface.
+type MockMsgModel struct {
+	ctrl     *gomock.Controller
+	recorder *MockMsgModelMockRecorder
+}
+
+// MockMsgModelMockRecorder is the mock recorder for MockMsgModel.
+type MockMsgModelMockRecorder struct {
+	mock *MockMsgModel
+}
+
+// NewMockMsgModel creates a new mock instance.
+func NewMockMsgModel(ctrl *gomock.Controller) *MockMsgModel {
+	mock := &MockMsgModel{ctrl: ctrl}
+	mock.recorder = &MockMsgModelMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMsgModel) EXPECT() *MockMsgModelMockRecorder {
+	return m.recorder
+}
+
+// IncomingCallback mocks base method.
+func (m *MockMsgModel) IncomingCallback(ctx context.Context, msg tg.Callback) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingCallback", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingCallback indicates an expected call of IncomingCallback.
+func (mr *MockMsgModelMockRecorder) IncomingCallback(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingCallback", reflect.TypeOf((*MockMsgModel)(nil).IncomingCallback), ctx, msg)
+}
+
+// IncomingChosenInlineResult mocks base method.
+func (m *MockMsgModel) IncomingChosenInlineResult(ctx context.Context, result tg.ChosenInlineResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingChosenInlineResult", ctx, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingChosenInlineResult indicates an expected call of IncomingChosenInlineResult.
+func (mr *MockMsgModelMockRecorder) IncomingChosenInlineResult(ctx, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingChosenInlineResult", reflect.TypeOf((*MockMsgModel)(nil).IncomingChosenInlineResult), ctx, result)
+}
+
+// IncomingInlineQuery mocks base method.
+func (m *MockMsgModel) IncomingInlineQuery(ctx context.Context, query tg.InlineQuery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingInlineQuery", ctx, query)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingInlineQuery indicates an expected call of IncomingInlineQuery.
+func (mr *MockMsgModelMockRecorder) IncomingInlineQuery(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingInlineQuery", reflect.TypeOf((*MockMsgModel)(nil).IncomingInlineQuery), ctx, query)
+}
+
+// IncomingMessage mocks base method.
+func (m *MockMsgModel) IncomingMessage(ctx context.Context, msg tg.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingMessage", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingMessage indicates an expected call of IncomingMessage.
+func (mr *MockMsgModelMockRecorder) IncomingMessage(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingMessage", reflect.TypeOf((*MockMsgModel)(nil).IncomingMessage), ctx, msg)
+}
+
+// MockMsgHandler is a mock of MsgHandler interface.
+type MockMsgHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockMsgHandlerMockRecorder
+}
+
+// MockMsgHandlerMockRecorder is the mock recorder for MockMsgHandler.
+type MockMsgHandlerMockRecorder struct {
+	mock *MockMsgHandler
+}
+
+// NewMockMsgHandler creates a new mock instance.
+func NewMockMsgHandler(ctrl *gomock.Controller) *MockMsgHandler {
+	mock := &MockMsgHandler{ctrl: ctrl}
+	mock.recorder = &MockMsgHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMsgHandler) EXPECT() *MockMsgHandlerMockRecorder {
+	return m.recorder
+}
+
+// AnswerInlineQuery mocks base method.
+func (m *MockMsgHandler) AnswerInlineQuery(queryID string, results []tg.InlineResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerInlineQuery", queryID, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnswerInlineQuery indicates an expected call of AnswerInlineQuery.
+func (mr *MockMsgHandlerMockRecorder) AnswerInlineQuery(queryID, results interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerInlineQuery", reflect.TypeOf((*MockMsgHandler)(nil).AnswerInlineQuery), queryID, results)
+}
+
+// EditKeyboard mocks base method.
+func (m *MockMsgHandler) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditKeyboard", text, userID, msg, keyboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditKeyboard indicates an expected call of EditKeyboard.
+func (mr *MockMsgHandlerMockRecorder) EditKeyboard(text, userID, msg, keyboard interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditKeyboard", reflect.TypeOf((*MockMsgHandler)(nil).EditKeyboard), text, userID, msg, keyboard)
+}
+
+// EditMessage mocks base method.
+func (m *MockMsgHandler) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessage", text, userID, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessage indicates an expected call of EditMessage.
+func (mr *MockMsgHandlerMockRecorder) EditMessage(text, userID, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessage", reflect.TypeOf((*MockMsgHandler)(nil).EditMessage), text, userID, msg)
+}
+
+// IncomingCallback mocks base method.
+func (m *MockMsgHandler) IncomingCallback(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingCallback", ctx, model, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingCallback indicates an expected call of IncomingCallback.
+func (mr *MockMsgHandlerMockRecorder) IncomingCallback(ctx, model, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingCallback", reflect.TypeOf((*MockMsgHandler)(nil).IncomingCallback), ctx, model, msg)
+}
+
+// IncomingChosenInlineResult mocks base method.
+func (m *MockMsgHandler) IncomingChosenInlineResult(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingChosenInlineResult", ctx, model, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingChosenInlineResult indicates an expected call of IncomingChosenInlineResult.
+func (mr *MockMsgHandlerMockRecorder) IncomingChosenInlineResult(ctx, model, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingChosenInlineResult", reflect.TypeOf((*MockMsgHandler)(nil).IncomingChosenInlineResult), ctx, model, msg)
+}
+
+// IncomingInlineQuery mocks base method.
+func (m *MockMsgHandler) IncomingInlineQuery(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingInlineQuery", ctx, model, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingInlineQuery indicates an expected call of IncomingInlineQuery.
+func (mr *MockMsgHandlerMockRecorder) IncomingInlineQuery(ctx, model, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingInlineQuery", reflect.TypeOf((*MockMsgHandler)(nil).IncomingInlineQuery), ctx, model, msg)
+}
+
+// IncomingMessage mocks base method.
+func (m *MockMsgHandler) IncomingMessage(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncomingMessage", ctx, model, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncomingMessage indicates an expected call of IncomingMessage.
+func (mr *MockMsgHandlerMockRecorder) IncomingMessage(ctx, model, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncomingMessage", reflect.TypeOf((*MockMsgHandler)(nil).IncomingMessage), ctx, model, msg)
+}
+
+// SendDocument mocks base method.
+func (m *MockMsgHandler) SendDocument(doc []byte, name string, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDocument", doc, name, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDocument indicates an expected call of SendDocument.
+func (mr *MockMsgHandlerMockRecorder) SendDocument(doc, name, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDocument", reflect.TypeOf((*MockMsgHandler)(nil).SendDocument), doc, name, userID)
+}
+
+// SendImage mocks base method.
+func (m *MockMsgHandler) SendImage(img []byte, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendImage", img, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendImage indicates an expected call of SendImage.
+func (mr *MockMsgHandlerMockRecorder) SendImage(img, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendImage", reflect.TypeOf((*MockMsgHandler)(nil).SendImage), img, userID)
+}
+
+// SendKeyboard mocks base method.
+func (m *MockMsgHandler) SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendKeyboard", text, userID, keyboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendKeyboard indicates an expected call of SendKeyboard.
+func (mr *MockMsgHandlerMockRecorder) SendKeyboard(text, userID, keyboard interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKeyboard", reflect.TypeOf((*MockMsgHandler)(nil).SendKeyboard), text, userID, keyboard)
+}
+
+// SendMessage mocks base method.
+func (m *MockMsgHandler) SendMessage(text string, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", text, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockMsgHandlerMockRecorder) SendMessage(text, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockMsgHandler)(nil).SendMessage), text, userID)
+}
+
+// SendTrackedMessage mocks base method.
+func (m *MockMsgHandler) SendTrackedMessage(text string, userID int64, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTrackedMessage", text, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTrackedMessage indicates an expected call of SendTrackedMessage.
+func (mr *MockMsgHandlerMockRecorder) SendTrackedMessage(text, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTrackedMessage", reflect.TypeOf((*MockMsgHandler)(nil).SendTrackedMessage), text, userID, key)
+}
+
+// MockUpdatesStore is a mock of UpdatesStore interface.
+type MockUpdatesStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUpdatesStoreMockRecorder
+}
+
+// MockUpdatesStoreMockRecorder is the mock recorder for MockUpdatesStore.
+type MockUpdatesStoreMockRecorder struct {
+	mock *MockUpdatesStore
+}
+
+// NewMockUpdatesStore creates a new mock instance.
+func NewMockUpdatesStore(ctrl *gomock.Controller) *MockUpdatesStore {
+	mock := &MockUpdatesStore{ctrl: ctrl}
+	mock.recorder = &MockUpdatesStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUpdatesStore) EXPECT() *MockUpdatesStoreMockRecorder {
+	return m.recorder
+}
+
+// MarkUpdateProcessed mocks base method.
+func (m *MockUpdatesStore) MarkUpdateProcessed(ctx context.Context, updateID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUpdateProcessed", ctx, updateID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUpdateProcessed indicates an expected call of MarkUpdateProcessed.
+func (mr *MockUpdatesStoreMockRecorder) MarkUpdateProcessed(ctx, updateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUpdateProcessed", reflect.TypeOf((*MockUpdatesStore)(nil).MarkUpdateProcessed), ctx, updateID)
+}
+
+// UnmarkUpdate mocks base method.
+func (m *MockUpdatesStore) UnmarkUpdate(ctx context.Context, updateID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarkUpdate", ctx, updateID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmarkUpdate indicates an expected call of UnmarkUpdate.
+func (mr *MockUpdatesStoreMockRecorder) UnmarkUpdate(ctx, updateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarkUpdate", reflect.TypeOf((*MockUpdatesStore)(nil).UnmarkUpdate), ctx, updateID)
+}
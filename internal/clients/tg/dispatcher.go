@@ -0,0 +1,147 @@
+package tg
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+)
+
+const (
+	defaultWorkers   = 16
+	defaultQueueSize = 100
+)
+
+var ErrDispatcherStopped = errors.New("dispatcher is stopped")
+
+type queuedUpdate struct {
+	update   tgbotapi.Update
+	queuedAt time.Time
+}
+
+// Dispatcher обрабатывает апдейты параллельно, сохраняя порядок для каждого пользователя: апдейты распределяются
+// по очередям воркеров по id пользователя, поэтому сообщения одного пользователя всегда попадают к одному воркеру
+// и обрабатываются по очереди, а медленный отчет одного пользователя не задерживает остальных
+type Dispatcher struct {
+	ctx        context.Context
+	msgHandler MsgHandler
+	msgModel   MsgModel
+
+	queues []chan queuedUpdate
+	wg     sync.WaitGroup
+
+	// mu не дает закрыть очереди, пока в них кто-то пишет
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewDispatcher запускает workers воркеров, у каждого очередь на queueSize апдейтов. Апдейты обрабатываются
+// в контексте ctx, но без его отмены: уже принятые апдейты дообрабатываются при остановке
+func NewDispatcher(ctx context.Context, workers, queueSize int, msgHandler MsgHandler, msgModel MsgModel) *Dispatcher {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	d := &Dispatcher{
+		ctx:        detachedContext{ctx},
+		msgHandler: msgHandler,
+		msgModel:   msgModel,
+		queues:     make([]chan queuedUpdate, workers),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan queuedUpdate, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+// Dispatch ставит апдейт в очередь воркера пользователя. Если очередь заполнена, ждет, пока в ней освободится место
+// или отменится ctx - так медленная обработка придерживает прием новых апдейтов
+func (d *Dispatcher) Dispatch(ctx context.Context, update tgbotapi.Update) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.stopped {
+		return ErrDispatcherStopped
+	}
+
+	queue := d.queues[uint64(updateUserID(update))%uint64(len(d.queues))]
+	item := queuedUpdate{update: update, queuedAt: time.Now()}
+
+	select {
+	case queue <- item:
+	default:
+		metrics.UpdatesQueueFull.Inc()
+		select {
+		case queue <- item:
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "waiting for queue")
+		}
+	}
+	metrics.UpdatesQueueLength.Inc()
+
+	return nil
+}
+
+// Stop перестает принимать апдейты и ждет, пока воркеры обработают все уже поставленные в очереди
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) work(queue <-chan queuedUpdate) {
+	defer d.wg.Done()
+
+	for item := range queue {
+		metrics.UpdatesQueueLength.Dec()
+		metrics.HistogramUpdatesQueueWait.Observe(time.Since(item.queuedAt).Seconds())
+
+		handleUpdate(d.ctx, d.msgHandler, d.msgModel, item.update)
+	}
+}
+
+// updateUserID id пользователя, от которого пришел апдейт. Для апдейтов без пользователя - 0,
+// они все попадают в одну очередь
+func updateUserID(update tgbotapi.Update) int64 {
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		return update.CallbackQuery.From.ID
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.Message != nil && update.Message.Chat != nil:
+		return update.Message.Chat.ID
+	case update.InlineQuery != nil && update.InlineQuery.From != nil:
+		return update.InlineQuery.From.ID
+	case update.ChosenInlineResult != nil && update.ChosenInlineResult.From != nil:
+		return update.ChosenInlineResult.From.ID
+	default:
+		return 0
+	}
+}
+
+// detachedContext контекст со значениями родителя, но без его отмены и дедлайна
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}       { return nil }
+func (c detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key any) any           { return c.parent.Value(key) }
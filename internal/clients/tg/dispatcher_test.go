@@ -0,0 +1,164 @@
+//go:build test_all || unit_test
+
+package tg_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg/_mocks"
+)
+
+func messageUpdate(userID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: userID},
+		Text: text,
+	}}
+}
+
+func Test_Dispatcher_KeepsOrderPerUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+
+	var (
+		mu  sync.Mutex
+		got = make(map[int64][]string)
+	)
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ tg.MsgModel, update tgbotapi.Update) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got[update.Message.From.ID] = append(got[update.Message.From.ID], update.Message.Text)
+			return nil
+		}).Times(5 * 20)
+
+	dispatcher := tg.NewDispatcher(context.Background(), 3, 5, msgHandler, msgModel)
+
+	want := make(map[int64][]string)
+	for i := 0; i < 20; i++ {
+		for userID := int64(1); userID <= 5; userID++ {
+			text := strconv.Itoa(i)
+			want[userID] = append(want[userID], text)
+			assert.NoError(t, dispatcher.Dispatch(context.Background(), messageUpdate(userID, text)))
+		}
+	}
+	dispatcher.Stop()
+
+	assert.Equal(t, want, got)
+}
+
+func Test_Dispatcher_SlowUserDoesntBlockOthers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+
+	release := make(chan struct{})
+	fastDone := make(chan struct{})
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ tg.MsgModel, update tgbotapi.Update) error {
+			if update.Message.Text == "/report year" {
+				<-release
+			} else {
+				close(fastDone)
+			}
+			return nil
+		}).Times(2)
+
+	// у пользователей 1 и 2 разные воркеры
+	dispatcher := tg.NewDispatcher(context.Background(), 2, 5, msgHandler, msgModel)
+
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), messageUpdate(1, "/report year")))
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), messageUpdate(2, "кофе 250")))
+
+	select {
+	case <-fastDone:
+	case <-time.After(time.Second):
+		t.Error("сообщение второго пользователя ждет отчета первого")
+	}
+
+	close(release)
+	dispatcher.Stop()
+}
+
+func Test_Dispatcher_Backpressure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ tg.MsgModel, _ tgbotapi.Update) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		}).Times(2)
+
+	dispatcher := tg.NewDispatcher(context.Background(), 1, 1, msgHandler, msgModel)
+
+	// первый апдейт обрабатывается, второй ждет в очереди, для третьего места нет
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), messageUpdate(1, "1")))
+	<-started
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), messageUpdate(1, "2")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, dispatcher.Dispatch(ctx, messageUpdate(1, "3")), context.DeadlineExceeded)
+
+	close(release)
+	dispatcher.Stop()
+}
+
+func Test_Dispatcher_StopDrainsQueues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ tg.MsgModel, _ tgbotapi.Update) error {
+			// апдейты дообрабатываются и после отмены контекста приложения
+			assert.NoError(t, ctx.Err())
+			time.Sleep(time.Millisecond)
+			return nil
+		}).Times(10)
+
+	dispatcher := tg.NewDispatcher(ctx, 2, 10, msgHandler, msgModel)
+	for i := int64(0); i < 10; i++ {
+		assert.NoError(t, dispatcher.Dispatch(context.Background(), messageUpdate(i, "кофе 250")))
+	}
+
+	cancel()
+	dispatcher.Stop()
+
+	assert.ErrorIs(t, dispatcher.Dispatch(context.Background(), messageUpdate(1, "кофе 250")), tg.ErrDispatcherStopped)
+}
+
+func Test_Dispatcher_InlineUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+
+	query := tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q1", From: &tgbotapi.User{ID: 1}, Query: "кофе 250"}}
+	chosen := tgbotapi.Update{ChosenInlineResult: &tgbotapi.ChosenInlineResult{ResultID: "0", From: &tgbotapi.User{ID: 1}, Query: "кофе 250"}}
+
+	// запрос и выбор варианта одного пользователя обрабатываются по порядку
+	gomock.InOrder(
+		msgHandler.EXPECT().IncomingInlineQuery(gomock.Any(), msgModel, query),
+		msgHandler.EXPECT().IncomingChosenInlineResult(gomock.Any(), msgModel, chosen),
+	)
+
+	dispatcher := tg.NewDispatcher(context.Background(), 2, 10, msgHandler, msgModel)
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), query))
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), chosen))
+	dispatcher.Stop()
+}
@@ -0,0 +1,69 @@
+package tg
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MaxCallbackData телеграм не принимает кнопки, у которых данные колбэка длиннее 64 байт
+const MaxCallbackData = 64
+
+var (
+	ErrEmptyKeyboard       = errors.New("keyboard has no buttons")
+	ErrCallbackDataTooLong = errors.New("callback data is too long")
+)
+
+// Button кнопка инлайн клавиатуры: Text видит пользователь, Data приходит в колбэке при нажатии
+type Button struct {
+	Text string `json:"text"`
+	Data string `json:"data"`
+}
+
+func NewButton(text, data string) Button {
+	return Button{Text: text, Data: data}
+}
+
+// Keyboard инлайн клавиатура, кнопки по рядам
+type Keyboard [][]Button
+
+// Grid раскладывает кнопки в ряды по cols штук
+func Grid(cols int, buttons ...Button) Keyboard {
+	if cols < 1 {
+		cols = 1
+	}
+
+	k := make(Keyboard, 0, (len(buttons)+cols-1)/cols)
+	for len(buttons) > 0 {
+		n := cols
+		if n > len(buttons) {
+			n = len(buttons)
+		}
+		k = append(k, buttons[:n:n])
+		buttons = buttons[n:]
+	}
+	return k
+}
+
+// Row добавляет в клавиатуру ряд из кнопок
+func (k Keyboard) Row(buttons ...Button) Keyboard {
+	if len(buttons) == 0 {
+		return k
+	}
+	return append(k, buttons)
+}
+
+// Validate проверяет, что телеграм примет клавиатуру
+func (k Keyboard) Validate() error {
+	empty := true
+	for _, row := range k {
+		for _, b := range row {
+			empty = false
+			if len(b.Data) > MaxCallbackData {
+				return errors.Wrapf(ErrCallbackDataTooLong, "button %q", b.Text)
+			}
+		}
+	}
+	if empty {
+		return ErrEmptyKeyboard
+	}
+	return nil
+}
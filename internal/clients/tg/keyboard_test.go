@@ -0,0 +1,43 @@
+//go:build test_all || unit_test
+
+package tg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+)
+
+func Test_Grid(t *testing.T) {
+	a, b, c := tg.NewButton("a", "1"), tg.NewButton("b", "2"), tg.NewButton("c", "3")
+
+	assert.Equal(t, tg.Keyboard{{a, b}, {c}}, tg.Grid(2, a, b, c))
+	assert.Equal(t, tg.Keyboard{{a}, {b}, {c}}, tg.Grid(1, a, b, c))
+	assert.Equal(t, tg.Keyboard{{a, b, c}}, tg.Grid(5, a, b, c))
+	assert.Equal(t, tg.Keyboard{{a}, {b}}, tg.Grid(0, a, b))
+	assert.Empty(t, tg.Grid(2))
+}
+
+func Test_Keyboard_Row(t *testing.T) {
+	a, b, c := tg.NewButton("a", "1"), tg.NewButton("b", "2"), tg.NewButton("c", "3")
+
+	keyboard := tg.Grid(2, a, b).Row(c)
+	assert.Equal(t, tg.Keyboard{{a, b}, {c}}, keyboard)
+
+	// пустой ряд не добавляется
+	assert.Equal(t, tg.Keyboard{{a}}, tg.Keyboard{{a}}.Row())
+}
+
+func Test_Keyboard_Validate(t *testing.T) {
+	assert.NoError(t, tg.Keyboard{{tg.NewButton("ок", strings.Repeat("x", tg.MaxCallbackData))}}.Validate())
+
+	// кириллица занимает по два байта, поэтому 40 букв уже не помещаются
+	err := tg.Keyboard{{tg.NewButton("ок", "ok")}, {tg.NewButton("длинно", strings.Repeat("я", 40))}}.Validate()
+	assert.True(t, errors.Is(err, tg.ErrCallbackDataTooLong))
+
+	assert.True(t, errors.Is(tg.Keyboard{}.Validate(), tg.ErrEmptyKeyboard))
+	assert.True(t, errors.Is(tg.Keyboard{{}}.Validate(), tg.ErrEmptyKeyboard))
+}
@@ -11,61 +11,152 @@ type TokenGetter interface {
 	Token() string
 }
 
+type ConfigGetter interface {
+	TokenGetter
+	// TgWorkers сколько апдейтов обрабатывается параллельно, TgQueueSize - размер очереди каждого воркера
+	TgWorkers() int
+	TgQueueSize() int
+}
+
 type MsgModel interface {
 	IncomingCallback(ctx context.Context, msg Callback) error
 	IncomingMessage(ctx context.Context, msg Message) error
+	IncomingInlineQuery(ctx context.Context, query InlineQuery) error
+	IncomingChosenInlineResult(ctx context.Context, result ChosenInlineResult) error
 }
 
 type MsgHandler interface {
 	SendMessage(text string, userID int64) error
 	SendImage(img []byte, userID int64) error
-	SendKeyboard(text string, userID int64, buttonTexts []string) error
+	SendDocument(doc []byte, name string, userID int64) error
+	SendKeyboard(text string, userID int64, keyboard Keyboard) error
+	SendTrackedMessage(text string, userID int64, key string) error
+	EditMessage(text string, userID int64, msg MessageRef) error
+	EditKeyboard(text string, userID int64, msg MessageRef, keyboard Keyboard) error
+	AnswerInlineQuery(queryID string, results []InlineResult) error
 
 	IncomingCallback(ctx context.Context, model MsgModel, msg tgbotapi.Update) error
 	IncomingMessage(ctx context.Context, model MsgModel, msg tgbotapi.Update) error
+	IncomingInlineQuery(ctx context.Context, model MsgModel, msg tgbotapi.Update) error
+	IncomingChosenInlineResult(ctx context.Context, model MsgModel, msg tgbotapi.Update) error
+}
+
+// UpdatesStore помнит уже обработанные апдейты: телеграм может доставить один апдейт в вебхук несколько раз,
+// а запущенных реплик бота может быть несколько
+type UpdatesStore interface {
+	// MarkUpdateProcessed отмечает апдейт обработанным, возвращает false, если он уже был отмечен
+	MarkUpdateProcessed(ctx context.Context, updateID int) (bool, error)
+	// UnmarkUpdate снимает отметку, если апдейт так и не удалось обработать
+	UnmarkUpdate(ctx context.Context, updateID int) error
 }
 
 type Callback struct {
-	UserID   int64
-	UserName string
-	Data     string
+	UserID       int64
+	UserName     string
+	Data         string
+	LanguageCode string // язык интерфейса телеграма у пользователя, например "ru" или "en-US"
+	MessageID    int    // сообщение бота с нажатой кнопкой
 }
 
 type Message struct {
-	Text     string
-	UserID   int64
-	UserName string
+	Text         string
+	UserID       int64
+	UserName     string
+	LanguageCode string
+}
+
+// InlineQuery текст, который пользователь набирает после имени бота в любом чате: "@bot 250 кофе"
+type InlineQuery struct {
+	ID           string
+	Query        string
+	UserID       int64
+	UserName     string
+	LanguageCode string
+}
+
+// InlineResult вариант ответа на InlineQuery. Text - сообщение, которое пользователь отправит в чат, выбрав вариант
+type InlineResult struct {
+	ID          string
+	Title       string
+	Description string
+	Text        string
+}
+
+// ChosenInlineResult вариант, который пользователь выбрал в ответе на InlineQuery. Телеграм присылает его,
+// только если у бота включен inline feedback
+type ChosenInlineResult struct {
+	ResultID     string
+	Query        string
+	UserID       int64
+	UserName     string
+	LanguageCode string
+}
+
+// MessageRef сообщение бота, которое нужно изменить. ID - id сообщения в телеграме, если он известен (например,
+// у сообщения с нажатой кнопкой). Key - ключ, под которым id сообщения запоминается после отправки или изменения;
+// если ID не указан, меняется сообщение, запомненное под этим ключом
+type MessageRef struct {
+	ID  int    `json:"id,omitempty"`
+	Key string `json:"key,omitempty"`
 }
 
 type Client struct {
 	client     *tgbotapi.BotAPI
 	msgHandler MsgHandler
+
+	workers   int
+	queueSize int
 }
 
-func New(tokenGetter TokenGetter, msgHandler MsgHandler) (*Client, error) {
-	client, err := tgbotapi.NewBotAPI(tokenGetter.Token())
+func New(conf ConfigGetter, msgHandler MsgHandler) (*Client, error) {
+	client, err := tgbotapi.NewBotAPI(conf.Token())
 	if err != nil {
 		return nil, errors.Wrap(err, "NewBotAPI")
 	}
 	return &Client{
 		client:     client,
 		msgHandler: msgHandler,
+		workers:    conf.TgWorkers(),
+		queueSize:  conf.TgQueueSize(),
 	}, nil
 }
 
+// ListenUpdates получает апдейты через long polling, пока не отменен ctx, и обрабатывает их через Dispatcher.
+// Так может работать только одна реплика бота, для нескольких реплик есть ListenWebhook
 func (c *Client) ListenUpdates(ctx context.Context, msgModel MsgModel) {
+	dispatcher := NewDispatcher(ctx, c.workers, c.queueSize, c.msgHandler, msgModel)
+	// при остановке дожидаемся обработки уже полученных апдейтов: телеграм их повторно не пришлет
+	defer dispatcher.Stop()
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := c.client.GetUpdatesChan(u)
+	go func() {
+		<-ctx.Done()
+		c.client.StopReceivingUpdates()
+	}()
 
+	// канал закроется после StopReceivingUpdates, но сначала из него вычитаются все полученные апдейты
 	for update := range updates {
-		switch {
-		case update.CallbackQuery != nil:
-			_ = c.msgHandler.IncomingCallback(ctx, msgModel, update)
+		_ = dispatcher.Dispatch(context.Background(), update)
+	}
+}
+
+// handleUpdate передает апдейт в обработчик сообщений, нажатий на кнопки или inline-запросов,
+// остальные апдейты пропускаются
+func handleUpdate(ctx context.Context, msgHandler MsgHandler, msgModel MsgModel, update tgbotapi.Update) {
+	switch {
+	case update.CallbackQuery != nil:
+		_ = msgHandler.IncomingCallback(ctx, msgModel, update)
+
+	case update.Message != nil:
+		_ = msgHandler.IncomingMessage(ctx, msgModel, update)
+
+	case update.InlineQuery != nil:
+		_ = msgHandler.IncomingInlineQuery(ctx, msgModel, update)
 
-		case update.Message != nil:
-			_ = c.msgHandler.IncomingMessage(ctx, msgModel, update)
-		}
+	case update.ChosenInlineResult != nil:
+		_ = msgHandler.IncomingChosenInlineResult(ctx, msgModel, update)
 	}
 }
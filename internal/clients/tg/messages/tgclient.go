@@ -2,21 +2,44 @@ package messages
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg/outbox"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"go.uber.org/zap"
 )
 
+// outboxWorkers сколько сообщений отправляется в телеграм одновременно
+const outboxWorkers = 4
+
 type TokenGetter interface {
 	Token() string
 }
 
+// MessageIDStore хранит id отправленных ботом сообщений, чтобы их можно было изменить позже
+type MessageIDStore interface {
+	SaveMessageID(ctx context.Context, chatID int64, key string, messageID int) error
+	GetMessageID(ctx context.Context, chatID int64, key string) (int, error)
+}
+
+type Store interface {
+	outbox.Store
+	MessageIDStore
+}
+
+// MsgHandler ставит исходящие сообщения в очередь outbox, а отправляет их botSender
 type MsgHandler struct {
 	client *tgbotapi.BotAPI
+	outbox *outbox.Outbox
 }
 
-func New(conf TokenGetter) (*MsgHandler, error) {
+// New name - имя очереди исходящих сообщений в store, у каждой реплики бота оно свое
+func New(conf TokenGetter, store Store, name string) (*MsgHandler, error) {
 	client, err := tgbotapi.NewBotAPI(conf.Token())
 	if err != nil {
 		return nil, errors.Wrap(err, "NewBotAPI")
@@ -24,55 +47,103 @@ func New(conf TokenGetter) (*MsgHandler, error) {
 
 	return &MsgHandler{
 		client: client,
+		outbox: outbox.New(name, &botSender{client: client, ids: store}, store),
 	}, nil
 }
 
-func (m *MsgHandler) SendMessage(text string, userID int64) error {
-	_, err := m.client.Send(tgbotapi.NewMessage(userID, text))
-	if err != nil {
-		return errors.Wrap(err, "client.Send")
+// RunOutbox отправляет сообщения из очереди, пока не отменен ctx. Сначала в очередь возвращаются сообщения,
+// которые не успели отправить до перезапуска
+func (m *MsgHandler) RunOutbox(ctx context.Context) error {
+	if err := m.outbox.Restore(ctx); err != nil {
+		return errors.Wrap(err, "outbox.Restore")
 	}
-
+	m.outbox.Run(ctx, outboxWorkers)
 	return nil
 }
 
+func (m *MsgHandler) SendMessage(text string, userID int64) error {
+	return m.enqueue(outbox.Message{ChatID: userID, Kind: outbox.KindText, Text: text})
+}
+
 func (m *MsgHandler) SendImage(img []byte, userId int64) error {
-	b := tgbotapi.FileBytes{Bytes: img}
+	return m.enqueue(outbox.Message{ChatID: userId, Kind: outbox.KindImage, Image: img})
+}
 
-	_, err := m.client.Send(tgbotapi.NewPhoto(userId, b))
-	if err != nil {
-		return errors.Wrap(err, "client.Send")
+// SendDocument отправляет файл name с содержимым doc
+func (m *MsgHandler) SendDocument(doc []byte, name string, userID int64) error {
+	return m.enqueue(outbox.Message{ChatID: userID, Kind: outbox.KindDocument, Document: doc, FileName: name})
+}
+
+func (m *MsgHandler) SendKeyboard(text string, userId int64, keyboard tg.Keyboard) error {
+	if err := keyboard.Validate(); err != nil {
+		return errors.Wrap(err, "keyboard.Validate")
 	}
+	return m.enqueue(outbox.Message{ChatID: userId, Kind: outbox.KindKeyboard, Text: text, Keyboard: keyboard})
+}
 
-	return nil
+// SendTrackedMessage отправляет сообщение и запоминает его id под ключом key, чтобы потом изменить его через EditMessage
+func (m *MsgHandler) SendTrackedMessage(text string, userID int64, key string) error {
+	return m.enqueue(outbox.Message{ChatID: userID, Kind: outbox.KindText, Text: text, Ref: &tg.MessageRef{Key: key}})
 }
 
-func (m *MsgHandler) SendKeyboard(text string, userId int64, buttonTexts []string) error {
-	msg := tgbotapi.NewMessage(userId, text)
+// EditMessage меняет текст сообщения msg и убирает его кнопки. Если сообщение изменить нельзя, отправляется новое
+func (m *MsgHandler) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	return m.enqueue(outbox.Message{ChatID: userID, Kind: outbox.KindEdit, Text: text, Ref: &msg})
+}
 
-	keyboard := tgbotapi.InlineKeyboardMarkup{}
-	for _, txt := range buttonTexts {
-		var row []tgbotapi.InlineKeyboardButton
-		btn := tgbotapi.NewInlineKeyboardButtonData(txt, txt)
-		row = append(row, btn)
-		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+// EditKeyboard меняет текст и кнопки сообщения msg. Если сообщение изменить нельзя, отправляется новое
+func (m *MsgHandler) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	if err := keyboard.Validate(); err != nil {
+		return errors.Wrap(err, "keyboard.Validate")
 	}
+	return m.enqueue(outbox.Message{ChatID: userID, Kind: outbox.KindEdit, Text: text, Keyboard: keyboard, Ref: &msg})
+}
 
-	msg.ReplyMarkup = keyboard
+// AnswerInlineQuery отвечает на inline-запрос сразу, минуя очередь: телеграм ждет ответ всего несколько секунд.
+// Ответ не кешируется, потому что у каждого пользователя свои траты
+func (m *MsgHandler) AnswerInlineQuery(queryID string, results []tg.InlineResult) error {
+	articles := make([]interface{}, 0, len(results))
+	for _, r := range results {
+		article := tgbotapi.NewInlineQueryResultArticle(r.ID, r.Title, r.Text)
+		article.Description = r.Description
+		articles = append(articles, article)
+	}
 
-	_, err := m.client.Send(msg)
-	if err != nil {
-		return errors.Wrap(err, "client.Send")
+	if _, err := m.client.Request(tgbotapi.InlineConfig{
+		InlineQueryID: queryID,
+		Results:       articles,
+		IsPersonal:    true,
+	}); err != nil {
+		return errors.Wrap(err, "client.Request")
 	}
+	return nil
+}
 
+func (m *MsgHandler) enqueue(msg outbox.Message) error {
+	if err := m.outbox.Enqueue(context.Background(), msg); err != nil {
+		return errors.Wrap(err, "outbox.Enqueue")
+	}
 	return nil
 }
 
 func (m *MsgHandler) IncomingCallback(ctx context.Context, model tg.MsgModel, update tgbotapi.Update) error {
+	// пока на нажатие не ответили, телеграм крутит на кнопке индикатор загрузки
+	if _, err := m.client.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "")); err != nil {
+		logs.Error("answer callback query error", zap.Error(err), zap.Int64("userId", update.CallbackQuery.From.ID))
+	}
+
+	var messageID int
+	if update.CallbackQuery.Message != nil {
+		messageID = update.CallbackQuery.Message.MessageID
+	}
+
 	return model.IncomingCallback(ctx, tg.Callback{
-		UserID:   update.CallbackQuery.From.ID,
-		UserName: update.CallbackQuery.From.UserName,
-		Data:     update.CallbackQuery.Data,
+		UserID:    update.CallbackQuery.From.ID,
+		UserName:  update.CallbackQuery.From.UserName,
+		Data:      update.CallbackQuery.Data,
+		MessageID: messageID,
+
+		LanguageCode: update.CallbackQuery.From.LanguageCode,
 	})
 }
 
@@ -81,5 +152,158 @@ func (m *MsgHandler) IncomingMessage(ctx context.Context, model tg.MsgModel, upd
 		Text:     update.Message.Text,
 		UserID:   update.Message.From.ID,
 		UserName: update.Message.From.UserName,
+
+		LanguageCode: update.Message.From.LanguageCode,
+	})
+}
+
+func (m *MsgHandler) IncomingInlineQuery(ctx context.Context, model tg.MsgModel, update tgbotapi.Update) error {
+	return model.IncomingInlineQuery(ctx, tg.InlineQuery{
+		ID:       update.InlineQuery.ID,
+		Query:    update.InlineQuery.Query,
+		UserID:   update.InlineQuery.From.ID,
+		UserName: update.InlineQuery.From.UserName,
+
+		LanguageCode: update.InlineQuery.From.LanguageCode,
 	})
 }
+
+func (m *MsgHandler) IncomingChosenInlineResult(ctx context.Context, model tg.MsgModel, update tgbotapi.Update) error {
+	return model.IncomingChosenInlineResult(ctx, tg.ChosenInlineResult{
+		ResultID: update.ChosenInlineResult.ResultID,
+		Query:    update.ChosenInlineResult.Query,
+		UserID:   update.ChosenInlineResult.From.ID,
+		UserName: update.ChosenInlineResult.From.UserName,
+
+		LanguageCode: update.ChosenInlineResult.From.LanguageCode,
+	})
+}
+
+// botSender отправляет сообщения из очереди в телеграм
+type botSender struct {
+	client *tgbotapi.BotAPI
+	ids    MessageIDStore
+}
+
+func (s *botSender) Send(msg outbox.Message) error {
+	var c tgbotapi.Chattable
+	switch msg.Kind {
+	case outbox.KindText:
+		c = tgbotapi.NewMessage(msg.ChatID, msg.Text)
+
+	case outbox.KindImage:
+		c = tgbotapi.NewPhoto(msg.ChatID, tgbotapi.FileBytes{Bytes: msg.Image})
+
+	case outbox.KindDocument:
+		c = tgbotapi.NewDocument(msg.ChatID, tgbotapi.FileBytes{Name: msg.FileName, Bytes: msg.Document})
+
+	case outbox.KindKeyboard:
+		m := tgbotapi.NewMessage(msg.ChatID, msg.Text)
+		m.ReplyMarkup = inlineKeyboard(msg.Keyboard)
+		c = m
+
+	case outbox.KindEdit:
+		return s.edit(msg)
+
+	default:
+		return errors.Wrapf(outbox.ErrUndeliverable, "unknown message kind %q", msg.Kind)
+	}
+
+	sent, err := s.client.Send(c)
+	if err != nil {
+		return sendError(err)
+	}
+	s.remember(msg, sent.MessageID)
+	return nil
+}
+
+// edit меняет сообщение msg.Ref. Если его id неизвестен или телеграм не дает его изменить (сообщение удалено
+// или слишком старое), вместо изменения отправляется новое сообщение
+func (s *botSender) edit(msg outbox.Message) error {
+	if msg.Ref == nil {
+		return errors.Wrap(outbox.ErrUndeliverable, "edit without message ref")
+	}
+
+	messageID := msg.Ref.ID
+	if messageID == 0 && msg.Ref.Key != "" {
+		var err error
+		messageID, err = s.ids.GetMessageID(context.Background(), msg.ChatID, msg.Ref.Key)
+		if err != nil {
+			logs.Error("get message id error", zap.Error(err), zap.Int64("chatId", msg.ChatID))
+		}
+	}
+	if messageID == 0 {
+		return s.Send(asNewMessage(msg))
+	}
+
+	edit := tgbotapi.NewEditMessageText(msg.ChatID, messageID, msg.Text)
+	if len(msg.Keyboard) > 0 {
+		keyboard := inlineKeyboard(msg.Keyboard)
+		edit.ReplyMarkup = &keyboard
+	}
+
+	if _, err := s.client.Send(edit); err != nil {
+		var tgErr *tgbotapi.Error
+		switch {
+		case !errors.As(err, &tgErr) || tgErr.Code != http.StatusBadRequest:
+			return sendError(err)
+		case strings.Contains(tgErr.Message, "message is not modified"):
+			// пользователь нажал ту же кнопку еще раз, менять нечего
+		default:
+			return s.Send(asNewMessage(msg))
+		}
+	}
+	s.remember(msg, messageID)
+	return nil
+}
+
+// remember запоминает id сообщения, если у него есть ключ
+func (s *botSender) remember(msg outbox.Message, messageID int) {
+	if msg.Ref == nil || msg.Ref.Key == "" {
+		return
+	}
+	if err := s.ids.SaveMessageID(context.Background(), msg.ChatID, msg.Ref.Key, messageID); err != nil {
+		logs.Error("save message id error", zap.Error(err), zap.Int64("chatId", msg.ChatID))
+	}
+}
+
+// asNewMessage новое сообщение вместо изменения старого, его id запоминается под тем же ключом
+func asNewMessage(msg outbox.Message) outbox.Message {
+	msg.Kind = outbox.KindText
+	if len(msg.Keyboard) > 0 {
+		msg.Kind = outbox.KindKeyboard
+	}
+	if msg.Ref != nil {
+		msg.Ref = &tg.MessageRef{Key: msg.Ref.Key}
+	}
+	return msg
+}
+
+func inlineKeyboard(buttons tg.Keyboard) tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	for _, row := range buttons {
+		markupRow := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+		for _, b := range row {
+			markupRow = append(markupRow, tgbotapi.NewInlineKeyboardButtonData(b.Text, b.Data))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, markupRow)
+	}
+	return keyboard
+}
+
+// sendError переводит ошибки телеграма в ошибки очереди: 429 - подождать, 400 и 403 - повторять бессмысленно
+func sendError(err error) error {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return errors.Wrap(err, "client.Send")
+	}
+
+	switch {
+	case tgErr.Code == http.StatusTooManyRequests && tgErr.RetryAfter > 0:
+		return &outbox.RetryAfterError{After: time.Duration(tgErr.RetryAfter) * time.Second}
+	case tgErr.Code == http.StatusBadRequest || tgErr.Code == http.StatusForbidden:
+		return errors.Wrap(outbox.ErrUndeliverable, tgErr.Message)
+	default:
+		return errors.Wrap(err, "client.Send")
+	}
+}
@@ -0,0 +1,121 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg/outbox"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+)
+
+// apiCall запрос к API телеграма: метод и id сообщения, которое меняется
+type apiCall struct {
+	method    string
+	messageID string
+}
+
+// memIDs хранилище id сообщений в памяти
+type memIDs map[string]int
+
+func (s memIDs) SaveMessageID(_ context.Context, chatID int64, key string, messageID int) error {
+	s[strconv.FormatInt(chatID, 10)+key] = messageID
+	return nil
+}
+
+func (s memIDs) GetMessageID(_ context.Context, chatID int64, key string) (int, error) {
+	return s[strconv.FormatInt(chatID, 10)+key], nil
+}
+
+// senderUp botSender, который ходит в поддельный API телеграма. editError - описание ошибки 400,
+// которой API отвечает на изменение сообщения, пустое - изменение проходит. Новые сообщения получают id 100
+func senderUp(t *testing.T, editError string) (*botSender, memIDs, *[]apiCall) {
+	var calls []apiCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		method := path.Base(r.URL.Path)
+
+		resp := tgbotapi.APIResponse{Ok: true}
+		switch method {
+		case "getMe":
+			resp.Result = json.RawMessage(`{"id":1,"is_bot":true,"username":"bot"}`)
+		case "sendMessage":
+			resp.Result = json.RawMessage(`{"message_id":100,"chat":{"id":123}}`)
+		case "editMessageText":
+			if editError != "" {
+				resp = tgbotapi.APIResponse{ErrorCode: http.StatusBadRequest, Description: editError}
+				break
+			}
+			resp.Result = json.RawMessage(`{"message_id":` + r.Form.Get("message_id") + `,"chat":{"id":123}}`)
+		}
+		if method != "getMe" {
+			calls = append(calls, apiCall{method: method, messageID: r.Form.Get("message_id")})
+		}
+
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", server.URL+"/bot%s/%s")
+	assert.NoError(t, err)
+
+	ids := memIDs{}
+	return &botSender{client: client, ids: ids}, ids, &calls
+}
+
+func Test_BotSender_Edit(t *testing.T) {
+	logs.InitLogger()
+
+	edit := func(ref tg.MessageRef) outbox.Message {
+		return outbox.Message{ChatID: 123, Kind: outbox.KindEdit, Text: "Отчет готов", Ref: &ref}
+	}
+
+	t.Run("сообщение по ключу", func(t *testing.T) {
+		sender, ids, calls := senderUp(t, "")
+		ids["123report"] = 42
+
+		assert.NoError(t, sender.Send(edit(tg.MessageRef{Key: "report"})))
+		assert.Equal(t, []apiCall{{method: "editMessageText", messageID: "42"}}, *calls)
+	})
+
+	t.Run("нажатое сообщение запоминается под ключом", func(t *testing.T) {
+		sender, ids, calls := senderUp(t, "")
+
+		assert.NoError(t, sender.Send(edit(tg.MessageRef{ID: 42, Key: "report"})))
+		assert.Equal(t, []apiCall{{method: "editMessageText", messageID: "42"}}, *calls)
+		assert.Equal(t, 42, ids["123report"])
+	})
+
+	t.Run("id неизвестен - новое сообщение", func(t *testing.T) {
+		sender, ids, calls := senderUp(t, "")
+
+		assert.NoError(t, sender.Send(edit(tg.MessageRef{Key: "report"})))
+		assert.Equal(t, []apiCall{{method: "sendMessage"}}, *calls)
+		assert.Equal(t, 100, ids["123report"])
+	})
+
+	t.Run("сообщение удалено - новое сообщение", func(t *testing.T) {
+		sender, ids, calls := senderUp(t, "Bad Request: message to edit not found")
+		ids["123report"] = 42
+
+		assert.NoError(t, sender.Send(edit(tg.MessageRef{Key: "report"})))
+		assert.Equal(t, []apiCall{{method: "editMessageText", messageID: "42"}, {method: "sendMessage"}}, *calls)
+		assert.Equal(t, 100, ids["123report"])
+	})
+
+	t.Run("текст не изменился", func(t *testing.T) {
+		sender, _, calls := senderUp(t, "Bad Request: message is not modified")
+
+		assert.NoError(t, sender.Send(edit(tg.MessageRef{ID: 42})))
+		assert.Equal(t, []apiCall{{method: "editMessageText", messageID: "42"}}, *calls)
+	})
+}
@@ -0,0 +1,423 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// ограничения телеграма: не больше 30 сообщений в секунду всего, в личный чат - около одного в секунду
+	// (короткие всплески допускаются), в группу - не больше 20 в минуту
+	globalRate  = 30
+	globalBurst = 30
+	chatRate    = 1
+	groupRate   = 20.0 / 60
+	chatBurst   = 3
+
+	// maxQueueSize сколько сообщений может ждать отправки, больше - отбрасываются
+	maxQueueSize = 10000
+	// maxAttempts сколько раз пробовать отправить сообщение, если телеграм отвечает ошибкой (кроме 429)
+	maxAttempts = 5
+
+	// idleWait как долго воркер ждет новых сообщений, если будить его некому
+	idleWait = time.Minute
+	// deleteTimeout сколько ждем хранилище, удаляя отправленное сообщение
+	deleteTimeout = 5 * time.Second
+)
+
+var (
+	ErrQueueFull = errors.New("outbox queue is full")
+	// ErrUndeliverable сообщение доставить невозможно (бот заблокирован, чат удален), повторять бессмысленно
+	ErrUndeliverable = errors.New("message is undeliverable")
+)
+
+// RetryAfterError телеграм ответил 429 и просит подождать перед следующей отправкой в этот чат
+type RetryAfterError struct {
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %s", e.After)
+}
+
+// Kind тип исходящего сообщения
+type Kind string
+
+const (
+	KindText     Kind = "text"
+	KindImage    Kind = "image"
+	KindKeyboard Kind = "keyboard"
+	// KindDocument файл FileName с содержимым Document
+	KindDocument Kind = "document"
+	// KindEdit изменение текста (и кнопок) уже отправленного сообщения Ref
+	KindEdit Kind = "edit"
+)
+
+// Message исходящее сообщение. Хранится в json, чтобы недоставленные сообщения пережили перезапуск
+type Message struct {
+	ChatID   int64       `json:"chatId"`
+	Kind     Kind        `json:"kind"`
+	Text     string      `json:"text,omitempty"`
+	Image    []byte      `json:"image,omitempty"`
+	Document []byte      `json:"document,omitempty"`
+	FileName string      `json:"fileName,omitempty"`
+	Keyboard tg.Keyboard `json:"keyboard,omitempty"`
+	// Ref у изменения - какое сообщение менять, у нового сообщения - под каким ключом запомнить его id
+	Ref *tg.MessageRef `json:"ref,omitempty"`
+}
+
+// Sender отправляет сообщение в телеграм. На 429 должен вернуть *RetryAfterError,
+// на ошибки, которые не исправятся повтором, - ErrUndeliverable
+type Sender interface {
+	Send(msg Message) error
+}
+
+// Store хранилище недоставленных сообщений
+type Store interface {
+	SaveOutgoing(ctx context.Context, outbox, id string, msg []byte) error
+	DeleteOutgoing(ctx context.Context, outbox, id string) error
+	LoadOutgoing(ctx context.Context, outbox string) (map[string][]byte, error)
+}
+
+type item struct {
+	id       string // id растут со временем, по ним восстанавливается порядок сообщений
+	msg      Message
+	attempts int
+}
+
+type chatQueue struct {
+	items       []*item
+	limit       bucket
+	pausedUntil time.Time // до этого момента в чат ничего не отправляется (429 или ошибка)
+	busy        bool      // сообщение из этого чата сейчас отправляется, следующее ждет, чтобы не нарушить порядок
+}
+
+// Outbox очередь исходящих сообщений: соблюдает ограничения телеграма на частоту отправки, повторяет отправку
+// после 429 через retry_after и хранит недоставленные сообщения в редисе до перезапуска. Сообщения одного чата
+// отправляются по порядку, разные чаты - в порядке очереди
+type Outbox struct {
+	name   string
+	sender Sender
+	store  Store
+	now    func() time.Time
+
+	mu      sync.Mutex
+	chats   map[int64]*chatQueue
+	size    int
+	global  bucket
+	lastSeq int64
+
+	wake chan struct{}
+}
+
+// New очередь исходящих сообщений. name - имя очереди в хранилище, у каждой реплики бота оно свое
+func New(name string, sender Sender, store Store) *Outbox {
+	return &Outbox{
+		name:   name,
+		sender: sender,
+		store:  store,
+		now:    time.Now,
+		chats:  make(map[int64]*chatQueue),
+		global: bucket{rate: globalRate, burst: globalBurst},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Enqueue ставит сообщение в очередь на отправку. Ошибка только если очередь переполнена: если недоступно
+// хранилище, сообщение все равно будет отправлено, но не переживет перезапуск
+func (o *Outbox) Enqueue(ctx context.Context, msg Message) error {
+	o.mu.Lock()
+	if o.size >= maxQueueSize {
+		o.mu.Unlock()
+		metrics.OutboxDropped.WithLabelValues(metrics.DropReasonQueueFull).Inc()
+		return ErrQueueFull
+	}
+	it := &item{id: o.nextID(), msg: msg}
+	o.mu.Unlock()
+
+	// сохраняем до постановки в очередь, иначе сообщение могут успеть отправить и удалить раньше, чем сохранить
+	if data, err := json.Marshal(msg); err != nil {
+		logs.Error("outbox marshal error", zap.Error(err))
+	} else if err = o.store.SaveOutgoing(ctx, o.name, it.id, data); err != nil {
+		logs.Error("outbox save error", zap.Error(err))
+	}
+
+	o.mu.Lock()
+	o.push(it)
+	o.mu.Unlock()
+
+	o.signal()
+	return nil
+}
+
+// Restore возвращает в очередь сообщения, которые не успели отправить до перезапуска
+func (o *Outbox) Restore(ctx context.Context) error {
+	saved, err := o.store.LoadOutgoing(ctx, o.name)
+	if err != nil {
+		return errors.Wrap(err, "store.LoadOutgoing")
+	}
+
+	ids := make([]string, 0, len(saved))
+	for id := range saved {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	o.mu.Lock()
+	for _, id := range ids {
+		var msg Message
+		if err = json.Unmarshal(saved[id], &msg); err != nil {
+			logs.Error("outbox unmarshal error", zap.Error(err), zap.String("id", id))
+			continue
+		}
+		o.push(&item{id: id, msg: msg})
+
+		// новые сообщения должны встать после восстановленных
+		if seq, err := strconv.ParseInt(id, 10, 64); err == nil && seq > o.lastSeq {
+			o.lastSeq = seq
+		}
+	}
+	o.mu.Unlock()
+
+	o.signal()
+	return nil
+}
+
+// Run отправляет сообщения в workers потоков, пока не отменен ctx. Неотправленные сообщения остаются в хранилище
+func (o *Outbox) Run(ctx context.Context, workers int) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (o *Outbox) work(ctx context.Context) {
+	for {
+		chatID, it, wait := o.next()
+		if it == nil {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-o.wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+			continue
+		}
+
+		o.done(chatID, it, o.sender.Send(it.msg))
+	}
+}
+
+// next выбирает самое старое сообщение среди чатов, в которые сейчас можно отправлять. Если отправлять нечего,
+// возвращает, через сколько стоит проверить снова
+func (o *Outbox) next() (int64, *item, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	wait := idleWait
+
+	var (
+		bestID int64
+		best   *chatQueue
+	)
+	for chatID, c := range o.chats {
+		if c.busy {
+			continue
+		}
+		if len(c.items) == 0 {
+			// лимит чата восстановился полностью, помнить его больше не нужно
+			if c.limit.full(now) {
+				delete(o.chats, chatID)
+			}
+			continue
+		}
+		if c.pausedUntil.After(now) {
+			wait = minDuration(wait, c.pausedUntil.Sub(now))
+			continue
+		}
+		if w := c.limit.wait(now); w > 0 {
+			wait = minDuration(wait, w)
+			continue
+		}
+		if best == nil || c.items[0].id < best.items[0].id {
+			bestID, best = chatID, c
+		}
+	}
+
+	if best == nil {
+		return 0, nil, wait
+	}
+	if w := o.global.wait(now); w > 0 {
+		return 0, nil, w
+	}
+
+	o.global.take(now)
+	best.limit.take(now)
+	best.busy = true
+	return bestID, best.items[0], 0
+}
+
+// done обрабатывает результат отправки сообщения it из чата chatID
+func (o *Outbox) done(chatID int64, it *item, err error) {
+	o.mu.Lock()
+	c := o.chats[chatID]
+	c.busy = false
+
+	var (
+		remove     bool
+		retryAfter *RetryAfterError
+		now        = o.now()
+	)
+	switch {
+	case err == nil:
+		remove = true
+
+	case errors.As(err, &retryAfter):
+		// телеграм сам сказал, сколько ждать, попытка не считается
+		c.pausedUntil = now.Add(retryAfter.After)
+		metrics.OutboxRetries.WithLabelValues(metrics.RetryReasonRateLimit).Inc()
+
+	case errors.Is(err, ErrUndeliverable):
+		remove = true
+		metrics.OutboxDropped.WithLabelValues(metrics.DropReasonUndeliverable).Inc()
+		logs.Error("outbox message undeliverable", zap.Error(err), zap.Int64("chatId", chatID))
+
+	default:
+		it.attempts++
+		if it.attempts >= maxAttempts {
+			remove = true
+			metrics.OutboxDropped.WithLabelValues(metrics.DropReasonAttempts).Inc()
+			logs.Error("outbox message dropped after retries", zap.Error(err), zap.Int64("chatId", chatID))
+			break
+		}
+		// 1, 4, 9, 16 секунд
+		c.pausedUntil = now.Add(time.Duration(it.attempts*it.attempts) * time.Second)
+		metrics.OutboxRetries.WithLabelValues(metrics.RetryReasonError).Inc()
+	}
+
+	if remove {
+		c.items = c.items[1:]
+		o.size--
+		metrics.OutboxQueueLength.Dec()
+	}
+	o.mu.Unlock()
+
+	if remove {
+		// контекст Run при остановке бота уже отменен, а отправленное сообщение все равно нужно удалить,
+		// иначе после перезапуска оно уйдет повторно
+		ctx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+		if err = o.store.DeleteOutgoing(ctx, o.name, it.id); err != nil {
+			logs.Error("outbox delete error", zap.Error(err))
+		}
+		cancel()
+	}
+
+	// чат освободился, его следующее сообщение может забрать другой воркер
+	o.signal()
+}
+
+func (o *Outbox) push(it *item) {
+	c, ok := o.chats[it.msg.ChatID]
+	if !ok {
+		c = &chatQueue{limit: bucket{rate: chatRate, burst: chatBurst}}
+		// отрицательные id у групп, у них лимит строже
+		if it.msg.ChatID < 0 {
+			c.limit.rate = groupRate
+		}
+		o.chats[it.msg.ChatID] = c
+	}
+	// сообщения одного чата идут по возрастанию id, даже если сохранение в хранилище поменяло их местами.
+	// Сообщение, которое сейчас отправляется, не сдвигается
+	i := len(c.items)
+	for i > 0 && c.items[i-1].id > it.id && !(i == 1 && c.busy) {
+		i--
+	}
+	c.items = append(c.items, nil)
+	copy(c.items[i+1:], c.items[i:])
+	c.items[i] = it
+	o.size++
+	metrics.OutboxQueueLength.Inc()
+}
+
+// nextID id следующего сообщения: растет вместе со временем, даже если часы совпали
+func (o *Outbox) nextID() string {
+	seq := o.now().UnixNano()
+	if seq <= o.lastSeq {
+		seq = o.lastSeq + 1
+	}
+	o.lastSeq = seq
+	return fmt.Sprintf("%020d", seq)
+}
+
+func (o *Outbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// bucket корзина токенов: rate токенов в секунду, не больше burst
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.last.IsZero() {
+		b.tokens = b.burst
+	} else if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// wait через сколько появится токен
+func (b *bucket) wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *bucket) take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
+
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,326 @@
+//go:build test_all || unit_test
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+)
+
+type memStore struct {
+	mu   sync.Mutex
+	msgs map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{msgs: make(map[string][]byte)}
+}
+
+func (s *memStore) SaveOutgoing(_ context.Context, outbox, id string, msg []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs[outbox+id] = msg
+	return nil
+}
+
+func (s *memStore) DeleteOutgoing(ctx context.Context, outbox, id string) error {
+	// как и редис, с отмененным контекстом ничего не удаляет
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.msgs, outbox+id)
+	return nil
+}
+
+func (s *memStore) LoadOutgoing(_ context.Context, outbox string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make(map[string][]byte)
+	for key, msg := range s.msgs {
+		if len(key) > len(outbox) && key[:len(outbox)] == outbox {
+			res[key[len(outbox):]] = msg
+		}
+	}
+	return res, nil
+}
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (s *recordingSender) Send(msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// testOutbox очередь с остановленными часами, время двигает сам тест
+func testOutbox(store Store) (*Outbox, *time.Time) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	o := New("test", &recordingSender{}, store)
+	o.now = func() time.Time { return now }
+	return o, &now
+}
+
+func textMsg(chatID int64, text string) Message {
+	return Message{ChatID: chatID, Kind: KindText, Text: text}
+}
+
+func Test_Outbox_ChatLimit(t *testing.T) {
+	o, now := testOutbox(newMemStore())
+	for _, txt := range []string{"1", "2", "3", "4"} {
+		assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, txt)))
+	}
+
+	// всплеск из chatBurst сообщений отправляется сразу
+	for i := 0; i < chatBurst; i++ {
+		chatID, it, _ := o.next()
+		assert.NotNil(t, it)
+		o.done(chatID, it, nil)
+	}
+
+	// а следующее - только когда восстановится лимит чата
+	_, it, wait := o.next()
+	assert.Nil(t, it)
+	assert.Equal(t, time.Second, wait)
+
+	*now = now.Add(time.Second)
+	_, it, _ = o.next()
+	if assert.NotNil(t, it) {
+		assert.Equal(t, "4", it.msg.Text)
+	}
+}
+
+func Test_Outbox_GroupLimit(t *testing.T) {
+	o, _ := testOutbox(newMemStore())
+	for _, txt := range []string{"1", "2", "3", "4"} {
+		assert.NoError(t, o.Enqueue(context.Background(), textMsg(-100, txt)))
+	}
+
+	for i := 0; i < chatBurst; i++ {
+		chatID, it, _ := o.next()
+		o.done(chatID, it, nil)
+	}
+
+	// в группу можно 20 сообщений в минуту
+	_, it, wait := o.next()
+	assert.Nil(t, it)
+	assert.Equal(t, 3*time.Second, wait)
+}
+
+func Test_Outbox_GlobalLimit(t *testing.T) {
+	o, now := testOutbox(newMemStore())
+	for chatID := int64(1); chatID <= globalBurst+1; chatID++ {
+		assert.NoError(t, o.Enqueue(context.Background(), textMsg(chatID, "привет")))
+	}
+
+	for i := 0; i < globalBurst; i++ {
+		chatID, it, _ := o.next()
+		assert.NotNil(t, it)
+		o.done(chatID, it, nil)
+	}
+
+	_, it, wait := o.next()
+	assert.Nil(t, it)
+	assert.Equal(t, time.Second/globalRate, wait)
+
+	*now = now.Add(wait)
+	chatID, it, _ := o.next()
+	assert.NotNil(t, it)
+	assert.Equal(t, int64(globalBurst+1), chatID)
+}
+
+func Test_Outbox_OldestChatFirst(t *testing.T) {
+	o, _ := testOutbox(newMemStore())
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(2, "первое")))
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "второе")))
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(2, "третье")))
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		chatID, it, _ := o.next()
+		got = append(got, it.msg.Text)
+		o.done(chatID, it, nil)
+	}
+	assert.Equal(t, []string{"первое", "второе", "третье"}, got)
+}
+
+func Test_Outbox_BusyChatWaits(t *testing.T) {
+	o, _ := testOutbox(newMemStore())
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "первое")))
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "второе")))
+
+	chatID, first, _ := o.next()
+	// пока первое сообщение отправляется, второе не должен забрать другой воркер
+	_, it, _ := o.next()
+	assert.Nil(t, it)
+
+	o.done(chatID, first, nil)
+	_, it, _ = o.next()
+	if assert.NotNil(t, it) {
+		assert.Equal(t, "второе", it.msg.Text)
+	}
+}
+
+func Test_Outbox_RetryAfter(t *testing.T) {
+	logs.InitLogger()
+	o, now := testOutbox(newMemStore())
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "привет")))
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(2, "пока")))
+
+	chatID, it, _ := o.next()
+	o.done(chatID, it, &RetryAfterError{After: 5 * time.Second})
+
+	// другие чаты 429 не ждут
+	chatID, it, _ = o.next()
+	assert.Equal(t, int64(2), chatID)
+	o.done(chatID, it, nil)
+
+	_, it, wait := o.next()
+	assert.Nil(t, it)
+	assert.Equal(t, 5*time.Second, wait)
+
+	*now = now.Add(5 * time.Second)
+	chatID, it, _ = o.next()
+	assert.Equal(t, int64(1), chatID)
+	if assert.NotNil(t, it) {
+		assert.Equal(t, "привет", it.msg.Text)
+		assert.Equal(t, 0, it.attempts)
+	}
+}
+
+func Test_Outbox_Undeliverable(t *testing.T) {
+	logs.InitLogger()
+	store := newMemStore()
+	o, _ := testOutbox(store)
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "привет")))
+
+	chatID, it, _ := o.next()
+	o.done(chatID, it, errors.Wrap(ErrUndeliverable, "bot was blocked by the user"))
+
+	assert.Equal(t, 0, o.size)
+	assert.Empty(t, store.msgs)
+}
+
+func Test_Outbox_DropAfterAttempts(t *testing.T) {
+	logs.InitLogger()
+	store := newMemStore()
+	o, now := testOutbox(store)
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "привет")))
+
+	for i := 1; i <= maxAttempts; i++ {
+		chatID, it, _ := o.next()
+		if !assert.NotNil(t, it, "attempt %d", i) {
+			return
+		}
+		o.done(chatID, it, errors.New("connection reset"))
+		*now = now.Add(time.Duration(i*i) * time.Second)
+	}
+
+	assert.Equal(t, 0, o.size)
+	assert.Empty(t, store.msgs)
+}
+
+func Test_Outbox_Restore(t *testing.T) {
+	store := newMemStore()
+	before, _ := testOutbox(store)
+	for _, txt := range []string{"1", "2", "3"} {
+		assert.NoError(t, before.Enqueue(context.Background(), textMsg(1, txt)))
+	}
+	// первое успели отправить до перезапуска
+	chatID, it, _ := before.next()
+	before.done(chatID, it, nil)
+
+	after, _ := testOutbox(store)
+	assert.NoError(t, after.Restore(context.Background()))
+	assert.NoError(t, after.Enqueue(context.Background(), textMsg(1, "4")))
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		chatID, it, _ := after.next()
+		got = append(got, it.msg.Text)
+		after.done(chatID, it, nil)
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, got)
+	assert.Empty(t, store.msgs)
+}
+
+func Test_Outbox_Run(t *testing.T) {
+	store := newMemStore()
+	sender := &recordingSender{}
+	o := New("test", sender, store)
+
+	for chatID := int64(1); chatID <= 3; chatID++ {
+		for _, txt := range []string{"1", "2", "3"} {
+			assert.NoError(t, o.Enqueue(context.Background(), textMsg(chatID, txt)))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		o.Run(ctx, 4)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		sender.mu.Lock()
+		defer sender.mu.Unlock()
+		return len(sender.sent) == 9
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	got := make(map[int64][]string)
+	for _, msg := range sender.sent {
+		got[msg.ChatID] = append(got[msg.ChatID], msg.Text)
+	}
+	for chatID := int64(1); chatID <= 3; chatID++ {
+		assert.Equal(t, []string{"1", "2", "3"}, got[chatID])
+	}
+	assert.Empty(t, store.msgs)
+}
+
+// cancelingSender останавливает очередь во время отправки
+type cancelingSender struct {
+	cancel context.CancelFunc
+}
+
+func (s *cancelingSender) Send(Message) error {
+	s.cancel()
+	return nil
+}
+
+func Test_Outbox_DeleteAfterStop(t *testing.T) {
+	store := newMemStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	o := New("test", &cancelingSender{cancel: cancel}, store)
+
+	assert.NoError(t, o.Enqueue(context.Background(), textMsg(1, "1")))
+	o.Run(ctx, 1)
+
+	// сообщение отправлено, пока бот останавливался: после перезапуска отправлять его заново не нужно
+	assert.Empty(t, store.msgs)
+}
+
+func Test_Message_JSON(t *testing.T) {
+	msg := Message{ChatID: 1, Kind: KindKeyboard, Text: "выбери", Keyboard: tg.Keyboard{{tg.NewButton("да", "yes"), tg.NewButton("нет", "no")}}}
+	data, err := json.Marshal(msg)
+	assert.NoError(t, err)
+
+	var got Message
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, msg, got)
+}
@@ -0,0 +1,169 @@
+package tg
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"go.uber.org/zap"
+)
+
+const (
+	// ModePolling получение апдейтов через long polling (по умолчанию)
+	ModePolling = "polling"
+	// ModeWebhook получение апдейтов через вебхук
+	ModeWebhook = "webhook"
+
+	// secretTokenHeader заголовок, в котором телеграм присылает секрет, указанный при регистрации вебхука
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// maxUpdateSize апдейты телеграма небольшие, все, что больше, считаем мусором
+	maxUpdateSize = 1 << 20
+
+	shutdownTimeout = time.Second * 10
+	// dispatchTimeout сколько запрос ждет места в заполненной очереди, прежде чем ответить 503
+	dispatchTimeout = time.Second * 5
+	// unmarkTimeout на снятие отметки с апдейта, запрос к этому моменту уже может быть отменен
+	unmarkTimeout = time.Second
+)
+
+type WebhookConfigGetter interface {
+	WebhookURL() string
+	WebhookPort() int
+	WebhookSecret() string
+}
+
+type webhookHandler struct {
+	dispatcher   *Dispatcher
+	updatesStore UpdatesStore
+	secret       string
+}
+
+// NewWebhookHandler http обработчик вебхука телеграма: проверяет секрет, отбрасывает повторно доставленные апдейты
+// и передает апдейты в dispatcher, то есть в ту же цепочку MsgHandler/MsgModel, что и long polling.
+// Если апдейт не удалось поставить в очередь, отметка с него снимается, а телеграм получает 503 и пришлет его снова
+func NewWebhookHandler(dispatcher *Dispatcher, updatesStore UpdatesStore, secret string) http.Handler {
+	return &webhookHandler{
+		dispatcher:   dispatcher,
+		updatesStore: updatesStore,
+		secret:       secret,
+	}
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(h.secret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	marked, err := h.updatesStore.MarkUpdateProcessed(r.Context(), update.UpdateID)
+	if err != nil {
+		// без хранилища апдейт может обработаться дважды, но это лучше, чем не обработать его совсем
+		logs.Error("mark update processed error", zap.Error(err), zap.Int("update_id", update.UpdateID))
+	} else if !marked {
+		// повторная доставка, апдейт уже обработан этой или другой репликой
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dispatchTimeout)
+	defer cancel()
+	if err = h.dispatcher.Dispatch(ctx, update); err != nil {
+		logs.Error("dispatch update error", zap.Error(err), zap.Int("update_id", update.UpdateID))
+		if marked {
+			h.unmark(update.UpdateID)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	// на любой ответ кроме 2xx телеграм будет присылать апдейт повторно
+	w.WriteHeader(http.StatusOK)
+}
+
+// unmark снимает отметку с апдейта в отдельном контексте: контекст запроса мог быть отменен
+func (h *webhookHandler) unmark(updateID int) {
+	ctx, cancel := context.WithTimeout(context.Background(), unmarkTimeout)
+	defer cancel()
+
+	if err := h.updatesStore.UnmarkUpdate(ctx, updateID); err != nil {
+		logs.Error("unmark update error", zap.Error(err), zap.Int("update_id", updateID))
+	}
+}
+
+// ListenWebhook регистрирует вебхук в телеграме и принимает апдейты по http, пока не отменен ctx.
+// В отличие от long polling так можно запустить несколько реплик бота за балансировщиком
+func (c *Client) ListenWebhook(ctx context.Context, msgModel MsgModel, updatesStore UpdatesStore, conf WebhookConfigGetter) error {
+	// без секрета любой, кто знает адрес вебхука, сможет писать от имени пользователей
+	if conf.WebhookSecret() == "" {
+		return errors.New("webhook secret is empty")
+	}
+
+	webhookURL, err := url.Parse(conf.WebhookURL())
+	if err != nil {
+		return errors.Wrap(err, "url.Parse")
+	}
+	if webhookURL.Path == "" {
+		webhookURL.Path = "/"
+	}
+
+	// в tgbotapi.WebhookConfig нет поля для секрета, поэтому параметры собираются вручную
+	params := tgbotapi.Params{"url": webhookURL.String()}
+	params.AddNonEmpty("secret_token", conf.WebhookSecret())
+	if _, err = c.client.MakeRequest("setWebhook", params); err != nil {
+		return errors.Wrap(err, "setWebhook")
+	}
+
+	dispatcher := NewDispatcher(ctx, c.workers, c.queueSize, c.msgHandler, msgModel)
+	// defer выполнится после остановки сервера, поэтому к этому моменту новых апдейтов уже не будет,
+	// а Stop дождется обработки принятых
+	defer dispatcher.Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle(webhookURL.Path, NewWebhookHandler(dispatcher, updatesStore, conf.WebhookSecret()))
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", conf.WebhookPort()),
+		Handler:           mux,
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	logs.Info("starting webhook server", zap.Int("port", conf.WebhookPort()), zap.String("path", webhookURL.Path))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		return errors.Wrap(err, "server.ListenAndServe")
+	case <-ctx.Done():
+	}
+
+	// Shutdown дожидается запросов, которые сейчас ставят апдейты в очередь, и только после этого
+	// останавливаются воркеры
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		return errors.Wrap(err, "server.Shutdown")
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+//go:build test_all || unit_test
+
+package tg_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg/_mocks"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+)
+
+const secret = "somesecret"
+
+// postUpdate отправляет в вебхук записанный апдейт из test_data/tg_updates, как это делает телеграм
+func postUpdate(t *testing.T, url, file, token string) int {
+	body, err := os.ReadFile("./../../../test_data/tg_updates/" + file)
+	assert.NoError(t, err)
+
+	return post(t, url, string(body), token)
+}
+
+func post(t *testing.T, url, body, token string) int {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+// webhookUp поднимает вебхук на локальном http сервере. Апдейты обрабатываются воркерами асинхронно,
+// поэтому перед проверкой моков нужно остановить dispatcher - он дождется обработки
+func webhookUp(t *testing.T) (*httptest.Server, *tg.Dispatcher, *mocks.MockMsgHandler, *mocks.MockMsgModel, *mocks.MockUpdatesStore) {
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+	store := mocks.NewMockUpdatesStore(ctrl)
+
+	dispatcher := tg.NewDispatcher(context.Background(), 2, 10, msgHandler, msgModel)
+	t.Cleanup(dispatcher.Stop)
+
+	server := httptest.NewServer(tg.NewWebhookHandler(dispatcher, store, secret))
+	t.Cleanup(server.Close)
+
+	return server, dispatcher, msgHandler, msgModel, store
+}
+
+func Test_Webhook_Message(t *testing.T) {
+	server, dispatcher, msgHandler, msgModel, store := webhookUp(t)
+
+	store.EXPECT().MarkUpdateProcessed(gomock.Any(), 815603401).Return(true, nil)
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ tg.MsgModel, update tgbotapi.Update) error {
+			assert.Equal(t, "кофе 250", update.Message.Text)
+			assert.Equal(t, int64(123), update.Message.From.ID)
+			assert.Equal(t, "ru", update.Message.From.LanguageCode)
+			return nil
+		})
+
+	assert.Equal(t, http.StatusOK, postUpdate(t, server.URL, "message.json", secret))
+	dispatcher.Stop()
+}
+
+func Test_Webhook_Callback(t *testing.T) {
+	server, dispatcher, msgHandler, msgModel, store := webhookUp(t)
+
+	store.EXPECT().MarkUpdateProcessed(gomock.Any(), 815603402).Return(true, nil)
+	msgHandler.EXPECT().IncomingCallback(gomock.Any(), msgModel, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ tg.MsgModel, update tgbotapi.Update) error {
+			assert.Equal(t, "Отмена", update.CallbackQuery.Data)
+			return nil
+		})
+
+	assert.Equal(t, http.StatusOK, postUpdate(t, server.URL, "callback.json", secret))
+	dispatcher.Stop()
+}
+
+func Test_Webhook_DuplicateUpdate(t *testing.T) {
+	server, dispatcher, msgHandler, msgModel, store := webhookUp(t)
+
+	// первая доставка обрабатывается, повторная (в эту или другую реплику) - нет
+	gomock.InOrder(
+		store.EXPECT().MarkUpdateProcessed(gomock.Any(), 815603401).Return(true, nil),
+		store.EXPECT().MarkUpdateProcessed(gomock.Any(), 815603401).Return(false, nil),
+	)
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).Return(nil).Times(1)
+
+	assert.Equal(t, http.StatusOK, postUpdate(t, server.URL, "message.json", secret))
+	// на повтор тоже отвечаем 200, иначе телеграм продолжит его присылать
+	assert.Equal(t, http.StatusOK, postUpdate(t, server.URL, "message.json", secret))
+	dispatcher.Stop()
+}
+
+func Test_Webhook_StoreError_ShouldProcessUpdate(t *testing.T) {
+	// ошибка хранилища пишется в лог
+	logs.InitLogger()
+	server, dispatcher, msgHandler, msgModel, store := webhookUp(t)
+
+	store.EXPECT().MarkUpdateProcessed(gomock.Any(), 815603401).Return(false, errors.New("redis is down"))
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).Return(nil)
+
+	assert.Equal(t, http.StatusOK, postUpdate(t, server.URL, "message.json", secret))
+	dispatcher.Stop()
+}
+
+func Test_Webhook_DispatcherStopped(t *testing.T) {
+	// ошибка постановки в очередь пишется в лог
+	logs.InitLogger()
+	server, dispatcher, _, _, store := webhookUp(t)
+	dispatcher.Stop()
+
+	store.EXPECT().MarkUpdateProcessed(gomock.Any(), 815603401).Return(true, nil)
+	store.EXPECT().UnmarkUpdate(gomock.Any(), 815603401).Return(nil)
+
+	assert.Equal(t, http.StatusServiceUnavailable, postUpdate(t, server.URL, "message.json", secret))
+}
+
+// memoryUpdates хранилище отметок в памяти, как общий для реплик редис
+type memoryUpdates struct {
+	mu     sync.Mutex
+	marked map[int]bool
+}
+
+func (s *memoryUpdates) MarkUpdateProcessed(_ context.Context, updateID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.marked[updateID] {
+		return false, nil
+	}
+	s.marked[updateID] = true
+	return true, nil
+}
+
+func (s *memoryUpdates) UnmarkUpdate(_ context.Context, updateID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.marked, updateID)
+	return nil
+}
+
+func Test_Webhook_DispatchFailed_ShouldProcessRetry(t *testing.T) {
+	// ошибка постановки в очередь пишется в лог
+	logs.InitLogger()
+	ctrl := gomock.NewController(t)
+	msgHandler := mocks.NewMockMsgHandler(ctrl)
+	msgModel := mocks.NewMockMsgModel(ctrl)
+	store := &memoryUpdates{marked: map[int]bool{}}
+
+	// реплика, которая не смогла поставить апдейт в очередь
+	stopped := tg.NewDispatcher(context.Background(), 1, 1, msgHandler, msgModel)
+	stopped.Stop()
+	failed := httptest.NewServer(tg.NewWebhookHandler(stopped, store, secret))
+	t.Cleanup(failed.Close)
+
+	dispatcher := tg.NewDispatcher(context.Background(), 2, 10, msgHandler, msgModel)
+	t.Cleanup(dispatcher.Stop)
+	server := httptest.NewServer(tg.NewWebhookHandler(dispatcher, store, secret))
+	t.Cleanup(server.Close)
+
+	msgHandler.EXPECT().IncomingMessage(gomock.Any(), msgModel, gomock.Any()).Return(nil).Times(1)
+
+	assert.Equal(t, http.StatusServiceUnavailable, postUpdate(t, failed.URL, "message.json", secret))
+	// телеграм присылает апдейт повторно, и он обрабатывается, а не считается дублем
+	assert.Equal(t, http.StatusOK, postUpdate(t, server.URL, "message.json", secret))
+	dispatcher.Stop()
+}
+
+func Test_Webhook_InvalidRequests(t *testing.T) {
+	server, _, _, _, _ := webhookUp(t)
+
+	t.Run("неверный секрет", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, postUpdate(t, server.URL, "message.json", "wrong"))
+	})
+
+	t.Run("без секрета", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, postUpdate(t, server.URL, "message.json", ""))
+	})
+
+	t.Run("не json", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post(t, server.URL, "not json", secret))
+	})
+
+	t.Run("не POST", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
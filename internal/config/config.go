@@ -21,6 +21,32 @@ type Config struct {
 
 	GRPCHostMessages string `yaml:"grpc-host-messages"`
 	GRPCPortMessages int    `yaml:"grpc-port-messages"`
+
+	// TgMode способ получения апдейтов телеграма: polling (по умолчанию) или webhook
+	TgMode        string `yaml:"tg-mode"`
+	WebhookURL    string `yaml:"webhook-url"` // публичный адрес вебхука, например https://bot.example.com/tg/webhook
+	WebhookPort   int    `yaml:"webhook-port"`
+	WebhookSecret string `yaml:"webhook-secret"` // телеграм присылает его в заголовке каждого запроса
+
+	// TgWorkers сколько апдейтов обрабатывается параллельно, TgQueueSize - размер очереди каждого воркера.
+	// Если не заданы, берутся значения по умолчанию
+	TgWorkers   int `yaml:"tg-workers"`
+	TgQueueSize int `yaml:"tg-queue-size"`
+
+	// ReplicaID имя реплики бота, под ним в редисе хранится ее очередь исходящих сообщений. Должно быть своим
+	// у каждой реплики и не меняться между перезапусками, иначе недоставленные сообщения останутся в старой очереди.
+	// Если не задано, берется значение по умолчанию: так можно запускать одну реплику
+	ReplicaID string `yaml:"replica-id"`
+
+	// RateLimits ограничения частоты запросов одного пользователя по классам команд (default, heavy, inline).
+	// Для незаданных классов берутся ограничения по умолчанию
+	RateLimits map[string]RateLimit `yaml:"rate-limits"`
+}
+
+// RateLimit ограничение частоты запросов: в среднем Rate запросов в минуту, подряд - не больше Burst
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
 }
 
 type Service struct {
@@ -70,3 +96,36 @@ func (s *Service) GRPCHostMessages() string {
 func (s *Service) GRPCPortMessages() int {
 	return s.config.GRPCPortMessages
 }
+
+func (s *Service) TgMode() string {
+	return s.config.TgMode
+}
+
+func (s *Service) WebhookURL() string {
+	return s.config.WebhookURL
+}
+
+func (s *Service) WebhookPort() int {
+	return s.config.WebhookPort
+}
+
+func (s *Service) WebhookSecret() string {
+	return s.config.WebhookSecret
+}
+
+func (s *Service) TgWorkers() int {
+	return s.config.TgWorkers
+}
+
+func (s *Service) TgQueueSize() int {
+	return s.config.TgQueueSize
+}
+
+func (s *Service) ReplicaID() string {
+	return s.config.ReplicaID
+}
+
+func (s *Service) RateLimit(class string) (float64, int, bool) {
+	limit, ok := s.config.RateLimits[class]
+	return limit.Rate, limit.Burst, ok
+}
@@ -0,0 +1,103 @@
+// Package anomaly поиск необычно крупных трат: сумма сравнивается с медианой трат пользователя в категории
+// за последние Window. Разброс считается через MAD - медиану абсолютных отклонений от медианы, ее не сдвигают
+// единичные крупные траты, в отличие от среднего и стандартного отклонения
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// Window за какой период учитываются траты
+	Window = 90 * 24 * time.Hour
+	// MaxSamples сколько последних трат категории хранится. Медиана по ним почти не отличается от медианы по всем
+	// тратам за Window, зато статистика обновляется без чтения истории
+	MaxSamples = 100
+	// MinSamples с какого количества трат в категории статистике можно доверять
+	MinSamples = 5
+	// Threshold на сколько MAD сумма должна превышать медиану, чтобы считаться необычной
+	Threshold = 3
+	// minSpread нижняя граница MAD в долях медианы: если в категории траты всегда одинаковые (подписка, проезд),
+	// MAD равен нулю, и необычной оказалась бы любая сумма чуть больше обычной
+	minSpread = 0.1
+)
+
+// Sample сумма траты и ее время
+type Sample struct {
+	Sum float64   `json:"sum"`
+	At  time.Time `json:"at"`
+}
+
+// Stats статистика трат пользователя в одной категории: последние траты за Window в порядке добавления
+type Stats struct {
+	Samples []Sample
+}
+
+// Result сравнение суммы с обычными тратами категории
+type Result struct {
+	Median float64 // обычная сумма траты в категории
+	MAD    float64 // разброс сумм, не меньше minSpread от медианы
+	// Unusual сумма больше медианы на Threshold разбросов. Всегда false, пока трат в категории меньше MinSamples
+	Unusual bool
+}
+
+// Add статистика после траты sum в момент at. Траты старше Window на момент now и сверх MaxSamples отбрасываются
+func (s Stats) Add(sum float64, at, now time.Time) Stats {
+	samples := append(s.fresh(now), Sample{Sum: sum, At: at})
+	if len(samples) > MaxSamples {
+		samples = samples[len(samples)-MaxSamples:]
+	}
+	return Stats{Samples: samples}
+}
+
+// Check необычна ли трата sum в момент now по сравнению с тратами за Window
+func (s Stats) Check(sum float64, now time.Time) Result {
+	samples := s.fresh(now)
+	if len(samples) == 0 {
+		return Result{}
+	}
+
+	sums := make([]float64, len(samples))
+	for i, sample := range samples {
+		sums[i] = sample.Sum
+	}
+	med := median(sums)
+
+	for i := range sums {
+		sums[i] = math.Abs(sums[i] - med)
+	}
+	mad := math.Max(median(sums), med*minSpread)
+
+	return Result{
+		Median:  med,
+		MAD:     mad,
+		Unusual: len(samples) >= MinSamples && sum > med+Threshold*mad,
+	}
+}
+
+// fresh траты не старше Window на момент now. Траты задним числом могут лежать не по порядку, поэтому
+// проверяется каждая
+func (s Stats) fresh(now time.Time) []Sample {
+	from := now.Add(-Window)
+
+	res := make([]Sample, 0, len(s.Samples)+1)
+	for _, sample := range s.Samples {
+		if !sample.At.Before(from) {
+			res = append(res, sample)
+		}
+	}
+	return res
+}
+
+// median медиана, values переупорядочивается
+func median(values []float64) float64 {
+	sort.Float64s(values)
+
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
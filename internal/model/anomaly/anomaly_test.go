@@ -0,0 +1,105 @@
+//go:build test_all || unit_test
+
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2022, 11, 15, 12, 0, 0, 0, time.UTC)
+
+func statsOf(sums ...float64) Stats {
+	var s Stats
+	for i, sum := range sums {
+		s = s.Add(sum, now.Add(-time.Duration(len(sums)-i)*time.Hour), now)
+	}
+	return s
+}
+
+func Test_Check(t *testing.T) {
+	t.Run("медиана и MAD", func(t *testing.T) {
+		res := statsOf(100, 200, 300, 400, 10000).Check(0, now)
+
+		assert.Equal(t, float64(300), res.Median)
+		// отклонения 200, 100, 0, 100, 9700
+		assert.Equal(t, float64(100), res.MAD)
+	})
+
+	t.Run("крупная трата необычна", func(t *testing.T) {
+		s := statsOf(100, 200, 300, 400, 10000)
+
+		assert.True(t, s.Check(601, now).Unusual)
+		assert.False(t, s.Check(600, now).Unusual)
+	})
+
+	t.Run("маленькая трата не бывает необычной", func(t *testing.T) {
+		assert.False(t, statsOf(1000, 1000, 1100, 900, 1000).Check(1, now).Unusual)
+	})
+
+	t.Run("мало трат - статистике не доверяем", func(t *testing.T) {
+		res := statsOf(100, 100, 100, 100).Check(100000, now)
+
+		assert.False(t, res.Unusual)
+		assert.Equal(t, float64(100), res.Median)
+	})
+
+	t.Run("одинаковые траты: разброс не меньше 10% медианы", func(t *testing.T) {
+		s := statsOf(500, 500, 500, 500, 500)
+
+		assert.Equal(t, float64(50), s.Check(0, now).MAD)
+		assert.False(t, s.Check(600, now).Unusual)
+		assert.True(t, s.Check(700, now).Unusual)
+	})
+
+	t.Run("без трат", func(t *testing.T) {
+		assert.Equal(t, Result{}, Stats{}.Check(100, now))
+	})
+
+	t.Run("старые траты не учитываются", func(t *testing.T) {
+		s := Stats{Samples: []Sample{
+			{Sum: 100, At: now.Add(-Window - time.Hour)},
+			{Sum: 100, At: now.Add(-Window - time.Hour)},
+			{Sum: 100, At: now.Add(-Window - time.Hour)},
+			{Sum: 5000, At: now.Add(-time.Hour)},
+			{Sum: 5000, At: now.Add(-time.Hour)},
+		}}
+
+		assert.Equal(t, float64(5000), s.Check(0, now).Median)
+	})
+}
+
+func Test_Add(t *testing.T) {
+	t.Run("старые траты выбрасываются", func(t *testing.T) {
+		s := Stats{Samples: []Sample{
+			{Sum: 100, At: now.Add(-Window - time.Hour)},
+			{Sum: 200, At: now.Add(-time.Hour)},
+		}}
+
+		s = s.Add(300, now, now)
+
+		assert.Equal(t, []Sample{{Sum: 200, At: now.Add(-time.Hour)}, {Sum: 300, At: now}}, s.Samples)
+	})
+
+	t.Run("хранится не больше MaxSamples трат", func(t *testing.T) {
+		var s Stats
+		for i := 0; i < MaxSamples+10; i++ {
+			s = s.Add(float64(i), now, now)
+		}
+
+		assert.Len(t, s.Samples, MaxSamples)
+		assert.Equal(t, float64(10), s.Samples[0].Sum)
+		assert.Equal(t, float64(MaxSamples+9), s.Samples[MaxSamples-1].Sum)
+	})
+
+	t.Run("исходная статистика не меняется", func(t *testing.T) {
+		s := Stats{Samples: make([]Sample, 1, 10)}
+		s.Samples[0] = Sample{Sum: 100, At: now}
+
+		_ = s.Add(200, now, now)
+
+		assert.Len(t, s.Samples, 1)
+	})
+}
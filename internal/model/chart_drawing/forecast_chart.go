@@ -0,0 +1,89 @@
+package chart_drawing
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	chart "github.com/wcharczuk/go-chart/v2"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
+)
+
+// ForecastLabels подписи линий графика прогноза на языке пользователя
+type ForecastLabels struct {
+	Actual    string
+	Projected string
+	Limit     string
+}
+
+// ForecastChart генерирует график накопленных трат по дням месяца: фактические траты, прогноз до конца месяца
+// и лимит (если limit не -1)
+func (m *Model) ForecastChart(f forecast.Forecast, limit float64, labels ForecastLabels) ([]byte, error) {
+	// прогноз рисуется с последнего дня с фактическими тратами, чтобы линии не разрывались
+	start := len(f.Actual) - 1
+	if start < 0 {
+		start = 0
+	}
+
+	series := []chart.Series{
+		chart.ContinuousSeries{
+			Name:    labels.Projected,
+			XValues: days(start, len(f.Projected)),
+			YValues: f.Projected[start:],
+			Style: chart.Style{
+				StrokeColor:     chart.ColorBlue,
+				StrokeWidth:     3,
+				StrokeDashArray: []float64{10, 10},
+			},
+		},
+		chart.ContinuousSeries{
+			Name:    labels.Actual,
+			XValues: days(0, len(f.Actual)),
+			YValues: f.Actual,
+			Style: chart.Style{
+				StrokeColor: chart.ColorBlue,
+				StrokeWidth: 3,
+			},
+		},
+	}
+	if limit != forecast.NoLimit {
+		series = append(series, chart.ContinuousSeries{
+			Name:    labels.Limit,
+			XValues: []float64{1, float64(len(f.Projected))},
+			YValues: []float64{limit, limit},
+			Style: chart.Style{
+				StrokeColor: chart.ColorRed,
+				StrokeWidth: 2,
+			},
+		})
+	}
+
+	graph := chart.Chart{
+		Width:  1000,
+		Height: 600,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 20, Left: 20},
+		},
+		XAxis:  chart.XAxis{ValueFormatter: chart.IntValueFormatter},
+		YAxis:  chart.YAxis{ValueFormatter: chart.IntValueFormatter},
+		Series: series,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	img := bytes.NewBuffer([]byte{})
+
+	err := graph.Render(chart.PNG, img)
+	if err != nil {
+		return nil, errors.Wrap(err, "graph.Render")
+	}
+
+	return img.Bytes(), nil
+}
+
+// days номера дней месяца с from+1 по to включительно
+func days(from, to int) []float64 {
+	res := make([]float64, 0, to-from)
+	for day := from + 1; day <= to; day++ {
+		res = append(res, float64(day))
+	}
+	return res
+}
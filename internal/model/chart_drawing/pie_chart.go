@@ -2,9 +2,13 @@ package chart_drawing
 
 import (
 	"bytes"
+	"fmt"
+	"hash/fnv"
+	"sort"
 
 	"github.com/pkg/errors"
 	chart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
 	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/report"
 )
 
@@ -14,20 +18,97 @@ func New() *Model {
 	return &Model{}
 }
 
-// PieChart генерирует круговую диаграмму, на которой указаны траты по категориям
-func (m *Model) PieChart(data []model.ReportItem) ([]byte, error) {
-	values := make([]chart.Value, len(data))
-	for i := range values {
-		values[i] = chart.Value{
-			Value: data[i].Summa,
-			Label: data[i].PurchaseCategory,
-		}
-	}
+// Размеры круговой диаграммы в пикселях: слева круг, справа легенда
+const (
+	pieWidth       = 1500
+	pieHeight      = 1000
+	piePadding     = 40
+	pieLegendWidth = 500
+	pieLegendRow   = 90 // высота строки легенды: категория и под ней сумма с долей
+	pieLegendBox   = 28 // размер цветного квадрата в легенде
+	pieFontSize    = 18.0
+
+	// pieMinShare категории с меньшей долей собираются в сектор "Прочее", у таких секторов нет подписи
+	pieMinShare = 0.03
+	// pieMaxSlices больше секторов на диаграмме не читается
+	pieMaxSlices = 10
+)
+
+// pieColors палитра секторов. Цвет категории выбирается только по хешу ее названия, поэтому одна и та же
+// категория во всех отчетах одного цвета. Палитра большая, чтобы разные категории одного отчета редко совпадали по цвету
+var pieColors = []drawing.Color{
+	drawing.ColorFromHex("4e79a7"),
+	drawing.ColorFromHex("a0cbe8"),
+	drawing.ColorFromHex("f28e2b"),
+	drawing.ColorFromHex("ffbe7d"),
+	drawing.ColorFromHex("59a14f"),
+	drawing.ColorFromHex("8cd17d"),
+	drawing.ColorFromHex("b6992d"),
+	drawing.ColorFromHex("f1ce63"),
+	drawing.ColorFromHex("499894"),
+	drawing.ColorFromHex("86bcb6"),
+	drawing.ColorFromHex("e15759"),
+	drawing.ColorFromHex("ff9d9a"),
+	drawing.ColorFromHex("6a3d9a"),
+	drawing.ColorFromHex("d37295"),
+	drawing.ColorFromHex("fabfd2"),
+	drawing.ColorFromHex("b07aa1"),
+	drawing.ColorFromHex("d4a6c8"),
+	drawing.ColorFromHex("9d7660"),
+	drawing.ColorFromHex("d7b5a6"),
+	drawing.ColorFromHex("1f77b4"),
+	drawing.ColorFromHex("9467bd"),
+	drawing.ColorFromHex("17becf"),
+	drawing.ColorFromHex("bcbd22"),
+	drawing.ColorFromHex("8c564b"),
+}
+
+var (
+	pieOtherColor = drawing.ColorFromHex("bab0ac")
+	pieEmptyColor = chart.ColorLightGray
+	pieMutedText  = chart.ColorAlternateGray
+)
+
+// pieSlice сектор диаграммы
+type pieSlice struct {
+	Label string
+	Summa float64
+	Share float64
+	Color drawing.Color
+}
+
+// PieChart генерирует круговую диаграмму трат по категориям с долями на секторах и легендой с суммами.
+// Мелкие категории собираются в сектор labels.Other, без трат рисуется пустой круг с подписью labels.Empty
+func (m *Model) PieChart(data []model.ReportItem, labels model.PieLabels) ([]byte, error) {
+	slices := pieSlices(data, labels.Other)
 
 	pie := chart.PieChart{
-		Width:  1000,
-		Height: 1000,
-		Values: values,
+		Width:  pieWidth,
+		Height: pieHeight,
+		Background: chart.Style{
+			Padding: chart.Box{Top: piePadding, Left: piePadding, Bottom: piePadding, Right: pieLegendWidth + piePadding},
+		},
+	}
+
+	switch len(slices) {
+	case 0:
+		pie.Values = pieCircle(pieEmptyColor)
+		pie.Elements = []chart.Renderable{pieCenterText(labels.Empty)}
+	case 1:
+		pie.Values = pieCircle(slices[0].Color)
+		pie.Elements = []chart.Renderable{pieLegend(slices, labels.FormatSum)}
+	default:
+		pie.Values = make([]chart.Value, len(slices))
+		for i, sl := range slices {
+			pie.Values[i] = chart.Value{
+				Value: sl.Summa,
+				Style: chart.Style{FillColor: sl.Color, FontSize: pieFontSize, FontColor: chart.ColorBlack},
+			}
+			if sl.Share >= pieMinShare {
+				pie.Values[i].Label = pieShare(sl.Share)
+			}
+		}
+		pie.Elements = []chart.Renderable{pieLegend(slices, labels.FormatSum)}
 	}
 
 	img := bytes.NewBuffer([]byte{})
@@ -39,3 +120,128 @@ func (m *Model) PieChart(data []model.ReportItem) ([]byte, error) {
 
 	return img.Bytes(), nil
 }
+
+// pieSlices сектора диаграммы по убыванию сумм, категории без трат пропускаются.
+// Категории с долей меньше pieMinShare и не поместившиеся в pieMaxSlices собираются в сектор other,
+// если таких категорий больше одной
+func pieSlices(data []model.ReportItem, other string) []pieSlice {
+	items := make([]model.ReportItem, 0, len(data))
+	total := 0.0
+	for _, item := range data {
+		if item.Summa > 0 {
+			items = append(items, item)
+			total += item.Summa
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Summa != items[j].Summa {
+			return items[i].Summa > items[j].Summa
+		}
+		return items[i].PurchaseCategory < items[j].PurchaseCategory
+	})
+
+	keep := len(items)
+	for i, item := range items {
+		if i == pieMaxSlices-1 || item.Summa/total < pieMinShare {
+			keep = i
+			break
+		}
+	}
+	if len(items)-keep < 2 {
+		keep = len(items)
+	}
+
+	res := make([]pieSlice, 0, keep+1)
+	for _, item := range items[:keep] {
+		res = append(res, pieSlice{
+			Label: item.PurchaseCategory,
+			Summa: item.Summa,
+			Share: item.Summa / total,
+			Color: pieColor(item.PurchaseCategory),
+		})
+	}
+
+	if keep < len(items) {
+		summa := 0.0
+		for _, item := range items[keep:] {
+			summa += item.Summa
+		}
+		res = append(res, pieSlice{Label: other, Summa: summa, Share: summa / total, Color: pieOtherColor})
+	}
+	return res
+}
+
+// pieColor цвет категории по хешу названия. От остальных категорий отчета цвет не зависит,
+// поэтому изредка две категории одной диаграммы совпадают по цвету
+func pieColor(category string) drawing.Color {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(category))
+	return pieColors[h.Sum32()%uint32(len(pieColors))]
+}
+
+// pieCircle сплошной круг из двух одноцветных половин: единственное значение go-chart
+// рисует кривым кругом без стиля значения
+func pieCircle(color drawing.Color) []chart.Value {
+	style := chart.Style{FillColor: color, StrokeColor: color}
+	return []chart.Value{{Value: 1, Style: style}, {Value: 1, Style: style}}
+}
+
+// pieShare доля в процентах, доли меньше процента не округляются до нуля
+func pieShare(share float64) string {
+	if share < 0.01 {
+		return "<1%"
+	}
+	return fmt.Sprintf("%.0f%%", share*100)
+}
+
+// pieLegend легенда справа от круга: цвет, категория и под ней сумма с долей
+func pieLegend(slices []pieSlice, formatSum func(float64) string) chart.Renderable {
+	return func(r chart.Renderer, _ chart.Box, defaults chart.Style) {
+		left := pieWidth - pieLegendWidth
+		textLeft := left + pieLegendBox + 16
+		textWidth := pieWidth - piePadding - textLeft
+		top := (pieHeight - len(slices)*pieLegendRow) / 2
+
+		for i, sl := range slices {
+			y := top + i*pieLegendRow
+			chart.Draw.Box(r, chart.Box{Top: y, Left: left, Right: left + pieLegendBox, Bottom: y + pieLegendBox},
+				chart.Style{FillColor: sl.Color, StrokeColor: sl.Color, StrokeWidth: 1})
+
+			chart.Style{Font: defaults.Font, FontSize: pieFontSize, FontColor: chart.ColorBlack}.WriteTextOptionsToRenderer(r)
+			r.Text(fitText(r, sl.Label, textWidth), textLeft, y+pieLegendBox-4)
+
+			chart.Style{Font: defaults.Font, FontSize: pieFontSize, FontColor: pieMutedText}.WriteTextOptionsToRenderer(r)
+			r.Text(fitText(r, formatSum(sl.Summa)+"  "+pieShare(sl.Share), textWidth), textLeft, y+2*pieLegendBox+8)
+		}
+	}
+}
+
+// pieCenterText подпись в центре круга
+func pieCenterText(text string) chart.Renderable {
+	return func(r chart.Renderer, canvasBox chart.Box, defaults chart.Style) {
+		chart.Draw.TextWithin(r, text, canvasBox, chart.Style{
+			Font:                defaults.Font,
+			FontSize:            pieFontSize * 1.5,
+			FontColor:           pieMutedText,
+			TextHorizontalAlign: chart.TextHorizontalAlignCenter,
+			TextVerticalAlign:   chart.TextVerticalAlignMiddle,
+		})
+	}
+}
+
+// fitText обрезает текст с многоточием, чтобы он поместился в width пикселей.
+// Шрифт должен быть уже выставлен в рисовальщике
+func fitText(r chart.Renderer, text string, width int) string {
+	if r.MeasureText(text).Width() <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		res := string(runes) + "…"
+		if r.MeasureText(res).Width() <= width {
+			return res
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+//go:build test_all || unit_test
+
+package chart_drawing
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/report"
+)
+
+// update перезаписать эталонные картинки: go test -tags unit_test ./internal/model/chart_drawing -update
+var update = flag.Bool("update", false, "update golden images")
+
+const goldenDir = "./../../../test_data/golden"
+
+// assertGolden сравнивает картинку с эталоном попиксельно: сжатие PNG может отличаться между версиями Go
+func assertGolden(t *testing.T, name string, raw []byte) {
+	t.Helper()
+
+	path := filepath.Join(goldenDir, name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, raw, 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "нет эталона, запустите тест с -update")
+
+	got, exp := decodeRGBA(t, raw), decodeRGBA(t, want)
+	require.Equal(t, exp.Bounds(), got.Bounds())
+	if !bytes.Equal(exp.Pix, got.Pix) {
+		failed := filepath.Join(t.TempDir(), name)
+		_ = os.WriteFile(failed, raw, 0o644)
+		t.Errorf("картинка отличается от эталона %s, получено: %s", path, failed)
+	}
+}
+
+func decodeRGBA(t *testing.T, raw []byte) *image.RGBA {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	res := image.NewRGBA(img.Bounds())
+	draw.Draw(res, res.Bounds(), img, img.Bounds().Min, draw.Src)
+	return res
+}
+
+func testLabels() model.PieLabels {
+	return model.PieLabels{
+		Other: "Прочее",
+		Empty: "Трат за этот период нет",
+		FormatSum: func(v float64) string {
+			return fmt.Sprintf("%.2f RUB", v)
+		},
+	}
+}
+
+func Test_PieChart_golden(t *testing.T) {
+	many := []model.ReportItem{
+		{PurchaseCategory: "Продукты", Summa: 32000},
+		{PurchaseCategory: "Кафе и рестораны", Summa: 12500},
+		{PurchaseCategory: "Такси", Summa: 6400},
+		{PurchaseCategory: "Коммунальные платежи и интернет", Summa: 9100},
+		{PurchaseCategory: "Аптека", Summa: 900},
+		{PurchaseCategory: "Книги", Summa: 450},
+		{PurchaseCategory: "Подписки", Summa: 299},
+		{PurchaseCategory: "Без трат", Summa: 0},
+	}
+
+	tests := []struct {
+		name string
+		data []model.ReportItem
+	}{
+		{name: "pie_many.png", data: many},
+		{name: "pie_single.png", data: []model.ReportItem{{PurchaseCategory: "Кафе", Summa: 1234.5}}},
+		{name: "pie_empty.png", data: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := New().PieChart(tt.data, testLabels())
+
+			require.NoError(t, err)
+			assertGolden(t, tt.name, raw)
+		})
+	}
+}
+
+func Test_pieSlices(t *testing.T) {
+	t.Run("мелкие категории собираются в Прочее", func(t *testing.T) {
+		res := pieSlices([]model.ReportItem{
+			{PurchaseCategory: "Кафе", Summa: 200},
+			{PurchaseCategory: "Книги", Summa: 10},
+			{PurchaseCategory: "Продукты", Summa: 780},
+			{PurchaseCategory: "Аптека", Summa: 10},
+			{PurchaseCategory: "Без трат", Summa: 0},
+		}, "Прочее")
+
+		require.Len(t, res, 3)
+		assert.Equal(t, "Продукты", res[0].Label)
+		assert.Equal(t, "Кафе", res[1].Label)
+		assert.Equal(t, pieSlice{Label: "Прочее", Summa: 20, Share: 0.02, Color: pieOtherColor}, res[2])
+	})
+
+	t.Run("одна мелкая категория остается своим сектором", func(t *testing.T) {
+		res := pieSlices([]model.ReportItem{
+			{PurchaseCategory: "Продукты", Summa: 990},
+			{PurchaseCategory: "Книги", Summa: 10},
+		}, "Прочее")
+
+		require.Len(t, res, 2)
+		assert.Equal(t, "Книги", res[1].Label)
+	})
+
+	t.Run("секторов не больше pieMaxSlices", func(t *testing.T) {
+		data := make([]model.ReportItem, 15)
+		for i := range data {
+			data[i] = model.ReportItem{PurchaseCategory: fmt.Sprintf("Категория %02d", i), Summa: 100}
+		}
+
+		res := pieSlices(data, "Прочее")
+
+		require.Len(t, res, pieMaxSlices)
+		assert.Equal(t, "Категория 00", res[0].Label)
+		assert.Equal(t, pieSlice{Label: "Прочее", Summa: 600, Share: 0.4, Color: pieOtherColor}, res[pieMaxSlices-1])
+	})
+
+	t.Run("без трат", func(t *testing.T) {
+		assert.Empty(t, pieSlices([]model.ReportItem{{PurchaseCategory: "Кафе"}}, "Прочее"))
+	})
+}
+
+func Test_pieColor(t *testing.T) {
+	// хеши названий "Такси" и "Спорт" попадают в один цвет палитры
+	assert.Equal(t, pieColor("Такси"), pieColor("Спорт"))
+
+	first := pieSlices([]model.ReportItem{
+		{PurchaseCategory: "Такси", Summa: 100},
+		{PurchaseCategory: "Спорт", Summa: 50},
+		{PurchaseCategory: "Кафе", Summa: 30},
+	}, "Прочее")
+	second := pieSlices([]model.ReportItem{
+		{PurchaseCategory: "Продукты", Summa: 500},
+		{PurchaseCategory: "Спорт", Summa: 200},
+		{PurchaseCategory: "Такси", Summa: 100},
+	}, "Прочее")
+
+	// цвет категории не зависит от остальных категорий отчета и их порядка, даже при совпадении цветов
+	colors := map[string]drawing.Color{}
+	for _, sl := range append(first, second...) {
+		if c, ok := colors[sl.Label]; ok {
+			assert.Equal(t, c, sl.Color, sl.Label)
+		}
+		colors[sl.Label] = sl.Color
+		assert.Equal(t, pieColor(sl.Label), sl.Color, sl.Label)
+	}
+}
+
+func Test_pieShare(t *testing.T) {
+	assert.Equal(t, "42%", pieShare(0.4213))
+	assert.Equal(t, "1%", pieShare(0.01))
+	assert.Equal(t, "<1%", pieShare(0.009))
+}
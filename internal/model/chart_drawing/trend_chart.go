@@ -0,0 +1,55 @@
+package chart_drawing
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/pkg/errors"
+	chart "github.com/wcharczuk/go-chart/v2"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/report"
+)
+
+// TrendChart генерирует график трат по дням периода. formatDate подписывает дни на оси на языке пользователя
+func (m *Model) TrendChart(days []model.DailyTotal, formatDate func(time.Time) string) ([]byte, error) {
+	xValues := make([]time.Time, len(days))
+	yValues := make([]float64, len(days))
+	for i := range days {
+		xValues[i] = days[i].Day
+		yValues[i] = days[i].Summa
+	}
+
+	graph := chart.Chart{
+		Width:  1000,
+		Height: 500,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 20, Left: 20},
+		},
+		XAxis: chart.XAxis{ValueFormatter: func(v interface{}) string {
+			if tf, ok := v.(float64); ok {
+				return formatDate(chart.TimeFromFloat64(tf))
+			}
+			return ""
+		}},
+		YAxis: chart.YAxis{ValueFormatter: chart.IntValueFormatter},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				XValues: xValues,
+				YValues: yValues,
+				Style: chart.Style{
+					StrokeColor: chart.ColorBlue,
+					StrokeWidth: 3,
+					FillColor:   chart.ColorBlue.WithAlpha(60),
+				},
+			},
+		},
+	}
+
+	img := bytes.NewBuffer([]byte{})
+
+	err := graph.Render(chart.PNG, img)
+	if err != nil {
+		return nil, errors.Wrap(err, "graph.Render")
+	}
+
+	return img.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+type categoryRule struct {
+	UserID     int64   `db:"user_id"`
+	Type       string  `db:"rule_type"`
+	Pattern    string  `db:"pattern"`
+	MinSum     float64 `db:"min_sum"`
+	MaxSum     float64 `db:"max_sum"`
+	CategoryID uint64  `db:"category_id"`
+}
+
+// AddCategoryRule добавить правило автоматического подбора категории
+func (s *Service) AddCategoryRule(ctx context.Context, rule model.CategoryRule) error {
+	if rule.UserID == 0 {
+		return errors.New("user is empty")
+	}
+	if rule.CategoryID == 0 {
+		return errors.New("category is empty")
+	}
+
+	if err := s.UserCreateIfNotExist(ctx, rule.UserID); err != nil {
+		return errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert(tblCategoryRules).
+		Columns(tblCategoryRulesColUserID, tblCategoryRulesColType, tblCategoryRulesColPattern,
+			tblCategoryRulesColMinSum, tblCategoryRulesColMaxSum, tblCategoryRulesColCategoryID).
+		Values(rule.UserID, string(rule.Type), rule.Pattern, rule.MinSum, rule.MaxSum, rule.CategoryID).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// GetUserCategoryRules получить правила пользователя в порядке их создания
+func (s *Service) GetUserCategoryRules(ctx context.Context, userID int64) ([]model.CategoryRule, error) {
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblCategoryRulesColUserID, tblCategoryRulesColType, tblCategoryRulesColPattern,
+			tblCategoryRulesColMinSum, tblCategoryRulesColMaxSum, tblCategoryRulesColCategoryID).
+		From(tblCategoryRules).
+		Where(sq.Eq{tblCategoryRulesColUserID: userID}).
+		OrderBy(tblCategoryRulesColID).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []categoryRule
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	rules := make([]model.CategoryRule, len(rows))
+	for i := range rows {
+		rules[i] = model.CategoryRule{
+			UserID:     rows[i].UserID,
+			Type:       model.RuleType(rows[i].Type),
+			Pattern:    rows[i].Pattern,
+			MinSum:     rows[i].MinSum,
+			MaxSum:     rows[i].MaxSum,
+			CategoryID: rows[i].CategoryID,
+		}.Compiled()
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,75 @@
+//go:build test_all || integration_test
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-testfixtures/testfixtures/v3"
+	"github.com/stretchr/testify/assert"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_GetUserCategoryRules(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/category_rules.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	rules, err := s.GetUserCategoryRules(ctx, 123)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CategoryRule{
+		{UserID: 123, Type: model.RuleTypeSubstring, Pattern: "пятёрочка", CategoryID: 2},
+		{UserID: 123, Type: model.RuleTypeAmount, MinSum: 100, MaxSum: 500, CategoryID: 2},
+	}, rules)
+}
+
+func Test_AddCategoryRule(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.Files(
+			"./../../../test_data/fixtures/users.yml",
+			"./../../../test_data/fixtures/categories.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	err = s.AddCategoryRule(ctx, model.CategoryRule{
+		UserID:     123,
+		Type:       model.RuleTypeRegexp,
+		Pattern:    "^такси",
+		CategoryID: 2,
+	})
+	assert.NoError(t, err)
+
+	// проверим что правило действительно создалось
+	rules, err := s.GetUserCategoryRules(ctx, 123)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CategoryRule{
+		model.CategoryRule{UserID: 123, Type: model.RuleTypeRegexp, Pattern: "^такси", CategoryID: 2}.Compiled(),
+	}, rules)
+}
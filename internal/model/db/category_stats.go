@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
+)
+
+// GetCategoryStats статистика трат пользователя в категории. Пустая, если трат в категории еще не было
+func (s *Service) GetCategoryStats(ctx context.Context, userID int64, categoryID uint64) (anomaly.Stats, error) {
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblCategoryStatsColSamples).
+		From(tblCategoryStats).
+		Where(sq.Eq{
+			tblCategoryStatsColUserID:     userID,
+			tblCategoryStatsColCategoryID: categoryID,
+		}).
+		ToSql()
+	if err != nil {
+		return anomaly.Stats{}, errors.Wrap(err, "query creating error")
+	}
+
+	var rows [][]byte
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return anomaly.Stats{}, errors.Wrap(err, "db.SelectContext")
+	}
+	if len(rows) == 0 {
+		return anomaly.Stats{}, nil
+	}
+
+	var stats anomaly.Stats
+	if err = json.Unmarshal(rows[0], &stats.Samples); err != nil {
+		return anomaly.Stats{}, errors.Wrap(err, "json.Unmarshal")
+	}
+	return stats, nil
+}
+
+// SaveCategoryStats сохранить статистику трат пользователя в категории
+func (s *Service) SaveCategoryStats(ctx context.Context, userID int64, categoryID uint64, stats anomaly.Stats) error {
+	samples := stats.Samples
+	if samples == nil {
+		samples = []anomaly.Sample{}
+	}
+	raw, err := json.Marshal(samples)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert(tblCategoryStats).
+		Columns(tblCategoryStatsColUserID, tblCategoryStatsColCategoryID, tblCategoryStatsColSamples).
+		Values(userID, categoryID, string(raw)).
+		Suffix("ON CONFLICT (" + tblCategoryStatsColUserID + ", " + tblCategoryStatsColCategoryID + ") DO UPDATE SET " +
+			tblCategoryStatsColSamples + " = EXCLUDED." + tblCategoryStatsColSamples).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build test_all || integration_test
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
+)
+
+func Test_CategoryStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	// статистики еще нет
+	stats, err := s.GetCategoryStats(ctx, 123, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, stats.Samples)
+
+	at := time.Date(2022, 11, 15, 12, 0, 0, 0, time.UTC)
+	first := anomaly.Stats{Samples: []anomaly.Sample{{Sum: 100, At: at}}}
+	assert.NoError(t, s.SaveCategoryStats(ctx, 123, 2, first))
+
+	// повторное сохранение заменяет статистику
+	second := first.Add(250.5, at.Add(time.Hour), at.Add(time.Hour))
+	assert.NoError(t, s.SaveCategoryStats(ctx, 123, 2, second))
+	// статистика другой категории не затрагивается
+	assert.NoError(t, s.SaveCategoryStats(ctx, 123, 3, first))
+
+	stats, err = s.GetCategoryStats(ctx, 123, 2)
+	assert.NoError(t, err)
+	assert.Len(t, stats.Samples, 2)
+	assert.Equal(t, 250.5, stats.Samples[1].Sum)
+	assert.True(t, at.Add(time.Hour).Equal(stats.Samples[1].At))
+}
+
+func Test_ChangeConfirmUnusual(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	// по умолчанию подтверждение не спрашивается
+	info, err := s.GetUserInfo(ctx, 123)
+	assert.NoError(t, err)
+	assert.False(t, info.ConfirmUnusual)
+
+	assert.NoError(t, s.ChangeConfirmUnusual(ctx, 123, true))
+
+	info, err = s.GetUserInfo(ctx, 123)
+	assert.NoError(t, err)
+	assert.True(t, info.ConfirmUnusual)
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+type subscription struct {
+	UserID   int64          `db:"id"`
+	Digest   string         `db:"digest"`
+	Lang     sql.NullString `db:"lang"`
+	Timezone sql.NullString `db:"tz"`
+}
+
+// ChangeDigest подписка на отчет за каждый прошедший период, пустой период - подписки нет
+func (s *Service) ChangeDigest(ctx context.Context, userID int64, period string) error {
+	if err := s.UserCreateIfNotExist(ctx, userID); err != nil {
+		return errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	value := sql.NullString{String: period, Valid: period != ""}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update(tblUsers).
+		Set(tblUsersColDigest, value).
+		Where(sq.Eq{tblUsersColID: userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// GetDigestSubscriptions все подписки на отчеты
+func (s *Service) GetDigestSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblUsersColID, tblUsersColDigest, tblUsersColLang, tblUsersColTimezone).
+		From(tblUsers).
+		Where(sq.NotEq{tblUsersColDigest: nil}).
+		OrderBy(tblUsersColID).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []subscription
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	subscriptions := make([]model.Subscription, 0, len(rows))
+	for i := range rows {
+		period, err := model.ParsePeriod(rows[i].Digest)
+		if err != nil {
+			return nil, errors.Wrap(err, "ParsePeriod")
+		}
+		subscriptions = append(subscriptions, model.Subscription{
+			UserID:   rows[i].UserID,
+			Period:   period,
+			Lang:     i18n.Lang(rows[i].Lang.String),
+			Timezone: rows[i].Timezone.String,
+		})
+	}
+
+	return subscriptions, nil
+}
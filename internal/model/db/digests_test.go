@@ -0,0 +1,37 @@
+//go:build test_all || integration_test
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_DigestSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	week, err := model.ParsePeriod("week")
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.ChangeDigest(ctx, 123, "week"))
+	assert.NoError(t, s.ChangeLang(ctx, 123, i18n.EN))
+	assert.NoError(t, s.ChangeDigest(ctx, 124, "month"))
+	// отписка
+	assert.NoError(t, s.ChangeDigest(ctx, 124, ""))
+	assert.NoError(t, s.UserCreateIfNotExist(ctx, 125))
+
+	subscriptions, err := s.GetDigestSubscriptions(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Subscription{
+		{UserID: 123, Period: week, Lang: i18n.EN},
+	}, subscriptions)
+}
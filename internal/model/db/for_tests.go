@@ -29,9 +29,10 @@ func selectAllFromTestTableCategories(ctx context.Context, s *Service, categorie
 }
 
 type purchaseTestRow struct {
-	Sum        float64 `db:"sum"` // сумма траты в рублях
-	CategoryID uint64  `db:"category_id"`
-	UserID     int64   `db:"user_id"`
+	Sum        float64  `db:"sum"` // сумма траты в рублях
+	CategoryID uint64   `db:"category_id"`
+	UserID     int64    `db:"user_id"`
+	Currency   Currency `db:"curr"`
 
 	// коэффициенты валют на момент совершения траты
 	USDRatio float64 `db:"usd_ratio"`
@@ -40,7 +41,7 @@ type purchaseTestRow struct {
 }
 
 func selectAllFromTestTablePurchases(ctx context.Context, s *Service, purchases *[]purchaseTestRow) {
-	_ = s.db.SelectContext(ctx, purchases, "SELECT sum, user_id, category_id, usd_ratio, cny_ratio, eur_ratio  FROM purchases") // nolint:errcheck
+	_ = s.db.SelectContext(ctx, purchases, "SELECT sum, user_id, category_id, curr, usd_ratio, cny_ratio, eur_ratio  FROM purchases") // nolint:errcheck
 }
 
 // rate курс валют к RUB
@@ -13,8 +13,11 @@ import (
 )
 
 type purchase struct {
+	Timestamp    time.Time      `db:"ts"`
 	Sum          float64        `db:"sum"` // сумма траты в рублях
 	CategoryName sql.NullString `db:"category_name"`
+	Description  string         `db:"description"`
+	Currency     sql.NullString `db:"curr"` // валюта, в которой заплатил пользователь, NULL - неизвестна
 
 	// коэффициенты валют на момент совершения траты
 	USDRatio float64 `db:"usd_ratio"`
@@ -40,16 +43,18 @@ func (s *Service) AddPurchase(ctx context.Context, req model.AddPurchaseReq) err
 		}
 	}
 
+	curr, err := currencyFromModelTypeConv(req.Currency)
+	if err != nil {
+		return errors.Wrap(err, "currencyFromModelTypeConv")
+	}
+
 	query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
 		Insert(tblPurchases).
 		Columns(tblPurchasesColCategoryID, tblPurchasesColSum, tblPurchasesColEURRatio,
-			tblPurchasesColUSDRatio, tblPurchasesColCNYRatio, tblPurchasesColUserID).
-		Values(req.CategoryID, req.Sum, req.EURRatio, req.USDRatio, req.CNYRatio, req.UserID)
-
-	nullTime := time.Time{}
-	if req.Date == nullTime {
-		query.Columns(tblPurchasesColTimestamp).Values(sq.Expr("now()"))
-	}
+			tblPurchasesColUSDRatio, tblPurchasesColCNYRatio, tblPurchasesColUserID, tblPurchasesColDescription,
+			tblPurchasesColTimestamp, tblPurchasesColNote, tblPurchasesColTags, tblPurchasesColCurrency).
+		Values(req.CategoryID, req.Sum, req.EURRatio, req.USDRatio, req.CNYRatio, req.UserID, req.Description,
+			req.Date, req.Note, tagsArray(req.Tags), curr)
 
 	q, args, err := query.ToSql()
 	if err != nil {
@@ -70,7 +75,7 @@ func (s *Service) GetUserPurchasesFromDate(ctx context.Context, fromDate time.Ti
 		return nil, errors.Wrap(err, "UserCreateIfNotExist")
 	}
 
-	q, args, err := sq.Expr(`SELECT "sum", category_name, usd_ratio, cny_ratio, eur_ratio 
+	q, args, err := sq.Expr(`SELECT ts, "sum", category_name, description, curr, usd_ratio, cny_ratio, eur_ratio 
 							FROM purchases 
 							LEFT JOIN (
 								SELECT id, category_name 
@@ -86,23 +91,85 @@ func (s *Service) GetUserPurchasesFromDate(ctx context.Context, fromDate time.Ti
 		return nil, errors.Wrap(err, "db.SelectContext")
 	}
 
-	purchases := make([]model.Purchase, 0)
+	return toModelPurchases(rows)
+}
+
+// GetUserPurchasesBetween получить траты пользователя с from (включительно) до to (не включительно)
+func (s *Service) GetUserPurchasesBetween(ctx context.Context, from, to time.Time, userID int64) ([]model.Purchase, error) {
+	q, args, err := sq.Expr(`SELECT ts, "sum", category_name, description, curr, usd_ratio, cny_ratio, eur_ratio 
+							FROM purchases 
+							LEFT JOIN (
+								SELECT id, category_name 
+								FROM categories 
+							) AS user_categories ON (purchases.category_id=user_categories.id) 
+							WHERE user_id = $1 AND $2 <= ts AND ts < $3;`, userID, from, to).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []purchase
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	return toModelPurchases(rows)
+}
+
+// HasUserPurchasesFrom есть ли у пользователя траты начиная с fromDate. Вызывается для каждого напоминания,
+// поэтому сами траты не читаются
+func (s *Service) HasUserPurchasesFrom(ctx context.Context, userID int64, fromDate time.Time) (bool, error) {
+	q, args, err := sq.Expr(`SELECT EXISTS (
+								SELECT 1 
+								FROM purchases 
+								WHERE user_id = $1 AND ts >= $2 
+								LIMIT 1
+							)`, userID, fromDate).ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "query creating error")
+	}
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "db.QueryContext")
+	}
+	var has bool
+	if err = read(rows, &has); err != nil {
+		return false, errors.Wrap(err, "read")
+	}
+
+	return has, nil
+}
+
+func toModelPurchases(rows []purchase) ([]model.Purchase, error) {
+	purchases := make([]model.Purchase, 0, len(rows))
 	for _, p := range rows {
-		purchases = append(purchases, model.Purchase{
+		res := model.Purchase{
+			Date:             p.Timestamp,
 			PurchaseCategory: p.CategoryName.String,
+			Description:      p.Description,
 			Summa:            p.Sum,
+			UnknownCurrency:  !p.Currency.Valid,
 			RateToRUB: currency.RateToRUB{
 				USD: p.USDRatio,
 				CNY: p.CNYRatio,
 				EUR: p.EURRatio,
 			},
-		})
-	}
+		}
+		if p.Currency.Valid {
+			curr, err := currencyToModelTypeConv(Currency(p.Currency.String))
+			if err != nil {
+				return nil, errors.Wrap(err, "currencyToModelTypeConv")
+			}
+			res.Currency = curr
+		}
 
+		purchases = append(purchases, res)
+	}
 	return purchases, nil
 }
 
-// GetUserPurchasesSumFromMonth получить сумму расходов пользователя за календарный месяц (на вход отправить текущую дату)
+// GetUserPurchasesSumFromMonth получить сумму расходов пользователя за календарный месяц
+// (на вход отправить текущую дату в часовом поясе пользователя)
 func (s *Service) GetUserPurchasesSumFromMonth(ctx context.Context, userID int64, fromDate time.Time) (float64, error) {
 	if userID == 0 {
 		return 0, errors.New("userID is empty")
@@ -112,14 +179,10 @@ func (s *Service) GetUserPurchasesSumFromMonth(ctx context.Context, userID int64
 		return 0, errors.Wrap(err, "UserCreateIfNotExist")
 	}
 
+	// границы месяца считаются в часовом поясе переданной даты, то есть в поясе пользователя
 	y, m, _ := fromDate.Date()
-	from := fmt.Sprintf("%d-%d-01", y, m)
-	var to string
-	if m < 12 {
-		to = fmt.Sprintf("%d-%d-01", y, m+1)
-	} else {
-		to = fmt.Sprintf("%d-01-01", y+1)
-	}
+	from := time.Date(y, m, 1, 0, 0, 0, 0, fromDate.Location())
+	to := from.AddDate(0, 1, 0)
 
 	q, args, err := sq.Expr(`SELECT SUM(sum) 
 							FROM purchases 
@@ -146,3 +209,72 @@ func (s *Service) GetUserPurchasesSumFromMonth(ctx context.Context, userID int64
 
 	return sum.Float64, nil
 }
+
+type categorizedPurchase struct {
+	Description  string `db:"description"`
+	CategoryName string `db:"category_name"`
+}
+
+// GetUserCategorizedPurchases получить описания и категории последних трат пользователя (траты без категории не учитываются)
+func (s *Service) GetUserCategorizedPurchases(ctx context.Context, userID int64, limit uint64) ([]model.CategorizedPurchase, error) {
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblPurchases+"."+tblPurchasesColDescription, tblCategories+"."+tblCategoriesColCategoryName).
+		From(tblPurchases).
+		Join(fmt.Sprintf("%s ON %s.%s = %s.%s", tblCategories,
+			tblPurchases, tblPurchasesColCategoryID, tblCategories, tblCategoriesColID)).
+		Where(sq.Eq{tblPurchasesColUserID: userID}).
+		Where(sq.NotEq{tblPurchasesColCategoryID: defaultCategoryID}).
+		OrderBy(tblPurchasesColTimestamp + " DESC").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []categorizedPurchase
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	res := make([]model.CategorizedPurchase, len(rows))
+	for i := range rows {
+		res[i] = model.CategorizedPurchase(rows[i])
+	}
+
+	return res, nil
+}
+
+type dailySum struct {
+	Day string  `db:"day"`
+	Sum float64 `db:"sum"`
+}
+
+// GetUserDailySums получить суммы трат пользователя в рублях по дням с from (включительно) до to (не включительно).
+// Дни считаются в часовом поясе from, дни без трат не возвращаются
+func (s *Service) GetUserDailySums(ctx context.Context, userID int64, from, to time.Time) ([]model.DailySum, error) {
+	loc := from.Location()
+
+	q, args, err := sq.Expr(`SELECT to_char(ts AT TIME ZONE $1, 'YYYY-MM-DD') AS day, SUM(sum) AS "sum" 
+							FROM purchases 
+							WHERE user_id = $2 AND $3 <= ts AND ts < $4 
+							GROUP BY day 
+							ORDER BY day;`, loc.String(), userID, from, to).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []dailySum
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	res := make([]model.DailySum, 0, len(rows))
+	for _, r := range rows {
+		day, err := time.ParseInLocation("2006-01-02", r.Day, loc)
+		if err != nil {
+			return nil, errors.Wrap(err, "time.ParseInLocation")
+		}
+		res = append(res, model.DailySum{Day: day, Summa: r.Sum})
+	}
+	return res, nil
+}
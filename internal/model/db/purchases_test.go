@@ -38,6 +38,7 @@ func Test_AddPurchase(t *testing.T) {
 		Sum:        100,
 		CategoryID: 1,
 		Date:       nowTime,
+		Currency:   currency.USD,
 		USDRatio:   1,
 		CNYRatio:   1,
 		EURRatio:   1,
@@ -49,7 +50,7 @@ func Test_AddPurchase(t *testing.T) {
 	var purchases []purchaseTestRow
 	selectAllFromTestTablePurchases(ctx, s, &purchases)
 
-	assert.EqualValues(t, []purchaseTestRow{{Sum: 100, UserID: 123, CategoryID: 1, USDRatio: 1, CNYRatio: 1, EURRatio: 1}}, purchases)
+	assert.EqualValues(t, []purchaseTestRow{{Sum: 100, UserID: 123, CategoryID: 1, Currency: USD, USDRatio: 1, CNYRatio: 1, EURRatio: 1}}, purchases)
 }
 
 func Test_GetUserPurchasesFromDate(t *testing.T) {
@@ -74,13 +75,83 @@ func Test_GetUserPurchasesFromDate(t *testing.T) {
 	res, err := s.GetUserPurchasesFromDate(ctx, fromTime, 123)
 
 	assert.NoError(t, err)
+	assert.Equal(t, []string{"2022-10-01", "2022-10-06", "2022-10-24"}, purchaseDays(res))
 	assert.EqualValues(t, []model.Purchase{
 		{PurchaseCategory: "some category 1", Summa: 200, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
-		{PurchaseCategory: "Не заданная категория", Summa: 300, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
-		{PurchaseCategory: "some category 2", Summa: 400, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
+		{PurchaseCategory: "Не заданная категория", Summa: 300, Currency: currency.USD, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
+		{PurchaseCategory: "some category 2", Summa: 400, UnknownCurrency: true, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
 	}, res)
 }
 
+func Test_HasUserPurchasesFrom(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/get_user_purchases_from_date.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	has, err := s.HasUserPurchasesFrom(ctx, 123, time.Date(2022, 10, 24, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	has, err = s.HasUserPurchasesFrom(ctx, 123, time.Date(2022, 10, 25, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
+func Test_GetUserPurchasesBetween(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/get_user_purchases_from_date.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	// конец периода не включается
+	from := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 10, 24, 0, 0, 0, 0, time.UTC)
+	res, err := s.GetUserPurchasesBetween(ctx, from, to, 123)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2022-10-01", "2022-10-06"}, purchaseDays(res))
+	assert.EqualValues(t, []model.Purchase{
+		{PurchaseCategory: "some category 1", Summa: 200, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
+		{PurchaseCategory: "Не заданная категория", Summa: 300, Currency: currency.USD, RateToRUB: currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}},
+	}, res)
+}
+
+// purchaseDays дни трат, сами даты у трат обнуляются: время из базы приходит в часовом поясе сессии,
+// поэтому сравнивать его целиком с ожидаемым нельзя
+func purchaseDays(purchases []model.Purchase) []string {
+	days := make([]string, len(purchases))
+	for i := range purchases {
+		days[i] = purchases[i].Date.Format("2006-01-02")
+		purchases[i].Date = time.Time{}
+	}
+	return days
+}
+
 func Test_GetUserPurchasesSumFromMonth(t *testing.T) {
 	t.Parallel()
 
@@ -114,4 +185,68 @@ func Test_GetUserPurchasesSumFromMonth(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, float64(600), res)
 	})
+
+	t.Run("границы месяца в часовом поясе пользователя", func(t *testing.T) {
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+
+		// трата 30.11 в 14:30 по UTC во Владивостоке сделана уже 1 декабря
+		res, err := s.GetUserPurchasesSumFromMonth(ctx, 345, time.Date(2022, 12, 15, 0, 0, 0, 0, vladivostok))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100), res)
+
+		res, err = s.GetUserPurchasesSumFromMonth(ctx, 345, time.Date(2022, 11, 15, 0, 0, 0, 0, vladivostok))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(0), res)
+
+		// а в UTC это еще ноябрь
+		res, err = s.GetUserPurchasesSumFromMonth(ctx, 345, time.Date(2022, 11, 15, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100), res)
+	})
+}
+
+func Test_GetUserDailySums(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/get_user_purchases_sum_from_month.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	t.Run("суммы по дням, конец периода не включается", func(t *testing.T) {
+		from := time.Date(2022, 11, 27, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2022, 12, 9, 0, 0, 0, 0, time.UTC)
+		res, err := s.GetUserDailySums(ctx, 123, from, to)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.DailySum{
+			{Day: time.Date(2022, 11, 27, 0, 0, 0, 0, time.UTC), Summa: 100},
+			{Day: time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC), Summa: 100},
+			{Day: time.Date(2022, 12, 6, 0, 0, 0, 0, time.UTC), Summa: 200},
+		}, res)
+	})
+
+	t.Run("дни в часовом поясе пользователя", func(t *testing.T) {
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+
+		from := time.Date(2022, 11, 1, 0, 0, 0, 0, vladivostok)
+		res, err := s.GetUserDailySums(ctx, 345, from, from.AddDate(0, 2, 0))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.DailySum{
+			{Day: time.Date(2022, 12, 1, 0, 0, 0, 0, vladivostok), Summa: 100},
+		}, res)
+	})
 }
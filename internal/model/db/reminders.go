@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+type reminder struct {
+	UserID   int64          `db:"id"`
+	RemindAt int            `db:"remind_at"`
+	Lang     sql.NullString `db:"lang"`
+	Timezone sql.NullString `db:"tz"`
+}
+
+// ChangeReminder время ежедневного напоминания в минутах от полуночи, отрицательное - напоминание выключено
+func (s *Service) ChangeReminder(ctx context.Context, userID int64, remindAt int) error {
+	if err := s.UserCreateIfNotExist(ctx, userID); err != nil {
+		return errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	value := sql.NullInt16{Int16: int16(remindAt), Valid: remindAt >= 0}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update(tblUsers).
+		Set(tblUsersColRemindAt, value).
+		Where(sq.Eq{tblUsersColID: userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// GetReminders все включенные напоминания
+func (s *Service) GetReminders(ctx context.Context) ([]model.Reminder, error) {
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblUsersColID, tblUsersColRemindAt, tblUsersColLang, tblUsersColTimezone).
+		From(tblUsers).
+		Where(sq.NotEq{tblUsersColRemindAt: nil}).
+		OrderBy(tblUsersColID).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []reminder
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	reminders := make([]model.Reminder, len(rows))
+	for i := range rows {
+		reminders[i] = model.Reminder{
+			UserID:   rows[i].UserID,
+			At:       rows[i].RemindAt,
+			Lang:     i18n.Lang(rows[i].Lang.String),
+			Timezone: rows[i].Timezone.String,
+		}
+	}
+
+	return reminders, nil
+}
@@ -0,0 +1,35 @@
+//go:build test_all || integration_test
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_Reminders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	assert.NoError(t, s.ChangeReminder(ctx, 123, 21*60))
+	assert.NoError(t, s.ChangeLang(ctx, 123, i18n.EN))
+	assert.NoError(t, s.ChangeTimezone(ctx, 123, "Asia/Vladivostok"))
+	assert.NoError(t, s.ChangeReminder(ctx, 124, 9*60))
+	// выключенное напоминание не возвращается
+	assert.NoError(t, s.ChangeReminder(ctx, 124, -1))
+	assert.NoError(t, s.UserCreateIfNotExist(ctx, 125))
+
+	reminders, err := s.GetReminders(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Reminder{
+		{UserID: 123, At: 21 * 60, Lang: i18n.EN, Timezone: "Asia/Vladivostok"},
+	}, reminders)
+}
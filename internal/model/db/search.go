@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+type foundPurchase struct {
+	Timestamp    time.Time      `db:"ts"`
+	CategoryName string         `db:"category_name"`
+	Description  string         `db:"description"`
+	Note         string         `db:"note"`
+	Tags         pq.StringArray `db:"tags"`
+	Sum          float64        `db:"sum"` // сумма траты в рублях
+
+	// коэффициенты валют на момент совершения траты
+	USDRatio float64 `db:"usd_ratio"`
+	CNYRatio float64 `db:"cny_ratio"`
+	EURRatio float64 `db:"eur_ratio"`
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы пользовательский текст искался как есть
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// sumInCurrency выражение для суммы траты в валюте cy по курсу на момент траты
+func sumInCurrency(cy currency.Currency) (string, error) {
+	col := tblPurchases + "." + tblPurchasesColSum
+	switch cy {
+	case currency.RUB:
+		return col, nil
+	case currency.USD:
+		return col + " * " + tblPurchasesColUSDRatio, nil
+	case currency.EUR:
+		return col + " * " + tblPurchasesColEURRatio, nil
+	case currency.CNY:
+		return col + " * " + tblPurchasesColCNYRatio, nil
+	default:
+		return "", errors.New("invalid currency")
+	}
+}
+
+// SearchPurchases найти траты пользователя по фильтру, от новых к старым
+func (s *Service) SearchPurchases(ctx context.Context, filter model.SearchFilter, limit, offset uint64) ([]model.FoundPurchase, error) {
+	if filter.UserID == 0 {
+		return nil, errors.New("user is empty")
+	}
+
+	query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblPurchases+"."+tblPurchasesColTimestamp, tblCategories+"."+tblCategoriesColCategoryName,
+			tblPurchasesColDescription, tblPurchasesColNote, tblPurchasesColTags, tblPurchases+"."+tblPurchasesColSum,
+			tblPurchasesColUSDRatio, tblPurchasesColCNYRatio, tblPurchasesColEURRatio).
+		From(tblPurchases).
+		Join(fmt.Sprintf("%s ON %s.%s = %s.%s", tblCategories,
+			tblPurchases, tblPurchasesColCategoryID, tblCategories, tblCategoriesColID)).
+		Where(sq.Eq{tblPurchasesColUserID: filter.UserID})
+
+	for _, w := range filter.Words {
+		pattern := "%" + likeEscaper.Replace(w) + "%"
+		query = query.Where(sq.Or{
+			sq.ILike{tblCategories + "." + tblCategoriesColCategoryName: pattern},
+			sq.ILike{tblPurchasesColNote: pattern},
+			sq.ILike{tblPurchasesColDescription: pattern},
+		})
+	}
+
+	if filter.MinSum != 0 || filter.MaxSum != 0 {
+		sumExpr, err := sumInCurrency(filter.Currency)
+		if err != nil {
+			return nil, errors.Wrap(err, "sumInCurrency")
+		}
+		if filter.MinSum != 0 {
+			query = query.Where(sq.Expr(sumExpr+" >= ?", filter.MinSum))
+		}
+		if filter.MaxSum != 0 {
+			query = query.Where(sq.Expr(sumExpr+" <= ?", filter.MaxSum))
+		}
+	}
+
+	nilTime := time.Time{}
+	if filter.FromDate != nilTime {
+		query = query.Where(sq.GtOrEq{tblPurchases + "." + tblPurchasesColTimestamp: filter.FromDate})
+	}
+	if filter.ToDate != nilTime {
+		// ToDate включительно, поэтому сравниваем с началом следующего дня
+		query = query.Where(sq.Lt{tblPurchases + "." + tblPurchasesColTimestamp: filter.ToDate.AddDate(0, 0, 1)})
+	}
+
+	q, args, err := query.
+		OrderBy(tblPurchases+"."+tblPurchasesColTimestamp+" DESC", tblPurchases+"."+tblPurchasesColID+" DESC").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []foundPurchase
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	res := make([]model.FoundPurchase, len(rows))
+	for i, r := range rows {
+		res[i] = model.FoundPurchase{
+			Date:        r.Timestamp,
+			Category:    r.CategoryName,
+			Description: r.Description,
+			Note:        r.Note,
+			Tags:        r.Tags,
+			Summa:       r.Sum,
+			RateToRUB: currency.RateToRUB{
+				USD: r.USDRatio,
+				CNY: r.CNYRatio,
+				EUR: r.EURRatio,
+			},
+		}
+	}
+
+	return res, nil
+}
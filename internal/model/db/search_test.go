@@ -0,0 +1,106 @@
+//go:build test_all || integration_test
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-testfixtures/testfixtures/v3"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_SearchPurchases(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/search_purchases.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	date := func(raw string) time.Time {
+		d, _ := time.Parse("02.01.2006", raw)
+		return d
+	}
+	sums := func(res []model.FoundPurchase) []float64 {
+		r := make([]float64, len(res))
+		for i := range res {
+			r[i] = res[i].Summa
+		}
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		filter model.SearchFilter
+		limit  uint64
+		offset uint64
+		want   []float64
+	}{
+		{
+			name:   "по описанию и заметке, от новых к старым",
+			filter: model.SearchFilter{UserID: 123, Words: []string{"такси"}},
+			limit:  10,
+			want:   []float64{6000, 600},
+		},
+		{
+			name:   "по категории",
+			filter: model.SearchFilter{UserID: 123, Words: []string{"ЕДА"}},
+			limit:  10,
+			want:   []float64{1200},
+		},
+		{
+			name:   "спецсимволы like ищутся как есть",
+			filter: model.SearchFilter{UserID: 123, Words: []string{"100%_"}},
+			limit:  10,
+			want:   []float64{1200},
+		},
+		{
+			name:   "сумма в валюте пользователя",
+			filter: model.SearchFilter{UserID: 123, MinSum: 500, Currency: currency.USD},
+			limit:  10,
+			want:   []float64{6000, 1200},
+		},
+		{
+			name:   "диапазон дат включительно",
+			filter: model.SearchFilter{UserID: 123, FromDate: date("01.03.2024"), ToDate: date("15.03.2024")},
+			limit:  10,
+			want:   []float64{1200, 6000},
+		},
+		{
+			name:   "условия комбинируются",
+			filter: model.SearchFilter{UserID: 123, Words: []string{"такси"}, MaxSum: 1000, FromDate: date("01.02.2024"), ToDate: date("29.02.2024")},
+			limit:  10,
+			want:   []float64{600},
+		},
+		{
+			name:   "страница",
+			filter: model.SearchFilter{UserID: 123},
+			limit:  1,
+			offset: 1,
+			want:   []float64{6000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.SearchPurchases(ctx, tt.filter, tt.limit, tt.offset)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, sums(res))
+		})
+	}
+}
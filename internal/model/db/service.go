@@ -14,20 +14,43 @@ var (
 	tblUsersColCurrency      = "curr"
 	tblUsersColLimit         = "month_limit"
 	tblUsersColCategoriesIDs = "category_ids"
+	tblUsersColLang          = "lang"
+	tblUsersColTimezone      = "tz"
+	tblUsersColRemindAt      = "remind_at"
+	tblUsersColDigest        = "digest"
+	tblUsersColConfirm       = "confirm_unusual"
 
 	tblCategories                = "categories"
 	tblCategoriesColID           = "id"
 	tblCategoriesColCategoryName = "category_name"
 
-	tblPurchases = "purchases"
-	//tblPurchasesColID         = "id"
-	tblPurchasesColCategoryID = "category_id"
-	tblPurchasesColUserID     = "user_id"
-	tblPurchasesColSum        = "sum"
-	tblPurchasesColTimestamp  = "ts"
-	tblPurchasesColEURRatio   = "eur_ratio"
-	tblPurchasesColUSDRatio   = "usd_ratio"
-	tblPurchasesColCNYRatio   = "cny_ratio"
+	tblPurchases               = "purchases"
+	tblPurchasesColID          = "id"
+	tblPurchasesColCategoryID  = "category_id"
+	tblPurchasesColUserID      = "user_id"
+	tblPurchasesColSum         = "sum"
+	tblPurchasesColTimestamp   = "ts"
+	tblPurchasesColEURRatio    = "eur_ratio"
+	tblPurchasesColUSDRatio    = "usd_ratio"
+	tblPurchasesColCNYRatio    = "cny_ratio"
+	tblPurchasesColDescription = "description"
+	tblPurchasesColNote        = "note"
+	tblPurchasesColTags        = "tags"
+	tblPurchasesColCurrency    = "curr"
+
+	tblCategoryRules              = "category_rules"
+	tblCategoryRulesColID         = "id"
+	tblCategoryRulesColUserID     = "user_id"
+	tblCategoryRulesColType       = "rule_type"
+	tblCategoryRulesColPattern    = "pattern"
+	tblCategoryRulesColMinSum     = "min_sum"
+	tblCategoryRulesColMaxSum     = "max_sum"
+	tblCategoryRulesColCategoryID = "category_id"
+
+	tblCategoryStats              = "category_stats"
+	tblCategoryStatsColUserID     = "user_id"
+	tblCategoryStatsColCategoryID = "category_id"
+	tblCategoryStatsColSamples    = "samples"
 
 	tblRate            = "rate"
 	tblRateColDate     = "date"
@@ -36,6 +59,9 @@ var (
 	tblRateColCNYRatio = "cny_ratio"
 )
 
+// defaultCategoryID id категории, в которую попадают траты без указанной категории
+const defaultCategoryID = 1
+
 var (
 	ErrUserDoesntExists      = errors.New("user doesnt exists")
 	ErrCategoryAlreadyExists = errors.New("category is already exists")
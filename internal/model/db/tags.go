@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// tagsArray колонка tags не может быть NULL, поэтому вместо nil пишем пустой массив
+func tagsArray(tags []string) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return tags
+}
+
+// GetUserPurchasesFromDateWithTags получить траты пользователя начиная с даты, у которых есть все указанные теги
+func (s *Service) GetUserPurchasesFromDateWithTags(ctx context.Context, fromDate time.Time, userID int64, tags []string) ([]model.Purchase, error) {
+	if err := s.UserCreateIfNotExist(ctx, userID); err != nil {
+		return nil, errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(tblPurchasesColTimestamp, tblPurchasesColSum, tblCategoriesColCategoryName, tblPurchasesColDescription,
+			tblPurchasesColCurrency, tblPurchasesColUSDRatio, tblPurchasesColCNYRatio, tblPurchasesColEURRatio).
+		From(tblPurchases).
+		LeftJoin(fmt.Sprintf("%s ON %s.%s = %s.%s", tblCategories,
+			tblPurchases, tblPurchasesColCategoryID, tblCategories, tblCategoriesColID)).
+		Where(sq.Eq{tblPurchasesColUserID: userID}).
+		Where(sq.GtOrEq{tblPurchasesColTimestamp: fromDate}).
+		Where(sq.Expr(tblPurchasesColTags+" @> ?", tagsArray(tags))).
+		OrderBy(tblPurchasesColTimestamp).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []purchase
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	return toModelPurchases(rows)
+}
+
+type taggedPurchase struct {
+	Tag string  `db:"tag"`
+	Sum float64 `db:"sum"` // сумма траты в рублях
+
+	// коэффициенты валют на момент совершения траты
+	USDRatio float64 `db:"usd_ratio"`
+	CNYRatio float64 `db:"cny_ratio"`
+	EURRatio float64 `db:"eur_ratio"`
+}
+
+// GetUserTaggedPurchases получить все траты пользователя с тегами. Трата с несколькими тегами вернется по разу на каждый тег
+func (s *Service) GetUserTaggedPurchases(ctx context.Context, userID int64) ([]model.TaggedPurchase, error) {
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("unnest("+tblPurchasesColTags+") AS tag", tblPurchasesColSum,
+			tblPurchasesColUSDRatio, tblPurchasesColCNYRatio, tblPurchasesColEURRatio).
+		From(tblPurchases).
+		Where(sq.Eq{tblPurchasesColUserID: userID}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "query creating error")
+	}
+
+	var rows []taggedPurchase
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, errors.Wrap(err, "db.SelectContext")
+	}
+
+	res := make([]model.TaggedPurchase, len(rows))
+	for i, r := range rows {
+		res[i] = model.TaggedPurchase{
+			Tag:   r.Tag,
+			Summa: r.Sum,
+			RateToRUB: currency.RateToRUB{
+				USD: r.USDRatio,
+				CNY: r.CNYRatio,
+				EUR: r.EURRatio,
+			},
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,87 @@
+//go:build test_all || integration_test
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-testfixtures/testfixtures/v3"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_GetUserPurchasesFromDateWithTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/tagged_purchases.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	fromTime, _ := time.Parse("02.01.2006", "01.10.2022")
+	rates := currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}
+
+	t.Run("один тег", func(t *testing.T) {
+		res, err := s.GetUserPurchasesFromDateWithTags(ctx, fromTime, 123, []string{"москва"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2022-10-01", "2022-10-06"}, purchaseDays(res))
+		assert.EqualValues(t, []model.Purchase{
+			{PurchaseCategory: "еда", Summa: 200, RateToRUB: rates},
+			{PurchaseCategory: "Не заданная категория", Summa: 300, RateToRUB: rates},
+		}, res)
+	})
+
+	t.Run("трата должна содержать все теги", func(t *testing.T) {
+		res, err := s.GetUserPurchasesFromDateWithTags(ctx, fromTime, 123, []string{"москва", "командировка"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2022-10-01"}, purchaseDays(res))
+		assert.EqualValues(t, []model.Purchase{
+			{PurchaseCategory: "еда", Summa: 200, RateToRUB: rates},
+		}, res)
+	})
+}
+
+func Test_GetUserTaggedPurchases(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	fixtures, err := testfixtures.New(
+		testfixtures.Database(s.db.DB),
+		testfixtures.Dialect("postgres"),
+		testfixtures.DangerousSkipTestDatabaseCheck(),
+		testfixtures.FilesMultiTables(
+			"./../../../test_data/fixtures/tagged_purchases.yml",
+		),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Load())
+
+	res, err := s.GetUserTaggedPurchases(ctx, 123)
+
+	assert.NoError(t, err)
+	rates := currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}
+	assert.ElementsMatch(t, []model.TaggedPurchase{
+		{Tag: "командировка", Summa: 100, RateToRUB: rates},
+		{Tag: "командировка", Summa: 200, RateToRUB: rates},
+		{Tag: "москва", Summa: 200, RateToRUB: rates},
+		{Tag: "москва", Summa: 300, RateToRUB: rates},
+	}, res)
+}
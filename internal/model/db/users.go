@@ -8,14 +8,19 @@ import (
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 )
 
 type user struct {
-	UserID      int64         `db:"id"`
-	Currency    Currency      `db:"curr"` // выбранная пользователем валюта
-	CategoryIDs pq.Int64Array `db:"category_ids"`
-	Limit       float64       `db:"month_limit"`
+	UserID      int64          `db:"id"`
+	Currency    Currency       `db:"curr"` // выбранная пользователем валюта
+	CategoryIDs pq.Int64Array  `db:"category_ids"`
+	Limit       float64        `db:"month_limit"`
+	Lang        sql.NullString `db:"lang"` // NULL, если пользователь не выбирал язык
+	Timezone    sql.NullString `db:"tz"`   // NULL, если пользователь не выбирал часовой пояс
+	// ConfirmUnusual спрашивать ли подтверждение перед записью необычно крупной траты
+	ConfirmUnusual bool `db:"confirm_unusual"`
 }
 
 // Currency тип валюты
@@ -144,6 +149,72 @@ func (s *Service) ChangeCurrency(ctx context.Context, userID int64, currency cur
 	return nil
 }
 
+// ChangeLang смена языка пользователя
+func (s *Service) ChangeLang(ctx context.Context, userID int64, lang i18n.Lang) error {
+	if err := s.UserCreateIfNotExist(ctx, userID); err != nil {
+		return errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update(tblUsers).
+		Set(tblUsersColLang, string(lang)).
+		Where(sq.Eq{tblUsersColID: userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// ChangeConfirmUnusual спрашивать ли подтверждение перед записью необычно крупной траты
+func (s *Service) ChangeConfirmUnusual(ctx context.Context, userID int64, confirm bool) error {
+	if err := s.UserCreateIfNotExist(ctx, userID); err != nil {
+		return errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update(tblUsers).
+		Set(tblUsersColConfirm, confirm).
+		Where(sq.Eq{tblUsersColID: userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// ChangeTimezone смена часового пояса пользователя (название из базы IANA, например Asia/Vladivostok)
+func (s *Service) ChangeTimezone(ctx context.Context, userID int64, timezone string) error {
+	if err := s.UserCreateIfNotExist(ctx, userID); err != nil {
+		return errors.Wrap(err, "UserCreateIfNotExist")
+	}
+
+	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update(tblUsers).
+		Set(tblUsersColTimezone, timezone).
+		Where(sq.Eq{tblUsersColID: userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "query creating error")
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
 // GetUserInfo возвращает информацию о пользователе в формате модели
 func (s *Service) GetUserInfo(ctx context.Context, userID int64) (model.User, error) {
 	res, err := s.getUserInfo(ctx, userID)
@@ -172,13 +243,18 @@ func (s *Service) GetUserInfo(ctx context.Context, userID int64) (model.User, er
 		Currency:   curr,
 		Categories: res.CategoryIDs,
 		Limit:      res.Limit,
+		Lang:       i18n.Lang(res.Lang.String),
+		Timezone:   res.Timezone.String,
+
+		ConfirmUnusual: res.ConfirmUnusual,
 	}, nil
 }
 
 // getUserInfo возвращает информацию о пользователе (для использования внутри пакета)
 func (s *Service) getUserInfo(ctx context.Context, userID int64) (user, error) {
 	q, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
-		Select(tblUsersColID, tblUsersColCurrency, tblUsersColLimit, tblUsersColCategoriesIDs).
+		Select(tblUsersColID, tblUsersColCurrency, tblUsersColLimit, tblUsersColCategoriesIDs, tblUsersColLang, tblUsersColTimezone,
+			tblUsersColConfirm).
 		From(tblUsers).
 		Where(sq.Eq{
 			tblUsersColID: userID,
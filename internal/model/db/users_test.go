@@ -10,6 +10,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	model "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 )
 
@@ -43,6 +44,46 @@ func Test_ChangeCurrency(t *testing.T) {
 	})
 }
 
+func Test_ChangeLang(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	// пока язык не выбран, он пустой
+	info, err := s.GetUserInfo(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, i18n.Lang(""), info.Lang)
+
+	err = s.ChangeLang(ctx, 123, i18n.EN)
+	assert.NoError(t, err)
+
+	info, err = s.GetUserInfo(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, i18n.EN, info.Lang)
+}
+
+func Test_ChangeTimezone(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, close := newTestDB(ctx, t)
+	defer close()
+
+	// пока часовой пояс не выбран, он пустой
+	info, err := s.GetUserInfo(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, "", info.Timezone)
+
+	err = s.ChangeTimezone(ctx, 123, "Asia/Vladivostok")
+	assert.NoError(t, err)
+
+	info, err = s.GetUserInfo(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Vladivostok", info.Timezone)
+}
+
 func Test_GetUserInfo(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,112 @@
+// Package forecast прогноз трат на конец месяца по тратам за прошедшие дни месяца и истории пользователя
+package forecast
+
+// NoLimit лимит не задан
+const NoLimit = -1
+
+// Input данные для прогноза. Все суммы в одной валюте
+type Input struct {
+	// Daily траты по дням текущего месяца с первого числа по сегодня включительно
+	Daily []float64
+	// DaysInMonth сколько дней в текущем месяце
+	DaysInMonth int
+	// History траты по дням перед первым числом текущего месяца, последний элемент - последний день прошлого месяца.
+	// Дни до первой траты не учитываются: пользователь мог начать записывать траты недавно
+	History []float64
+	// Limit месячный лимит, NoLimit - лимита нет
+	Limit float64
+}
+
+// Forecast прогноз на месяц
+type Forecast struct {
+	// Actual накопленные траты по дням месяца по сегодня включительно
+	Actual []float64
+	// Projected накопленные траты по дням на весь месяц: по сегодня - фактические, дальше - прогноз
+	Projected []float64
+	// DailyRate сколько в среднем будет тратиться в оставшиеся дни
+	DailyRate float64
+	// Total прогноз трат на конец месяца
+	Total float64
+	// LimitDay день месяца, в который траты превысят лимит (или уже превысили), 0 - лимит не будет превышен
+	LimitDay int
+}
+
+// LimitExceeded превышен ли лимит уже сегодня или раньше
+func (f Forecast) LimitExceeded() bool {
+	return f.LimitDay != 0 && f.LimitDay <= len(f.Actual)
+}
+
+// Project прогноз трат на конец месяца. Оставшиеся дни считаются по среднему за день: в начале месяца оно
+// берется в основном из истории, к концу месяца - из трат текущего месяца. Без истории используются только
+// траты текущего месяца
+func Project(in Input) Forecast {
+	today := len(in.Daily)
+	if today > in.DaysInMonth {
+		today = in.DaysInMonth
+	}
+
+	res := Forecast{
+		Actual:    make([]float64, today),
+		Projected: make([]float64, in.DaysInMonth),
+	}
+
+	var spent float64
+	for day := 0; day < today; day++ {
+		spent += in.Daily[day]
+		res.Actual[day] = spent
+		res.Projected[day] = spent
+	}
+
+	res.DailyRate = dailyRate(spent, today, in.DaysInMonth, in.History)
+	for day := today; day < in.DaysInMonth; day++ {
+		res.Projected[day] = spent + res.DailyRate*float64(day-today+1)
+	}
+	if in.DaysInMonth > 0 {
+		res.Total = res.Projected[in.DaysInMonth-1]
+	}
+
+	if in.Limit != NoLimit {
+		for day, sum := range res.Projected {
+			if sum > in.Limit {
+				res.LimitDay = day + 1
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// dailyRate средние траты в день для оставшихся дней месяца
+func dailyRate(spent float64, today, daysInMonth int, history []float64) float64 {
+	var current float64
+	if today > 0 {
+		current = spent / float64(today)
+	}
+
+	past, ok := historyRate(history)
+	if !ok || daysInMonth == 0 {
+		return current
+	}
+
+	// чем больше дней месяца прошло, тем больше доверяем тратам этого месяца
+	weight := float64(today) / float64(daysInMonth)
+	return weight*current + (1-weight)*past
+}
+
+// historyRate средние траты в день с первой траты в истории. false, если трат в истории нет
+func historyRate(history []float64) (float64, bool) {
+	first := 0
+	for first < len(history) && history[first] == 0 {
+		first++
+	}
+	if first == len(history) {
+		return 0, false
+	}
+
+	var sum float64
+	for _, s := range history[first:] {
+		sum += s
+	}
+	return sum / float64(len(history)-first), true
+}
@@ -0,0 +1,108 @@
+//go:build test_all || unit_test
+
+package forecast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Project(t *testing.T) {
+	t.Run("без истории прогноз по среднему за текущий месяц", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{100, 0, 200, 100},
+			DaysInMonth: 10,
+			Limit:       NoLimit,
+		})
+
+		assert.Equal(t, []float64{100, 100, 300, 400}, res.Actual)
+		assert.Equal(t, []float64{100, 100, 300, 400, 500, 600, 700, 800, 900, 1000}, res.Projected)
+		assert.Equal(t, float64(100), res.DailyRate)
+		assert.Equal(t, float64(1000), res.Total)
+		assert.Equal(t, 0, res.LimitDay)
+		assert.False(t, res.LimitExceeded())
+	})
+
+	t.Run("в начале месяца прогноз в основном по истории", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{300},
+			DaysInMonth: 10,
+			History:     []float64{100, 100, 100},
+			Limit:       NoLimit,
+		})
+
+		// 0.1*300 + 0.9*100
+		assert.InDelta(t, 120, res.DailyRate, 1e-9)
+		assert.InDelta(t, 300+9*120, res.Total, 1e-9)
+	})
+
+	t.Run("дни истории до первой траты не учитываются", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{0, 0},
+			DaysInMonth: 4,
+			History:     []float64{0, 0, 0, 50, 150},
+			Limit:       NoLimit,
+		})
+
+		// 0.5*0 + 0.5*100
+		assert.InDelta(t, 50, res.DailyRate, 1e-9)
+		assert.Equal(t, []float64{0, 0, 50, 100}, res.Projected)
+	})
+
+	t.Run("история без трат не учитывается", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{100},
+			DaysInMonth: 3,
+			History:     []float64{0, 0},
+			Limit:       NoLimit,
+		})
+
+		assert.Equal(t, float64(100), res.DailyRate)
+		assert.Equal(t, float64(300), res.Total)
+	})
+
+	t.Run("день превышения лимита по прогнозу", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{100, 100},
+			DaysInMonth: 10,
+			Limit:       550,
+		})
+
+		assert.Equal(t, 6, res.LimitDay)
+		assert.False(t, res.LimitExceeded())
+	})
+
+	t.Run("лимит равен прогнозу - не превышен", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{100, 100},
+			DaysInMonth: 10,
+			Limit:       1000,
+		})
+
+		assert.Equal(t, 0, res.LimitDay)
+	})
+
+	t.Run("лимит уже превышен", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{100, 500, 0},
+			DaysInMonth: 5,
+			Limit:       500,
+		})
+
+		assert.Equal(t, 2, res.LimitDay)
+		assert.True(t, res.LimitExceeded())
+	})
+
+	t.Run("последний день месяца", func(t *testing.T) {
+		res := Project(Input{
+			Daily:       []float64{100, 200, 300},
+			DaysInMonth: 3,
+			History:     []float64{1000},
+			Limit:       NoLimit,
+		})
+
+		assert.Equal(t, res.Actual, res.Projected)
+		assert.Equal(t, float64(600), res.Total)
+	})
+}
@@ -0,0 +1,385 @@
+package freetext
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+)
+
+// ErrNotPurchase в тексте не нашлось суммы, значит это не трата
+var ErrNotPurchase = errors.New("text is not a purchase")
+
+// Purchase трата, разобранная из свободного текста
+type Purchase struct {
+	Sum         float64
+	RawSum      string // сумма в том виде, в котором ее понимает purchases.AddPurchase
+	Currency    currency.Currency
+	HasCurrency bool      // валюта указана явно, иначе трата в основной валюте пользователя
+	Date        time.Time // дата траты (начало дня)
+	HasDate     bool      // дата указана явно, иначе трата сегодняшняя
+	Description string    // все, что осталось от текста: категория или описание траты
+	Tags        []string  // хэштеги без решетки
+	Note        string    // заметка, написанная в кавычках
+}
+
+// maxLabelLen длина описания траты в байтах, после которой описание обрезается: длинный текст не помещается на кнопке
+const maxLabelLen = 64
+
+// String короткое описание траты, используется как текст кнопки при подтверждении и заголовок инлайн-ответа.
+// В данных кнопки передается номер варианта, а не этот текст, поэтому обрезка нужна только для читаемости
+func (p Purchase) String() string {
+	sum := p.RawSum
+	if p.HasCurrency {
+		sum += " " + currencySymbols[p.Currency]
+	}
+	var date string
+	if p.HasDate {
+		date = " · " + p.Date.Format("02.01.2006")
+	}
+
+	if p.Description == "" {
+		return sum + date
+	}
+
+	description := []rune(p.Description)
+	for len(sum)+len(" · ")+len(string(description))+len(date) > maxLabelLen && len(description) > 1 {
+		description = append(description[:len(description)-2], '…')
+	}
+
+	return sum + " · " + string(description) + date
+}
+
+var (
+	// amountToken сумма, к которой может быть приклеен символ или код валюты: 250, 250.50, 250,50, $5, 600₽, 600руб
+	amountToken = regexp.MustCompile(`^([$€¥₽])?(\d+(?:[.,]\d{1,2})?)([^\d.,]*)$`)
+	// fullDateToken дата в формате дд.мм.гггг
+	fullDateToken = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.(\d{4})$`)
+	// shortDateToken дата без года (дд.мм), ее легко спутать с дробной суммой
+	shortDateToken = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})$`)
+	// trimChars знаки препинания, которые отрезаются от краев слов
+	trimChars = ",;:!?()«»\"'"
+)
+
+// currencyWords слова и символы, которыми обозначают валюту
+var currencyWords = map[string]currency.Currency{
+	"₽": currency.RUB, "р": currency.RUB, "р.": currency.RUB, "руб": currency.RUB, "руб.": currency.RUB,
+	"рубль": currency.RUB, "рубля": currency.RUB, "рублей": currency.RUB, "rub": currency.RUB,
+
+	"$": currency.USD, "бакс": currency.USD, "бакса": currency.USD, "баксов": currency.USD, "доллар": currency.USD,
+	"доллара": currency.USD, "долларов": currency.USD, "usd": currency.USD,
+
+	"€": currency.EUR, "евро": currency.EUR, "eur": currency.EUR,
+
+	"¥": currency.CNY, "юань": currency.CNY, "юаня": currency.CNY, "юаней": currency.CNY, "cny": currency.CNY,
+}
+
+var currencySymbols = map[currency.Currency]string{
+	currency.RUB: "₽",
+	currency.USD: "$",
+	currency.EUR: "€",
+	currency.CNY: "¥",
+}
+
+// relativeDays слова, обозначающие дату относительно сегодняшнего дня
+var relativeDays = map[string]int{
+	"сегодня":   0,
+	"вчера":     -1,
+	"позавчера": -2,
+}
+
+// weekdays названия дней недели во всех формах, в которых их пишут в сообщениях о тратах
+var weekdays = map[string]time.Weekday{
+	"понедельник": time.Monday, "пн": time.Monday,
+	"вторник": time.Tuesday, "вт": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"четверг": time.Thursday, "чт": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+	"воскресенье": time.Sunday, "вс": time.Sunday,
+}
+
+// prepositions предлоги, которые относятся к дате или сумме и не должны попадать в описание
+var prepositions = map[string]struct{}{
+	"в": {}, "во": {}, "за": {}, "на": {},
+}
+
+type tokenKind byte
+
+const (
+	kindWord tokenKind = iota
+	kindAmount
+	kindMaybeDate // дд.мм без года: дата, если в тексте есть другая сумма, иначе сумма
+	kindDate
+	kindCurrency
+	kindPreposition
+)
+
+type token struct {
+	kind tokenKind
+	text string // исходный текст слова
+
+	amount      string // сумма в формате с точкой
+	currency    currency.Currency
+	hasCurrency bool
+	date        time.Time
+}
+
+// Parse разбирает свободный текст вида "кофе 250" или "вчера такси 600 руб" в трату.
+// Если текст можно понять несколькими способами (например, "2 кофе 250"), вернется несколько вариантов,
+// и пользователю нужно предложить выбрать правильный. now определяет "сегодня" для относительных дат
+func Parse(text string, now time.Time) ([]Purchase, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	text, tags, note := ExtractTagsAndNote(text)
+	tokens := tokenize(text, today)
+
+	var amounts []int
+	var maybeDates []int
+	for i, t := range tokens {
+		switch t.kind {
+		case kindAmount:
+			amounts = append(amounts, i)
+		case kindMaybeDate:
+			maybeDates = append(maybeDates, i)
+		}
+	}
+
+	// дд.мм считаем датой, только если сумма в тексте уже есть
+	for _, i := range maybeDates {
+		if len(amounts) > 0 {
+			tokens[i].kind = kindDate
+		} else {
+			tokens[i].kind = kindAmount
+			tokens[i].amount = strings.ReplaceAll(tokens[i].text, ",", ".")
+			amounts = append(amounts, i)
+		}
+	}
+	if len(amounts) == 0 {
+		return nil, ErrNotPurchase
+	}
+
+	// валюта, написанная отдельным словом, относится к сумме перед ней (или после нее, если перед ней суммы нет)
+	for i, t := range tokens {
+		if t.kind != kindCurrency {
+			continue
+		}
+		switch {
+		case i > 0 && tokens[i-1].kind == kindAmount && !tokens[i-1].hasCurrency:
+			tokens[i-1].currency, tokens[i-1].hasCurrency = t.currency, true
+		case i+1 < len(tokens) && tokens[i+1].kind == kindAmount && !tokens[i+1].hasCurrency:
+			tokens[i+1].currency, tokens[i+1].hasCurrency = t.currency, true
+		}
+	}
+
+	// если валюта указана ровно у одной суммы, то сумма траты - она, остальные числа - часть описания
+	var withCurrency []int
+	for _, i := range amounts {
+		if tokens[i].hasCurrency {
+			withCurrency = append(withCurrency, i)
+		}
+	}
+	if len(withCurrency) == 1 {
+		amounts = withCurrency
+	}
+
+	res := make([]Purchase, 0, len(amounts))
+	for _, sumIdx := range amounts {
+		p, err := buildPurchase(tokens, sumIdx, today)
+		if err != nil {
+			return nil, err
+		}
+		p.Tags, p.Note = tags, note
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+func buildPurchase(tokens []token, sumIdx int, today time.Time) (Purchase, error) {
+	sumToken := tokens[sumIdx]
+
+	sum, err := strconv.ParseFloat(sumToken.amount, 64)
+	if err != nil || sum <= 0 {
+		return Purchase{}, ErrNotPurchase
+	}
+
+	p := Purchase{
+		Sum:         sum,
+		RawSum:      sumToken.amount,
+		Currency:    sumToken.currency,
+		HasCurrency: sumToken.hasCurrency,
+		Date:        today,
+	}
+
+	var description []string
+	for i, t := range tokens {
+		switch t.kind {
+		case kindDate:
+			p.Date, p.HasDate = t.date, true
+
+		case kindAmount:
+			if i != sumIdx {
+				description = append(description, t.text)
+			}
+
+		case kindPreposition:
+			// предлог перед датой или суммой ("в пятницу", "за 250") в описание не попадает
+			if i+1 < len(tokens) && tokens[i+1].kind != kindWord && tokens[i+1].kind != kindPreposition &&
+				(tokens[i+1].kind != kindAmount || i+1 == sumIdx) {
+				continue
+			}
+			description = append(description, t.text)
+
+		case kindWord:
+			description = append(description, t.text)
+		}
+	}
+	p.Description = strings.Join(description, " ")
+
+	if p.HasDate && p.Date.After(today) {
+		return Purchase{}, errors.Wrap(ErrNotPurchase, "date in the future")
+	}
+
+	return p, nil
+}
+
+func tokenize(text string, today time.Time) []token {
+	fields := strings.Fields(text)
+	tokens := make([]token, 0, len(fields))
+
+	for _, f := range fields {
+		word := strings.Trim(f, trimChars)
+		if word == "" {
+			continue
+		}
+		lower := strings.ToLower(word)
+
+		if c, ok := currencyWords[lower]; ok {
+			tokens = append(tokens, token{kind: kindCurrency, text: word, currency: c, hasCurrency: true})
+			continue
+		}
+
+		if d, ok := relativeDays[lower]; ok {
+			tokens = append(tokens, token{kind: kindDate, text: word, date: today.AddDate(0, 0, d)})
+			continue
+		}
+
+		if wd, ok := weekdays[lower]; ok {
+			tokens = append(tokens, token{kind: kindDate, text: word, date: lastWeekday(today, wd)})
+			continue
+		}
+
+		if _, ok := prepositions[lower]; ok {
+			tokens = append(tokens, token{kind: kindPreposition, text: word})
+			continue
+		}
+
+		if res := fullDateToken.FindStringSubmatch(lower); len(res) == 4 {
+			if d, ok := makeDate(res[3], res[2], res[1], today.Location()); ok {
+				tokens = append(tokens, token{kind: kindDate, text: word, date: d})
+				continue
+			}
+		}
+
+		if res := shortDateToken.FindStringSubmatch(lower); len(res) == 3 {
+			if d, ok := makeDate(strconv.Itoa(today.Year()), res[2], res[1], today.Location()); ok {
+				// дата без года в будущем - это прошлогодняя дата
+				if d.After(today) {
+					d = d.AddDate(-1, 0, 0)
+				}
+				tokens = append(tokens, token{kind: kindMaybeDate, text: word, date: d})
+				continue
+			}
+		}
+
+		if res := amountToken.FindStringSubmatch(lower); len(res) == 4 {
+			t := token{kind: kindAmount, text: word, amount: strings.ReplaceAll(res[2], ",", ".")}
+
+			suffix := res[3]
+			switch {
+			case res[1] != "" && suffix == "":
+				t.currency, t.hasCurrency = currencyWords[res[1]], true
+			case res[1] == "" && suffix == "":
+			case res[1] == "":
+				c, ok := currencyWords[suffix]
+				if !ok {
+					// число, приклеенное к слову ("2шт", "5км"), - это часть описания
+					tokens = append(tokens, token{kind: kindWord, text: word})
+					continue
+				}
+				t.currency, t.hasCurrency = c, true
+			default:
+				tokens = append(tokens, token{kind: kindWord, text: word})
+				continue
+			}
+
+			tokens = append(tokens, t)
+			continue
+		}
+
+		tokens = append(tokens, token{kind: kindWord, text: word})
+	}
+
+	return tokens
+}
+
+// lastWeekday ближайший прошедший (или сегодняшний) день недели
+func lastWeekday(today time.Time, wd time.Weekday) time.Time {
+	diff := (int(today.Weekday()) - int(wd) + 7) % 7
+	return today.AddDate(0, 0, -diff)
+}
+
+func makeDate(rawYear, rawMonth, rawDay string, loc *time.Location) (time.Time, bool) {
+	y, errY := strconv.Atoi(rawYear)
+	m, errM := strconv.Atoi(rawMonth)
+	d, errD := strconv.Atoi(rawDay)
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}, false
+	}
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return time.Time{}, false
+	}
+
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, loc)
+	// 31.02 превратится в начало марта, такую дату не принимаем
+	if date.Day() != d {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+// CurrencySuffix код валюты, который можно приклеить к сумме в команде /add
+func (p Purchase) CurrencySuffix() string {
+	if !p.HasCurrency {
+		return ""
+	}
+	code, err := currency.CurrencyToStr(p.Currency)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(code)
+}
+
+// Command команда /add, равнозначная этой трате
+func (p Purchase) Command() string {
+	cmd := fmt.Sprintf("/add %s%s", p.RawSum, p.CurrencySuffix())
+	if p.Description != "" {
+		cmd += " " + p.Description
+	}
+	if p.HasDate {
+		cmd += " " + p.Date.Format("02.01.2006")
+	}
+	if len(p.Tags) != 0 {
+		cmd += " " + FormatTags(p.Tags)
+	}
+	if p.Note != "" {
+		cmd += ` "` + p.Note + `"`
+	}
+	return cmd
+}
@@ -0,0 +1,331 @@
+//go:build test_all || unit_test
+
+package freetext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+)
+
+// now среда, 13.03.2024, 15:04
+var now = time.Date(2024, time.March, 13, 15, 4, 0, 0, time.UTC)
+
+func date(d, m, y int) time.Time {
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
+
+func Test_Parse(t *testing.T) {
+	today := date(13, 3, 2024)
+
+	tests := []struct {
+		name string
+		text string
+		want []Purchase
+	}{
+		// суммы
+		{
+			name: "категория и сумма",
+			text: "кофе 250",
+			want: []Purchase{{Sum: 250, RawSum: "250", Date: today, Description: "кофе"}},
+		},
+		{
+			name: "сумма перед категорией",
+			text: "250 кофе",
+			want: []Purchase{{Sum: 250, RawSum: "250", Date: today, Description: "кофе"}},
+		},
+		{
+			name: "дробная сумма через точку",
+			text: "кофе 250.50",
+			want: []Purchase{{Sum: 250.5, RawSum: "250.50", Date: today, Description: "кофе"}},
+		},
+		{
+			name: "дробная сумма через запятую",
+			text: "кофе 250,5",
+			want: []Purchase{{Sum: 250.5, RawSum: "250.5", Date: today, Description: "кофе"}},
+		},
+		{
+			name: "только сумма",
+			text: "250",
+			want: []Purchase{{Sum: 250, RawSum: "250", Date: today}},
+		},
+		{
+			name: "описание из нескольких слов",
+			text: "Обед с коллегами 1200",
+			want: []Purchase{{Sum: 1200, RawSum: "1200", Date: today, Description: "Обед с коллегами"}},
+		},
+		{
+			name: "предлог перед суммой не попадает в описание",
+			text: "такси за 600",
+			want: []Purchase{{Sum: 600, RawSum: "600", Date: today, Description: "такси"}},
+		},
+		{
+			name: "знаки препинания по краям слов отбрасываются",
+			text: "кофе, 250!",
+			want: []Purchase{{Sum: 250, RawSum: "250", Date: today, Description: "кофе"}},
+		},
+		{
+			name: "число, приклеенное к слову, остается в описании",
+			text: "бензин 40л 2500",
+			want: []Purchase{{Sum: 2500, RawSum: "2500", Date: today, Description: "бензин 40л"}},
+		},
+
+		// валюты
+		{
+			name: "символ рубля после суммы через пробел",
+			text: "кофе 250 ₽",
+			want: []Purchase{{Sum: 250, RawSum: "250", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "кофе"}},
+		},
+		{
+			name: "символ рубля приклеен к сумме",
+			text: "кофе 250₽",
+			want: []Purchase{{Sum: 250, RawSum: "250", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "кофе"}},
+		},
+		{
+			name: "руб отдельным словом",
+			text: "такси 600 руб",
+			want: []Purchase{{Sum: 600, RawSum: "600", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "такси"}},
+		},
+		{
+			name: "руб. с точкой",
+			text: "такси 600 руб.",
+			want: []Purchase{{Sum: 600, RawSum: "600", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "такси"}},
+		},
+		{
+			name: "р приклеено к сумме",
+			text: "такси 600р",
+			want: []Purchase{{Sum: 600, RawSum: "600", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "такси"}},
+		},
+		{
+			name: "рублей",
+			text: "такси 600 рублей",
+			want: []Purchase{{Sum: 600, RawSum: "600", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "такси"}},
+		},
+		{
+			name: "доллар символом перед суммой",
+			text: "кофе $5",
+			want: []Purchase{{Sum: 5, RawSum: "5", Currency: currency.USD, HasCurrency: true, Date: today, Description: "кофе"}},
+		},
+		{
+			name: "доллар символом после суммы",
+			text: "кофе 5$",
+			want: []Purchase{{Sum: 5, RawSum: "5", Currency: currency.USD, HasCurrency: true, Date: today, Description: "кофе"}},
+		},
+		{
+			name: "баксы",
+			text: "подписка 10 баксов",
+			want: []Purchase{{Sum: 10, RawSum: "10", Currency: currency.USD, HasCurrency: true, Date: today, Description: "подписка"}},
+		},
+		{
+			name: "бакс в единственном числе",
+			text: "жвачка 1 бакс",
+			want: []Purchase{{Sum: 1, RawSum: "1", Currency: currency.USD, HasCurrency: true, Date: today, Description: "жвачка"}},
+		},
+		{
+			name: "код валюты в любом регистре",
+			text: "отель 120 USD",
+			want: []Purchase{{Sum: 120, RawSum: "120", Currency: currency.USD, HasCurrency: true, Date: today, Description: "отель"}},
+		},
+		{
+			name: "евро символом",
+			text: "музей €15",
+			want: []Purchase{{Sum: 15, RawSum: "15", Currency: currency.EUR, HasCurrency: true, Date: today, Description: "музей"}},
+		},
+		{
+			name: "евро словом",
+			text: "музей 15 евро",
+			want: []Purchase{{Sum: 15, RawSum: "15", Currency: currency.EUR, HasCurrency: true, Date: today, Description: "музей"}},
+		},
+		{
+			name: "юани символом",
+			text: "чай 30¥",
+			want: []Purchase{{Sum: 30, RawSum: "30", Currency: currency.CNY, HasCurrency: true, Date: today, Description: "чай"}},
+		},
+		{
+			name: "юани словом",
+			text: "чай 30 юаней",
+			want: []Purchase{{Sum: 30, RawSum: "30", Currency: currency.CNY, HasCurrency: true, Date: today, Description: "чай"}},
+		},
+		{
+			name: "валюта перед суммой отдельным словом",
+			text: "кофе $ 5",
+			want: []Purchase{{Sum: 5, RawSum: "5", Currency: currency.USD, HasCurrency: true, Date: today, Description: "кофе"}},
+		},
+
+		// даты
+		{
+			name: "сегодня",
+			text: "сегодня кофе 250",
+			want: []Purchase{{Sum: 250, RawSum: "250", Date: today, HasDate: true, Description: "кофе"}},
+		},
+		{
+			name: "вчера",
+			text: "вчера такси 600 руб",
+			want: []Purchase{{Sum: 600, RawSum: "600", Currency: currency.RUB, HasCurrency: true, Date: date(12, 3, 2024), HasDate: true, Description: "такси"}},
+		},
+		{
+			name: "позавчера в конце текста",
+			text: "такси 600 позавчера",
+			want: []Purchase{{Sum: 600, RawSum: "600", Date: date(11, 3, 2024), HasDate: true, Description: "такси"}},
+		},
+		{
+			name: "день недели в прошлом",
+			text: "в понедельник кино 500",
+			want: []Purchase{{Sum: 500, RawSum: "500", Date: date(11, 3, 2024), HasDate: true, Description: "кино"}},
+		},
+		{
+			name: "день недели в винительном падеже",
+			text: "в пятницу бар 3000",
+			want: []Purchase{{Sum: 3000, RawSum: "3000", Date: date(8, 3, 2024), HasDate: true, Description: "бар"}},
+		},
+		{
+			name: "сокращенный день недели",
+			text: "сб рынок 1500",
+			want: []Purchase{{Sum: 1500, RawSum: "1500", Date: date(9, 3, 2024), HasDate: true, Description: "рынок"}},
+		},
+		{
+			name: "сегодняшний день недели - это сегодня",
+			text: "в среду обед 400",
+			want: []Purchase{{Sum: 400, RawSum: "400", Date: today, HasDate: true, Description: "обед"}},
+		},
+		{
+			name: "четверг - это прошлая неделя",
+			text: "четверг обед 400",
+			want: []Purchase{{Sum: 400, RawSum: "400", Date: date(7, 3, 2024), HasDate: true, Description: "обед"}},
+		},
+		{
+			name: "полная дата",
+			text: "кино 500 01.03.2024",
+			want: []Purchase{{Sum: 500, RawSum: "500", Date: date(1, 3, 2024), HasDate: true, Description: "кино"}},
+		},
+		{
+			name: "дата без года, если есть сумма",
+			text: "кино 500 01.03",
+			want: []Purchase{{Sum: 500, RawSum: "500", Date: date(1, 3, 2024), HasDate: true, Description: "кино"}},
+		},
+		{
+			name: "дата без года в будущем относится к прошлому году",
+			text: "кино 500 20.12",
+			want: []Purchase{{Sum: 500, RawSum: "500", Date: date(20, 12, 2023), HasDate: true, Description: "кино"}},
+		},
+		{
+			name: "дд.мм без другой суммы - это дробная сумма",
+			text: "кофе 12.03",
+			want: []Purchase{{Sum: 12.03, RawSum: "12.03", Date: today, Description: "кофе"}},
+		},
+		{
+			name: "несуществующая дата - это не дата",
+			text: "кофе 31.02.2024 250",
+			want: []Purchase{{Sum: 250, RawSum: "250", Date: today, Description: "кофе 31.02.2024"}},
+		},
+
+		// неоднозначные случаи
+		{
+			name: "два числа - два варианта",
+			text: "2 кофе 250",
+			want: []Purchase{
+				{Sum: 2, RawSum: "2", Date: today, Description: "кофе 250"},
+				{Sum: 250, RawSum: "250", Date: today, Description: "2 кофе"},
+			},
+		},
+		{
+			name: "валюта снимает неоднозначность",
+			text: "2 кофе 250 руб",
+			want: []Purchase{{Sum: 250, RawSum: "250", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "2 кофе"}},
+		},
+		{
+			name: "валюта у обеих сумм - все еще неоднозначно",
+			text: "обмен 100$ 9000₽",
+			want: []Purchase{
+				{Sum: 100, RawSum: "100", Currency: currency.USD, HasCurrency: true, Date: today, Description: "обмен 9000₽"},
+				{Sum: 9000, RawSum: "9000", Currency: currency.RUB, HasCurrency: true, Date: today, Description: "обмен 100$"},
+			},
+		},
+		{
+			name: "предлог остается в описании, только если следующее число не сумма",
+			text: "билеты на 2 300",
+			want: []Purchase{
+				{Sum: 2, RawSum: "2", Date: today, Description: "билеты 300"},
+				{Sum: 300, RawSum: "300", Date: today, Description: "билеты на 2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Parse(tt.text, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func Test_Parse_NotPurchase(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "пустая строка", text: ""},
+		{name: "нет суммы", text: "привет, как дела?"},
+		{name: "только дата", text: "вчера"},
+		{name: "только валюта", text: "руб"},
+		{name: "нулевая сумма", text: "кофе 0"},
+		{name: "дата в будущем", text: "кофе 250 01.01.2030"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text, now)
+
+			assert.ErrorIs(t, err, ErrNotPurchase)
+		})
+	}
+}
+
+func Test_Purchase_String(t *testing.T) {
+	t.Run("все поля", func(t *testing.T) {
+		p := Purchase{RawSum: "600", Currency: currency.USD, HasCurrency: true, Date: date(12, 3, 2024), HasDate: true, Description: "такси"}
+		assert.Equal(t, "600 $ · такси · 12.03.2024", p.String())
+	})
+
+	t.Run("только сумма", func(t *testing.T) {
+		p := Purchase{RawSum: "600"}
+		assert.Equal(t, "600", p.String())
+	})
+}
+
+func Test_Purchase_Command(t *testing.T) {
+	t.Run("с валютой и датой", func(t *testing.T) {
+		p := Purchase{RawSum: "600", Currency: currency.USD, HasCurrency: true, Date: date(12, 3, 2024), HasDate: true, Description: "такси"}
+		assert.Equal(t, "/add 600usd такси 12.03.2024", p.Command())
+	})
+
+	t.Run("без валюты и даты", func(t *testing.T) {
+		p := Purchase{RawSum: "250.5", Description: "кофе"}
+		assert.Equal(t, "/add 250.5 кофе", p.Command())
+	})
+
+	t.Run("дата без описания", func(t *testing.T) {
+		p := Purchase{RawSum: "250", Date: date(12, 3, 2024), HasDate: true}
+		assert.Equal(t, "/add 250 12.03.2024", p.Command())
+	})
+}
+
+func Test_Purchase_String_Long(t *testing.T) {
+	p := Purchase{
+		RawSum:      "1500",
+		Currency:    currency.RUB,
+		HasCurrency: true,
+		Date:        date(12, 3, 2024),
+		HasDate:     true,
+		Description: "подарок на день рождения коллеге из соседнего отдела",
+	}
+
+	label := p.String()
+
+	assert.LessOrEqual(t, len(label), maxLabelLen)
+	assert.Equal(t, "1500 ₽ · подарок на день рож… · 12.03.2024", label)
+}
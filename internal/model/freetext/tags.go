@@ -0,0 +1,47 @@
+package freetext
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// tagToken хэштег: #командировка, #москва_2024
+	tagToken = regexp.MustCompile(`#([\p{L}\d_]+)`)
+	// noteToken заметка в прямых или "елочных" кавычках
+	noteToken = regexp.MustCompile(`"([^"]*)"|«([^»]*)»`)
+)
+
+// ExtractTagsAndNote вырезает из текста хэштеги и заметку в кавычках, они могут стоять в любом месте.
+// Теги приводятся к нижнему регистру и не повторяются, несколько заметок склеиваются через пробел
+func ExtractTagsAndNote(text string) (rest string, tags []string, note string) {
+	var notes []string
+	text = noteToken.ReplaceAllStringFunc(text, func(s string) string {
+		res := noteToken.FindStringSubmatch(s)
+		if n := strings.TrimSpace(res[1] + res[2]); n != "" {
+			notes = append(notes, n)
+		}
+		return " "
+	})
+
+	seen := make(map[string]struct{})
+	text = tagToken.ReplaceAllStringFunc(text, func(s string) string {
+		tag := strings.ToLower(strings.TrimPrefix(s, "#"))
+		if _, ok := seen[tag]; !ok {
+			seen[tag] = struct{}{}
+			tags = append(tags, tag)
+		}
+		return " "
+	})
+
+	return strings.Join(strings.Fields(text), " "), tags, strings.Join(notes, " ")
+}
+
+// FormatTags теги в том виде, в котором их пишет пользователь: #командировка #москва
+func FormatTags(tags []string) string {
+	res := make([]string, len(tags))
+	for i, t := range tags {
+		res[i] = "#" + t
+	}
+	return strings.Join(res, " ")
+}
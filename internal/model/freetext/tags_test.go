@@ -0,0 +1,76 @@
+//go:build test_all || unit_test
+
+package freetext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExtractTagsAndNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantRest string
+		wantTags []string
+		wantNote string
+	}{
+		{
+			name:     "теги и заметка после категории",
+			text:     `/add 1200 еда #командировка #москва "обед с клиентом"`,
+			wantRest: "/add 1200 еда",
+			wantTags: []string{"командировка", "москва"},
+			wantNote: "обед с клиентом",
+		},
+		{
+			name:     "теги между категорией и датой",
+			text:     "/add 1200 еда #командировка 01.03.2024",
+			wantRest: "/add 1200 еда 01.03.2024",
+			wantTags: []string{"командировка"},
+		},
+		{
+			name:     "елочки",
+			text:     "такси 600 «до аэропорта»",
+			wantRest: "такси 600",
+			wantNote: "до аэропорта",
+		},
+		{
+			name:     "теги в разном регистре и повторы",
+			text:     "кофе 250 #Работа #работа #кофе_с_собой",
+			wantRest: "кофе 250",
+			wantTags: []string{"работа", "кофе_с_собой"},
+		},
+		{
+			name:     "пустая заметка",
+			text:     `кофе 250 ""`,
+			wantRest: "кофе 250",
+		},
+		{
+			name:     "без тегов и заметки",
+			text:     "/report month",
+			wantRest: "/report month",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, tags, note := ExtractTagsAndNote(tt.text)
+
+			assert.Equal(t, tt.wantRest, rest)
+			assert.Equal(t, tt.wantTags, tags)
+			assert.Equal(t, tt.wantNote, note)
+		})
+	}
+}
+
+func Test_Parse_WithTagsAndNote(t *testing.T) {
+	res, err := Parse(`вчера такси 600 руб #командировка "до аэропорта"`, now)
+
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, []string{"командировка"}, res[0].Tags)
+	assert.Equal(t, "до аэропорта", res[0].Note)
+	assert.Equal(t, "такси", res[0].Description)
+	assert.Equal(t, `/add 600rub такси 12.03.2024 #командировка "до аэропорта"`, res[0].Command())
+}
@@ -0,0 +1,216 @@
+package fsm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// State имя состояния диалога. Пустое состояние - пользователь ни в каком диалоге не участвует
+type State string
+
+const None State = ""
+
+// keySuffix чтобы не перепутать значения, которые могут лежать в хранилище с таким же ключом и не относиться к диалогам
+const keySuffix = "status"
+
+var (
+	ErrNoState      = errors.New("user has no dialog state")
+	ErrExpired      = errors.New("dialog state expired")
+	ErrUnknownState = errors.New("unknown dialog state")
+)
+
+// Store хранилище состояний. Значения в нем живут StringTTL, это таймаут состояний по умолчанию
+type Store interface {
+	SetString(ctx context.Context, key string, value string) error
+	GetString(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, key string) error
+	StringTTL() time.Duration
+}
+
+// Event ввод пользователя, пришедший, пока он находится в каком-то состоянии (например, нажатие на кнопку)
+type Event struct {
+	UserID    int64
+	UserName  string
+	Data      string
+	MessageID int // сообщение бота с нажатой кнопкой, его можно изменить вместо отправки нового
+}
+
+// handler обработчик состояния, payload еще не раскодирован
+type handler func(ctx context.Context, ev Event, payload json.RawMessage) error
+
+type state struct {
+	handle  handler
+	timeout time.Duration
+}
+
+// Machine конечный автомат диалогов: у каждого пользователя одно текущее состояние и данные к нему.
+// Обработчик состояния сам решает, куда перейти дальше: Enter в следующее состояние или Reset
+type Machine struct {
+	store  Store
+	states map[State]state
+	now    func() time.Time
+}
+
+func New(store Store) *Machine {
+	return &Machine{
+		store:  store,
+		states: make(map[State]state),
+		now:    time.Now,
+	}
+}
+
+// StateOption настройка состояния
+type StateOption func(s *state)
+
+// WithTimeout сколько пользователь может находиться в состоянии, если это меньше, чем Store хранит значения.
+// Без таймаута состояние живет Store.StringTTL
+func WithTimeout(d time.Duration) StateOption {
+	return func(s *state) {
+		s.timeout = d
+	}
+}
+
+// Handle объявляет состояние и его обработчик. Данные состояния P передаются в Enter и раскодируются перед вызовом h
+func Handle[P any](m *Machine, name State, h func(ctx context.Context, ev Event, payload P) error, opts ...StateOption) {
+	s := state{
+		handle: func(ctx context.Context, ev Event, raw json.RawMessage) error {
+			var payload P
+			if len(raw) != 0 {
+				if err := json.Unmarshal(raw, &payload); err != nil {
+					return errors.Wrap(err, "unmarshalling payload")
+				}
+			}
+			return h(ctx, ev, payload)
+		},
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	m.states[name] = s
+}
+
+// session то, что лежит в хранилище
+type session struct {
+	State    State           `json:"state"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	Deadline *time.Time      `json:"deadline,omitempty"`
+}
+
+func createKey(userID int64) string {
+	return strconv.FormatInt(userID, 10) + keySuffix
+}
+
+// Enter переводит пользователя в состояние name с данными payload
+func (m *Machine) Enter(ctx context.Context, userID int64, name State, payload any) error {
+	s, ok := m.states[name]
+	if !ok {
+		return errors.Wrap(ErrUnknownState, string(name))
+	}
+
+	sess := session{State: name}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return errors.Wrap(err, "marshalling payload")
+		}
+		sess.Payload = raw
+	}
+	timeout := m.store.StringTTL()
+	if s.timeout != 0 && s.timeout < timeout {
+		timeout = s.timeout
+	}
+	deadline := m.now().Add(timeout)
+	sess.Deadline = &deadline
+
+	raw, err := json.Marshal(sess)
+	if err != nil {
+		return errors.Wrap(err, "marshalling err")
+	}
+
+	if err = m.store.SetString(ctx, createKey(userID), base64.StdEncoding.EncodeToString(raw)); err != nil {
+		return errors.Wrap(err, "store.SetString")
+	}
+
+	return nil
+}
+
+// Reset выводит пользователя из диалога
+func (m *Machine) Reset(ctx context.Context, userID int64) error {
+	if err := m.store.Delete(ctx, createKey(userID)); err != nil {
+		return errors.Wrap(err, "store.Delete")
+	}
+	return nil
+}
+
+// Current текущее состояние пользователя. Истекшее состояние считается пустым
+func (m *Machine) Current(ctx context.Context, userID int64) (State, error) {
+	sess, err := m.load(ctx, userID)
+	if err != nil {
+		return None, err
+	}
+	if m.expired(sess) {
+		return None, nil
+	}
+	return sess.State, nil
+}
+
+// Dispatch передает ввод пользователя обработчику его текущего состояния.
+// Если состояния нет, оно истекло или неизвестно, пользователь выводится из диалога и возвращается ошибка
+func (m *Machine) Dispatch(ctx context.Context, ev Event) error {
+	sess, err := m.load(ctx, ev.UserID)
+	if err != nil {
+		return err
+	}
+	if sess.State == None {
+		return ErrNoState
+	}
+
+	s, ok := m.states[sess.State]
+	if !ok {
+		if err = m.Reset(ctx, ev.UserID); err != nil {
+			return err
+		}
+		return errors.Wrap(ErrUnknownState, string(sess.State))
+	}
+
+	if m.expired(sess) {
+		if err = m.Reset(ctx, ev.UserID); err != nil {
+			return err
+		}
+		return ErrExpired
+	}
+
+	return s.handle(ctx, ev, sess.Payload)
+}
+
+func (m *Machine) load(ctx context.Context, userID int64) (session, error) {
+	res, err := m.store.GetString(ctx, createKey(userID))
+	if err != nil {
+		return session{}, errors.Wrap(err, "store.GetString")
+	}
+	if res == "" {
+		return session{}, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(res)
+	if err != nil {
+		return session{}, errors.Wrap(err, "base64.StdEncoding.DecodeString")
+	}
+
+	var sess session
+	if err = json.Unmarshal(raw, &sess); err != nil {
+		return session{}, errors.Wrap(err, "unmarshalling err")
+	}
+
+	return sess, nil
+}
+
+func (m *Machine) expired(sess session) bool {
+	return sess.Deadline != nil && m.now().After(*sess.Deadline)
+}
@@ -0,0 +1,144 @@
+//go:build test_all || unit_test
+
+package fsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/fsm/_mocks"
+)
+
+type testPayload struct {
+	Command string `json:"command"`
+	Page    int    `json:"page"`
+}
+
+// storeUp хранилище, которое помнит последнее записанное значение
+func storeUp(t *testing.T) *mocks.MockStore {
+	store := mocks.NewMockStore(gomock.NewController(t))
+
+	var value string
+	store.EXPECT().StringTTL().AnyTimes().Return(30 * time.Minute)
+	store.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string, v string) error {
+			value = v
+			return nil
+		})
+	store.EXPECT().GetString(gomock.Any(), "123status").AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string) (string, error) {
+			return value, nil
+		})
+	store.EXPECT().Delete(gomock.Any(), "123status").AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string) error {
+			value = ""
+			return nil
+		})
+
+	return store
+}
+
+func TestMachine_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	m := New(storeUp(t))
+
+	var got testPayload
+	var gotEvent Event
+	Handle(m, "first", func(ctx context.Context, ev Event, p testPayload) error {
+		got, gotEvent = p, ev
+		return m.Enter(ctx, ev.UserID, "second", nil)
+	})
+	Handle(m, "second", func(ctx context.Context, ev Event, _ struct{}) error {
+		return m.Reset(ctx, ev.UserID)
+	})
+
+	err := m.Enter(ctx, 123, "first", testPayload{Command: "/find такси", Page: 2})
+	assert.NoError(t, err)
+
+	ev := Event{UserID: 123, UserName: "name", Data: "button"}
+	assert.NoError(t, m.Dispatch(ctx, ev))
+	assert.Equal(t, testPayload{Command: "/find такси", Page: 2}, got)
+	assert.Equal(t, ev, gotEvent)
+
+	state, err := m.Current(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, State("second"), state)
+
+	assert.NoError(t, m.Dispatch(ctx, ev))
+
+	state, err = m.Current(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, None, state)
+
+	assert.ErrorIs(t, m.Dispatch(ctx, ev), ErrNoState)
+}
+
+func TestMachine_Timeout(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := New(storeUp(t))
+	m.now = func() time.Time { return now }
+
+	called := false
+	Handle(m, "confirm", func(ctx context.Context, ev Event, _ struct{}) error {
+		called = true
+		return nil
+	}, WithTimeout(10*time.Minute))
+
+	assert.NoError(t, m.Enter(ctx, 123, "confirm", nil))
+
+	now = now.Add(11 * time.Minute)
+	state, err := m.Current(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, None, state)
+
+	assert.ErrorIs(t, m.Dispatch(ctx, Event{UserID: 123}), ErrExpired)
+	assert.False(t, called)
+	// истекшее состояние удаляется
+	assert.ErrorIs(t, m.Dispatch(ctx, Event{UserID: 123}), ErrNoState)
+}
+
+func TestMachine_DefaultTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := New(storeUp(t))
+	m.now = func() time.Time { return now }
+
+	Handle(m, "page", func(ctx context.Context, ev Event, _ struct{}) error { return nil })
+	// таймаут дольше, чем хранилище держит значения, не действует
+	Handle(m, "long", func(ctx context.Context, ev Event, _ struct{}) error { return nil }, WithTimeout(time.Hour))
+
+	for _, name := range []State{"page", "long"} {
+		assert.NoError(t, m.Enter(ctx, 123, name, nil))
+
+		now = now.Add(29 * time.Minute)
+		state, err := m.Current(ctx, 123)
+		assert.NoError(t, err)
+		assert.Equal(t, name, state)
+
+		now = now.Add(2 * time.Minute)
+		assert.ErrorIs(t, m.Dispatch(ctx, Event{UserID: 123}), ErrExpired)
+	}
+}
+
+func TestMachine_UnknownState(t *testing.T) {
+	ctx := context.Background()
+
+	m := New(storeUp(t))
+
+	assert.ErrorIs(t, m.Enter(ctx, 123, "unknown", nil), ErrUnknownState)
+
+	// состояние, которое осталось в хранилище от старой версии бота
+	Handle(m, "old", func(ctx context.Context, ev Event, _ struct{}) error { return nil })
+	assert.NoError(t, m.Enter(ctx, 123, "old", nil))
+	delete(m.states, "old")
+
+	assert.ErrorIs(t, m.Dispatch(ctx, Event{UserID: 123}), ErrUnknownState)
+	assert.ErrorIs(t, m.Dispatch(ctx, Event{UserID: 123}), ErrNoState)
+}
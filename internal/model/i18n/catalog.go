@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Каталог устроен как в gettext: ключ - исходный русский текст, перевод ищется по нему.
+// Если перевода нет, отдается исходный текст, поэтому для русского языка каталог не нужен.
+// Тексты с формами множественного числа записываются через "|": "трата|траты|трат"
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[Lang]map[string]string{}
+)
+
+// Register добавляет переводы на язык lang. Каждый пакет регистрирует переводы своих текстов в init
+func Register(lang Lang, translations map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, ok := catalogs[lang]
+	if !ok {
+		c = make(map[string]string, len(translations))
+		catalogs[lang] = c
+	}
+	for k, v := range translations {
+		c[k] = v
+	}
+}
+
+// Has есть ли перевод текста на язык lang
+func Has(lang Lang, msg string) bool {
+	if lang == Default {
+		return true
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := catalogs[lang][msg]
+	return ok
+}
+
+// T перевод текста на язык lang
+func T(lang Lang, msg string) string {
+	if lang == Default {
+		return msg
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if res, ok := catalogs[lang][msg]; ok {
+		return res
+	}
+	return msg
+}
+
+// Sprintf перевод строки формата, затем подстановка аргументов
+func Sprintf(lang Lang, format string, args ...any) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// Plural форма слова для числа n: Plural(RU, 3, "трата|траты|трат") = "траты"
+func Plural(lang Lang, n int, forms string) string {
+	variants := strings.Split(T(lang, forms), "|")
+
+	idx := pluralIndex(lang, n)
+	if idx >= len(variants) {
+		idx = len(variants) - 1
+	}
+	return variants[idx]
+}
+
+// pluralIndex номер формы множественного числа по правилам языка
+func pluralIndex(lang Lang, n int) int {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case RU:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	default:
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+}
@@ -0,0 +1,72 @@
+//go:build test_all || unit_test
+
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// emptyCatalogs подменяет каталоги пустыми на время теста, чтобы переводы теста не попадали в другие тесты
+func emptyCatalogs(t *testing.T) {
+	mu.Lock()
+	saved := catalogs
+	catalogs = map[Lang]map[string]string{}
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		catalogs = saved
+		mu.Unlock()
+	})
+}
+
+func TestT(t *testing.T) {
+	emptyCatalogs(t)
+	Register(EN, map[string]string{
+		"Трата добавлена":           "Purchase added",
+		"Страница %d":               "Page %d",
+		"тестовая трата|траты|трат": "test purchase|test purchases",
+	})
+
+	assert.Equal(t, "Трата добавлена", T(RU, "Трата добавлена"))
+	assert.Equal(t, "Purchase added", T(EN, "Трата добавлена"))
+	// перевода нет - отдаем исходный текст
+	assert.Equal(t, "Категория создана", T(EN, "Категория создана"))
+	assert.Equal(t, "Page 2", Sprintf(EN, "Страница %d", 2))
+
+	assert.True(t, Has(RU, "что угодно"))
+	assert.True(t, Has(EN, "Трата добавлена"))
+	assert.False(t, Has(EN, "Категория создана"))
+
+	assert.Equal(t, "test purchases", Plural(EN, 3, "тестовая трата|траты|трат"))
+}
+
+func TestPlural(t *testing.T) {
+	forms := "трата|траты|трат"
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 0, want: "трат"},
+		{n: 1, want: "трата"},
+		{n: 2, want: "траты"},
+		{n: 4, want: "траты"},
+		{n: 5, want: "трат"},
+		{n: 11, want: "трат"},
+		{n: 12, want: "трат"},
+		{n: 14, want: "трат"},
+		{n: 21, want: "трата"},
+		{n: 22, want: "траты"},
+		{n: 111, want: "трат"},
+		{n: 101, want: "трата"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Plural(RU, tt.n, forms), tt.n)
+	}
+
+	assert.Equal(t, "purchase", Plural(EN, 1, "purchase|purchases"))
+	assert.Equal(t, "purchases", Plural(EN, 0, "purchase|purchases"))
+	assert.Equal(t, "purchases", Plural(EN, 21, "purchase|purchases"))
+}
@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type numberFormat struct {
+	group   string // разделитель разрядов
+	decimal string // разделитель дробной части
+}
+
+var numberFormats = map[Lang]numberFormat{
+	RU: {group: " ", decimal: ","},
+	EN: {group: ",", decimal: "."},
+}
+
+var dateLayouts = map[Lang]string{
+	RU: "02.01.2006",
+	EN: "Jan 2, 2006",
+}
+
+var shortDateLayouts = map[Lang]string{
+	RU: "02.01",
+	EN: "Jan 2",
+}
+
+// Number сумма с двумя знаками после запятой и разделителями разрядов: 1 234,50 или 1,234.50
+func Number(lang Lang, v float64) string {
+	f, ok := numberFormats[lang]
+	if !ok {
+		f = numberFormats[Default]
+	}
+
+	raw := strconv.FormatFloat(v, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(raw, "-") {
+		sign, raw = "-", raw[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+
+	res := strings.Builder{}
+	res.WriteString(sign)
+	for i, d := range intPart {
+		if i != 0 && (len(intPart)-i)%3 == 0 {
+			res.WriteString(f.group)
+		}
+		res.WriteRune(d)
+	}
+	res.WriteString(f.decimal)
+	res.WriteString(fracPart)
+
+	return res.String()
+}
+
+// Date дата в принятом для языка виде: 02.01.2006 или Jan 2, 2006
+func Date(lang Lang, t time.Time) string {
+	layout, ok := dateLayouts[lang]
+	if !ok {
+		layout = dateLayouts[Default]
+	}
+	return t.Format(layout)
+}
+
+// ShortDate дата без года в принятом для языка виде: 02.01 или Jan 2
+func ShortDate(lang Lang, t time.Time) string {
+	layout, ok := shortDateLayouts[lang]
+	if !ok {
+		layout = shortDateLayouts[Default]
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,42 @@
+//go:build test_all || unit_test
+
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		v      float64
+		wantRU string
+		wantEN string
+	}{
+		{v: 0, wantRU: "0,00", wantEN: "0.00"},
+		{v: 250, wantRU: "250,00", wantEN: "250.00"},
+		{v: 1234.5, wantRU: "1 234,50", wantEN: "1,234.50"},
+		{v: 1234567.891, wantRU: "1 234 567,89", wantEN: "1,234,567.89"},
+		{v: -100000, wantRU: "-100 000,00", wantEN: "-100,000.00"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantRU, Number(RU, tt.v))
+		assert.Equal(t, tt.wantEN, Number(EN, tt.v))
+	}
+}
+
+func TestDate(t *testing.T) {
+	d := time.Date(2024, 3, 8, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "08.03.2024", Date(RU, d))
+	assert.Equal(t, "Mar 8, 2024", Date(EN, d))
+}
+
+func TestShortDate(t *testing.T) {
+	d := time.Date(2024, 3, 8, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "08.03", ShortDate(RU, d))
+	assert.Equal(t, "Mar 8", ShortDate(EN, d))
+}
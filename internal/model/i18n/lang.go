@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Lang язык интерфейса
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+
+	// Default язык, на котором написаны исходные тексты
+	Default = RU
+)
+
+var ErrUnknownLang = errors.New("unknown language")
+
+// Parse язык, выбранный пользователем командой: "ru", "EN"
+func Parse(str string) (Lang, error) {
+	switch Lang(strings.ToLower(str)) {
+	case RU:
+		return RU, nil
+	case EN:
+		return EN, nil
+	default:
+		return "", ErrUnknownLang
+	}
+}
+
+// FromTelegram язык по language_code из телеграма ("ru", "en-US"). Русский отдаем и тем,
+// кто пишет с украинским или белорусским интерфейсом, остальным - английский
+func FromTelegram(code string) Lang {
+	if code == "" {
+		return Default
+	}
+
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+	switch base {
+	case "ru", "uk", "be", "kk":
+		return RU
+	default:
+		return EN
+	}
+}
+
+type ctxKey struct{}
+
+// WithLang запоминает в контексте язык пользователя, которому отвечаем
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lang)
+}
+
+// FromContext язык из контекста, если его там нет - Default
+func FromContext(ctx context.Context) Lang {
+	lang, ok := LangFromContext(ctx)
+	if !ok {
+		return Default
+	}
+	return lang
+}
+
+// LangFromContext язык из контекста и был ли он туда положен
+func LangFromContext(ctx context.Context) (Lang, bool) {
+	lang, ok := ctx.Value(ctxKey{}).(Lang)
+	return lang, ok && lang != ""
+}
@@ -0,0 +1,39 @@
+//go:build test_all || unit_test
+
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromTelegram(t *testing.T) {
+	assert.Equal(t, RU, FromTelegram(""))
+	assert.Equal(t, RU, FromTelegram("ru"))
+	assert.Equal(t, RU, FromTelegram("uk"))
+	assert.Equal(t, EN, FromTelegram("en"))
+	assert.Equal(t, EN, FromTelegram("en-US"))
+	assert.Equal(t, EN, FromTelegram("de"))
+}
+
+func TestParse(t *testing.T) {
+	lang, err := Parse("EN")
+	assert.NoError(t, err)
+	assert.Equal(t, EN, lang)
+
+	_, err = Parse("de")
+	assert.ErrorIs(t, err, ErrUnknownLang)
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Default, FromContext(ctx))
+
+	_, ok := LangFromContext(ctx)
+	assert.False(t, ok)
+
+	ctx = WithLang(ctx, EN)
+	assert.Equal(t, EN, FromContext(ctx))
+}
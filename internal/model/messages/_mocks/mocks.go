@@ -7,10 +7,16 @@ package mock_messages
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	tg "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	chart_drawing "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/chart_drawing"
 	currency "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	forecast "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
+	i18n "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	purchases "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	ratelimit "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
 )
 
 // MockMessageSender is a mock of MessageSender interface.
@@ -36,6 +42,62 @@ func (m *MockMessageSender) EXPECT() *MockMessageSenderMockRecorder {
 	return m.recorder
 }
 
+// AnswerInlineQuery mocks base method.
+func (m *MockMessageSender) AnswerInlineQuery(queryID string, results []tg.InlineResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerInlineQuery", queryID, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnswerInlineQuery indicates an expected call of AnswerInlineQuery.
+func (mr *MockMessageSenderMockRecorder) AnswerInlineQuery(queryID, results interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerInlineQuery", reflect.TypeOf((*MockMessageSender)(nil).AnswerInlineQuery), queryID, results)
+}
+
+// EditKeyboard mocks base method.
+func (m *MockMessageSender) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditKeyboard", text, userID, msg, keyboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditKeyboard indicates an expected call of EditKeyboard.
+func (mr *MockMessageSenderMockRecorder) EditKeyboard(text, userID, msg, keyboard interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditKeyboard", reflect.TypeOf((*MockMessageSender)(nil).EditKeyboard), text, userID, msg, keyboard)
+}
+
+// EditMessage mocks base method.
+func (m *MockMessageSender) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessage", text, userID, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessage indicates an expected call of EditMessage.
+func (mr *MockMessageSenderMockRecorder) EditMessage(text, userID, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessage", reflect.TypeOf((*MockMessageSender)(nil).EditMessage), text, userID, msg)
+}
+
+// SendDocument mocks base method.
+func (m *MockMessageSender) SendDocument(doc []byte, name string, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDocument", doc, name, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDocument indicates an expected call of SendDocument.
+func (mr *MockMessageSenderMockRecorder) SendDocument(doc, name, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDocument", reflect.TypeOf((*MockMessageSender)(nil).SendDocument), doc, name, userID)
+}
+
 // SendImage mocks base method.
 func (m *MockMessageSender) SendImage(img []byte, chatID int64) error {
 	m.ctrl.T.Helper()
@@ -51,17 +113,17 @@ func (mr *MockMessageSenderMockRecorder) SendImage(img, chatID interface{}) *gom
 }
 
 // SendKeyboard mocks base method.
-func (m *MockMessageSender) SendKeyboard(text string, userID int64, buttonTexts []string) error {
+func (m *MockMessageSender) SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendKeyboard", text, userID, buttonTexts)
+	ret := m.ctrl.Call(m, "SendKeyboard", text, userID, keyboard)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SendKeyboard indicates an expected call of SendKeyboard.
-func (mr *MockMessageSenderMockRecorder) SendKeyboard(text, userID, buttonTexts interface{}) *gomock.Call {
+func (mr *MockMessageSenderMockRecorder) SendKeyboard(text, userID, keyboard interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKeyboard", reflect.TypeOf((*MockMessageSender)(nil).SendKeyboard), text, userID, buttonTexts)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendKeyboard", reflect.TypeOf((*MockMessageSender)(nil).SendKeyboard), text, userID, keyboard)
 }
 
 // SendMessage mocks base method.
@@ -78,6 +140,20 @@ func (mr *MockMessageSenderMockRecorder) SendMessage(text, userID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockMessageSender)(nil).SendMessage), text, userID)
 }
 
+// SendTrackedMessage mocks base method.
+func (m *MockMessageSender) SendTrackedMessage(text string, userID int64, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTrackedMessage", text, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTrackedMessage indicates an expected call of SendTrackedMessage.
+func (mr *MockMessageSenderMockRecorder) SendTrackedMessage(text, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTrackedMessage", reflect.TypeOf((*MockMessageSender)(nil).SendTrackedMessage), text, userID, key)
+}
+
 // MockPurchasesModel is a mock of PurchasesModel interface.
 type MockPurchasesModel struct {
 	ctrl     *gomock.Controller
@@ -115,6 +191,20 @@ func (mr *MockPurchasesModelMockRecorder) AddCategory(ctx, category interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategory", reflect.TypeOf((*MockPurchasesModel)(nil).AddCategory), ctx, category)
 }
 
+// AddCategoryRule mocks base method.
+func (m *MockPurchasesModel) AddCategoryRule(ctx context.Context, userID int64, condition, category string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCategoryRule", ctx, userID, condition, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCategoryRule indicates an expected call of AddCategoryRule.
+func (mr *MockPurchasesModelMockRecorder) AddCategoryRule(ctx, userID, condition, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategoryRule", reflect.TypeOf((*MockPurchasesModel)(nil).AddCategoryRule), ctx, userID, condition, category)
+}
+
 // AddCategoryToUser mocks base method.
 func (m *MockPurchasesModel) AddCategoryToUser(ctx context.Context, userID int64, category string) error {
 	m.ctrl.T.Helper()
@@ -130,18 +220,51 @@ func (mr *MockPurchasesModelMockRecorder) AddCategoryToUser(ctx, userID, categor
 }
 
 // AddPurchase mocks base method.
-func (m *MockPurchasesModel) AddPurchase(ctx context.Context, userID int64, rawSum, category, rawDate string) (purchases.ExpensesAndLimit, error) {
+func (m *MockPurchasesModel) AddPurchase(ctx context.Context, userID int64, rawSum, category, rawDate string, opts ...purchases.AddPurchaseOption) (purchases.ExpensesAndLimit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddPurchase", ctx, userID, rawSum, category, rawDate)
+	varargs := []interface{}{ctx, userID, rawSum, category, rawDate}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddPurchase", varargs...)
 	ret0, _ := ret[0].(purchases.ExpensesAndLimit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddPurchase indicates an expected call of AddPurchase.
-func (mr *MockPurchasesModelMockRecorder) AddPurchase(ctx, userID, rawSum, category, rawDate interface{}) *gomock.Call {
+func (mr *MockPurchasesModelMockRecorder) AddPurchase(ctx, userID, rawSum, category, rawDate interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPurchase", reflect.TypeOf((*MockPurchasesModel)(nil).AddPurchase), ctx, userID, rawSum, category, rawDate)
+	varargs := append([]interface{}{ctx, userID, rawSum, category, rawDate}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPurchase", reflect.TypeOf((*MockPurchasesModel)(nil).AddPurchase), varargs...)
+}
+
+// AddUserCategory mocks base method.
+func (m *MockPurchasesModel) AddUserCategory(ctx context.Context, userID int64, category string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserCategory", ctx, userID, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUserCategory indicates an expected call of AddUserCategory.
+func (mr *MockPurchasesModelMockRecorder) AddUserCategory(ctx, userID, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserCategory", reflect.TypeOf((*MockPurchasesModel)(nil).AddUserCategory), ctx, userID, category)
+}
+
+// ChangeUserConfirmUnusual mocks base method.
+func (m *MockPurchasesModel) ChangeUserConfirmUnusual(ctx context.Context, userID int64, confirm bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserConfirmUnusual", ctx, userID, confirm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeUserConfirmUnusual indicates an expected call of ChangeUserConfirmUnusual.
+func (mr *MockPurchasesModelMockRecorder) ChangeUserConfirmUnusual(ctx, userID, confirm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserConfirmUnusual", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserConfirmUnusual), ctx, userID, confirm)
 }
 
 // ChangeUserCurrency mocks base method.
@@ -158,6 +281,34 @@ func (mr *MockPurchasesModelMockRecorder) ChangeUserCurrency(ctx, userID, curren
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserCurrency", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserCurrency), ctx, userID, currency)
 }
 
+// ChangeUserDigest mocks base method.
+func (m *MockPurchasesModel) ChangeUserDigest(ctx context.Context, userID int64, period purchases.Period) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserDigest", ctx, userID, period)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeUserDigest indicates an expected call of ChangeUserDigest.
+func (mr *MockPurchasesModelMockRecorder) ChangeUserDigest(ctx, userID, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserDigest", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserDigest), ctx, userID, period)
+}
+
+// ChangeUserLang mocks base method.
+func (m *MockPurchasesModel) ChangeUserLang(ctx context.Context, userID int64, lang i18n.Lang) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserLang", ctx, userID, lang)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeUserLang indicates an expected call of ChangeUserLang.
+func (mr *MockPurchasesModelMockRecorder) ChangeUserLang(ctx, userID, lang interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserLang", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserLang), ctx, userID, lang)
+}
+
 // ChangeUserLimit mocks base method.
 func (m *MockPurchasesModel) ChangeUserLimit(ctx context.Context, userID int64, rawLimit string) error {
 	m.ctrl.T.Helper()
@@ -172,18 +323,78 @@ func (mr *MockPurchasesModelMockRecorder) ChangeUserLimit(ctx, userID, rawLimit
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserLimit", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserLimit), ctx, userID, rawLimit)
 }
 
+// ChangeUserReminder mocks base method.
+func (m *MockPurchasesModel) ChangeUserReminder(ctx context.Context, userID int64, rawTime string) (purchases.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserReminder", ctx, userID, rawTime)
+	ret0, _ := ret[0].(purchases.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeUserReminder indicates an expected call of ChangeUserReminder.
+func (mr *MockPurchasesModelMockRecorder) ChangeUserReminder(ctx, userID, rawTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserReminder", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserReminder), ctx, userID, rawTime)
+}
+
+// ChangeUserTimezone mocks base method.
+func (m *MockPurchasesModel) ChangeUserTimezone(ctx context.Context, userID int64, name string) (*time.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserTimezone", ctx, userID, name)
+	ret0, _ := ret[0].(*time.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeUserTimezone indicates an expected call of ChangeUserTimezone.
+func (mr *MockPurchasesModelMockRecorder) ChangeUserTimezone(ctx, userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserTimezone", reflect.TypeOf((*MockPurchasesModel)(nil).ChangeUserTimezone), ctx, userID, name)
+}
+
 // CreateReportRequest mocks base method.
-func (m *MockPurchasesModel) CreateReportRequest(ctx context.Context, period purchases.Period, userID int64) error {
+func (m *MockPurchasesModel) CreateReportRequest(ctx context.Context, period purchases.Period, userID int64, lang i18n.Lang, opts purchases.ReportOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateReportRequest", ctx, period, userID)
+	ret := m.ctrl.Call(m, "CreateReportRequest", ctx, period, userID, lang, opts)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateReportRequest indicates an expected call of CreateReportRequest.
-func (mr *MockPurchasesModelMockRecorder) CreateReportRequest(ctx, period, userID interface{}) *gomock.Call {
+func (mr *MockPurchasesModelMockRecorder) CreateReportRequest(ctx, period, userID, lang, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReportRequest", reflect.TypeOf((*MockPurchasesModel)(nil).CreateReportRequest), ctx, period, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReportRequest", reflect.TypeOf((*MockPurchasesModel)(nil).CreateReportRequest), ctx, period, userID, lang, opts)
+}
+
+// FindPurchases mocks base method.
+func (m *MockPurchasesModel) FindPurchases(ctx context.Context, userID int64, query string, page int) (purchases.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPurchases", ctx, userID, query, page)
+	ret0, _ := ret[0].(purchases.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPurchases indicates an expected call of FindPurchases.
+func (mr *MockPurchasesModelMockRecorder) FindPurchases(ctx, userID, query, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPurchases", reflect.TypeOf((*MockPurchasesModel)(nil).FindPurchases), ctx, userID, query, page)
+}
+
+// Forecast mocks base method.
+func (m *MockPurchasesModel) Forecast(ctx context.Context, userID int64) (purchases.MonthForecast, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Forecast", ctx, userID)
+	ret0, _ := ret[0].(purchases.MonthForecast)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Forecast indicates an expected call of Forecast.
+func (mr *MockPurchasesModelMockRecorder) Forecast(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forecast", reflect.TypeOf((*MockPurchasesModel)(nil).Forecast), ctx, userID)
 }
 
 // GetAllCategories mocks base method.
@@ -216,6 +427,96 @@ func (mr *MockPurchasesModelMockRecorder) GetUserCategories(ctx, userID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCategories", reflect.TypeOf((*MockPurchasesModel)(nil).GetUserCategories), ctx, userID)
 }
 
+// GetUserLang mocks base method.
+func (m *MockPurchasesModel) GetUserLang(ctx context.Context, userID int64) (i18n.Lang, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLang", ctx, userID)
+	ret0, _ := ret[0].(i18n.Lang)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLang indicates an expected call of GetUserLang.
+func (mr *MockPurchasesModelMockRecorder) GetUserLang(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLang", reflect.TypeOf((*MockPurchasesModel)(nil).GetUserLang), ctx, userID)
+}
+
+// GetUserLocation mocks base method.
+func (m *MockPurchasesModel) GetUserLocation(ctx context.Context, userID int64) (*time.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLocation", ctx, userID)
+	ret0, _ := ret[0].(*time.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLocation indicates an expected call of GetUserLocation.
+func (mr *MockPurchasesModelMockRecorder) GetUserLocation(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLocation", reflect.TypeOf((*MockPurchasesModel)(nil).GetUserLocation), ctx, userID)
+}
+
+// GetUserTags mocks base method.
+func (m *MockPurchasesModel) GetUserTags(ctx context.Context, userID int64) ([]purchases.TagTotal, currency.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTags", ctx, userID)
+	ret0, _ := ret[0].([]purchases.TagTotal)
+	ret1, _ := ret[1].(currency.Currency)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserTags indicates an expected call of GetUserTags.
+func (mr *MockPurchasesModelMockRecorder) GetUserTags(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTags", reflect.TypeOf((*MockPurchasesModel)(nil).GetUserTags), ctx, userID)
+}
+
+// PreviewPurchase mocks base method.
+func (m *MockPurchasesModel) PreviewPurchase(ctx context.Context, userID int64, category string, sum float64) (purchases.PurchasePreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewPurchase", ctx, userID, category, sum)
+	ret0, _ := ret[0].(purchases.PurchasePreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewPurchase indicates an expected call of PreviewPurchase.
+func (mr *MockPurchasesModelMockRecorder) PreviewPurchase(ctx, userID, category, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewPurchase", reflect.TypeOf((*MockPurchasesModel)(nil).PreviewPurchase), ctx, userID, category, sum)
+}
+
+// RemoveUserDigest mocks base method.
+func (m *MockPurchasesModel) RemoveUserDigest(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserDigest", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserDigest indicates an expected call of RemoveUserDigest.
+func (mr *MockPurchasesModelMockRecorder) RemoveUserDigest(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserDigest", reflect.TypeOf((*MockPurchasesModel)(nil).RemoveUserDigest), ctx, userID)
+}
+
+// SuggestCategories mocks base method.
+func (m *MockPurchasesModel) SuggestCategories(ctx context.Context, userID int64, description string, categories []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestCategories", ctx, userID, description, categories)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestCategories indicates an expected call of SuggestCategories.
+func (mr *MockPurchasesModelMockRecorder) SuggestCategories(ctx, userID, description, categories interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestCategories", reflect.TypeOf((*MockPurchasesModel)(nil).SuggestCategories), ctx, userID, description, categories)
+}
+
 // ToPeriod mocks base method.
 func (m *MockPurchasesModel) ToPeriod(str string) (purchases.Period, error) {
 	m.ctrl.T.Helper()
@@ -296,3 +597,92 @@ func (mr *MockStatusStoreMockRecorder) SetString(ctx, key, value interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetString", reflect.TypeOf((*MockStatusStore)(nil).SetString), ctx, key, value)
 }
+
+// StringTTL mocks base method.
+func (m *MockStatusStore) StringTTL() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StringTTL")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// StringTTL indicates an expected call of StringTTL.
+func (mr *MockStatusStoreMockRecorder) StringTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StringTTL", reflect.TypeOf((*MockStatusStore)(nil).StringTTL))
+}
+
+// MockRateLimiter is a mock of RateLimiter interface.
+type MockRateLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimiterMockRecorder
+}
+
+// MockRateLimiterMockRecorder is the mock recorder for MockRateLimiter.
+type MockRateLimiterMockRecorder struct {
+	mock *MockRateLimiter
+}
+
+// NewMockRateLimiter creates a new mock instance.
+func NewMockRateLimiter(ctrl *gomock.Controller) *MockRateLimiter {
+	mock := &MockRateLimiter{ctrl: ctrl}
+	mock.recorder = &MockRateLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimiter) EXPECT() *MockRateLimiterMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimiter) Allow(ctx context.Context, userID int64, class string) ratelimit.Decision {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, userID, class)
+	ret0, _ := ret[0].(ratelimit.Decision)
+	return ret0
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimiterMockRecorder) Allow(ctx, userID, class interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimiter)(nil).Allow), ctx, userID, class)
+}
+
+// MockChartDrawer is a mock of ChartDrawer interface.
+type MockChartDrawer struct {
+	ctrl     *gomock.Controller
+	recorder *MockChartDrawerMockRecorder
+}
+
+// MockChartDrawerMockRecorder is the mock recorder for MockChartDrawer.
+type MockChartDrawerMockRecorder struct {
+	mock *MockChartDrawer
+}
+
+// NewMockChartDrawer creates a new mock instance.
+func NewMockChartDrawer(ctrl *gomock.Controller) *MockChartDrawer {
+	mock := &MockChartDrawer{ctrl: ctrl}
+	mock.recorder = &MockChartDrawerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChartDrawer) EXPECT() *MockChartDrawerMockRecorder {
+	return m.recorder
+}
+
+// ForecastChart mocks base method.
+func (m *MockChartDrawer) ForecastChart(f forecast.Forecast, limit float64, labels chart_drawing.ForecastLabels) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForecastChart", f, limit, labels)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForecastChart indicates an expected call of ForecastChart.
+func (mr *MockChartDrawerMockRecorder) ForecastChart(f, limit, labels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForecastChart", reflect.TypeOf((*MockChartDrawer)(nil).ForecastChart), f, limit, labels)
+}
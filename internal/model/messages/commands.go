@@ -0,0 +1,353 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
+)
+
+// commandArgs аргументы команды, разобранные по ее грамматике
+type commandArgs struct {
+	// Groups группы регулярного выражения грамматики, Groups[0] - вся строка аргументов
+	Groups []string
+	// хэштеги и заметка, если команда их поддерживает
+	Tags []string
+	Note string
+}
+
+// command описание команды бота
+type command struct {
+	name string // команда без слэша
+	// args грамматика аргументов, целиком совпадающая со строкой аргументов. nil - у команды нет аргументов
+	args  *regexp.Regexp
+	usage string // шаблон вызова, показывается при ошибке ввода и в /help
+	help  string // что делает команда
+	// examples примеры вызова для /help <команда>
+	examples []string
+	// tagged из аргументов перед разбором вырезаются хэштеги и заметка в кавычках
+	tagged bool
+	// label метка для метрик
+	label string
+	// class класс команды для ограничения частоты запросов, по умолчанию ratelimit.ClassDefault
+	class   string
+	handler func(ctx context.Context, m *Model, msg Message, args commandArgs) error
+}
+
+// rateClass класс команды для ограничения частоты запросов
+func (c command) rateClass() string {
+	if c.class == "" {
+		return ratelimit.ClassDefault
+	}
+	return c.class
+}
+
+// parseArgs разбирает строку аргументов по грамматике команды
+func (c command) parseArgs(raw string) (commandArgs, bool) {
+	var args commandArgs
+	if c.tagged {
+		raw, args.Tags, args.Note = freetext.ExtractTagsAndNote(raw)
+	}
+
+	if c.args == nil {
+		return args, raw == ""
+	}
+
+	res := c.args.FindStringSubmatch(raw)
+	if res == nil {
+		return commandArgs{}, false
+	}
+	args.Groups = res
+
+	return args, true
+}
+
+var (
+	// commands все команды бота в том порядке, в котором они выводятся в /help.
+	// Заполняется в init, потому что /help собирается из этого же списка
+	commands []command
+	// commandsByName команды по имени
+	commandsByName map[string]command
+)
+
+func init() {
+	commands = []command{
+		{
+			name:     "start",
+			usage:    "/start",
+			help:     "начать работу с ботом: валюта, лимит и стартовые категории",
+			examples: []string{"/start"},
+			label:    "start",
+			handler: func(ctx context.Context, m *Model, msg Message, _ commandArgs) error {
+				return m.msgStart(ctx, msg)
+			},
+		},
+		{
+			name:     "help",
+			args:     regexp.MustCompile(`^(?:/?(\w+))?$`),
+			usage:    "/help [команда]",
+			help:     "список команд или подробное описание одной команды",
+			examples: []string{"/help", "/help add"},
+			label:    "help",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				if args.Groups[1] == "" {
+					return m.SendMessage(helpText(ctx), msg.UserID)
+				}
+				text, ok := commandHelpText(ctx, args.Groups[1])
+				if !ok {
+					return m.SendMessage(tr(ctx, ErrTxtUnknownHelpTopic), msg.UserID)
+				}
+				return m.SendMessage(text, msg.UserID)
+			},
+		},
+		{
+			name:     "cancel",
+			usage:    "/cancel",
+			help:     "выйти из текущего диалога: выбора категории, подтверждения траты, знакомства с ботом",
+			examples: []string{"/cancel"},
+			label:    "cancel",
+			handler: func(ctx context.Context, m *Model, msg Message, _ commandArgs) error {
+				return m.msgCancel(ctx, msg)
+			},
+		},
+		{
+			name: "add",
+			// сумма, к которой может быть приклеен код валюты, затем необязательные категория и дата
+			args:     regexp.MustCompile(`^(\d+(?:\.\d+)?)([A-Za-z]{3})?(?: ([\wА-Яа-яЁё\- ]+?))?(?: (\d{2}\.\d{2}\.\d{4}))?$`),
+			usage:    "/add <сумма>[валюта] [категория] [dd.mm.yyyy] [#тег] [\"заметка\"]",
+			help:     "добавить трату, без категории - выбор категории кнопками",
+			tagged:   true,
+			label:    metricsCommAddPurchase,
+			examples: []string{"/add 250", "/add 250 Кафе", "/add 10usd Кофе 01.03.2024", "/add 1200 Еда #командировка \"обед с клиентом\""},
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				purchase := addPurchaseArgs{
+					Sum:      args.Groups[1],
+					Currency: args.Groups[2],
+					Category: args.Groups[3],
+					Date:     args.Groups[4],
+					Tags:     args.Tags,
+					Note:     args.Note,
+				}
+				if purchase.Category == "" {
+					return m.msgSelectCategory(ctx, msg, purchase)
+				}
+				return m.msgAddPurchase(ctx, msg, purchase)
+			},
+		},
+		{
+			name:     "category",
+			args:     regexp.MustCompile(`^([\wА-Яа-яЁё\- ]+)$`),
+			usage:    "/category <название>",
+			help:     "создать категорию",
+			examples: []string{"/category Подарки"},
+			label:    "add_category",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgAddCategory(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "rule",
+			args:     regexp.MustCompile(`^(.+?)\s*->\s*(.+)$`),
+			usage:    "/rule <условие> -> <категория>",
+			help:     "правило автоматического подбора категории: подстрока, /регулярное выражение/ или диапазон суммы",
+			examples: []string{"/rule пятёрочка -> Продукты", "/rule /^такси/ -> Транспорт", "/rule >5000 -> Крупные покупки"},
+			label:    "add_rule",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgAddRule(ctx, msg, args.Groups[1], args.Groups[2])
+			},
+		},
+		{
+			name:     "report",
+			args:     regexp.MustCompile(`^(?:(month|week|year)(?:\s+|$))?(?:(all)(?:\s+|$))?(?:(today)(?:\s+|$))?(pdf)?$`),
+			usage:    "/report [week|month|year] [all] [today] [pdf] [#тег]",
+			help:     "отчет по тратам за период, без периода - выбор кнопками. all - суммы в каждой валюте оплаты, today - пересчет по сегодняшнему курсу, pdf - еще и документ со всеми тратами",
+			examples: []string{"/report month", "/report year #командировка", "/report month all today", "/report month pdf"},
+			tagged:   true,
+			label:    "report",
+			class:    ratelimit.ClassHeavy,
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				filter := reportFilter{
+					Tags:          args.Tags,
+					AllCurrencies: args.Groups[2] != "",
+					TodayRates:    args.Groups[3] != "",
+					PDF:           args.Groups[4] != "",
+				}
+				return m.msgReport(ctx, msg, args.Groups[1], filter, tg.MessageRef{})
+			},
+		},
+		{
+			name:     "tags",
+			usage:    "/tags",
+			help:     "теги с суммами трат по ним",
+			examples: []string{"/tags"},
+			label:    "tags",
+			class:    ratelimit.ClassHeavy,
+			handler: func(ctx context.Context, m *Model, msg Message, _ commandArgs) error {
+				return m.msgTags(ctx, msg)
+			},
+		},
+		{
+			name:     "forecast",
+			usage:    "/forecast",
+			help:     "прогноз трат на конец месяца и дата превышения лимита с графиком",
+			examples: []string{"/forecast"},
+			label:    "forecast",
+			class:    ratelimit.ClassHeavy,
+			handler: func(ctx context.Context, m *Model, msg Message, _ commandArgs) error {
+				return m.msgForecast(ctx, msg)
+			},
+		},
+		{
+			name:     "find",
+			args:     regexp.MustCompile(`^(.+)$`),
+			usage:    "/find <слова> [>сумма] [<сумма] [сумма..сумма] [dd.mm.yyyy..dd.mm.yyyy]",
+			help:     "поиск трат",
+			examples: []string{"/find такси", "/find >1000 01.03.2024..31.03.2024", "/find кафе 100..500"},
+			label:    "find",
+			class:    ratelimit.ClassHeavy,
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgFind(ctx, msg, args.Groups[1], 0, tg.MessageRef{})
+			},
+		},
+		{
+			name:     "currency",
+			args:     regexp.MustCompile(`^([A-Za-z]{3})?$`),
+			usage:    "/currency [RUB|USD|EUR|CNY]",
+			help:     "сменить основную валюту, без аргумента - выбор кнопками",
+			examples: []string{"/currency USD", "/currency"},
+			label:    "select_currency",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgCurrency(ctx, msg, args.Groups[1], tg.MessageRef{})
+			},
+		},
+		{
+			name:     "limit",
+			args:     regexp.MustCompile(`^(-?\d+(?:\.\d+)?)$`),
+			usage:    "/limit <сумма>",
+			help:     "месячный лимит трат, -1 снимает лимит",
+			examples: []string{"/limit 30000", "/limit -1"},
+			label:    "set_limit",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgLimit(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "lang",
+			args:     regexp.MustCompile(`^([A-Za-z]{2})$`),
+			usage:    "/lang <ru|en>",
+			help:     "язык интерфейса, по умолчанию - как в телеграме",
+			examples: []string{"/lang en"},
+			label:    "set_lang",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgLang(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "tz",
+			args:     regexp.MustCompile(`^(\S+)?$`),
+			usage:    "/tz [часовой пояс]",
+			help:     "часовой пояс, в котором считаются дни и месяцы, без аргумента - текущий",
+			examples: []string{"/tz Asia/Vladivostok", "/tz Europe/Moscow"},
+			label:    "set_timezone",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgTimezone(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "remind",
+			args:     regexp.MustCompile(`^(\d{1,2}:\d{2}|(?i:off))$`),
+			usage:    "/remind <ЧЧ:ММ|off>",
+			help:     "ежедневное напоминание записать траты, если за день их нет",
+			examples: []string{"/remind 21:00", "/remind off"},
+			label:    "set_reminder",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgRemind(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "confirm",
+			args:     regexp.MustCompile(`^((?i:on|off))$`),
+			usage:    "/confirm <on|off>",
+			help:     "спрашивать подтверждение перед записью необычно крупной траты",
+			examples: []string{"/confirm on", "/confirm off"},
+			label:    "set_confirm",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgConfirm(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "subscribe",
+			args:     regexp.MustCompile(`^(weekly|monthly)$`),
+			usage:    "/subscribe <weekly|monthly>",
+			help:     "присылать отчет за каждую прошедшую неделю или месяц со сравнением с предыдущим периодом",
+			examples: []string{"/subscribe weekly", "/subscribe monthly"},
+			label:    "subscribe",
+			handler: func(ctx context.Context, m *Model, msg Message, args commandArgs) error {
+				return m.msgSubscribe(ctx, msg, args.Groups[1])
+			},
+		},
+		{
+			name:     "unsubscribe",
+			usage:    "/unsubscribe",
+			help:     "отменить подписку на отчеты",
+			examples: []string{"/unsubscribe"},
+			label:    "unsubscribe",
+			handler: func(ctx context.Context, m *Model, msg Message, _ commandArgs) error {
+				return m.msgUnsubscribe(ctx, msg)
+			},
+		},
+	}
+
+	commandsByName = make(map[string]command, len(commands))
+	for _, c := range commands {
+		commandsByName[c.name] = c
+	}
+}
+
+// splitCommand отделяет имя команды от аргументов: "/add 100 кофе" -> "add", "100 кофе".
+// В группах телеграм дописывает к команде имя бота (/add@bot), оно отбрасывается
+func splitCommand(text string) (name, args string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+
+	name, args, _ = strings.Cut(text[1:], " ")
+	name, _, _ = strings.Cut(name, "@")
+
+	return strings.ToLower(name), strings.TrimSpace(args), name != ""
+}
+
+// helpText список команд для /help, собирается из описаний команд
+func helpText(ctx context.Context) string {
+	res := strings.Builder{}
+	res.WriteString(tr(ctx, TxtHelpHeader) + "\n")
+	for _, c := range commands {
+		res.WriteString(fmt.Sprintf("%s - %s\n", tr(ctx, c.usage), tr(ctx, c.help)))
+	}
+	res.WriteString("\n" + tr(ctx, TxtHelpFreeText) + "\n")
+	res.WriteString(tr(ctx, TxtHelpMore))
+	return res.String()
+}
+
+// commandHelpText подробное описание команды для /help <команда>
+func commandHelpText(ctx context.Context, name string) (string, bool) {
+	c, ok := commandsByName[strings.ToLower(name)]
+	if !ok {
+		return "", false
+	}
+
+	res := strings.Builder{}
+	res.WriteString(fmt.Sprintf("%s\n%s", tr(ctx, c.usage), tr(ctx, c.help)))
+	if len(c.examples) > 0 {
+		res.WriteString("\n\n" + tr(ctx, TxtHelpExamples))
+		for _, e := range c.examples {
+			res.WriteString("\n" + e)
+		}
+	}
+	return res.String(), true
+}
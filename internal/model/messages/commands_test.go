@@ -0,0 +1,225 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+)
+
+func Test_splitCommand(t *testing.T) {
+	tests := []struct {
+		text     string
+		wantName string
+		wantArgs string
+		wantOk   bool
+	}{
+		{text: "/add 100 кофе", wantName: "add", wantArgs: "100 кофе", wantOk: true},
+		{text: "/tags", wantName: "tags", wantOk: true},
+		{text: "/ADD 100", wantName: "add", wantArgs: "100", wantOk: true},
+		{text: "/add@financial_bot 100", wantName: "add", wantArgs: "100", wantOk: true},
+		{text: "  /add   100  ", wantName: "add", wantArgs: "100", wantOk: true},
+		{text: "кофе 100"},
+		{text: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			name, args, ok := splitCommand(tt.text)
+
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.wantName, name)
+				assert.Equal(t, tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func Test_command_parseArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		args       string
+		wantOk     bool
+		wantGroups []string
+		wantTags   []string
+		wantNote   string
+	}{
+		{name: "только сумма", command: "add", args: "100", wantOk: true, wantGroups: []string{"100", "100", "", "", ""}},
+		{name: "дробная сумма", command: "add", args: "100.5", wantOk: true, wantGroups: []string{"100.5", "100.5", "", "", ""}},
+		{name: "сумма с валютой", command: "add", args: "10usd", wantOk: true, wantGroups: []string{"10usd", "10", "usd", "", ""}},
+		{
+			name: "сумма и категория из нескольких слов", command: "add", args: "450 Пятёрочка у дома", wantOk: true,
+			wantGroups: []string{"450 Пятёрочка у дома", "450", "", "Пятёрочка у дома", ""},
+		},
+		{
+			name: "сумма, категория и дата", command: "add", args: "450 кофе 01.03.2024", wantOk: true,
+			wantGroups: []string{"450 кофе 01.03.2024", "450", "", "кофе", "01.03.2024"},
+		},
+		{
+			name: "теги и заметка", command: "add", args: `1200 еда #командировка "обед с клиентом"`, wantOk: true,
+			wantGroups: []string{"1200 еда", "1200", "", "еда", ""},
+			wantTags:   []string{"командировка"}, wantNote: "обед с клиентом",
+		},
+		{name: "сумма не число", command: "add", args: "сто кофе"},
+		{name: "мусор после суммы", command: "add", args: "100kg!"},
+		{name: "нет аргументов", command: "add", args: ""},
+
+		{name: "снятие лимита", command: "limit", args: "-1", wantOk: true, wantGroups: []string{"-1", "-1"}},
+		{name: "лимит не число", command: "limit", args: "много"},
+
+		{name: "отчет за месяц", command: "report", args: "month", wantOk: true, wantGroups: []string{"month", "month", "", "", ""}},
+		{
+			name: "отчет с тегом", command: "report", args: "month #командировка", wantOk: true,
+			wantGroups: []string{"month", "month", "", "", ""}, wantTags: []string{"командировка"},
+		},
+		{name: "отчет в pdf", command: "report", args: "month pdf", wantOk: true, wantGroups: []string{"month pdf", "month", "", "", "pdf"}},
+		{name: "отчет в pdf без периода", command: "report", args: "pdf", wantOk: true, wantGroups: []string{"pdf", "", "", "", "pdf"}},
+		{
+			name: "отчет по всем валютам по сегодняшнему курсу", command: "report", args: "week all today pdf", wantOk: true,
+			wantGroups: []string{"week all today pdf", "week", "all", "today", "pdf"},
+		},
+		{name: "только пересчет по курсу", command: "report", args: "today", wantOk: true, wantGroups: []string{"today", "", "", "today", ""}},
+		{name: "настройки не по порядку", command: "report", args: "today all"},
+		{name: "pdf без пробела", command: "report", args: "monthpdf"},
+		{name: "неизвестный период", command: "report", args: "day"},
+
+		{name: "правило", command: "rule", args: "такси -> Транспорт", wantOk: true, wantGroups: []string{"такси -> Транспорт", "такси", "Транспорт"}},
+		{name: "правило без стрелки", command: "rule", args: "такси Транспорт"},
+
+		{name: "валюта", command: "currency", args: "usd", wantOk: true, wantGroups: []string{"usd", "usd"}},
+		{name: "валюта не код", command: "currency", args: "доллар"},
+
+		{name: "команда без аргументов", command: "tags", args: "", wantOk: true},
+		{name: "лишние аргументы", command: "tags", args: "все"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, ok := commandsByName[tt.command]
+			assert.True(t, ok)
+
+			args, ok := cmd.parseArgs(tt.args)
+
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.wantGroups, args.Groups)
+				assert.Equal(t, tt.wantTags, args.Tags)
+				assert.Equal(t, tt.wantNote, args.Note)
+			}
+		})
+	}
+}
+
+func Test_commands_AreDeclaredCompletely(t *testing.T) {
+	for _, c := range commands {
+		assert.NotEmpty(t, c.name)
+		assert.NotEmpty(t, c.usage, c.name)
+		assert.NotEmpty(t, c.help, c.name)
+		assert.NotEmpty(t, c.label, c.name)
+		assert.NotNil(t, c.handler, c.name)
+		assert.NotEmpty(t, c.examples, c.name)
+	}
+	assert.Len(t, commandsByName, len(commands), "имена команд не должны повторяться")
+}
+
+func Test_OnInvalidArgs_ShouldAnswerWithUsage(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	sender.EXPECT().SendMessage(fmt.Sprintf(ErrTxtCommandUsage, commandsByName["currency"].usage), int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/currency доллар",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnHelpCommand_ShouldListAllCommands(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	sender.EXPECT().SendMessage(gomock.Any(), int64(123)).DoAndReturn(func(text string, _ int64) error {
+		for _, c := range commands {
+			assert.Contains(t, text, c.usage)
+		}
+		return nil
+	})
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/help",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnHelpCommand_ShouldDescribeCommand(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	sender.EXPECT().SendMessage(gomock.Any(), int64(123)).DoAndReturn(func(text string, _ int64) error {
+		assert.Contains(t, text, commandsByName["find"].usage)
+		assert.Contains(t, text, "Примеры:")
+		for _, e := range commandsByName["find"].examples {
+			assert.Contains(t, text, e)
+		}
+		return nil
+	})
+	assert.NoError(t, model.IncomingMessage(ctx, tg.Message{Text: "/help /find", UserID: 123, UserName: "name"}))
+
+	sender.EXPECT().SendMessage(ErrTxtUnknownHelpTopic, int64(123))
+	assert.NoError(t, model.IncomingMessage(ctx, tg.Message{Text: "/help coffee", UserID: 123, UserName: "name"}))
+}
+
+func Test_OnLimitReset(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().ChangeUserLimit(gomock.Any(), int64(123), "-1").Return(nil)
+	sender.EXPECT().SendMessage(ScsTxtLimitChanged, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/limit -1",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnUnknownSlashCommand(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	sender.EXPECT().SendMessage(ErrTxtUnknownCommand, int64(123))
+
+	// похожа на трату, но начинается со слэша, значит это неизвестная команда
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/coffee 250",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
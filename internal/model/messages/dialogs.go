@@ -0,0 +1,126 @@
+package messages
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/fsm"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// состояния диалогов с пользователем. Ответы в диалогах приходят колбэками от кнопок
+const (
+	stateNonExistentCategory fsm.State = "msgNonExistentCategory"
+	stateConfirmPurchase     fsm.State = "msgConfirmPurchase"
+	stateFindPage            fsm.State = "msgFindPage"
+	stateSelectCurrency      fsm.State = "msgSelectCurrency"
+	stateSelectPeriod        fsm.State = "msgSelectPeriod"
+	stateSelectCategory      fsm.State = "msgSelectCategory"
+	stateConfirmUnusual      fsm.State = "msgConfirmUnusual"
+
+	stateOnboardingCurrency   fsm.State = "onboardingCurrency"
+	stateOnboardingLimit      fsm.State = "onboardingLimit"
+	stateOnboardingCategories fsm.State = "onboardingCategories"
+)
+
+// frozenCommand "замороженная" команда, которая будет выполнена после ответа пользователя
+type frozenCommand struct {
+	Command string `json:"command"`
+	// Options варианты, предложенные кнопками, в колбэке приходит номер варианта
+	Options []string `json:"options,omitempty"`
+}
+
+// pendingPurchase трата, для которой пользователь выбирает категорию или подтверждает необычно крупную сумму
+type pendingPurchase struct {
+	Command  string          `json:"command"`
+	Purchase addPurchaseArgs `json:"purchase"`
+	// Options предложенные категории, в колбэке приходит номер категории
+	Options []string `json:"options,omitempty"`
+}
+
+// reportFilter теги из /report, по которым отфильтруется отчет, когда пользователь выберет период,
+// и остальные настройки отчета из команды
+type reportFilter struct {
+	Tags          []string `json:"tags,omitempty"`
+	PDF           bool     `json:"pdf,omitempty"`
+	AllCurrencies bool     `json:"allCurrencies,omitempty"`
+	TodayRates    bool     `json:"todayRates,omitempty"`
+}
+
+// options настройки запроса на отчет
+func (f reportFilter) options() purchases.ReportOptions {
+	opts := purchases.ReportOptions{Tags: f.Tags, AllCurrencies: f.AllCurrencies, TodayRates: f.TodayRates}
+	if f.PDF {
+		opts.Format = purchases.ReportFormatPDF
+	}
+	return opts
+}
+
+// searchPage открытая страница результатов поиска
+type searchPage struct {
+	Query string `json:"query"`
+	Page  int    `json:"page,omitempty"`
+}
+
+// newDialogs объявляет все диалоги бота
+func (m *Model) newDialogs(store StatusStore) *fsm.Machine {
+	d := fsm.New(store)
+
+	fsm.Handle(d, stateNonExistentCategory, func(ctx context.Context, ev fsm.Event, p pendingPurchase) error {
+		return m.msgNonExistentCategory(ctx, callback(ev), p)
+	})
+	fsm.Handle(d, stateConfirmPurchase, func(ctx context.Context, ev fsm.Event, p frozenCommand) error {
+		return m.msgConfirmPurchase(ctx, callback(ev), p)
+	})
+	fsm.Handle(d, stateFindPage, func(ctx context.Context, ev fsm.Event, p searchPage) error {
+		return m.msgFindPage(ctx, callback(ev), p)
+	})
+	fsm.Handle(d, stateSelectCurrency, func(ctx context.Context, ev fsm.Event, _ struct{}) error {
+		return m.msgCurrencySelected(ctx, callback(ev))
+	})
+	fsm.Handle(d, stateSelectPeriod, func(ctx context.Context, ev fsm.Event, p reportFilter) error {
+		return m.msgPeriodSelected(ctx, callback(ev), p)
+	})
+	fsm.Handle(d, stateSelectCategory, func(ctx context.Context, ev fsm.Event, p pendingPurchase) error {
+		return m.msgCategorySelected(ctx, callback(ev), p)
+	})
+	fsm.Handle(d, stateConfirmUnusual, func(ctx context.Context, ev fsm.Event, p pendingPurchase) error {
+		return m.msgUnusualConfirmed(ctx, callback(ev), p)
+	})
+
+	fsm.Handle(d, stateOnboardingCurrency, func(ctx context.Context, ev fsm.Event, _ struct{}) error {
+		return m.msgOnboardingCurrency(ctx, callback(ev))
+	})
+	fsm.Handle(d, stateOnboardingLimit, func(ctx context.Context, ev fsm.Event, _ struct{}) error {
+		return m.msgOnboardingLimit(ctx, callback(ev))
+	})
+	fsm.Handle(d, stateOnboardingCategories, func(ctx context.Context, ev fsm.Event, p onboardingPicked) error {
+		return m.msgOnboardingCategories(ctx, callback(ev), p)
+	})
+
+	return d
+}
+
+// callback нажатие на кнопку, на которое отвечает обработчик состояния
+func callback(ev fsm.Event) Callback {
+	return Callback{UserID: ev.UserID, UserName: ev.UserName, Data: ev.Data, MessageID: ev.MessageID}
+}
+
+// msgCancel выход из любого диалога
+func (m *Model) msgCancel(ctx context.Context, Send Message) error {
+	state, err := m.dialogs.Current(ctx, Send.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "dialogs.Current")
+		return m.SendMessage(errText(ctx, err), Send.UserID)
+	}
+	if state == fsm.None {
+		return m.SendMessage(tr(ctx, ScsTxtNothingToCancel), Send.UserID)
+	}
+
+	if err = m.dialogs.Reset(ctx, Send.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.SendMessage(tr(ctx, ScsTxtDialogCanceled), Send.UserID)
+}
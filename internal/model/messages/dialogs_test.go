@@ -0,0 +1,76 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+)
+
+func Test_OnCancelCommand(t *testing.T) {
+	t.Run("пользователь в диалоге", func(t *testing.T) {
+		ctx := context.Background()
+
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+
+		statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(encodeState(t, stateOnboardingLimit), nil)
+		statusStore.EXPECT().Delete(gomock.Any(), "123status").Return(nil)
+		sender.EXPECT().SendMessage(ScsTxtDialogCanceled, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/cancel", UserID: 123, UserName: "name"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("отменять нечего", func(t *testing.T) {
+		ctx := context.Background()
+
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+
+		statusStore.EXPECT().GetString(gomock.Any(), "123status").Return("", nil)
+		sender.EXPECT().SendMessage(ScsTxtNothingToCancel, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/cancel", UserID: 123, UserName: "name"})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnCallbackAfterTimeout_ShouldAskToRetry(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	deadline := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+	raw := `{"state":"msgConfirmPurchase","payload":{"command":"2 кофе 250"},"deadline":"` + deadline + `"}`
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(base64.StdEncoding.EncodeToString([]byte(raw)), nil)
+	statusStore.EXPECT().Delete(gomock.Any(), "123status").Return(nil)
+	sender.EXPECT().SendMessage(ErrTxtDialogExpired, int64(123))
+
+	err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "1"})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnCallbackWithoutDialog(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").Return("", nil)
+	sender.EXPECT().SendMessage(ErrTxtInvalidStatus, int64(123))
+
+	err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "0"})
+
+	assert.NoError(t, err)
+}
@@ -2,12 +2,33 @@ package messages
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/messages/_mocks"
 )
 
+// mocksUp моки для пользователя, который не выбирал язык и пишет из русского телеграма
 func mocksUp(t *testing.T) (*mocks.MockMessageSender, *mocks.MockPurchasesModel, *mocks.MockStatusStore) {
+	return mocksUpWithLang(t, "")
+}
+
+// mocksUpWithLang моки для пользователя, выбравшего язык lang
+func mocksUpWithLang(t *testing.T, lang i18n.Lang) (*mocks.MockMessageSender, *mocks.MockPurchasesModel, *mocks.MockStatusStore) {
+	return mocksUpWithLocation(t, lang, time.UTC)
+}
+
+// mocksUpWithLocation моки для пользователя, выбравшего язык lang и часовой пояс loc
+func mocksUpWithLocation(t *testing.T, lang i18n.Lang, loc *time.Location) (*mocks.MockMessageSender, *mocks.MockPurchasesModel, *mocks.MockStatusStore) {
 	ctrl := gomock.NewController(t)
-	return mocks.NewMockMessageSender(ctrl), mocks.NewMockPurchasesModel(ctrl), mocks.NewMockStatusStore(ctrl)
+
+	purchasesModel := mocks.NewMockPurchasesModel(ctrl)
+	purchasesModel.EXPECT().GetUserLang(gomock.Any(), gomock.Any()).Return(lang, nil).AnyTimes()
+	purchasesModel.EXPECT().GetUserLocation(gomock.Any(), gomock.Any()).Return(loc, nil).AnyTimes()
+
+	statusStore := mocks.NewMockStatusStore(ctrl)
+	statusStore.EXPECT().StringTTL().Return(30 * time.Minute).AnyTimes()
+
+	return mocks.NewMockMessageSender(ctrl), purchasesModel, statusStore
 }
@@ -3,29 +3,39 @@ package messages
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/fsm"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
 )
 
 type Callback struct {
-	UserID   int64
-	UserName string
-	Data     string
+	UserID       int64
+	UserName     string
+	Data         string
+	LanguageCode string
+	MessageID    int
 }
 
+// IncomingCallback нажатия на кнопки обрабатывает текущее состояние диалога пользователя
 func (m *Model) IncomingCallback(ctx context.Context, msg tg.Callback) error {
-	info, err := m.getUserInfo(ctx, msg.UserID)
-	if err != nil {
-		return m.SendMessage("Ошибочка: "+err.Error(), msg.UserID)
+	if throttled, err := m.throttled(ctx, msg.UserID, msg.LanguageCode, ratelimit.ClassDefault, "callback"); throttled {
+		return err
 	}
+	ctx = m.withUserLang(ctx, msg.UserID, msg.LanguageCode)
 
-	switch info.Status {
-	case statusNonExistentCategory:
-		return m.msgNonExistentCategory(ctx, Callback(msg), info)
-
-	default:
-		if err = m.setUserInfo(ctx, msg.UserID, userInfo{}); err != nil {
-			return m.SendMessage("Ошибочка: "+err.Error(), msg.UserID)
-		}
-		return m.SendMessage(ErrTxtInvalidStatus, msg.UserID)
+	err := m.dialogs.Dispatch(ctx, fsm.Event{
+		UserID:    msg.UserID,
+		UserName:  msg.UserName,
+		Data:      msg.Data,
+		MessageID: msg.MessageID,
+	})
+	switch {
+	case errors.Is(err, fsm.ErrExpired):
+		return m.SendMessage(tr(ctx, ErrTxtDialogExpired), msg.UserID)
+	case errors.Is(err, fsm.ErrNoState), errors.Is(err, fsm.ErrUnknownState):
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
 	}
+
+	return err
 }
@@ -5,19 +5,27 @@ import (
 
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/normalize"
 )
 
-func (m *Model) msgNonExistentCategory(ctx context.Context, msg Callback, info userInfo) error {
+// msgNonExistentCategory категория, выбранная кнопкой для траты, категорию которой не удалось определить по тексту.
+// Текст траты становится ее описанием
+func (m *Model) msgNonExistentCategory(ctx context.Context, msg Callback, pending pendingPurchase) error {
 	// если пользователь выбрал создание новой категории
-	if msg.Data == ButtonTxtCreateCategory {
-		if err := m.setUserInfo(ctx, msg.UserID, userInfo{}); err != nil {
-			err = errors.Wrap(err, "setUserInfo")
-			return m.SendMessage("Ошибочка: "+err.Error(), msg.UserID)
+	if msg.Data == dataCreateCategory {
+		if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+			err = errors.Wrap(err, "dialogs.Reset")
+			return m.SendMessage(errText(ctx, err), msg.UserID)
 		}
-		return m.SendMessage(ScsTxtCategoryAddSelected, msg.UserID)
+		return m.SendMessage(tr(ctx, ScsTxtCategoryAddSelected), msg.UserID)
 	}
-	catName := msg.Data
+
+	i, ok := option(msg.Data, len(pending.Options))
+	if !ok {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+	}
+	catName := pending.Options[i]
 
 	// если пользователь выбрал одну из предложенных категорий
 	userHasThisCat := false
@@ -38,19 +46,145 @@ func (m *Model) msgNonExistentCategory(ctx context.Context, msg Callback, info u
 	if !userHasThisCat {
 		if err := m.purchasesModel.AddCategoryToUser(ctx, msg.UserID, normalize.Category(catName)); err != nil {
 			err = errors.Wrap(err, "purchasesModel.AddCategoryToUser")
-			return m.SendMessage("Ошибочка: "+err.Error(), msg.UserID)
+			return m.SendMessage(errText(ctx, err), msg.UserID)
 		}
-		_ = m.SendMessage(ScsTxtCategoryAddedToUser, msg.UserID)
+		_ = m.SendMessage(tr(ctx, ScsTxtCategoryAddedToUser), msg.UserID)
+	}
+
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	if !pending.Purchase.CategoryChosen {
+		pending.Purchase.Description = pending.Purchase.Category
+		pending.Purchase.CategoryChosen = true
+	}
+	pending.Purchase.Category = catName
+
+	return m.msgAddPurchase(ctx, Message{
+		Text:     pending.Command,
+		UserID:   msg.UserID,
+		UserName: msg.UserName,
+	}, pending.Purchase)
+}
+
+func (m *Model) msgConfirmPurchase(ctx context.Context, msg Callback, info frozenCommand) error {
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	if msg.Data == dataCancel {
+		return m.SendMessage(tr(ctx, ScsTxtPurchaseCanceled), msg.UserID)
+	}
+
+	// текст разбирается так же, как при отправке вариантов, поэтому номер варианта указывает на тот же вариант
+	candidates, err := freetext.Parse(info.Command, m.userNow(ctx, msg.UserID))
+	if err != nil {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
 	}
 
-	if err := m.setUserInfo(ctx, msg.UserID, userInfo{}); err != nil {
-		err = errors.Wrap(err, "setUserInfo")
-		return m.SendMessage("Ошибочка: "+err.Error(), msg.UserID)
+	i, ok := option(msg.Data, len(candidates))
+	if !ok {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
 	}
 
-	return m.IncomingMessage(ctx, tg.Message{
+	return m.addFreeTextPurchase(ctx, Message{
 		Text:     info.Command,
 		UserID:   msg.UserID,
 		UserName: msg.UserName,
-	})
+	}, candidates[i])
+}
+
+func (m *Model) msgFindPage(ctx context.Context, msg Callback, info searchPage) error {
+	page := info.Page
+	switch msg.Data {
+	case dataNextPage:
+		page++
+	case dataPrevPage:
+		page--
+	default:
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+	}
+
+	// если у новой страницы не будет кнопок, состояние больше не нужно. Иначе msgFind выставит его заново
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	// новая страница заменяет старую в том же сообщении
+	return m.msgFind(ctx, Message{UserID: msg.UserID, UserName: msg.UserName}, info.Query, page, tg.MessageRef{ID: msg.MessageID})
+}
+
+// msgCurrencySelected валюта, выбранная кнопкой после /currency без аргумента
+func (m *Model) msgCurrencySelected(ctx context.Context, msg Callback) error {
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	return m.msgCurrency(ctx, Message{UserID: msg.UserID, UserName: msg.UserName}, msg.Data, tg.MessageRef{ID: msg.MessageID})
+}
+
+// msgPeriodSelected период, выбранный кнопкой после /report без периода
+func (m *Model) msgPeriodSelected(ctx context.Context, msg Callback, filter reportFilter) error {
+	if !isPeriod(msg.Data) {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+	}
+
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	return m.msgReport(ctx, Message{UserID: msg.UserID, UserName: msg.UserName}, msg.Data, filter, tg.MessageRef{ID: msg.MessageID})
+}
+
+// msgCategorySelected категория, выбранная кнопкой для траты без категории
+func (m *Model) msgCategorySelected(ctx context.Context, msg Callback, pending pendingPurchase) error {
+	if msg.Data != dataNoCategory {
+		i, ok := option(msg.Data, len(pending.Options))
+		if !ok {
+			return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+		}
+		// у траты без категории нет и описания
+		pending.Purchase.Category = pending.Options[i]
+		pending.Purchase.CategoryChosen = true
+	}
+
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	return m.msgAddPurchase(ctx, Message{
+		Text:     pending.Command,
+		UserID:   msg.UserID,
+		UserName: msg.UserName,
+	}, pending.Purchase)
+}
+
+// msgUnusualConfirmed ответ на вопрос, точно ли записать необычно крупную трату
+func (m *Model) msgUnusualConfirmed(ctx context.Context, msg Callback, pending pendingPurchase) error {
+	if msg.Data != dataConfirm && msg.Data != dataCancel {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+	}
+
+	if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+		err = errors.Wrap(err, "dialogs.Reset")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	if msg.Data == dataCancel {
+		return m.SendMessage(tr(ctx, ScsTxtPurchaseCanceled), msg.UserID)
+	}
+
+	pending.Purchase.Confirmed = true
+	return m.msgAddPurchase(ctx, Message{
+		Text:     pending.Command,
+		UserID:   msg.UserID,
+		UserName: msg.UserName,
+	}, pending.Purchase)
 }
@@ -0,0 +1,104 @@
+package messages
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
+)
+
+// IncomingInlineQuery "@bot 250 кофе" в любом чате: текст разбирается как трата в свободной форме, варианты
+// показываются вместе с тем, сколько в этой категории уже потрачено за месяц. Трата добавляется, только когда
+// пользователь выберет вариант (IncomingChosenInlineResult)
+func (m *Model) IncomingInlineQuery(ctx context.Context, query tg.InlineQuery) error {
+	// запросы приходят на каждую набранную букву, поэтому в чат о превышении лимита не пишем, просто не отвечаем
+	if !m.allow(ctx, query.UserID, ratelimit.ClassInline, "inline").Allowed {
+		return m.tgClient.AnswerInlineQuery(query.ID, nil)
+	}
+	ctx = m.withUserLang(ctx, query.UserID, query.LanguageCode)
+
+	return metricsWrapper(
+		func() error {
+			candidates, err := freetext.Parse(query.Query, m.userNow(ctx, query.UserID))
+			if err != nil {
+				// пользователь еще не дописал трату
+				return m.tgClient.AnswerInlineQuery(query.ID, nil)
+			}
+
+			results := make([]tg.InlineResult, 0, len(candidates))
+			for i, c := range candidates {
+				category := purchases.CategoryKey(i18n.FromContext(ctx), c.Description)
+				preview, err := m.purchasesModel.PreviewPurchase(ctx, query.UserID, category, c.Sum)
+				if err != nil {
+					return errors.Wrap(err, "purchasesModel.PreviewPurchase")
+				}
+
+				// номер варианта указывает на тот же вариант, когда текст будет разобран заново после выбора
+				results = append(results, tg.InlineResult{
+					ID:          strconv.Itoa(i),
+					Title:       c.String(),
+					Description: previewText(ctx, preview),
+					Text:        trf(ctx, TxtInlinePurchase, c.String()),
+				})
+			}
+
+			return m.tgClient.AnswerInlineQuery(query.ID, results)
+		},
+		"inline",
+	)
+}
+
+// IncomingChosenInlineResult пользователь выбрал вариант траты в inline-режиме, она добавляется так же,
+// как написанная боту в свободной форме, а ответ приходит в личный чат с ботом
+func (m *Model) IncomingChosenInlineResult(ctx context.Context, result tg.ChosenInlineResult) error {
+	if throttled, err := m.throttled(ctx, result.UserID, result.LanguageCode, ratelimit.ClassDefault, "inline_add"); throttled {
+		return err
+	}
+	ctx = m.withUserLang(ctx, result.UserID, result.LanguageCode)
+
+	return metricsWrapper(
+		func() error {
+			candidates, err := freetext.Parse(result.Query, m.userNow(ctx, result.UserID))
+			if err != nil {
+				return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), result.UserID)
+			}
+
+			i, ok := option(result.ResultID, len(candidates))
+			if !ok {
+				return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), result.UserID)
+			}
+
+			return m.addFreeTextPurchase(ctx, Message{
+				Text:         result.Query,
+				UserID:       result.UserID,
+				UserName:     result.UserName,
+				LanguageCode: result.LanguageCode,
+			}, candidates[i])
+		},
+		"inline_add",
+	)
+}
+
+// previewText подпись варианта траты: сколько уже потрачено в ее категории за месяц
+func previewText(ctx context.Context, preview purchases.PurchasePreview) string {
+	if preview.Unknown {
+		return tr(ctx, TxtInlineUnknownCategory)
+	}
+
+	userCY, err := cy.CurrencyToStr(preview.Currency)
+	if err != nil {
+		return ""
+	}
+	expenses := i18n.Number(i18n.FromContext(ctx), preview.MonthExpenses)
+
+	if preview.Category == "" {
+		return trf(ctx, TxtInlineNoCategory, expenses, userCY)
+	}
+	return trf(ctx, TxtInlineCategoryMonth, purchases.CategoryName(i18n.FromContext(ctx), preview.Category), expenses, userCY)
+}
@@ -0,0 +1,70 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_OnInlineQuery_ShouldPreviewPurchases(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().PreviewPurchase(gomock.Any(), int64(123), "кофе 250", float64(2)).
+		Return(purchases.PurchasePreview{Category: "Кафе", MonthExpenses: 1250.5, Currency: cy.RUB}, nil)
+	purchasesModel.EXPECT().PreviewPurchase(gomock.Any(), int64(123), "2 кофе", float64(250)).
+		Return(purchases.PurchasePreview{Unknown: true, Currency: cy.RUB}, nil)
+	// трата еще не добавляется
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	sender.EXPECT().AnswerInlineQuery("q1", []tg.InlineResult{
+		{ID: "0", Title: "2 · кофе 250", Description: "«Кафе»: в этом месяце уже 1 250,50 RUB", Text: "Трата: 2 · кофе 250"},
+		{ID: "1", Title: "250 · 2 кофе", Description: TxtInlineUnknownCategory, Text: "Трата: 250 · 2 кофе"},
+	})
+
+	err := model.IncomingInlineQuery(ctx, tg.InlineQuery{ID: "q1", Query: "2 кофе 250", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+}
+
+func Test_OnInlineQueryWithoutSum_ShouldAnswerEmpty(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	sender.EXPECT().AnswerInlineQuery("q1", nil)
+
+	err := model.IncomingInlineQuery(ctx, tg.InlineQuery{ID: "q1", Query: "кофе", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+}
+
+func Test_OnChosenInlineResult_ShouldAddPurchase(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	t.Run("выбранный вариант", func(t *testing.T) {
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "2 кофе", "").
+			Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+		sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+		err := model.IncomingChosenInlineResult(ctx, tg.ChosenInlineResult{ResultID: "1", Query: "2 кофе 250", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("неизвестный вариант", func(t *testing.T) {
+		sender.EXPECT().SendMessage(ErrTxtInvalidStatus, int64(123))
+
+		err := model.IncomingChosenInlineResult(ctx, tg.ChosenInlineResult{ResultID: "5", Query: "2 кофе 250", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+}
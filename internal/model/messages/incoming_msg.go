@@ -2,114 +2,97 @@ package messages
 
 import (
 	"context"
-	"regexp"
+	"time"
 
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
 )
 
 type Message struct {
-	Text     string
-	UserID   int64
-	UserName string
+	Text         string
+	UserID       int64
+	UserName     string
+	LanguageCode string
 }
 
-var (
-	// addPurchaseOnlySum сообщение о добавлении траты без категории и даты (указывается текущая дата)
-	addPurchaseOnlySum = regexp.MustCompile(`/add (\d+.?\d*)`)
-	// addPurchaseSumAndCategory сообщение о добавлении траты с категорией, но без даты (указывается текущая дата)
-	addPurchaseSumAndCategory = regexp.MustCompile(`/add (\d+.?\d*) ([ \wФА-Яа-я]+)`)
-	// addPurchaseSumAndCategoryAndDate сообщение о добавлении траты с категорией и датой
-	addPurchaseSumAndCategoryAndDate = regexp.MustCompile(`/add (\d+\.?\d*) ([ \wФА-Яа-я]+) (\d{2}\.\d{2}\.\d{4})`)
-
-	// addCategory добавление новой категории
-	addCategory = regexp.MustCompile(`/category ([ \wФА-Яа-я\-]+)`)
-
-	// report создание отчета за выбранный период
-	report = regexp.MustCompile(`/report (month|week|year)`)
-
-	// currency команда для смены основной валюты пользователя
-	currency = regexp.MustCompile(`/currency ([A-Za-z]{3})`)
-	// limit команда для задания месячного лимита трат пользователю
-	limit = regexp.MustCompile(`/limit (\d+.?\d*)`)
-)
-
+// IncomingMessage роутер входящих сообщений: команда ищется по имени в реестре commands,
+// а ее аргументы разбираются по грамматике команды
 func (m *Model) IncomingMessage(ctx context.Context, message tg.Message) error {
 	msg := Message(message)
-	switch {
-	case msg.Text == "/start":
-		return m.SendMessage("hello", msg.UserID)
 
-	case report.MatchString(msg.Text):
-		return metricsWrapper(
-			func() error { return m.msgReport(ctx, msg) },
-			"report",
-		)
-
-	case addCategory.MatchString(msg.Text):
-		return metricsWrapper(
-			func() error { return m.msgAddCategory(ctx, msg) },
-			"add_category",
-		)
-
-	case addPurchaseSumAndCategoryAndDate.MatchString(msg.Text):
-		res := addPurchaseSumAndCategoryAndDate.FindStringSubmatch(msg.Text)
-		if len(res) < 4 {
-			return m.SendMessage(ErrTxtInvalidInput, msg.UserID)
-		}
-
-		return metricsWrapper(
-			func() error { return m.msgAddPurchase(ctx, msg, res[1], res[2], res[3]) },
-			metricsCommAddPurchase,
-		)
-
-	case addPurchaseSumAndCategory.MatchString(msg.Text):
-		res := addPurchaseSumAndCategory.FindStringSubmatch(msg.Text)
-		if len(res) < 3 {
-			return m.SendMessage(ErrTxtInvalidInput, msg.UserID)
-		}
+	// ограничение проверяется до любых запросов в базу: класс запроса понятен по одному тексту
+	name, rawArgs, isCommand := splitCommand(msg.Text)
+	cmd, ok := commandsByName[name]
+	class, label := ratelimit.ClassDefault, "free_text"
+	switch {
+	case isCommand && ok:
+		class, label = cmd.rateClass(), cmd.label
+	case isCommand:
+		label = "unknown"
+	}
+	if throttled, err := m.throttled(ctx, msg.UserID, msg.LanguageCode, class, label); throttled {
+		return err
+	}
 
-		return metricsWrapper(
-			func() error { return m.msgAddPurchase(ctx, msg, res[1], res[2], "") },
-			metricsCommAddPurchase,
-		)
+	ctx = m.withUserLang(ctx, msg.UserID, msg.LanguageCode)
 
-	case addPurchaseOnlySum.MatchString(msg.Text):
-		res := addPurchaseOnlySum.FindStringSubmatch(msg.Text)
-		if len(res) < 2 {
-			return m.SendMessage(ErrTxtInvalidInput, msg.UserID)
+	if !isCommand {
+		// все, что не похоже на команду, пробуем понять как трату, написанную в свободной форме: "вчера такси 600 руб"
+		if candidates, err := freetext.Parse(msg.Text, m.userNow(ctx, msg.UserID)); err == nil {
+			return metricsWrapper(
+				func() error { return m.msgFreeText(ctx, msg, candidates) },
+				"free_text",
+			)
 		}
+		return m.unknownCommand(ctx, msg)
+	}
+	if !ok {
+		return m.unknownCommand(ctx, msg)
+	}
 
-		return metricsWrapper(
-			func() error { return m.msgAddPurchase(ctx, msg, res[1], "", "") },
-			metricsCommAddPurchase,
-		)
+	return metricsWrapper(
+		func() error {
+			args, ok := cmd.parseArgs(rawArgs)
+			if !ok {
+				return m.SendMessage(trf(ctx, ErrTxtCommandUsage, tr(ctx, cmd.usage)), msg.UserID)
+			}
+			return cmd.handler(ctx, m, msg, args)
+		},
+		cmd.label,
+	)
+}
 
-	case currency.MatchString(msg.Text):
-		res := currency.FindStringSubmatch(msg.Text)
-		if len(res) < 2 {
-			return m.SendMessage(ErrTxtInvalidInput, msg.UserID)
-		}
+func (m *Model) unknownCommand(ctx context.Context, msg Message) error {
+	return metricsWrapper(
+		func() error { return m.SendMessage(tr(ctx, ErrTxtUnknownCommand), msg.UserID) },
+		"unknown",
+	)
+}
 
-		return metricsWrapper(
-			func() error { return m.msgCurrency(ctx, msg, res[1]) },
-			"select_currency",
-		)
+// withUserLang кладет в контекст язык, на котором отвечать пользователю: выбранный командой /lang,
+// а если он не выбран - язык интерфейса телеграма
+func (m *Model) withUserLang(ctx context.Context, userID int64, languageCode string) context.Context {
+	// язык уже определен, например, когда после нажатия на кнопку выполняется "замороженная" команда
+	if _, ok := i18n.LangFromContext(ctx); ok {
+		return ctx
+	}
 
-	case limit.MatchString(msg.Text):
-		res := limit.FindStringSubmatch(msg.Text)
-		if len(res) < 2 {
-			return m.SendMessage(ErrTxtInvalidInput, msg.UserID)
-		}
+	lang, err := m.purchasesModel.GetUserLang(ctx, userID)
+	if err != nil || lang == "" {
+		lang = i18n.FromTelegram(languageCode)
+	}
 
-		return metricsWrapper(
-			func() error { return m.msgLimit(ctx, msg, res[1]) },
-			"set_limit",
-		)
+	return i18n.WithLang(ctx, lang)
+}
 
-	default:
-		return metricsWrapper(
-			func() error { return m.SendMessage(ErrTxtUnknownCommand, msg.UserID) },
-			"unknown",
-		)
+// userNow текущее время в часовом поясе пользователя: от него считаются "сегодня", "вчера" и дни недели
+// в тратах, написанных в свободной форме
+func (m *Model) userNow(ctx context.Context, userID int64) time.Time {
+	loc, err := m.purchasesModel.GetUserLocation(ctx, userID)
+	if err != nil {
+		return m.now()
 	}
+	return m.now().In(loc)
 }
@@ -4,115 +4,553 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/chart_drawing"
 	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 )
 
-func (m *Model) msgReport(ctx context.Context, Send Message) error {
-	res := report.FindStringSubmatch(Send.Text)
-	if len(res) < 2 {
-		return m.tgClient.SendMessage(ErrTxtInvalidInput, Send.UserID)
+// msgReport запрос отчета. Без периода бот предлагает выбрать его кнопками. Сообщение "Отчет готовится..."
+// запоминается под ключом reportMsgKey: когда отчет будет готов, SendReport заменит его текстом отчета.
+// edit - сообщение с кнопками выбора периода, если период выбран кнопкой
+func (m *Model) msgReport(ctx context.Context, Send Message, rawPeriod string, filter reportFilter, edit tg.MessageRef) error {
+	if rawPeriod == "" {
+		if err := m.dialogs.Enter(ctx, Send.UserID, stateSelectPeriod, filter); err != nil {
+			err = errors.Wrap(err, "dialogs.Enter")
+			return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+		}
+		return m.tgClient.SendKeyboard(tr(ctx, TxtSelectPeriod), Send.UserID, periodKeyboard(ctx))
 	}
 
-	period, err := m.purchasesModel.ToPeriod(res[1])
+	period, err := m.purchasesModel.ToPeriod(rawPeriod)
 	if err != nil {
-		return m.tgClient.SendMessage(ErrTxtInvalidInput, Send.UserID)
+		return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidInput), Send.UserID)
 	}
 
-	err = m.purchasesModel.CreateReportRequest(ctx, period, Send.UserID)
+	err = m.purchasesModel.CreateReportRequest(ctx, period, Send.UserID, i18n.FromContext(ctx), filter.options())
 	if err != nil {
 		err = errors.Wrap(err, "purchasesModel.CreateReportRequest")
-		return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 	}
 
-	return m.tgClient.SendMessage(ScsTxtReportRequestCreated, Send.UserID)
+	if edit.ID == 0 {
+		return m.tgClient.SendTrackedMessage(tr(ctx, ScsTxtReportRequestCreated), Send.UserID, reportMsgKey)
+	}
+	return m.tgClient.EditMessage(tr(ctx, ScsTxtReportRequestCreated), Send.UserID, tg.MessageRef{ID: edit.ID, Key: reportMsgKey})
 }
 
-func (m *Model) msgAddCategory(ctx context.Context, Send Message) error {
-	res := addCategory.FindStringSubmatch(Send.Text)
-	if len(res) < 2 {
-		return m.tgClient.SendMessage(ErrTxtInvalidInput, Send.UserID)
+func (m *Model) msgAddCategory(ctx context.Context, Send Message, category string) error {
+	err := m.purchasesModel.AddCategory(ctx, category)
+	if err != nil {
+		err = errors.Wrap(err, "purchasesModel.AddCategory")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 	}
+	return m.tgClient.SendMessage(tr(ctx, ScsTxtCategoryCreated), Send.UserID)
+}
 
-	err := m.purchasesModel.AddCategory(ctx, res[1])
+func (m *Model) msgAddRule(ctx context.Context, Send Message, condition, category string) error {
+	err := m.purchasesModel.AddCategoryRule(ctx, Send.UserID, condition, category)
 	if err != nil {
-		err = errors.Wrap(err, "purchasesModel.AddCategory")
-		return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+		if errors.Is(err, purchases.ErrRuleParsing) {
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidRule), Send.UserID)
+		}
+		if errors.Is(err, purchases.ErrCategoryNotExist) {
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtRuleCategoryNotExist), Send.UserID)
+		}
+		err = errors.Wrap(err, "purchasesModel.AddCategoryRule")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+	return m.tgClient.SendMessage(tr(ctx, ScsTxtRuleAdded), Send.UserID)
+}
+
+// addPurchaseArgs аргументы команды /add, пустые строки значат, что аргумент не указан
+type addPurchaseArgs struct {
+	Sum      string   `json:"sum"`
+	Currency string   `json:"currency,omitempty"` // код валюты, если трата не в основной валюте пользователя
+	Category string   `json:"category,omitempty"`
+	Date     string   `json:"date,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Note     string   `json:"note,omitempty"`
+	// Confirmed пользователь подтвердил необычно крупную сумму
+	Confirmed bool `json:"confirmed,omitempty"`
+	// CategoryChosen категория выбрана кнопкой, тогда описание траты - исходный текст Description
+	CategoryChosen bool   `json:"categoryChosen,omitempty"`
+	Description    string `json:"description,omitempty"`
+}
+
+// msgSelectCategory трата без категории: бот предлагает кнопками самые частые категории пользователя.
+// Если категорий у пользователя нет, трата сразу добавляется без категории
+func (m *Model) msgSelectCategory(ctx context.Context, Send Message, args addPurchaseArgs) error {
+	categories, err := m.purchasesModel.GetUserCategories(ctx, Send.UserID)
+	if err != nil || len(categories) == 0 {
+		return m.msgAddPurchase(ctx, Send, args)
+	}
+
+	// без описания категории упорядочиваются по тому, как часто пользователь их выбирал
+	if top, err := m.purchasesModel.SuggestCategories(ctx, Send.UserID, "", categories); err == nil {
+		categories = top
 	}
-	return m.tgClient.SendMessage(ScsTxtCategoryCreated, Send.UserID)
+	if len(categories) > topCategoriesCount {
+		categories = categories[:topCategoriesCount]
+	}
+
+	if err = m.dialogs.Enter(ctx, Send.UserID, stateSelectCategory, pendingPurchase{
+		Command:  Send.Text,
+		Purchase: args,
+		Options:  categories,
+	}); err != nil {
+		err = errors.Wrap(err, "dialogs.Enter")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	keyboard := tg.Grid(2, optionButtons(categoryNames(ctx, categories))...).Row(button(ctx, ButtonTxtNoCategory, dataNoCategory))
+	return m.tgClient.SendKeyboard(tr(ctx, TxtSelectCategory), Send.UserID, keyboard)
 }
 
-func (m *Model) msgAddPurchase(ctx context.Context, Send Message, sum, category, date string) error {
-	expAndLim, err := m.purchasesModel.AddPurchase(ctx, Send.UserID, sum, category, date)
+func (m *Model) msgAddPurchase(ctx context.Context, Send Message, args addPurchaseArgs) error {
+	var opts []purchases.AddPurchaseOption
+	if args.Currency != "" {
+		purchaseCY, err := cy.StrToCurrency(args.Currency)
+		if err != nil {
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidCurrency), Send.UserID)
+		}
+		opts = append(opts, purchases.WithCurrency(purchaseCY))
+	}
+	if len(args.Tags) != 0 {
+		opts = append(opts, purchases.WithTags(args.Tags...))
+	}
+	if args.Note != "" {
+		opts = append(opts, purchases.WithNote(args.Note))
+	}
+	if args.Confirmed {
+		opts = append(opts, purchases.Confirmed())
+	}
+	if args.CategoryChosen {
+		opts = append(opts, purchases.WithDescription(args.Description))
+	}
+
+	// стартовые категории хранятся под исходными названиями, а пользователь пишет их на своем языке
+	category := purchases.CategoryKey(i18n.FromContext(ctx), args.Category)
+	expAndLim, err := m.purchasesModel.AddPurchase(ctx, Send.UserID, args.Sum, category, args.Date, opts...)
 	if err != nil {
 		err = errors.Wrap(err, "purchasesModel.AddPurchase")
 
 		if errors.Is(err, purchases.ErrCategoryNotExist) || errors.Is(err, purchases.ErrUserHasntCategory) {
 			categories, err := m.purchasesModel.GetAllCategories(ctx)
 			if err != nil {
-				return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+				return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 			}
 
-			buttons := make([]string, len(categories))
+			options := make([]string, len(categories))
 			for i := range categories {
-				buttons[i] = categories[i].Category
+				options[i] = categories[i].Category
+			}
+			// сначала показываем категории, в которые пользователь скорее всего хотел записать трату.
+			// если подсказать не получилось, просто сортируем по алфавиту
+			if suggested, err := m.purchasesModel.SuggestCategories(ctx, Send.UserID, args.Category, options); err == nil {
+				options = suggested
+			} else {
+				sort.Strings(options)
 			}
-			sort.Strings(buttons)
-			buttons = append(buttons, ButtonTxtCreateCategory)
-
-			if err = m.setUserInfo(ctx, Send.UserID, userInfo{
-				Status:  statusNonExistentCategory,
-				Command: Send.Text,
-			}); err != nil {
-				return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+
+			if err = m.dialogs.Enter(ctx, Send.UserID, stateNonExistentCategory,
+				pendingPurchase{Command: Send.Text, Purchase: args, Options: options}); err != nil {
+				return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 			}
 
-			return m.tgClient.SendKeyboard("Такой категории у вас еще нет, выберите одну из предложенных категорий или создайте свою с помощью команды /category", Send.UserID, buttons)
+			keyboard := tg.Grid(2, optionButtons(categoryNames(ctx, options))...).Row(button(ctx, ButtonTxtCreateCategory, dataCreateCategory))
+			return m.tgClient.SendKeyboard(tr(ctx, TxtNonExistentCategory), Send.UserID, keyboard)
+		}
+
+		if errors.Is(err, purchases.ErrUnusualPurchase) {
+			return m.confirmUnusual(ctx, Send, args, expAndLim)
 		}
 
-		return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 	}
 
 	userCur, err := cy.CurrencyToStr(expAndLim.Currency)
 	if err != nil {
 		err = errors.Wrap(err, "purchasesModel.CurrencyToStr")
-		return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 	}
 
-	txt := ScsTxtPurchaseAdded
+	lang := i18n.FromContext(ctx)
+	txt := tr(ctx, ScsTxtPurchaseAdded)
+	if expAndLim.Unusual != nil {
+		txt += "\n" + trf(ctx, TxtUnusualPurchase, i18n.Number(lang, expAndLim.Unusual.Typical), userCur)
+	}
 	if expAndLim.Limit != -1 {
-		txt += fmt.Sprintf("\n\nУ вас установлен лимит: %.2f %s. За этот месяц вы потратили уже %.2f %s.",
-			expAndLim.Limit, userCur, expAndLim.Expenses, userCur)
+		txt += "\n\n" + trf(ctx, TxtLimitInfo,
+			i18n.Number(lang, expAndLim.Limit), userCur, i18n.Number(lang, expAndLim.Expenses), userCur)
 		if expAndLim.LimitExceeded {
-			txt += "\nВЫ ПРЕВЫСИЛИ ЛИМИТ!"
+			txt += "\n" + tr(ctx, TxtLimitExceeded)
+		}
+
+		if f := expAndLim.Forecast; f != nil {
+			txt += "\n" + trf(ctx, TxtForecastTotal, i18n.Number(lang, f.Total), userCur)
+			if date, ok := f.LimitDate(); ok && !f.LimitExceeded() {
+				txt += "\n" + trf(ctx, TxtForecastLimitDate, i18n.Number(lang, f.Limit), userCur, i18n.Date(lang, date))
+			}
 		}
 	}
 
 	return m.tgClient.SendMessage(txt, Send.UserID)
 }
 
-func (m *Model) msgCurrency(ctx context.Context, Send Message, rawCY string) error {
+// confirmUnusual спрашивает, точно ли записать необычно крупную трату. Трата ждет ответа в состоянии диалога
+func (m *Model) confirmUnusual(ctx context.Context, Send Message, args addPurchaseArgs, expAndLim purchases.ExpensesAndLimit) error {
+	userCur, err := cy.CurrencyToStr(expAndLim.Currency)
+	if err != nil {
+		err = errors.Wrap(err, "CurrencyToStr")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	if err = m.dialogs.Enter(ctx, Send.UserID, stateConfirmUnusual, pendingPurchase{
+		Command:  Send.Text,
+		Purchase: args,
+	}); err != nil {
+		err = errors.Wrap(err, "dialogs.Enter")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	typical := i18n.Number(i18n.FromContext(ctx), expAndLim.Unusual.Typical)
+	keyboard := tg.Grid(2, button(ctx, ButtonTxtConfirm, dataConfirm), button(ctx, ButtonTxtCancel, dataCancel))
+	return m.tgClient.SendKeyboard(trf(ctx, TxtConfirmUnusual, typical, userCur), Send.UserID, keyboard)
+}
+
+// msgFreeText трата, написанная в свободной форме. Если ее можно понять по-разному, пользователь выбирает нужный вариант
+func (m *Model) msgFreeText(ctx context.Context, Send Message, candidates []freetext.Purchase) error {
+	if len(candidates) == 1 {
+		return m.addFreeTextPurchase(ctx, Send, candidates[0])
+	}
+
+	options := make([]string, len(candidates))
+	for i, c := range candidates {
+		options[i] = c.String()
+	}
+	// варианты длинные, поэтому по одному в ряд
+	keyboard := tg.Grid(1, optionButtons(options)...).Row(button(ctx, ButtonTxtCancel, dataCancel))
+
+	// сохраняем исходный текст, после выбора варианта он будет разобран заново
+	if err := m.dialogs.Enter(ctx, Send.UserID, stateConfirmPurchase, frozenCommand{Command: Send.Text}); err != nil {
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.tgClient.SendKeyboard(tr(ctx, TxtConfirmPurchase), Send.UserID, keyboard)
+}
+
+// addFreeTextPurchase добавляет разобранную трату так же, как если бы пользователь ввел команду /add
+func (m *Model) addFreeTextPurchase(ctx context.Context, Send Message, p freetext.Purchase) error {
+	var date string
+	if p.HasDate {
+		date = p.Date.Format("02.01.2006")
+	}
+
+	// если категории не окажется, "замороженной" командой станет равнозначная команда /add
+	Send.Text = p.Command()
+	return m.msgAddPurchase(ctx, Send, addPurchaseArgs{
+		Sum:      p.RawSum,
+		Currency: p.CurrencySuffix(),
+		Category: p.Description,
+		Date:     date,
+		Tags:     p.Tags,
+		Note:     p.Note,
+	})
+}
+
+func (m *Model) msgTags(ctx context.Context, Send Message) error {
+	totals, userCY, err := m.purchasesModel.GetUserTags(ctx, Send.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "purchasesModel.GetUserTags")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+	if len(totals) == 0 {
+		return m.tgClient.SendMessage(tr(ctx, ScsTxtNoTags), Send.UserID)
+	}
+
+	cyStr, err := cy.CurrencyToStr(userCY)
+	if err != nil {
+		err = errors.Wrap(err, "CurrencyToStr")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	lang := i18n.FromContext(ctx)
+	txt := strings.Builder{}
+	txt.WriteString(trf(ctx, TxtTagsHeader, cyStr) + "\n")
+	for _, t := range totals {
+		txt.WriteString(fmt.Sprintf("\t#%s: %s (%d %s)\n",
+			t.Tag, i18n.Number(lang, t.Summa), t.Count, i18n.Plural(lang, t.Count, TxtPurchasesPlural)))
+	}
+
+	return m.tgClient.SendMessage(txt.String(), Send.UserID)
+}
+
+// msgForecast прогноз трат на конец месяца с графиком фактических и прогнозных трат
+func (m *Model) msgForecast(ctx context.Context, Send Message) error {
+	f, err := m.purchasesModel.Forecast(ctx, Send.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "purchasesModel.Forecast")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	cyStr, err := cy.CurrencyToStr(f.Currency)
+	if err != nil {
+		err = errors.Wrap(err, "CurrencyToStr")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	img, err := m.drawer.ForecastChart(f.Forecast, f.Limit, chart_drawing.ForecastLabels{
+		Actual:    tr(ctx, TxtChartActual),
+		Projected: tr(ctx, TxtChartProjected),
+		Limit:     tr(ctx, TxtChartLimit),
+	})
+	if err != nil {
+		err = errors.Wrap(err, "drawer.ForecastChart")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	if err = m.tgClient.SendMessage(forecastText(ctx, f, cyStr), Send.UserID); err != nil {
+		return errors.Wrap(err, "tgClient.SendMessage")
+	}
+	return m.tgClient.SendImage(img, Send.UserID)
+}
+
+// forecastText сколько потрачено, прогноз на конец месяца и когда будет превышен лимит, если он есть
+func forecastText(ctx context.Context, f purchases.MonthForecast, cyStr string) string {
+	lang := i18n.FromContext(ctx)
+
+	var spent float64
+	if len(f.Actual) != 0 {
+		spent = f.Actual[len(f.Actual)-1]
+	}
+	lines := []string{
+		trf(ctx, TxtForecastSpent, i18n.Number(lang, spent), cyStr),
+		trf(ctx, TxtForecastTotal, i18n.Number(lang, f.Total), cyStr),
+	}
+
+	if f.Limit != -1 {
+		limit := i18n.Number(lang, f.Limit)
+		date, ok := f.LimitDate()
+		switch {
+		case !ok:
+			lines = append(lines, trf(ctx, TxtForecastLimitSafe, limit, cyStr))
+		case f.LimitExceeded():
+			lines = append(lines, trf(ctx, TxtForecastLimitExceeded, limit, cyStr, i18n.Date(lang, date)))
+		default:
+			lines = append(lines, trf(ctx, TxtForecastLimitDate, limit, cyStr, i18n.Date(lang, date)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// msgCurrency смена основной валюты. Без аргумента бот предлагает выбрать валюту кнопками,
+// edit - сообщение с этими кнопками, если валюта выбрана кнопкой
+func (m *Model) msgCurrency(ctx context.Context, Send Message, rawCY string, edit tg.MessageRef) error {
+	if rawCY == "" {
+		if err := m.dialogs.Enter(ctx, Send.UserID, stateSelectCurrency, nil); err != nil {
+			err = errors.Wrap(err, "dialogs.Enter")
+			return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+		}
+		return m.tgClient.SendKeyboard(tr(ctx, TxtSelectCurrency), Send.UserID, currencyKeyboard())
+	}
+
 	cy, err := cy.StrToCurrency(rawCY)
 	if err != nil {
-		return m.tgClient.SendMessage(ErrTxtInvalidCurrency, Send.UserID)
+		return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidCurrency), Send.UserID)
 	}
 
 	if err = m.purchasesModel.ChangeUserCurrency(ctx, Send.UserID, cy); err != nil {
 		err = errors.Wrap(err, "purchasesModel.ChangeUserCurrency")
-		return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
 	}
-	return m.tgClient.SendMessage(ScsTxtCurrencyChanged, Send.UserID)
+	return m.reply(tr(ctx, ScsTxtCurrencyChanged), Send.UserID, edit, nil)
 }
 
 func (m *Model) msgLimit(ctx context.Context, Send Message, limit string) error {
 	if err := m.purchasesModel.ChangeUserLimit(ctx, Send.UserID, limit); err != nil {
 		if errors.Is(err, purchases.ErrLimitParsing) {
-			return m.tgClient.SendMessage(ErrTxtInvalidInput, Send.UserID)
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidInput), Send.UserID)
 		}
 		err = errors.Wrap(err, "purchasesModel.ChangeUserLimit")
-		return m.tgClient.SendMessage("Ошибочка: "+err.Error(), Send.UserID)
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+	return m.tgClient.SendMessage(tr(ctx, ScsTxtLimitChanged), Send.UserID)
+}
+
+// msgLang смена языка. Ответ уже на новом языке
+func (m *Model) msgLang(ctx context.Context, Send Message, rawLang string) error {
+	lang, err := i18n.Parse(rawLang)
+	if err != nil {
+		return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidLang), Send.UserID)
+	}
+
+	if err = m.purchasesModel.ChangeUserLang(ctx, Send.UserID, lang); err != nil {
+		err = errors.Wrap(err, "purchasesModel.ChangeUserLang")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.tgClient.SendMessage(i18n.T(lang, ScsTxtLangChanged), Send.UserID)
+}
+
+// msgTimezone меняет часовой пояс пользователя, а без аргумента показывает текущий
+func (m *Model) msgTimezone(ctx context.Context, Send Message, name string) error {
+	var (
+		loc *time.Location
+		err error
+	)
+	if name == "" {
+		loc, err = m.purchasesModel.GetUserLocation(ctx, Send.UserID)
+		if err != nil {
+			err = errors.Wrap(err, "purchasesModel.GetUserLocation")
+			return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+		}
+		return m.tgClient.SendMessage(trf(ctx, TxtTimezoneInfo, loc.String(), localTime(ctx, m.now().In(loc))), Send.UserID)
+	}
+
+	loc, err = m.purchasesModel.ChangeUserTimezone(ctx, Send.UserID, name)
+	if err != nil {
+		if errors.Is(err, purchases.ErrUnknownTimezone) {
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidTimezone), Send.UserID)
+		}
+		err = errors.Wrap(err, "purchasesModel.ChangeUserTimezone")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.tgClient.SendMessage(trf(ctx, ScsTxtTimezoneChanged, loc.String(), localTime(ctx, m.now().In(loc))), Send.UserID)
+}
+
+// msgRemind включает или выключает ежедневное напоминание записать траты
+func (m *Model) msgRemind(ctx context.Context, Send Message, rawTime string) error {
+	reminder, err := m.purchasesModel.ChangeUserReminder(ctx, Send.UserID, rawTime)
+	if err != nil {
+		if errors.Is(err, purchases.ErrReminderParsing) {
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidReminder), Send.UserID)
+		}
+		err = errors.Wrap(err, "purchasesModel.ChangeUserReminder")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	if reminder.At < 0 {
+		return m.tgClient.SendMessage(tr(ctx, ScsTxtReminderOff), Send.UserID)
+	}
+	return m.tgClient.SendMessage(trf(ctx, ScsTxtReminderOn, reminder.Clock()), Send.UserID)
+}
+
+// msgConfirm включает или выключает вопрос "это точно?" перед записью необычно крупной траты
+func (m *Model) msgConfirm(ctx context.Context, Send Message, rawFlag string) error {
+	confirm := strings.EqualFold(rawFlag, "on")
+	if err := m.purchasesModel.ChangeUserConfirmUnusual(ctx, Send.UserID, confirm); err != nil {
+		err = errors.Wrap(err, "purchasesModel.ChangeUserConfirmUnusual")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	if confirm {
+		return m.tgClient.SendMessage(tr(ctx, ScsTxtConfirmOn), Send.UserID)
 	}
-	return m.tgClient.SendMessage(ScsTxtLimitChanged, Send.UserID)
+	return m.tgClient.SendMessage(tr(ctx, ScsTxtConfirmOff), Send.UserID)
+}
+
+// subscriptionPeriods периоды подписки /subscribe: период отчета и ответ на подписку
+var subscriptionPeriods = map[string]struct{ period, reply string }{
+	"weekly":  {period: "week", reply: ScsTxtSubscribedWeekly},
+	"monthly": {period: "month", reply: ScsTxtSubscribedMonthly},
+}
+
+// msgSubscribe подписка на отчет за каждую прошедшую неделю (weekly) или месяц (monthly)
+func (m *Model) msgSubscribe(ctx context.Context, Send Message, rawPeriod string) error {
+	sub, ok := subscriptionPeriods[rawPeriod]
+	if !ok {
+		return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidInput), Send.UserID)
+	}
+
+	period, err := m.purchasesModel.ToPeriod(sub.period)
+	if err != nil {
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	if err = m.purchasesModel.ChangeUserDigest(ctx, Send.UserID, period); err != nil {
+		err = errors.Wrap(err, "purchasesModel.ChangeUserDigest")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.tgClient.SendMessage(tr(ctx, sub.reply), Send.UserID)
+}
+
+// msgUnsubscribe отмена подписки на отчеты
+func (m *Model) msgUnsubscribe(ctx context.Context, Send Message) error {
+	if err := m.purchasesModel.RemoveUserDigest(ctx, Send.UserID); err != nil {
+		err = errors.Wrap(err, "purchasesModel.RemoveUserDigest")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.tgClient.SendMessage(tr(ctx, ScsTxtUnsubscribed), Send.UserID)
+}
+
+// localTime дата и время t в формате языка пользователя, чтобы он мог сверить их со своими часами
+func localTime(ctx context.Context, t time.Time) string {
+	return i18n.Date(i18n.FromContext(ctx), t) + " " + t.Format("15:04")
+}
+
+// msgFind отправляет страницу результатов поиска. Если страниц несколько, запрос запоминается в статусе,
+// а листать их можно кнопками. edit - сообщение с прошлой страницей, при листании оно заменяется новой
+func (m *Model) msgFind(ctx context.Context, Send Message, query string, page int, edit tg.MessageRef) error {
+	res, err := m.purchasesModel.FindPurchases(ctx, Send.UserID, query, page)
+	if err != nil {
+		if errors.Is(err, purchases.ErrSearchQueryParsing) {
+			return m.tgClient.SendMessage(tr(ctx, ErrTxtInvalidSearchQuery), Send.UserID)
+		}
+		err = errors.Wrap(err, "purchasesModel.FindPurchases")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+	if len(res.Items) == 0 && res.Page == 0 {
+		return m.tgClient.SendMessage(tr(ctx, ScsTxtNothingFound), Send.UserID)
+	}
+
+	userCY, err := cy.CurrencyToStr(res.Currency)
+	if err != nil {
+		err = errors.Wrap(err, "CurrencyToStr")
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	lang := i18n.FromContext(ctx)
+	txt := strings.Builder{}
+	txt.WriteString(trf(ctx, TxtFindHeader, res.Page+1) + "\n")
+	for _, p := range res.Items {
+		txt.WriteString(fmt.Sprintf("\t%s %s: %s %s", i18n.Date(lang, p.Date), purchases.CategoryName(lang, p.Category), i18n.Number(lang, p.Summa), userCY))
+		if p.Description != "" && !strings.EqualFold(p.Description, p.Category) {
+			txt.WriteString(" (" + p.Description + ")")
+		}
+		if p.Note != "" {
+			txt.WriteString(" \"" + p.Note + "\"")
+		}
+		if len(p.Tags) != 0 {
+			txt.WriteString(" " + freetext.FormatTags(p.Tags))
+		}
+		txt.WriteString("\n")
+	}
+
+	var buttons []tg.Button
+	if res.Page > 0 {
+		buttons = append(buttons, button(ctx, ButtonTxtPrevPage, dataPrevPage))
+	}
+	if res.HasNext {
+		buttons = append(buttons, button(ctx, ButtonTxtNextPage, dataNextPage))
+	}
+	if len(buttons) == 0 {
+		return m.reply(txt.String(), Send.UserID, edit, nil)
+	}
+
+	if err = m.dialogs.Enter(ctx, Send.UserID, stateFindPage, searchPage{Query: query, Page: res.Page}); err != nil {
+		return m.tgClient.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.reply(txt.String(), Send.UserID, edit, tg.Keyboard{buttons})
 }
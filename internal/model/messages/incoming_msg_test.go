@@ -4,20 +4,30 @@ package messages
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/messages/_mocks"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 )
 
-func Test_OnStartCommand_ShouldAnswerWithIntroMessage(t *testing.T) {
+func Test_OnStartCommand_ShouldStartOnboarding(t *testing.T) {
 	ctx := context.Background()
 
-	sender, purchasesModel, _ := mocksUp(t)
-	model := New(sender, purchasesModel, nil)
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
 
-	sender.EXPECT().SendMessage("hello", int64(123))
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).Return(nil)
+	sender.EXPECT().SendKeyboard(TxtOnboardingHello, int64(123), tg.Keyboard{{
+		tg.NewButton("RUB", "RUB"), tg.NewButton("USD", "USD"), tg.NewButton("EUR", "EUR"), tg.NewButton("CNY", "CNY"),
+	}})
 
 	err := model.IncomingMessage(ctx, tg.Message{
 		Text:     "/start",
@@ -32,7 +42,7 @@ func Test_OnUnknownCommand_ShouldAnswerWithHelpMessage(t *testing.T) {
 	ctx := context.Background()
 
 	sender, purchasesModel, _ := mocksUp(t)
-	model := New(sender, purchasesModel, nil)
+	model := New(sender, purchasesModel, nil, nil, nil)
 
 	sender.EXPECT().SendMessage("Не знаю эту команду", int64(123))
 
@@ -49,7 +59,7 @@ func Test_OnAddCategoryCommand(t *testing.T) {
 	ctx := context.Background()
 
 	sender, purchasesModel, _ := mocksUp(t)
-	model := New(sender, purchasesModel, nil)
+	model := New(sender, purchasesModel, nil, nil, nil)
 
 	sender.EXPECT().SendMessage("Категория создана", int64(123))
 	purchasesModel.EXPECT().AddCategory(gomock.Any(), gomock.Any()).Return(nil)
@@ -62,3 +72,538 @@ func Test_OnAddCategoryCommand(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func Test_OnAddPurchaseWithUnknownCategory_ShouldSuggestCategories(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "450", "пятёрочка", "").
+		Return(purchases.ExpensesAndLimit{}, purchases.ErrCategoryNotExist)
+	purchasesModel.EXPECT().GetAllCategories(gomock.Any()).Return([]purchases.CategoryRow{
+		{ID: 2, Category: "Кафе"},
+		{ID: 3, Category: "Продукты"},
+	}, nil)
+	purchasesModel.EXPECT().SuggestCategories(gomock.Any(), int64(123), "пятёрочка", []string{"Кафе", "Продукты"}).
+		Return([]string{"Продукты", "Кафе"}, nil)
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).Return(nil)
+	sender.EXPECT().SendKeyboard(gomock.Any(), int64(123), tg.Keyboard{
+		{tg.NewButton("Продукты", "0"), tg.NewButton("Кафе", "1")},
+		{tg.NewButton(ButtonTxtCreateCategory, dataCreateCategory)},
+	})
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/add 450 пятёрочка",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnFreeTextPurchase_ShouldAddPurchase(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "кофе", "").
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "кофе 250",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnFreeTextPurchaseYesterday_ShouldUseUserTimezone(t *testing.T) {
+	ctx := context.Background()
+
+	vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+	assert.NoError(t, err)
+
+	sender, purchasesModel, _ := mocksUpWithLocation(t, "", vladivostok)
+	model := New(sender, purchasesModel, nil, nil, nil)
+	// 30.11 14:30 по UTC - это уже 1 декабря во Владивостоке, значит "вчера" - 30 ноября
+	model.now = func() time.Time { return time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC) }
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "кофе", "30.11.2022").
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err = model.IncomingMessage(ctx, tg.Message{
+		Text:     "вчера кофе 250",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnTimezoneCommand(t *testing.T) {
+	ctx := context.Background()
+
+	vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+	assert.NoError(t, err)
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+	model.now = func() time.Time { return time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC) }
+
+	t.Run("смена часового пояса", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserTimezone(gomock.Any(), int64(123), "Asia/Vladivostok").Return(vladivostok, nil)
+		sender.EXPECT().SendMessage("Часовой пояс изменен на Asia/Vladivostok, у вас сейчас 01.12.2022 00:30", int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/tz Asia/Vladivostok", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("неизвестный часовой пояс", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserTimezone(gomock.Any(), int64(123), "Moscow").
+			Return(nil, purchases.ErrUnknownTimezone)
+		sender.EXPECT().SendMessage(ErrTxtInvalidTimezone, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/tz Moscow", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("без аргумента показывает текущий пояс", func(t *testing.T) {
+		sender.EXPECT().SendMessage("Ваш часовой пояс: UTC, у вас сейчас 30.11.2022 14:30. Сменить его можно командой \"/tz Asia/Vladivostok\"", int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/tz", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnRemindCommand(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	t.Run("включение напоминания", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserReminder(gomock.Any(), int64(123), "9:30").
+			Return(purchases.Reminder{UserID: 123, At: 9*60 + 30}, nil)
+		sender.EXPECT().SendMessage("Буду напоминать в 09:30 по вашему часовому поясу, если за день не будет ни одной траты. Сменить часовой пояс - /tz", int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/remind 9:30", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("выключение напоминания", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserReminder(gomock.Any(), int64(123), "off").
+			Return(purchases.Reminder{UserID: 123, At: -1}, nil)
+		sender.EXPECT().SendMessage(ScsTxtReminderOff, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/remind off", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("неверное время", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserReminder(gomock.Any(), int64(123), "25:00").
+			Return(purchases.Reminder{}, purchases.ErrReminderParsing)
+		sender.EXPECT().SendMessage(ErrTxtInvalidReminder, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/remind 25:00", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnSubscribeCommand(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	t.Run("подписка на месяц", func(t *testing.T) {
+		purchasesModel.EXPECT().ToPeriod("month").Return(purchases.Period(2), nil)
+		purchasesModel.EXPECT().ChangeUserDigest(gomock.Any(), int64(123), purchases.Period(2)).Return(nil)
+		sender.EXPECT().SendMessage(ScsTxtSubscribedMonthly, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/subscribe monthly", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("неизвестный период", func(t *testing.T) {
+		sender.EXPECT().SendMessage(fmt.Sprintf(ErrTxtCommandUsage, "/subscribe <weekly|monthly>"), int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/subscribe yearly", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("отписка", func(t *testing.T) {
+		purchasesModel.EXPECT().RemoveUserDigest(gomock.Any(), int64(123)).Return(nil)
+		sender.EXPECT().SendMessage(ScsTxtUnsubscribed, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/unsubscribe", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnForecastCommand(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	drawer := mocks.NewMockChartDrawer(gomock.NewController(t))
+	drawer.EXPECT().ForecastChart(gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte("png"), nil).AnyTimes()
+	model := New(sender, purchasesModel, drawer, nil, nil)
+	november := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("лимит будет превышен", func(t *testing.T) {
+		purchasesModel.EXPECT().Forecast(gomock.Any(), int64(123)).Return(purchases.MonthForecast{
+			Forecast: forecast.Project(forecast.Input{Daily: []float64{1000, 1000}, DaysInMonth: 30, Limit: 20000}),
+			Month:    november,
+			Limit:    20000,
+			Currency: cy.RUB,
+		}, nil)
+		sender.EXPECT().SendMessage("Потрачено с начала месяца: 2 000,00 RUB\nПрогноз на конец месяца: 30 000,00 RUB\n"+
+			"При таком темпе лимит 20 000,00 RUB будет превышен примерно 21.11.2022", int64(123))
+		sender.EXPECT().SendImage(gomock.Any(), int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/forecast", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("лимит уже превышен", func(t *testing.T) {
+		purchasesModel.EXPECT().Forecast(gomock.Any(), int64(123)).Return(purchases.MonthForecast{
+			Forecast: forecast.Project(forecast.Input{Daily: []float64{1000, 1000}, DaysInMonth: 30, Limit: 1500}),
+			Month:    november,
+			Limit:    1500,
+			Currency: cy.RUB,
+		}, nil)
+		sender.EXPECT().SendMessage("Потрачено с начала месяца: 2 000,00 RUB\nПрогноз на конец месяца: 30 000,00 RUB\n"+
+			"Лимит 1 500,00 RUB превышен 02.11.2022", int64(123))
+		sender.EXPECT().SendImage(gomock.Any(), int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/forecast", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("без лимита", func(t *testing.T) {
+		purchasesModel.EXPECT().Forecast(gomock.Any(), int64(123)).Return(purchases.MonthForecast{
+			Forecast: forecast.Project(forecast.Input{Daily: []float64{300}, DaysInMonth: 30, Limit: forecast.NoLimit}),
+			Month:    november,
+			Limit:    -1,
+			Currency: cy.USD,
+		}, nil)
+		sender.EXPECT().SendMessage("Потрачено с начала месяца: 300,00 USD\nПрогноз на конец месяца: 9 000,00 USD", int64(123))
+		sender.EXPECT().SendImage(gomock.Any(), int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/forecast", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnFreeTextPurchaseWithCurrency_ShouldAddPurchaseInThisCurrency(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "10", "подписка", "", gomock.Any()).
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "подписка 10 баксов",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnAmbiguousFreeTextPurchase_ShouldAskForConfirmation(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	// запоминаем статус, чтобы отдать его при нажатии на кнопку
+	var status string
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, value string) error {
+			status = value
+			return nil
+		})
+	sender.EXPECT().SendKeyboard(TxtConfirmPurchase, int64(123), tg.Keyboard{
+		{tg.NewButton("2 · кофе 250", "0")},
+		{tg.NewButton("250 · 2 кофе", "1")},
+		{tg.NewButton(ButtonTxtCancel, dataCancel)},
+	})
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "2 кофе 250",
+		UserID:   123,
+		UserName: "name",
+	})
+	assert.NoError(t, err)
+
+	t.Run("пользователь выбрал вариант", func(t *testing.T) {
+		statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(status, nil)
+		statusStore.EXPECT().Delete(gomock.Any(), "123status").Return(nil)
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "2 кофе", "").
+			Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+		sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{
+			UserID:   123,
+			UserName: "name",
+			Data:     "1",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("пользователь отменил добавление", func(t *testing.T) {
+		statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(status, nil)
+		statusStore.EXPECT().Delete(gomock.Any(), "123status").Return(nil)
+		sender.EXPECT().SendMessage(ScsTxtPurchaseCanceled, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{
+			UserID:   123,
+			UserName: "name",
+			Data:     dataCancel,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnAddPurchaseWithCurrencyCode(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "10", "кофе", "01.03.2024", gomock.Any()).
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/add 10usd кофе 01.03.2024",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnAddPurchaseWithTagsAndNote(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	// теги и заметка передаются опциями, категория приходит уже без них
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "1200", "еда", "", gomock.Any(), gomock.Any()).
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     `/add 1200 еда #командировка #москва "обед с клиентом"`,
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnReportWithTag(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().ToPeriod("month").Return(purchases.Period(2), nil)
+	purchasesModel.EXPECT().CreateReportRequest(gomock.Any(), purchases.Period(2), int64(123), i18n.RU, purchases.ReportOptions{Tags: []string{"командировка"}}).Return(nil)
+	sender.EXPECT().SendTrackedMessage(ScsTxtReportRequestCreated, int64(123), reportMsgKey)
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/report month #командировка",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnReportPDF(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().ToPeriod("month").Return(purchases.Period(2), nil)
+	purchasesModel.EXPECT().CreateReportRequest(gomock.Any(), purchases.Period(2), int64(123), i18n.RU, purchases.ReportOptions{Tags: []string{"командировка"}, Format: purchases.ReportFormatPDF}).Return(nil)
+	sender.EXPECT().SendTrackedMessage(ScsTxtReportRequestCreated, int64(123), reportMsgKey)
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/report month pdf #командировка",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnReportAllCurrenciesAtTodayRates(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().ToPeriod("month").Return(purchases.Period(2), nil)
+	purchasesModel.EXPECT().CreateReportRequest(gomock.Any(), purchases.Period(2), int64(123), i18n.RU,
+		purchases.ReportOptions{AllCurrencies: true, TodayRates: true}).Return(nil)
+	sender.EXPECT().SendTrackedMessage(ScsTxtReportRequestCreated, int64(123), reportMsgKey)
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/report month all today",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnTagsCommand(t *testing.T) {
+	t.Run("теги есть", func(t *testing.T) {
+		ctx := context.Background()
+
+		sender, purchasesModel, _ := mocksUp(t)
+		model := New(sender, purchasesModel, nil, nil, nil)
+
+		purchasesModel.EXPECT().GetUserTags(gomock.Any(), int64(123)).Return([]purchases.TagTotal{
+			{Tag: "командировка", Summa: 4200, Count: 3},
+			{Tag: "москва", Summa: 1200.5, Count: 1},
+		}, cy.RUB, nil)
+		sender.EXPECT().SendMessage("Ваши теги (RUB):\n\t#командировка: 4 200,00 (3 траты)\n\t#москва: 1 200,50 (1 трата)\n", int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{
+			Text:     "/tags",
+			UserID:   123,
+			UserName: "name",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("тегов нет", func(t *testing.T) {
+		ctx := context.Background()
+
+		sender, purchasesModel, _ := mocksUp(t)
+		model := New(sender, purchasesModel, nil, nil, nil)
+
+		purchasesModel.EXPECT().GetUserTags(gomock.Any(), int64(123)).Return(nil, cy.RUB, nil)
+		sender.EXPECT().SendMessage(ScsTxtNoTags, int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{
+			Text:     "/tags",
+			UserID:   123,
+			UserName: "name",
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnFindCommand_ShouldPaginate(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	date, _ := time.Parse("02.01.2006", "01.03.2024")
+	item := purchases.FoundPurchase{Date: date, Category: "Транспорт", Description: "такси", Tags: []string{"командировка"}, Summa: 600}
+
+	var status string
+	purchasesModel.EXPECT().FindPurchases(gomock.Any(), int64(123), "такси", 0).
+		Return(purchases.SearchResult{Items: []purchases.FoundPurchase{item}, Currency: cy.RUB, HasNext: true}, nil)
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, value string) error {
+			status = value
+			return nil
+		})
+	sender.EXPECT().SendKeyboard("Найденные траты, страница 1:\n\t01.03.2024 Транспорт: 600,00 RUB (такси) #командировка\n",
+		int64(123), tg.Keyboard{{tg.NewButton(ButtonTxtNextPage, dataNextPage)}})
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/find такси",
+		UserID:   123,
+		UserName: "name",
+	})
+	assert.NoError(t, err)
+
+	// на второй странице трат больше нет, поэтому остается только кнопка назад
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(status, nil)
+	statusStore.EXPECT().Delete(gomock.Any(), "123status").Return(nil)
+	purchasesModel.EXPECT().FindPurchases(gomock.Any(), int64(123), "такси", 1).
+		Return(purchases.SearchResult{Items: []purchases.FoundPurchase{item}, Currency: cy.RUB, Page: 1}, nil)
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).Return(nil)
+	// вторая страница заменяет первую в том же сообщении
+	sender.EXPECT().EditKeyboard("Найденные траты, страница 2:\n\t01.03.2024 Транспорт: 600,00 RUB (такси) #командировка\n",
+		int64(123), tg.MessageRef{ID: 77}, tg.Keyboard{{tg.NewButton(ButtonTxtPrevPage, dataPrevPage)}})
+
+	err = model.IncomingCallback(ctx, tg.Callback{
+		UserID:    123,
+		UserName:  "name",
+		Data:      dataNextPage,
+		MessageID: 77,
+	})
+	assert.NoError(t, err)
+}
+
+func Test_SendReport_ShouldReplaceProgressMessage(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	gomock.InOrder(
+		sender.EXPECT().EditMessage("Отчет готов\nОтчет за месяц", int64(123), tg.MessageRef{Key: reportMsgKey}),
+		sender.EXPECT().SendImage([]byte("png"), int64(123)),
+	)
+
+	err := model.SendReport(ctx, 123, "Отчет за месяц", []byte("png"))
+	assert.NoError(t, err)
+}
+
+func Test_SendReportDocument_ShouldReplaceProgressMessage(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	gomock.InOrder(
+		sender.EXPECT().EditMessage("Отчет готов\nОтчет за месяц", int64(123), tg.MessageRef{Key: reportMsgKey}),
+		sender.EXPECT().SendDocument([]byte("%PDF"), "report_2022-10-01.pdf", int64(123)),
+	)
+
+	err := model.SendReportDocument(ctx, 123, "Отчет за месяц", []byte("%PDF"), "report_2022-10-01.pdf")
+	assert.NoError(t, err)
+}
+
+func Test_OnFindCommand_NothingFound(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().FindPurchases(gomock.Any(), int64(123), ">5000", 0).
+		Return(purchases.SearchResult{Currency: cy.RUB}, nil)
+	sender.EXPECT().SendMessage(ScsTxtNothingFound, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:     "/find >5000",
+		UserID:   123,
+		UserName: "name",
+	})
+
+	assert.NoError(t, err)
+}
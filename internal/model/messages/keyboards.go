@@ -0,0 +1,88 @@
+package messages
+
+import (
+	"context"
+	"strconv"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+)
+
+// Данные колбэков служебных кнопок. Они не зависят от языка интерфейса и укладываются в 64 байта, которые телеграм
+// разрешает для данных колбэка. Варианты выбора (категории, трактовки траты) передаются номером варианта,
+// а сами варианты хранятся в данных состояния диалога
+const (
+	dataCancel         = "cancel"
+	dataCreateCategory = "new_category"
+	dataNoCategory     = "no_category"
+	dataPrevPage       = "prev"
+	dataNextPage       = "next"
+	dataSkip           = "skip"
+	dataDone           = "done"
+	dataConfirm        = "confirm"
+)
+
+var (
+	// currencies валюты, из которых выбирается основная
+	currencies = []string{"RUB", "USD", "EUR", "CNY"}
+	// periods периоды отчетов в порядке кнопок
+	periods = []struct {
+		data  string
+		label string
+	}{
+		{data: "week", label: ButtonTxtWeek},
+		{data: "month", label: ButtonTxtMonth},
+		{data: "year", label: ButtonTxtYear},
+	}
+)
+
+// topCategoriesCount сколько категорий предлагается кнопками при добавлении траты без категории
+const topCategoriesCount = 6
+
+// button кнопка с текстом на языке пользователя
+func button(ctx context.Context, text, data string) tg.Button {
+	return tg.NewButton(tr(ctx, text), data)
+}
+
+// optionButtons кнопки вариантов: на кнопке сам вариант, в данных - его номер
+func optionButtons(options []string) []tg.Button {
+	buttons := make([]tg.Button, len(options))
+	for i, o := range options {
+		buttons[i] = tg.NewButton(o, strconv.Itoa(i))
+	}
+	return buttons
+}
+
+// option номер варианта, выбранного кнопкой из optionButtons
+func option(data string, count int) (int, bool) {
+	i, err := strconv.Atoi(data)
+	if err != nil || i < 0 || i >= count {
+		return 0, false
+	}
+	return i, true
+}
+
+func currencyKeyboard() tg.Keyboard {
+	buttons := make([]tg.Button, len(currencies))
+	for i, c := range currencies {
+		buttons[i] = tg.NewButton(c, c)
+	}
+	return tg.Grid(len(buttons), buttons...)
+}
+
+func periodKeyboard(ctx context.Context) tg.Keyboard {
+	buttons := make([]tg.Button, len(periods))
+	for i, p := range periods {
+		buttons[i] = button(ctx, p.label, p.data)
+	}
+	return tg.Grid(len(buttons), buttons...)
+}
+
+// isPeriod данные кнопки из periodKeyboard
+func isPeriod(data string) bool {
+	for _, p := range periods {
+		if p.data == data {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/messages/_mocks"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// rememberStatus хранилище состояний, которое отдает последнее записанное состояние, как редис
+func rememberStatus(statusStore *mocks.MockStatusStore) {
+	var status string
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string, value string) error {
+			status = value
+			return nil
+		})
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string) (string, error) {
+			return status, nil
+		})
+	statusStore.EXPECT().Delete(gomock.Any(), "123status").AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string) error {
+			status = ""
+			return nil
+		})
+}
+
+func Test_OnCurrencyWithoutArgs_ShouldOfferCurrencies(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+	rememberStatus(statusStore)
+
+	sender.EXPECT().SendKeyboard(TxtSelectCurrency, int64(123), tg.Keyboard{{
+		tg.NewButton("RUB", "RUB"), tg.NewButton("USD", "USD"), tg.NewButton("EUR", "EUR"), tg.NewButton("CNY", "CNY"),
+	}})
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/currency", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+
+	purchasesModel.EXPECT().ChangeUserCurrency(gomock.Any(), int64(123), cy.EUR).Return(nil)
+	// сообщение с кнопками заменяется результатом
+	sender.EXPECT().EditMessage(ScsTxtCurrencyChanged, int64(123), tg.MessageRef{ID: 77})
+	err = model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "EUR", MessageID: 77})
+	assert.NoError(t, err)
+}
+
+func Test_OnReportWithoutPeriod_ShouldOfferPeriods(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+	rememberStatus(statusStore)
+
+	sender.EXPECT().SendKeyboard(TxtSelectPeriod, int64(123), tg.Keyboard{{
+		tg.NewButton(ButtonTxtWeek, "week"), tg.NewButton(ButtonTxtMonth, "month"), tg.NewButton(ButtonTxtYear, "year"),
+	}})
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/report #командировка", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+
+	t.Run("неизвестный период", func(t *testing.T) {
+		sender.EXPECT().SendMessage(ErrTxtInvalidStatus, int64(123))
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "decade"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("выбран период, теги из команды сохраняются", func(t *testing.T) {
+		purchasesModel.EXPECT().ToPeriod("month").Return(purchases.Period(2), nil)
+		purchasesModel.EXPECT().CreateReportRequest(gomock.Any(), purchases.Period(2), int64(123), gomock.Any(), purchases.ReportOptions{Tags: []string{"командировка"}}).Return(nil)
+		// сообщение с кнопками заменяется на "Отчет готовится...", а его id запоминается для готового отчета
+		sender.EXPECT().EditMessage(ScsTxtReportRequestCreated, int64(123), tg.MessageRef{ID: 77, Key: reportMsgKey})
+
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "month", MessageID: 77})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnAddWithoutCategory_ShouldOfferTopCategories(t *testing.T) {
+	ctx := context.Background()
+
+	// название категории длиннее 64 байт не помещается в данные колбэка, поэтому в данных номер категории
+	longCategory := strings.Repeat("Очень длинная категория ", 3)
+	userCategories := []string{"Кафе", "Продукты", "Транспорт", "Жилье", "Здоровье", "Одежда", "Подарки", longCategory}
+	top := []string{longCategory, "Кафе", "Продукты", "Транспорт", "Жилье", "Здоровье", "Одежда", "Подарки"}
+
+	offer := func(t *testing.T, model *Model, sender *mocks.MockMessageSender, purchasesModel *mocks.MockPurchasesModel) {
+		purchasesModel.EXPECT().GetUserCategories(gomock.Any(), int64(123)).Return(userCategories, nil)
+		purchasesModel.EXPECT().SuggestCategories(gomock.Any(), int64(123), "", userCategories).Return(top, nil)
+		sender.EXPECT().SendKeyboard(TxtSelectCategory, int64(123), tg.Keyboard{
+			{tg.NewButton(longCategory, "0"), tg.NewButton("Кафе", "1")},
+			{tg.NewButton("Продукты", "2"), tg.NewButton("Транспорт", "3")},
+			{tg.NewButton("Жилье", "4"), tg.NewButton("Здоровье", "5")},
+			{tg.NewButton(ButtonTxtNoCategory, dataNoCategory)},
+		})
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/add 500 #командировка", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	}
+
+	t.Run("выбрана категория", func(t *testing.T) {
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+		rememberStatus(statusStore)
+		offer(t, model, sender, purchasesModel)
+
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "500", longCategory, "", gomock.Any()).
+			Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+		sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "0"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("без категории", func(t *testing.T) {
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+		rememberStatus(statusStore)
+		offer(t, model, sender, purchasesModel)
+
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "500", "", "", gomock.Any()).
+			Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+		sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: dataNoCategory})
+		assert.NoError(t, err)
+	})
+
+	t.Run("номер категории за пределами предложенных", func(t *testing.T) {
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+		rememberStatus(statusStore)
+		offer(t, model, sender, purchasesModel)
+
+		sender.EXPECT().SendMessage(ErrTxtInvalidStatus, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "6"})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_OnUnknownCategorySelected_ShouldAddPurchase(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+	rememberStatus(statusStore)
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "кофе старбакс", "").
+		Return(purchases.ExpensesAndLimit{}, purchases.ErrCategoryNotExist)
+	purchasesModel.EXPECT().GetAllCategories(gomock.Any()).Return([]purchases.CategoryRow{
+		{ID: 2, Category: "Кафе"},
+		{ID: 3, Category: "Продукты"},
+	}, nil)
+	purchasesModel.EXPECT().SuggestCategories(gomock.Any(), int64(123), "кофе старбакс", []string{"Кафе", "Продукты"}).
+		Return([]string{"Кафе", "Продукты"}, nil)
+	sender.EXPECT().SendKeyboard(TxtNonExistentCategory, int64(123), gomock.Any())
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/add 250 кофе старбакс", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+
+	// трата записывается в выбранную категорию, а текст уходит в описание: клавиатура больше не появляется
+	purchasesModel.EXPECT().GetUserCategories(gomock.Any(), int64(123)).Return([]string{"Кафе"}, nil)
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "Кафе", "", gomock.Any()).
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err = model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "0"})
+	assert.NoError(t, err)
+}
+
+func Test_OnAddWithoutCategory_NoUserCategories(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().GetUserCategories(gomock.Any(), int64(123)).Return(nil, nil)
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "500", "", "").
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/add 500", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+}
+
+func Test_OnUnusualPurchase(t *testing.T) {
+	ctx := context.Background()
+	unusual := purchases.ExpensesAndLimit{Currency: cy.RUB, Unusual: &purchases.UnusualPurchase{Typical: 350}}
+
+	ask := func(t *testing.T, model *Model, sender *mocks.MockMessageSender, purchasesModel *mocks.MockPurchasesModel) {
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "5000", "Кафе", "").
+			Return(unusual, purchases.ErrUnusualPurchase)
+		sender.EXPECT().SendKeyboard("Это точно? Обычно в этой категории вы тратите около 350,00 RUB", int64(123), tg.Keyboard{
+			{tg.NewButton(ButtonTxtConfirm, dataConfirm), tg.NewButton(ButtonTxtCancel, dataCancel)},
+		})
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/add 5000 Кафе", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	}
+
+	t.Run("подтверждение", func(t *testing.T) {
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+		rememberStatus(statusStore)
+		ask(t, model, sender, purchasesModel)
+
+		// подтвержденная трата добавляется с опцией purchases.Confirmed
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "5000", "Кафе", "", gomock.Any()).
+			Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+		sender.EXPECT().SendMessage(ScsTxtPurchaseAdded, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: dataConfirm})
+		assert.NoError(t, err)
+	})
+
+	t.Run("отмена", func(t *testing.T) {
+		sender, purchasesModel, statusStore := mocksUp(t)
+		model := New(sender, purchasesModel, nil, statusStore, nil)
+		rememberStatus(statusStore)
+		ask(t, model, sender, purchasesModel)
+
+		sender.EXPECT().SendMessage(ScsTxtPurchaseCanceled, int64(123))
+
+		err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: dataCancel})
+		assert.NoError(t, err)
+	})
+
+	t.Run("без подтверждения трата добавляется с предупреждением", func(t *testing.T) {
+		sender, purchasesModel, _ := mocksUp(t)
+		model := New(sender, purchasesModel, nil, nil, nil)
+
+		purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "5000", "Кафе", "").
+			Return(purchases.ExpensesAndLimit{Limit: -1, Currency: cy.RUB, Unusual: &purchases.UnusualPurchase{Typical: 350}}, nil)
+		sender.EXPECT().SendMessage("Трата добавлена\n⚠️ Необычно крупная трата: обычно в этой категории вы тратите около 350,00 RUB", int64(123))
+
+		err := model.IncomingMessage(ctx, tg.Message{Text: "/add 5000 Кафе", UserID: 123, UserName: "name"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("настройка", func(t *testing.T) {
+		sender, purchasesModel, _ := mocksUp(t)
+		model := New(sender, purchasesModel, nil, nil, nil)
+
+		purchasesModel.EXPECT().ChangeUserConfirmUnusual(gomock.Any(), int64(123), true).Return(nil)
+		sender.EXPECT().SendMessage(ScsTxtConfirmOn, int64(123))
+		assert.NoError(t, model.IncomingMessage(ctx, tg.Message{Text: "/confirm on", UserID: 123, UserName: "name"}))
+
+		purchasesModel.EXPECT().ChangeUserConfirmUnusual(gomock.Any(), int64(123), false).Return(nil)
+		sender.EXPECT().SendMessage(ScsTxtConfirmOff, int64(123))
+		assert.NoError(t, model.IncomingMessage(ctx, tg.Message{Text: "/confirm OFF", UserID: 123, UserName: "name"}))
+	})
+}
@@ -2,49 +2,97 @@ package messages
 
 import (
 	"context"
+	"time"
 
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/chart_drawing"
 	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/fsm"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
 )
 
 type MessageSender interface {
 	SendMessage(text string, userID int64) error
 	SendImage(img []byte, chatID int64) error
-	SendKeyboard(text string, userID int64, buttonTexts []string) error
+	SendDocument(doc []byte, name string, userID int64) error
+	SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error
+	AnswerInlineQuery(queryID string, results []tg.InlineResult) error
+	SendTrackedMessage(text string, userID int64, key string) error
+	EditMessage(text string, userID int64, msg tg.MessageRef) error
+	EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error
 }
 
 type PurchasesModel interface {
-	AddPurchase(ctx context.Context, userID int64, rawSum, category, rawDate string) (purchases.ExpensesAndLimit, error)
+	AddPurchase(ctx context.Context, userID int64, rawSum, category, rawDate string, opts ...purchases.AddPurchaseOption) (purchases.ExpensesAndLimit, error)
 
 	AddCategory(ctx context.Context, category string) error
 	GetAllCategories(ctx context.Context) ([]purchases.CategoryRow, error)
 
-	CreateReportRequest(ctx context.Context, period purchases.Period, userID int64) (err error)
+	CreateReportRequest(ctx context.Context, period purchases.Period, userID int64, lang i18n.Lang, opts purchases.ReportOptions) (err error)
+	ChangeUserDigest(ctx context.Context, userID int64, period purchases.Period) error
+	RemoveUserDigest(ctx context.Context, userID int64) error
+	GetUserTags(ctx context.Context, userID int64) ([]purchases.TagTotal, cy.Currency, error)
+	FindPurchases(ctx context.Context, userID int64, query string, page int) (purchases.SearchResult, error)
+	Forecast(ctx context.Context, userID int64) (purchases.MonthForecast, error)
 
 	ChangeUserCurrency(ctx context.Context, userID int64, currency cy.Currency) error
 	ChangeUserLimit(ctx context.Context, userID int64, rawLimit string) error
+	ChangeUserLang(ctx context.Context, userID int64, lang i18n.Lang) error
+	GetUserLang(ctx context.Context, userID int64) (i18n.Lang, error)
+	ChangeUserTimezone(ctx context.Context, userID int64, name string) (*time.Location, error)
+	GetUserLocation(ctx context.Context, userID int64) (*time.Location, error)
+	ChangeUserReminder(ctx context.Context, userID int64, rawTime string) (purchases.Reminder, error)
+	ChangeUserConfirmUnusual(ctx context.Context, userID int64, confirm bool) error
 	AddCategoryToUser(ctx context.Context, userID int64, category string) error
+	AddUserCategory(ctx context.Context, userID int64, category string) error
 	GetUserCategories(ctx context.Context, userID int64) ([]string, error)
+	AddCategoryRule(ctx context.Context, userID int64, condition, category string) error
+	SuggestCategories(ctx context.Context, userID int64, description string, categories []string) ([]string, error)
+	PreviewPurchase(ctx context.Context, userID int64, category string, sum float64) (purchases.PurchasePreview, error)
 
 	ToPeriod(str string) (purchases.Period, error)
 }
 
+// StatusStore хранилище состояний диалогов, сколько оно хранит состояние, столько и ждем ответа пользователя
 type StatusStore interface {
 	SetString(ctx context.Context, key string, value string) error
 	GetString(ctx context.Context, key string) (string, error)
 	Delete(ctx context.Context, key string) error
+	StringTTL() time.Duration
+}
+
+// RateLimiter ограничение частоты запросов пользователя по классам команд
+type RateLimiter interface {
+	Allow(ctx context.Context, userID int64, class string) ratelimit.Decision
+}
+
+// ChartDrawer рисовальщик графиков
+type ChartDrawer interface {
+	ForecastChart(f forecast.Forecast, limit float64, labels chart_drawing.ForecastLabels) ([]byte, error)
 }
 
 type Model struct {
 	tgClient       MessageSender
 	purchasesModel PurchasesModel
-	statusStore    StatusStore
+	drawer         ChartDrawer
+	dialogs        *fsm.Machine
+	limiter        RateLimiter // nil - без ограничений
+
+	now func() time.Time
 }
 
-func New(tgClient MessageSender, purchasesModel PurchasesModel, redis StatusStore) *Model {
-	return &Model{
+func New(tgClient MessageSender, purchasesModel PurchasesModel, drawer ChartDrawer, redis StatusStore, limiter RateLimiter) *Model {
+	m := &Model{
 		tgClient:       tgClient,
 		purchasesModel: purchasesModel,
-		statusStore:    redis,
+		drawer:         drawer,
+		limiter:        limiter,
+		now:            time.Now,
 	}
+	m.dialogs = m.newDialogs(redis)
+
+	return m
 }
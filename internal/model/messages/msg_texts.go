@@ -1,10 +1,30 @@
 package messages
 
+import (
+	"context"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// Тексты написаны по-русски и служат ключами каталога переводов, английские переводы - в msg_texts_en.go
+
 var (
-	ErrTxtUnknownCommand  = "Не знаю эту команду"
-	ErrTxtInvalidInput    = "Кажется, вы ошиблись при вводе команды. Введите /help, чтобы посмотреть шаблоны команд"
-	ErrTxtInvalidCurrency = "Вы можете выбрать только одну из следующих валют: RUB, USD, EUR, CNY. Пожалуйста, введите команду /currency заново с одной из доступных валют"
-	ErrTxtInvalidStatus   = "Не верный статус, попробуйте заново"
+	ErrTxtPrefix           = "Ошибочка: "
+	ErrTxtUnknownCommand   = "Не знаю эту команду"
+	ErrTxtInvalidInput     = "Кажется, вы ошиблись при вводе команды. Введите /help, чтобы посмотреть шаблоны команд"
+	ErrTxtCommandUsage     = "Кажется, вы ошиблись при вводе команды. Формат команды: %s"
+	ErrTxtUnknownHelpTopic = "Такой команды нет. Введите /help, чтобы посмотреть все команды"
+	ErrTxtInvalidCurrency  = "Вы можете выбрать только одну из следующих валют: RUB, USD, EUR, CNY. Пожалуйста, введите команду /currency заново с одной из доступных валют"
+	ErrTxtInvalidStatus    = "Не верный статус, попробуйте заново"
+	ErrTxtDialogExpired    = "Время на ответ вышло, попробуйте заново"
+	ErrTxtInvalidLang      = "Доступные языки: ru, en"
+	ErrTxtInvalidTimezone  = "Не знаю такой часовой пояс. Укажите его как в базе IANA, например: \"/tz Asia/Vladivostok\", \"/tz Europe/Moscow\", \"/tz UTC\""
+	ErrTxtInvalidReminder  = "Укажите время напоминания в формате ЧЧ:ММ, например: \"/remind 21:00\". Выключить напоминание: \"/remind off\""
+	ErrTxtInvalidRule      = "Не получилось разобрать правило. Примеры: \"/rule пятёрочка -> Продукты\", \"/rule /^такси/ -> Транспорт\", \"/rule 100..300 -> Кофе\""
+
+	ErrTxtInvalidSearchQuery   = "Не получилось разобрать запрос. Примеры: \"/find такси\", \"/find >5000\", \"/find 01.03.2024..31.03.2024\", \"/find кофе 100..300\""
+	ErrTxtRuleCategoryNotExist = "Такой категории еще нет. Создайте ее с помощью команды /category, а затем добавьте правило заново"
 
 	ScsTxtPurchaseAdded        = "Трата добавлена"
 	ScsTxtCategoryCreated      = "Категория создана"
@@ -12,8 +32,96 @@ var (
 	ScsTxtCategoryAddSelected  = "Вы выбрали создание новой категории. Создайте категорию с помощью команды /category, а затем введите трату заново"
 	ScsTxtCurrencyChanged      = "Ваша основная валюта изменена"
 	ScsTxtLimitChanged         = "Лимит установлен. Для того, чтобы сбросить лимит, отправьте \"/limit -1\""
+	ScsTxtRuleAdded            = "Правило добавлено. Теперь подходящие траты будут попадать в эту категорию автоматически"
 	ScsTxtReportRequestCreated = "Отчет готовится..."
 	ScsTxtReportIsReady        = "Отчет готов"
+	ScsTxtPurchaseCanceled     = "Хорошо, трата не добавлена"
+	ScsTxtNothingFound         = "Ничего не нашлось"
+	ScsTxtDialogCanceled       = "Хорошо, отменил"
+	ScsTxtNothingToCancel      = "Нечего отменять"
+	ScsTxtLangChanged          = "Теперь я говорю по-русски"
+	ScsTxtTimezoneChanged      = "Часовой пояс изменен на %s, у вас сейчас %s"
+	ScsTxtReminderOn           = "Буду напоминать в %s по вашему часовому поясу, если за день не будет ни одной траты. Сменить часовой пояс - /tz"
+	ScsTxtReminderOff          = "Напоминание выключено"
+	ScsTxtSubscribedWeekly     = "Каждый понедельник утром буду присылать отчет за прошедшую неделю: сравнение с предыдущей неделей и сколько потрачено из лимита. Отписаться - /unsubscribe"
+	ScsTxtSubscribedMonthly    = "Первого числа каждого месяца утром буду присылать отчет за прошедший месяц: сравнение с предыдущим месяцем и сколько потрачено из лимита. Отписаться - /unsubscribe"
+	ScsTxtUnsubscribed         = "Подписка на отчеты отменена"
+	ScsTxtConfirmOn            = "Теперь перед записью необычно крупной траты я спрошу, точно ли все верно. Выключить - \"/confirm off\""
+	ScsTxtConfirmOff           = "Необычно крупные траты записываются сразу, с предупреждением в ответе"
+	ScsTxtNoTags               = "У вас пока нет трат с тегами. Чтобы добавить тег, допишите его к трате: /add 1200 еда #командировка"
+
+	ScsTxtOnboardingDone = "Все готово! Добавьте первую трату: \"/add 250 Кафе\" или просто напишите \"кофе 250\". Все команды с примерами - /help"
+
+	TxtConfirmPurchase       = "Не совсем понял, какая здесь сумма. Выберите подходящий вариант"
+	TxtNonExistentCategory   = "Такой категории у вас еще нет, выберите одну из предложенных категорий или создайте свою с помощью команды /category"
+	TxtLimitInfo             = "У вас установлен лимит: %s %s. За этот месяц вы потратили уже %s %s."
+	TxtLimitExceeded         = "ВЫ ПРЕВЫСИЛИ ЛИМИТ!"
+	TxtTagsHeader            = "Ваши теги (%s):"
+	TxtTimezoneInfo          = "Ваш часовой пояс: %s, у вас сейчас %s. Сменить его можно командой \"/tz Asia/Vladivostok\""
+	TxtFindHeader            = "Найденные траты, страница %d:"
+	TxtPurchasesPlural       = "трата|траты|трат"
+	TxtTooManyRequests       = "Слишком много запросов подряд, давайте немного передохнем. Попробуйте снова через %d %s"
+	TxtSecondsPlural         = "секунду|секунды|секунд"
+	TxtHelpHeader            = "Команды:"
+	TxtHelpFreeText          = "Трату можно написать и без команды: \"кофе 250\", \"вчера такси 600 руб\""
+	TxtHelpMore              = "Подробнее о команде: /help <команда>"
+	TxtHelpExamples          = "Примеры:"
+	TxtOnboardingHello       = "Привет! Я помогу вести учет трат. Для начала выберите основную валюту: в ней будут лимиты и отчеты"
+	TxtOnboardingLimit       = "Установить лимит трат на месяц? Выберите сумму или пропустите этот шаг. Свой лимит можно задать командой /limit"
+	TxtOnboardingCategories  = "Выберите категории, с которых начнете, и нажмите «Готово». Свои категории можно создать командой /category"
+	TxtSelectCurrency        = "Выберите основную валюту"
+	TxtSelectPeriod          = "За какой период собрать отчет?"
+	TxtSelectCategory        = "В какую категорию записать трату?"
+	TxtInlinePurchase        = "Трата: %s"
+	TxtInlineCategoryMonth   = "«%s»: в этом месяце уже %s %s"
+	TxtInlineNoCategory      = "Без категории: в этом месяце уже %s %s"
+	TxtInlineUnknownCategory = "Такой категории у вас нет, выбрать ее можно будет после добавления"
+	TxtForecastSpent         = "Потрачено с начала месяца: %s %s"
+	TxtForecastTotal         = "Прогноз на конец месяца: %s %s"
+	TxtForecastLimitDate     = "При таком темпе лимит %s %s будет превышен примерно %s"
+	TxtForecastLimitSafe     = "При таком темпе лимит %s %s не будет превышен"
+	TxtForecastLimitExceeded = "Лимит %s %s превышен %s"
+	TxtChartActual           = "Траты"
+	TxtChartProjected        = "Прогноз"
+	TxtChartLimit            = "Лимит"
+	TxtUnusualPurchase       = "⚠️ Необычно крупная трата: обычно в этой категории вы тратите около %s %s"
+	TxtConfirmUnusual        = "Это точно? Обычно в этой категории вы тратите около %s %s"
 
 	ButtonTxtCreateCategory = "Создать категорию"
+	ButtonTxtCancel         = "Отмена"
+	ButtonTxtPrevPage       = "⬅️ Назад"
+	ButtonTxtNextPage       = "Вперед ➡️"
+	ButtonTxtSkip           = "Пропустить"
+	ButtonTxtDone           = "Готово"
+	ButtonTxtNoCategory     = "Без категории"
+	ButtonTxtWeek           = "Неделя"
+	ButtonTxtMonth          = "Месяц"
+	ButtonTxtYear           = "Год"
+	ButtonTxtConfirm        = "Да, записать"
 )
+
+// tr перевод текста на язык пользователя, которому отвечаем
+func tr(ctx context.Context, text string) string {
+	return i18n.T(i18n.FromContext(ctx), text)
+}
+
+// trf перевод строки формата на язык пользователя и подстановка аргументов
+func trf(ctx context.Context, format string, args ...any) string {
+	return i18n.Sprintf(i18n.FromContext(ctx), format, args...)
+}
+
+// categoryNames названия категорий на языке пользователя для кнопок. В колбэках приходят номера категорий,
+// поэтому дальше используются исходные названия
+func categoryNames(ctx context.Context, categories []string) []string {
+	lang := i18n.FromContext(ctx)
+	res := make([]string, len(categories))
+	for i, c := range categories {
+		res[i] = purchases.CategoryName(lang, c)
+	}
+	return res
+}
+
+// errText текст ошибки для пользователя
+func errText(ctx context.Context, err error) string {
+	return tr(ctx, ErrTxtPrefix) + err.Error()
+}
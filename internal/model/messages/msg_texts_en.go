@@ -0,0 +1,124 @@
+package messages
+
+import "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+
+func init() {
+	i18n.Register(i18n.EN, map[string]string{
+		ErrTxtPrefix:               "Oops: ",
+		ErrTxtUnknownCommand:       "I don't know this command",
+		ErrTxtInvalidInput:         "Looks like the command is mistyped. Send /help to see command templates",
+		ErrTxtCommandUsage:         "Looks like the command is mistyped. Usage: %s",
+		ErrTxtUnknownHelpTopic:     "There is no such command. Send /help to see all commands",
+		ErrTxtInvalidCurrency:      "You can choose only one of these currencies: RUB, USD, EUR, CNY. Please send /currency again with one of them",
+		ErrTxtInvalidStatus:        "Unexpected answer, please try again",
+		ErrTxtDialogExpired:        "Time to answer is over, please try again",
+		ErrTxtInvalidLang:          "Available languages: ru, en",
+		ErrTxtInvalidReminder:      "Send the reminder time as HH:MM, for example: \"/remind 21:00\". To turn the reminder off, send \"/remind off\"",
+		ErrTxtInvalidTimezone:      "I don't know this time zone. Use an IANA name, for example: \"/tz America/New_York\", \"/tz Europe/London\", \"/tz UTC\"",
+		ErrTxtInvalidRule:          "Couldn't parse the rule. Examples: \"/rule starbucks -> Coffee\", \"/rule /^taxi/ -> Transport\", \"/rule 100..300 -> Coffee\"",
+		ErrTxtInvalidSearchQuery:   "Couldn't parse the query. Examples: \"/find taxi\", \"/find >5000\", \"/find 01.03.2024..31.03.2024\", \"/find coffee 100..300\"",
+		ErrTxtRuleCategoryNotExist: "There is no such category yet. Create it with /category and then add the rule again",
+
+		ScsTxtPurchaseAdded:        "Purchase added",
+		ScsTxtCategoryCreated:      "Category created",
+		ScsTxtCategoryAddedToUser:  "Category added to your list",
+		ScsTxtCategoryAddSelected:  "You chose to create a new category. Create it with /category and then send the purchase again",
+		ScsTxtCurrencyChanged:      "Your main currency has been changed",
+		ScsTxtLimitChanged:         "Limit set. To remove it, send \"/limit -1\"",
+		ScsTxtRuleAdded:            "Rule added. Matching purchases will now get this category automatically",
+		ScsTxtReportRequestCreated: "Preparing the report...",
+		ScsTxtReportIsReady:        "Your report is ready",
+		ScsTxtPurchaseCanceled:     "OK, the purchase was not added",
+		ScsTxtNothingFound:         "Nothing found",
+		ScsTxtDialogCanceled:       "OK, canceled",
+		ScsTxtNothingToCancel:      "Nothing to cancel",
+		ScsTxtLangChanged:          "Now I speak English",
+		ScsTxtTimezoneChanged:      "Time zone changed to %s, your time is %s",
+		ScsTxtReminderOn:           "I'll remind you at %s in your time zone if you haven't logged any expenses that day. To change the time zone, send /tz",
+		ScsTxtReminderOff:          "Reminder turned off",
+		ScsTxtSubscribedWeekly:     "Every Monday morning I'll send you a report for the past week: comparison with the week before and how much of the limit is spent. To unsubscribe, send /unsubscribe",
+		ScsTxtSubscribedMonthly:    "On the first day of every month I'll send you a report for the past month: comparison with the month before and how much of the limit is spent. To unsubscribe, send /unsubscribe",
+		ScsTxtUnsubscribed:         "Report subscription cancelled",
+		ScsTxtConfirmOn:            "Now I'll ask you to confirm unusually large purchases before saving them. To turn it off, send \"/confirm off\"",
+		ScsTxtConfirmOff:           "Unusually large purchases are saved right away, with a warning in the reply",
+		ScsTxtNoTags:               "You have no tagged purchases yet. To add a tag, append it to a purchase: /add 1200 food #trip",
+
+		ScsTxtOnboardingDone: "All set! Add your first purchase: \"/add 250 Cafe\" or just write \"coffee 250\". All commands with examples - /help",
+
+		TxtConfirmPurchase:       "I'm not sure which number is the amount. Please choose the right option",
+		TxtNonExistentCategory:   "You don't have this category yet. Choose one of the suggested categories or create your own with /category",
+		TxtLimitInfo:             "Your limit: %s %s. You have spent %s %s this month.",
+		TxtLimitExceeded:         "YOU HAVE EXCEEDED THE LIMIT!",
+		TxtTagsHeader:            "Your tags (%s):",
+		TxtTimezoneInfo:          "Your time zone: %s, your time is %s. To change it, send \"/tz America/New_York\"",
+		TxtFindHeader:            "Found purchases, page %d:",
+		TxtPurchasesPlural:       "purchase|purchases",
+		TxtTooManyRequests:       "Too many requests in a row, let's take a short break. Please try again in %d %s",
+		TxtSecondsPlural:         "second|seconds",
+		TxtHelpHeader:            "Commands:",
+		TxtHelpFreeText:          "You can also write a purchase without a command: \"coffee 250\", \"taxi 600 rub\"",
+		TxtHelpMore:              "More about a command: /help <command>",
+		TxtHelpExamples:          "Examples:",
+		TxtOnboardingHello:       "Hi! I'll help you track your expenses. First, choose your main currency: limits and reports will use it",
+		TxtOnboardingLimit:       "Set a monthly spending limit? Choose an amount or skip this step. You can set your own limit with /limit",
+		TxtOnboardingCategories:  "Choose the categories to start with and press «Done». You can create your own categories with /category",
+		TxtSelectCurrency:        "Choose your main currency",
+		TxtSelectPeriod:          "Which period should the report cover?",
+		TxtSelectCategory:        "Which category should the purchase go to?",
+		TxtInlinePurchase:        "Purchase: %s",
+		TxtInlineCategoryMonth:   "«%s»: %s %s spent this month",
+		TxtInlineNoCategory:      "No category: %s %s spent this month",
+		TxtInlineUnknownCategory: "You don't have this category, you can choose one after adding",
+		TxtForecastSpent:         "Spent since the start of the month: %s %s",
+		TxtForecastTotal:         "Month-end forecast: %s %s",
+		TxtForecastLimitDate:     "At this pace, the %s %s limit will be exceeded around %s",
+		TxtForecastLimitSafe:     "At this pace, the %s %s limit will not be exceeded",
+		TxtForecastLimitExceeded: "The %s %s limit was exceeded on %s",
+		TxtChartActual:           "Expenses",
+		TxtChartProjected:        "Forecast",
+		TxtChartLimit:            "Limit",
+		TxtUnusualPurchase:       "⚠️ Unusually large purchase: you usually spend about %s %s in this category",
+		TxtConfirmUnusual:        "Are you sure? You usually spend about %s %s in this category",
+
+		ButtonTxtCreateCategory: "Create category",
+		ButtonTxtCancel:         "Cancel",
+		ButtonTxtPrevPage:       "⬅️ Back",
+		ButtonTxtNextPage:       "Next ➡️",
+		ButtonTxtSkip:           "Skip",
+		ButtonTxtDone:           "Done",
+		ButtonTxtNoCategory:     "No category",
+		ButtonTxtWeek:           "Week",
+		ButtonTxtMonth:          "Month",
+		ButtonTxtYear:           "Year",
+		ButtonTxtConfirm:        "Yes, save it",
+
+		// шаблоны и описания команд
+		"начать работу с ботом: валюта, лимит и стартовые категории": "get started: currency, limit and starter categories",
+		"/help [команда]": "/help [command]",
+		"список команд или подробное описание одной команды":                                   "list of commands or details about one command",
+		"выйти из текущего диалога: выбора категории, подтверждения траты, знакомства с ботом": "leave the current dialog: category choice, purchase confirmation, getting started",
+		"/add <сумма>[валюта] [категория] [dd.mm.yyyy] [#тег] [\"заметка\"]":                   "/add <amount>[currency] [category] [dd.mm.yyyy] [#tag] [\"note\"]",
+		"добавить трату, без категории - выбор категории кнопками":                             "add a purchase, without a category - choose it with buttons",
+		"/category <название>":           "/category <name>",
+		"создать категорию":              "create a category",
+		"/rule <условие> -> <категория>": "/rule <condition> -> <category>",
+		"правило автоматического подбора категории: подстрока, /регулярное выражение/ или диапазон суммы": "rule for choosing a category automatically: substring, /regular expression/ or amount range",
+		"/report [week|month|year] [all] [today] [pdf] [#тег]": "/report [week|month|year] [all] [today] [pdf] [#tag]",
+		"отчет по тратам за период, без периода - выбор кнопками. all - суммы в каждой валюте оплаты, today - пересчет по сегодняшнему курсу, pdf - еще и документ со всеми тратами": "expenses report for a period, without a period - choose it with buttons. all - totals in each currency you paid in, today - convert at today's rate, pdf - also a document with all purchases",
+		"теги с суммами трат по ним":                                              "tags with their totals",
+		"/find <слова> [>сумма] [<сумма] [сумма..сумма] [dd.mm.yyyy..dd.mm.yyyy]": "/find <words> [>amount] [<amount] [amount..amount] [dd.mm.yyyy..dd.mm.yyyy]",
+		"поиск трат": "search purchases",
+		"сменить основную валюту, без аргумента - выбор кнопками": "change the main currency, without an argument - choose it with buttons",
+		"/limit <сумма>": "/limit <amount>",
+		"месячный лимит трат, -1 снимает лимит":           "monthly spending limit, -1 removes it",
+		"язык интерфейса, по умолчанию - как в телеграме": "interface language, Telegram's language by default",
+		"/tz [часовой пояс]": "/tz [time zone]",
+		"часовой пояс, в котором считаются дни и месяцы, без аргумента - текущий": "time zone used for days and months, shows the current one without an argument",
+		"/remind <ЧЧ:ММ|off>": "/remind <HH:MM|off>",
+		"ежедневное напоминание записать траты, если за день их нет":                               "daily reminder to log expenses if there are none that day",
+		"присылать отчет за каждую прошедшую неделю или месяц со сравнением с предыдущим периодом": "send a report for every past week or month compared with the previous period",
+		"отменить подписку на отчеты":                                                              "cancel the report subscription",
+		"спрашивать подтверждение перед записью необычно крупной траты":                            "ask for confirmation before saving an unusually large purchase",
+		"прогноз трат на конец месяца и дата превышения лимита с графиком":                         "month-end spending forecast and the date the limit will be exceeded, with a chart",
+	})
+}
@@ -0,0 +1,134 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// Test_EnglishCatalogIsComplete у каждого текста, который видит пользователь, должен быть перевод
+func Test_EnglishCatalogIsComplete(t *testing.T) {
+	texts := []string{
+		ErrTxtPrefix, ErrTxtUnknownCommand, ErrTxtInvalidInput, ErrTxtCommandUsage, ErrTxtUnknownHelpTopic,
+		ErrTxtInvalidCurrency, ErrTxtInvalidStatus, ErrTxtDialogExpired, ErrTxtInvalidLang, ErrTxtInvalidRule,
+		ErrTxtInvalidSearchQuery, ErrTxtRuleCategoryNotExist, ErrTxtInvalidTimezone,
+
+		ScsTxtPurchaseAdded, ScsTxtCategoryCreated, ScsTxtCategoryAddedToUser, ScsTxtCategoryAddSelected,
+		ScsTxtCurrencyChanged, ScsTxtLimitChanged, ScsTxtRuleAdded, ScsTxtReportRequestCreated, ScsTxtReportIsReady,
+		ScsTxtPurchaseCanceled,
+		ScsTxtNothingFound, ScsTxtDialogCanceled, ScsTxtNothingToCancel, ScsTxtLangChanged, ScsTxtNoTags,
+		ScsTxtOnboardingDone, ScsTxtTimezoneChanged, ScsTxtConfirmOn, ScsTxtConfirmOff,
+
+		TxtConfirmPurchase, TxtNonExistentCategory, TxtLimitInfo, TxtLimitExceeded, TxtTagsHeader, TxtFindHeader,
+		TxtPurchasesPlural, TxtHelpHeader, TxtHelpFreeText, TxtHelpMore, TxtHelpExamples,
+		TxtOnboardingHello, TxtOnboardingLimit, TxtOnboardingCategories, TxtTimezoneInfo, TxtTooManyRequests,
+		TxtSecondsPlural, TxtSelectCurrency, TxtSelectPeriod, TxtSelectCategory, TxtInlinePurchase,
+		TxtInlineCategoryMonth, TxtInlineNoCategory, TxtInlineUnknownCategory,
+		TxtForecastSpent, TxtForecastTotal, TxtForecastLimitDate, TxtForecastLimitSafe, TxtForecastLimitExceeded,
+		TxtChartActual, TxtChartProjected, TxtChartLimit, TxtUnusualPurchase, TxtConfirmUnusual,
+
+		ButtonTxtCreateCategory, ButtonTxtCancel, ButtonTxtPrevPage, ButtonTxtNextPage, ButtonTxtSkip, ButtonTxtDone,
+		ButtonTxtNoCategory, ButtonTxtWeek, ButtonTxtMonth, ButtonTxtYear, ButtonTxtConfirm,
+	}
+	for _, c := range commands {
+		texts = append(texts, c.help)
+		// шаблоны без русских слов переводить не нужно
+		if hasCyrillic(c.usage) {
+			texts = append(texts, c.usage)
+		}
+	}
+
+	for _, txt := range texts {
+		assert.True(t, i18n.Has(i18n.EN, txt), txt)
+	}
+}
+
+func hasCyrillic(s string) bool {
+	for _, r := range s {
+		if r >= 'А' && r <= 'я' {
+			return true
+		}
+	}
+	return false
+}
+
+func Test_OnLangCommand(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().ChangeUserLang(gomock.Any(), int64(123), i18n.EN).Return(nil)
+	sender.EXPECT().SendMessage("Now I speak English", int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/lang en", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+
+	sender.EXPECT().SendMessage(ErrTxtInvalidLang, int64(123))
+
+	err = model.IncomingMessage(ctx, tg.Message{Text: "/lang de", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+}
+
+func Test_LangFromTelegram(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	// язык не выбран командой, поэтому берется из телеграма
+	sender.EXPECT().SendMessage("I don't know this command", int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/coffee", UserID: 123, UserName: "name", LanguageCode: "en-US"})
+	assert.NoError(t, err)
+}
+
+func Test_ChosenLangOverridesTelegram(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUpWithLang(t, i18n.EN)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "Кафе", "").
+		Return(purchases.ExpensesAndLimit{Limit: 30000, Expenses: 1250.5, Forecast: &purchases.MonthForecast{
+			Forecast: forecast.Forecast{Actual: make([]float64, 10), Total: 40000, LimitDay: 23},
+			Month:    time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC),
+			Limit:    30000,
+		}}, nil)
+	sender.EXPECT().SendMessage("Purchase added\n\nYour limit: 30,000.00 RUB. You have spent 1,250.50 RUB this month.\n"+
+		"Month-end forecast: 40,000.00 RUB\nAt this pace, the 30,000.00 RUB limit will be exceeded around Nov 23, 2022", int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/add 250 Кафе", UserID: 123, UserName: "name", LanguageCode: "ru"})
+	assert.NoError(t, err)
+
+	purchasesModel.EXPECT().ToPeriod("month").Return(purchases.Period(2), nil)
+	purchasesModel.EXPECT().CreateReportRequest(gomock.Any(), purchases.Period(2), int64(123), i18n.EN, purchases.ReportOptions{}).Return(nil)
+	sender.EXPECT().SendTrackedMessage("Preparing the report...", int64(123), reportMsgKey)
+
+	err = model.IncomingMessage(ctx, tg.Message{Text: "/report month", UserID: 123, UserName: "name", LanguageCode: "ru"})
+	assert.NoError(t, err)
+}
+
+func Test_EnglishStarterCategories(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUpWithLang(t, i18n.EN)
+	model := New(sender, purchasesModel, nil, nil, nil)
+
+	// в базе категория хранится под исходным названием, пользователь пишет ее по-английски
+	purchasesModel.EXPECT().AddPurchase(gomock.Any(), int64(123), "250", "Кафе", "").
+		Return(purchases.ExpensesAndLimit{Limit: -1}, nil)
+	sender.EXPECT().SendMessage("Purchase added", int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/add 250 cafe", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+}
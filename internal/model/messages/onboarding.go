@@ -0,0 +1,143 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// онбординг: /start -> выбор валюты -> лимит (можно пропустить) -> стартовые категории -> пример траты.
+// Шаг мастера - состояние диалога, каждый шаг - клавиатура, поэтому ответы приходят колбэками.
+// Все шаги показываются в одном сообщении: каждый следующий шаг заменяет предыдущий
+
+var (
+	// onboardingLimits предлагаемые месячные лимиты в каждой валюте
+	onboardingLimits = map[cy.Currency][]float64{
+		cy.RUB: {10000, 30000, 50000, 100000},
+		cy.USD: {100, 300, 500, 1000},
+		cy.EUR: {100, 300, 500, 1000},
+		cy.CNY: {1000, 3000, 5000, 10000},
+	}
+	// onboardingCategories категории, с которых можно начать. Добавляются под исходными названиями на любом языке
+	onboardingCategories = purchases.StarterCategories
+)
+
+// pickedMark отметка на кнопке уже добавленной категории
+const pickedMark = "✅ "
+
+// onboardingPicked номера уже добавленных стартовых категорий
+type onboardingPicked struct {
+	Picked []int `json:"picked,omitempty"`
+}
+
+func (p onboardingPicked) has(i int) bool {
+	for _, j := range p.Picked {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Model) msgStart(ctx context.Context, Send Message) error {
+	if err := m.dialogs.Enter(ctx, Send.UserID, stateOnboardingCurrency, nil); err != nil {
+		err = errors.Wrap(err, "dialogs.Enter")
+		return m.SendMessage(errText(ctx, err), Send.UserID)
+	}
+
+	return m.SendKeyboard(tr(ctx, TxtOnboardingHello), Send.UserID, currencyKeyboard())
+}
+
+func (m *Model) msgOnboardingCurrency(ctx context.Context, msg Callback) error {
+	userCY, err := cy.StrToCurrency(msg.Data)
+	if err != nil {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidCurrency), msg.UserID)
+	}
+
+	if err = m.purchasesModel.ChangeUserCurrency(ctx, msg.UserID, userCY); err != nil {
+		err = errors.Wrap(err, "purchasesModel.ChangeUserCurrency")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	if err = m.dialogs.Enter(ctx, msg.UserID, stateOnboardingLimit, nil); err != nil {
+		err = errors.Wrap(err, "dialogs.Enter")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	limits := onboardingLimits[userCY]
+	buttons := make([]tg.Button, len(limits))
+	for i, l := range limits {
+		buttons[i] = tg.NewButton(fmt.Sprintf("%.0f %s", l, msg.Data), strconv.FormatFloat(l, 'f', -1, 64))
+	}
+	keyboard := tg.Grid(2, buttons...).Row(button(ctx, ButtonTxtSkip, dataSkip))
+
+	return m.EditKeyboard(tr(ctx, TxtOnboardingLimit), msg.UserID, tg.MessageRef{ID: msg.MessageID}, keyboard)
+}
+
+func (m *Model) msgOnboardingLimit(ctx context.Context, msg Callback) error {
+	if msg.Data != dataSkip {
+		// на кнопке "30000 RUB", в данных только сумма: лимит задается в основной валюте, выбранной на прошлом шаге
+		if _, err := strconv.ParseFloat(msg.Data, 64); err != nil {
+			return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+		}
+		if err := m.purchasesModel.ChangeUserLimit(ctx, msg.UserID, msg.Data); err != nil {
+			err = errors.Wrap(err, "purchasesModel.ChangeUserLimit")
+			return m.SendMessage(errText(ctx, err), msg.UserID)
+		}
+	}
+
+	if err := m.dialogs.Enter(ctx, msg.UserID, stateOnboardingCategories, nil); err != nil {
+		err = errors.Wrap(err, "dialogs.Enter")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	return m.EditKeyboard(tr(ctx, TxtOnboardingCategories), msg.UserID, tg.MessageRef{ID: msg.MessageID}, onboardingCategoriesKeyboard(ctx, onboardingPicked{}))
+}
+
+// msgOnboardingCategories каждое нажатие добавляет категорию и отмечает ее на кнопке, шаг заканчивается кнопкой "Готово"
+func (m *Model) msgOnboardingCategories(ctx context.Context, msg Callback, picked onboardingPicked) error {
+	if msg.Data == dataDone {
+		if err := m.dialogs.Reset(ctx, msg.UserID); err != nil {
+			err = errors.Wrap(err, "dialogs.Reset")
+			return m.SendMessage(errText(ctx, err), msg.UserID)
+		}
+		return m.EditMessage(tr(ctx, ScsTxtOnboardingDone), msg.UserID, tg.MessageRef{ID: msg.MessageID})
+	}
+
+	i, ok := option(msg.Data, len(onboardingCategories))
+	if !ok {
+		return m.SendMessage(tr(ctx, ErrTxtInvalidStatus), msg.UserID)
+	}
+	if picked.has(i) {
+		return nil
+	}
+
+	if err := m.purchasesModel.AddUserCategory(ctx, msg.UserID, onboardingCategories[i]); err != nil {
+		err = errors.Wrap(err, "purchasesModel.AddUserCategory")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	picked.Picked = append(picked.Picked, i)
+	if err := m.dialogs.Enter(ctx, msg.UserID, stateOnboardingCategories, picked); err != nil {
+		err = errors.Wrap(err, "dialogs.Enter")
+		return m.SendMessage(errText(ctx, err), msg.UserID)
+	}
+
+	return m.EditKeyboard(tr(ctx, TxtOnboardingCategories), msg.UserID, tg.MessageRef{ID: msg.MessageID}, onboardingCategoriesKeyboard(ctx, picked))
+}
+
+// onboardingCategoriesKeyboard стартовые категории, уже добавленные отмечены галочкой
+func onboardingCategoriesKeyboard(ctx context.Context, picked onboardingPicked) tg.Keyboard {
+	buttons := optionButtons(categoryNames(ctx, onboardingCategories))
+	for i := range buttons {
+		if picked.has(i) {
+			buttons[i].Text = pickedMark + buttons[i].Text
+		}
+	}
+	return tg.Grid(2, buttons...).Row(button(ctx, ButtonTxtDone, dataDone))
+}
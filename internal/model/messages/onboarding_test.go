@@ -0,0 +1,158 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	cy "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/fsm"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+func Test_Onboarding(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	// статус хранится как в редисе: последний записанный отдается при следующем нажатии
+	var status string
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string, value string) error {
+			status = value
+			return nil
+		})
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").AnyTimes().DoAndReturn(
+		func(_ context.Context, _ string) (string, error) {
+			return status, nil
+		})
+
+	// все шаги меняют сообщение, на кнопку которого нажали
+	welcome := tg.MessageRef{ID: 77}
+	press := func(data string) error {
+		return model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: data, MessageID: welcome.ID})
+	}
+
+	sender.EXPECT().SendKeyboard(TxtOnboardingHello, int64(123), currencyKeyboard())
+	err := model.IncomingMessage(ctx, tg.Message{Text: "/start", UserID: 123, UserName: "name"})
+	assert.NoError(t, err)
+
+	t.Run("выбор валюты", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserCurrency(gomock.Any(), int64(123), cy.USD).Return(nil)
+		sender.EXPECT().EditKeyboard(TxtOnboardingLimit, int64(123), welcome, tg.Keyboard{
+			{tg.NewButton("100 USD", "100"), tg.NewButton("300 USD", "300")},
+			{tg.NewButton("500 USD", "500"), tg.NewButton("1000 USD", "1000")},
+			{tg.NewButton(ButtonTxtSkip, dataSkip)},
+		})
+
+		assert.NoError(t, press("USD"))
+	})
+
+	t.Run("выбор лимита", func(t *testing.T) {
+		purchasesModel.EXPECT().ChangeUserLimit(gomock.Any(), int64(123), "300").Return(nil)
+		sender.EXPECT().EditKeyboard(TxtOnboardingCategories, int64(123), welcome, gomock.Any()).DoAndReturn(
+			func(_ string, _ int64, _ tg.MessageRef, keyboard tg.Keyboard) error {
+				// по две категории в ряд, "Готово" отдельным рядом
+				assert.Equal(t, tg.NewButton("Продукты", "0"), keyboard[0][0])
+				assert.Equal(t, tg.NewButton("Кафе", "1"), keyboard[0][1])
+				assert.Equal(t, []tg.Button{tg.NewButton(ButtonTxtDone, dataDone)}, keyboard[len(keyboard)-1])
+				return nil
+			})
+
+		assert.NoError(t, press("300"))
+	})
+
+	t.Run("выбор категорий", func(t *testing.T) {
+		purchasesModel.EXPECT().AddUserCategory(gomock.Any(), int64(123), "Кафе").Return(nil)
+		sender.EXPECT().EditKeyboard(TxtOnboardingCategories, int64(123), welcome, gomock.Any()).DoAndReturn(
+			func(_ string, _ int64, _ tg.MessageRef, keyboard tg.Keyboard) error {
+				// добавленная категория отмечена на кнопке
+				assert.Equal(t, tg.NewButton("Продукты", "0"), keyboard[0][0])
+				assert.Equal(t, tg.NewButton("✅ Кафе", "1"), keyboard[0][1])
+				return nil
+			})
+		assert.NoError(t, press("1"))
+
+		// повторное нажатие ничего не добавляет
+		assert.NoError(t, press("1"))
+
+		sender.EXPECT().SendMessage(ErrTxtInvalidStatus, int64(123))
+		assert.NoError(t, press("100"))
+	})
+
+	t.Run("завершение", func(t *testing.T) {
+		statusStore.EXPECT().Delete(gomock.Any(), "123status").Return(nil)
+		sender.EXPECT().EditMessage(ScsTxtOnboardingDone, int64(123), welcome)
+
+		assert.NoError(t, press(dataDone))
+	})
+}
+
+func Test_Onboarding_SkipLimit(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(encodeState(t, stateOnboardingLimit), nil)
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, value string) error {
+			assert.Equal(t, stateOnboardingCategories, decodeState(t, value))
+			return nil
+		})
+	// лимит не меняется
+	purchasesModel.EXPECT().ChangeUserLimit(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	sender.EXPECT().EditKeyboard(TxtOnboardingCategories, int64(123), tg.MessageRef{ID: 77}, gomock.Any())
+
+	err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: dataSkip, MessageID: 77})
+
+	assert.NoError(t, err)
+}
+
+func Test_Onboarding_EnglishCategories(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUpWithLang(t, i18n.EN)
+	model := New(sender, purchasesModel, nil, statusStore, nil)
+
+	statusStore.EXPECT().GetString(gomock.Any(), "123status").Return(encodeState(t, stateOnboardingCategories), nil)
+	statusStore.EXPECT().SetString(gomock.Any(), "123status", gomock.Any()).Return(nil)
+	// категория добавляется под исходным названием, переводится только на кнопке
+	purchasesModel.EXPECT().AddUserCategory(gomock.Any(), int64(123), "Кафе").Return(nil)
+	sender.EXPECT().EditKeyboard("Choose the categories to start with and press «Done». You can create your own categories with /category",
+		int64(123), tg.MessageRef{ID: 77}, gomock.Any()).DoAndReturn(
+		func(_ string, _ int64, _ tg.MessageRef, keyboard tg.Keyboard) error {
+			assert.Equal(t, tg.NewButton("Groceries", "0"), keyboard[0][0])
+			assert.Equal(t, tg.NewButton("✅ Cafe", "1"), keyboard[0][1])
+			return nil
+		})
+
+	err := model.IncomingCallback(ctx, tg.Callback{UserID: 123, UserName: "name", Data: "1", MessageID: 77})
+
+	assert.NoError(t, err)
+}
+
+// encodeState состояние без данных в том виде, в котором оно лежит в хранилище
+func encodeState(t *testing.T, state fsm.State) string {
+	raw, err := json.Marshal(map[string]fsm.State{"state": state})
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// decodeState состояние из значения, записанного в хранилище
+func decodeState(t *testing.T, value string) fsm.State {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	assert.NoError(t, err)
+	var res struct {
+		State fsm.State `json:"state"`
+	}
+	assert.NoError(t, json.Unmarshal(raw, &res))
+	return res.State
+}
@@ -2,6 +2,7 @@ package messages
 
 import (
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
 )
 
 func (m *Model) SendMessage(text string, userID int64) error {
@@ -22,11 +23,62 @@ func (m *Model) SendImage(img []byte, userID int64) error {
 	return nil
 }
 
-func (m *Model) SendKeyboard(text string, userID int64, buttonTexts []string) error {
-	err := m.tgClient.SendKeyboard(text, userID, buttonTexts)
+func (m *Model) SendDocument(doc []byte, name string, userID int64) error {
+	err := m.tgClient.SendDocument(doc, name, userID)
+	if err != nil {
+		return errors.Wrap(err, "client.SendDocument")
+	}
+
+	return nil
+}
+
+func (m *Model) SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error {
+	err := m.tgClient.SendKeyboard(text, userID, keyboard)
 	if err != nil {
 		return errors.Wrap(err, "client.SendKeyboard")
 	}
 
 	return nil
 }
+
+func (m *Model) SendTrackedMessage(text string, userID int64, key string) error {
+	err := m.tgClient.SendTrackedMessage(text, userID, key)
+	if err != nil {
+		return errors.Wrap(err, "client.SendTrackedMessage")
+	}
+
+	return nil
+}
+
+func (m *Model) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	err := m.tgClient.EditMessage(text, userID, msg)
+	if err != nil {
+		return errors.Wrap(err, "client.EditMessage")
+	}
+
+	return nil
+}
+
+func (m *Model) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	err := m.tgClient.EditKeyboard(text, userID, msg, keyboard)
+	if err != nil {
+		return errors.Wrap(err, "client.EditKeyboard")
+	}
+
+	return nil
+}
+
+// reply отвечает новым сообщением, а если известно сообщение бота edit (например, с нажатой кнопкой) - меняет его.
+// Без кнопок у измененного сообщения пропадают и старые кнопки
+func (m *Model) reply(text string, userID int64, edit tg.MessageRef, keyboard tg.Keyboard) error {
+	switch {
+	case edit == tg.MessageRef{} && len(keyboard) == 0:
+		return m.SendMessage(text, userID)
+	case edit == tg.MessageRef{}:
+		return m.SendKeyboard(text, userID, keyboard)
+	case len(keyboard) == 0:
+		return m.EditMessage(text, userID, edit)
+	default:
+		return m.EditKeyboard(text, userID, edit, keyboard)
+	}
+}
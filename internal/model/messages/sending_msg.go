@@ -1,6 +1,13 @@
 package messages
 
-import "context"
+import (
+	"context"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+)
+
+// reportMsgKey ключ сообщения "Отчет готовится...", которое заменяется текстом готового отчета
+const reportMsgKey = "report"
 
 type ReportData struct {
 	UserID        int64
@@ -8,8 +15,29 @@ type ReportData struct {
 	ReportIMG     []byte
 }
 
+// SendReport отправляет готовый отчет. Текст уже переведен сервисом отчетов на язык из запроса, вместе со строкой
+// "Отчет готов" он заменяет сообщение "Отчет готовится...", а если его уже нельзя изменить - отправляется новым сообщением
 func (m *Model) SendReport(ctx context.Context, userID int64, text string, img []byte) error {
-	_ = m.tgClient.SendMessage(ScsTxtReportIsReady, userID)
-	_ = m.tgClient.SendMessage(text, userID)
+	_ = m.tgClient.EditMessage(m.reportReadyText(ctx, userID, text), userID, tg.MessageRef{Key: reportMsgKey})
+	return m.tgClient.SendImage(img, userID)
+}
+
+// SendReportDocument отправляет готовый отчет в PDF: текст, как у SendReport, и следом файл name
+func (m *Model) SendReportDocument(ctx context.Context, userID int64, text string, doc []byte, name string) error {
+	_ = m.tgClient.EditMessage(m.reportReadyText(ctx, userID, text), userID, tg.MessageRef{Key: reportMsgKey})
+	return m.tgClient.SendDocument(doc, name, userID)
+}
+
+// reportReadyText текст отчета под строкой "Отчет готов" на языке пользователя
+func (m *Model) reportReadyText(ctx context.Context, userID int64, text string) string {
+	ctx = m.withUserLang(ctx, userID, "")
+	return tr(ctx, ScsTxtReportIsReady) + "\n" + text
+}
+
+// SendDigest отправляет отчет по подписке. Он приходит сам по себе, поэтому не заменяет сообщение "Отчет готовится..."
+func (m *Model) SendDigest(ctx context.Context, userID int64, text string, img []byte) error {
+	if err := m.tgClient.SendMessage(text, userID); err != nil {
+		return err
+	}
 	return m.tgClient.SendImage(img, userID)
 }
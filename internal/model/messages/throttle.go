@@ -0,0 +1,44 @@
+package messages
+
+import (
+	"context"
+	"math"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+)
+
+// throttled проверяет, не превысил ли пользователь ограничение частоты запросов класса class. Если превысил,
+// запрос не выполняется, а при первом отказе пользователя вежливо просят подождать. Проверка идет раньше
+// любых запросов в базу, язык пользователя (languageCode - язык телеграма) определяется, только чтобы ответить
+func (m *Model) throttled(ctx context.Context, userID int64, languageCode, class, label string) (bool, error) {
+	decision := m.allow(ctx, userID, class, label)
+	if decision.Allowed {
+		return false, nil
+	}
+	if !decision.Notify {
+		return true, nil
+	}
+
+	seconds := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	ctx = m.withUserLang(ctx, userID, languageCode)
+	lang := i18n.FromContext(ctx)
+	return true, m.SendMessage(trf(ctx, TxtTooManyRequests, seconds, i18n.Plural(lang, seconds, TxtSecondsPlural)), userID)
+}
+
+// allow решение ограничителя без уведомления пользователя: там, где писать ему в чат неуместно
+func (m *Model) allow(ctx context.Context, userID int64, class, label string) ratelimit.Decision {
+	if m.limiter == nil {
+		return ratelimit.Decision{Allowed: true}
+	}
+
+	decision := m.limiter.Allow(ctx, userID, class)
+	if !decision.Allowed {
+		metrics.ThrottledRequests.WithLabelValues(class, label).Inc()
+	}
+	return decision
+}
@@ -0,0 +1,128 @@
+//go:build test_all || unit_test
+
+package messages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/clients/tg"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/messages/_mocks"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
+)
+
+func Test_OnThrottledCommand_ShouldAskToWait(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	limiter := mocks.NewMockRateLimiter(gomock.NewController(t))
+	model := New(sender, purchasesModel, nil, nil, limiter)
+
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassHeavy).
+		Return(ratelimit.Decision{RetryAfter: 2500 * time.Millisecond, Notify: true})
+	sender.EXPECT().SendMessage("Слишком много запросов подряд, давайте немного передохнем. Попробуйте снова через 3 секунды", int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:   "/report year",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnThrottledAgain_ShouldKeepSilent(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	limiter := mocks.NewMockRateLimiter(gomock.NewController(t))
+	model := New(sender, purchasesModel, nil, nil, limiter)
+
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassDefault).
+		Return(ratelimit.Decision{RetryAfter: time.Second})
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:   "кофе 250",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnAllowedCommand_ShouldRun(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	limiter := mocks.NewMockRateLimiter(gomock.NewController(t))
+	model := New(sender, purchasesModel, nil, nil, limiter)
+
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassDefault).Return(ratelimit.Decision{Allowed: true})
+	sender.EXPECT().SendMessage(gomock.Any(), int64(123))
+
+	err := model.IncomingMessage(ctx, tg.Message{
+		Text:   "/help",
+		UserID: 123,
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnThrottledCallback_ShouldNotDispatch(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, statusStore := mocksUp(t)
+	limiter := mocks.NewMockRateLimiter(gomock.NewController(t))
+	model := New(sender, purchasesModel, nil, statusStore, limiter)
+
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassDefault).Return(ratelimit.Decision{RetryAfter: time.Second})
+
+	err := model.IncomingCallback(ctx, tg.Callback{
+		UserID: 123,
+		Data:   "USD",
+	})
+
+	assert.NoError(t, err)
+}
+
+func Test_OnThrottled_ShouldNotTouchRepo(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	sender := mocks.NewMockMessageSender(ctrl)
+	// ни язык, ни часовой пояс пользователя не запрашиваются: любой вызов модели провалит тест
+	purchasesModel := mocks.NewMockPurchasesModel(ctrl)
+	limiter := mocks.NewMockRateLimiter(ctrl)
+	model := New(sender, purchasesModel, nil, nil, limiter)
+
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassDefault).Return(ratelimit.Decision{RetryAfter: time.Second})
+
+	err := model.IncomingMessage(ctx, tg.Message{Text: "вчера такси 600", UserID: 123})
+	assert.NoError(t, err)
+
+	// язык нужен только для ответа о превышении
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassDefault).
+		Return(ratelimit.Decision{RetryAfter: time.Second, Notify: true})
+	purchasesModel.EXPECT().GetUserLang(gomock.Any(), int64(123)).Return(i18n.EN, nil)
+	sender.EXPECT().SendMessage("Too many requests in a row, let's take a short break. Please try again in 1 second", int64(123))
+
+	err = model.IncomingMessage(ctx, tg.Message{Text: "вчера такси 600", UserID: 123})
+	assert.NoError(t, err)
+}
+
+func Test_OnThrottledInlineQuery_ShouldAnswerEmpty(t *testing.T) {
+	ctx := context.Background()
+
+	sender, purchasesModel, _ := mocksUp(t)
+	limiter := mocks.NewMockRateLimiter(gomock.NewController(t))
+	model := New(sender, purchasesModel, nil, nil, limiter)
+
+	// у подсказок свое ограничение: они не тратят запросы обычных команд
+	limiter.EXPECT().Allow(gomock.Any(), int64(123), ratelimit.ClassInline).Return(ratelimit.Decision{RetryAfter: time.Second})
+	sender.EXPECT().AnswerInlineQuery("q1", nil)
+
+	err := model.IncomingInlineQuery(ctx, tg.InlineQuery{ID: "q1", Query: "кофе 250", UserID: 123})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/pkg/errors"
+	"golang.org/x/image/math/fixed"
+)
+
+// font шрифт TrueType, встраиваемый в документ как CID-шрифт с кодировкой Identity-H: в тексте записываются
+// номера глифов, а не символы. Чтобы текст из PDF можно было скопировать и найти, к шрифту прикладывается
+// таблица ToUnicode из глифов, которые встретились в документе
+type font struct {
+	ttf    []byte
+	parsed *truetype.Font
+	name   string
+	used   map[truetype.Index]rune // использованные глифы и символы, которые они изображают
+}
+
+func parseFont(ttf []byte) (*font, error) {
+	parsed, err := truetype.Parse(ttf)
+	if err != nil {
+		return nil, errors.Wrap(err, "truetype.Parse")
+	}
+
+	// в имени шрифта PDF допустимы не все символы
+	name := strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return -1
+		}
+		return r
+	}, parsed.Name(truetype.NameIDPostscriptName))
+	if name == "" {
+		name = "Font"
+	}
+
+	return &font{
+		ttf:    ttf,
+		parsed: parsed,
+		name:   name,
+		used:   make(map[truetype.Index]rune),
+	}, nil
+}
+
+func (f *font) glyph(r rune) truetype.Index {
+	return f.parsed.Index(r)
+}
+
+// width ширина глифа в тысячных долях кегля
+func (f *font) width(glyph truetype.Index) int {
+	return int(f.parsed.HMetric(fixed.Int26_6(1000), glyph).AdvanceWidth)
+}
+
+// encode текст в виде шестнадцатеричной строки номеров глифов
+func (f *font) encode(text string) string {
+	res := strings.Builder{}
+	for _, r := range text {
+		g := f.glyph(r)
+		if _, ok := f.used[g]; !ok {
+			f.used[g] = r
+		}
+		fmt.Fprintf(&res, "%04X", uint16(g))
+	}
+	return res.String()
+}
+
+func (f *font) write(w *writer) error {
+	glyphs := make([]truetype.Index, 0, len(f.used))
+	for g := range f.used {
+		glyphs = append(glyphs, g)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	widths := strings.Builder{}
+	for _, g := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", g, f.width(g))
+	}
+
+	b := f.parsed.Bounds(fixed.Int26_6(1000))
+	w.object(objFont, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+		"/DescendantFonts [%s] /ToUnicode %s >>", f.name, ref(objCIDFont), ref(objToUnicode)))
+	w.object(objCIDFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %s /W [%s] /CIDToGIDMap /Identity >>", f.name, ref(objFontDescriptor), widths.String()))
+	w.object(objFontDescriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 "+
+		"/FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %s >>",
+		f.name, b.Min.X, b.Min.Y, b.Max.X, b.Max.Y, b.Max.Y, b.Min.Y, b.Max.Y, ref(objFontFile)))
+
+	if err := w.stream(objFontFile, fmt.Sprintf("/Length1 %d", len(f.ttf)), f.ttf); err != nil {
+		return errors.Wrap(err, "write font file")
+	}
+	if err := w.stream(objToUnicode, "", f.toUnicode(glyphs)); err != nil {
+		return errors.Wrap(err, "write ToUnicode")
+	}
+	return nil
+}
+
+// toUnicodeChunk сколько соответствий можно записать в одном блоке bfchar
+const toUnicodeChunk = 100
+
+// toUnicode таблица соответствия глифов символам
+func (f *font) toUnicode(glyphs []truetype.Index) []byte {
+	res := strings.Builder{}
+	res.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	for start := 0; start < len(glyphs); start += toUnicodeChunk {
+		end := start + toUnicodeChunk
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+
+		fmt.Fprintf(&res, "%d beginbfchar\n", end-start)
+		for _, g := range glyphs[start:end] {
+			fmt.Fprintf(&res, "<%04X> <", uint16(g))
+			for _, c := range utf16.Encode([]rune{f.used[g]}) {
+				fmt.Fprintf(&res, "%04X", c)
+			}
+			res.WriteString(">\n")
+		}
+		res.WriteString("endbfchar\n")
+	}
+
+	res.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return []byte(res.String())
+}
@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+
+	// картинки для отчетов рисуются в PNG
+	_ "image/png"
+
+	"github.com/pkg/errors"
+)
+
+// Image картинка, добавленная в документ. Одну картинку можно нарисовать на нескольких страницах,
+// в файл она попадет один раз
+type Image struct {
+	id     int
+	width  int
+	height int
+	rgb    []byte
+}
+
+// AddImage добавляет в документ картинку в формате PNG. Прозрачные места закрашиваются белым
+func (d *Document) AddImage(raw []byte) (*Image, error) {
+	src, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, "image.Decode")
+	}
+
+	bounds := src.Bounds()
+	img := &Image{
+		id:     len(d.images) + 1,
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		rgb:    make([]byte, 0, 3*bounds.Dx()*bounds.Dy()),
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			img.rgb = append(img.rgb, overWhite(c.R, c.A), overWhite(c.G, c.A), overWhite(c.B, c.A))
+		}
+	}
+
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+// Size размер картинки в пикселях
+func (img *Image) Size() (int, int) {
+	return img.width, img.height
+}
+
+func (img *Image) write(w *writer, obj int) error {
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8",
+		img.width, img.height)
+	return w.stream(obj, dict, img.rgb)
+}
+
+// overWhite компонента цвета с прозрачностью alpha, наложенная на белый фон
+func overWhite(c, alpha uint8) uint8 {
+	return uint8((uint32(c)*uint32(alpha) + 255*(255-uint32(alpha))) / 255)
+}
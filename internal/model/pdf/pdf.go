@@ -0,0 +1,233 @@
+// Package pdf простой генератор PDF-документов: страницы A4, текст шрифтом TrueType, линии, прямоугольники
+// и картинки. Шрифт встраивается целиком, поэтому текст может быть на любом языке, который есть в шрифте.
+// Координаты задаются в пунктах (1/72 дюйма) от левого верхнего угла страницы
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// Размеры страницы A4 в пунктах
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Color цвет RGB, компоненты от 0 до 1
+type Color struct {
+	R, G, B float64
+}
+
+var (
+	Black     = Color{}
+	Gray      = Color{R: 0.45, G: 0.45, B: 0.45}
+	LightGray = Color{R: 0.92, G: 0.92, B: 0.92}
+)
+
+// Document PDF-документ. Страницы и картинки только добавляются, документ собирается в Bytes
+type Document struct {
+	title  string
+	font   *font
+	pages  []*Page
+	images []*Image
+}
+
+// New документ, текст в котором пишется шрифтом ttf
+func New(ttf []byte) (*Document, error) {
+	f, err := parseFont(ttf)
+	if err != nil {
+		return nil, errors.Wrap(err, "parseFont")
+	}
+	return &Document{font: f}, nil
+}
+
+// SetTitle заголовок документа, его показывают программы просмотра
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// AddPage добавляет в конец документа новую страницу
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// PageCount количество страниц в документе
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// TextWidth ширина текста размера size в пунктах
+func (d *Document) TextWidth(text string, size float64) float64 {
+	var width int
+	for _, r := range text {
+		width += d.font.width(d.font.glyph(r))
+	}
+	return float64(width) * size / 1000
+}
+
+// Page страница документа
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// Text пишет текст размера size, y - базовая линия текста
+func (p *Page) Text(x, y, size float64, color Color, text string) {
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s rg %s %s Td <%s> Tj ET\n",
+		num(size), rgb(color), num(x), num(PageHeight-y), p.doc.font.encode(text))
+}
+
+// Line рисует отрезок толщины width
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		rgb(color), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect рисует закрашенный прямоугольник, x и y - его левый верхний угол
+func (p *Page) Rect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n", rgb(color), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Image рисует картинку в прямоугольнике с левым верхним углом x, y
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", num(w), num(h), num(x), num(PageHeight-y-h), img.id)
+}
+
+// номера объектов шрифта, за ними идут картинки и страницы
+const (
+	objCatalog = iota + 1
+	objPages
+	objInfo
+	objFont
+	objCIDFont
+	objFontDescriptor
+	objFontFile
+	objToUnicode
+	objFirstFree
+)
+
+// Bytes собранный документ
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		return nil, errors.New("document has no pages")
+	}
+
+	firstImage := objFirstFree
+	// у каждой страницы два объекта: сама страница и ее содержимое
+	firstPage := firstImage + len(d.images)
+	w := newWriter(firstPage + 2*len(d.pages) - 1)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = ref(firstPage + 2*i)
+	}
+	w.object(objCatalog, fmt.Sprintf("<< /Type /Catalog /Pages %s >>", ref(objPages)))
+	w.object(objPages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(objInfo, fmt.Sprintf("<< /Title %s /Producer (financial-tg-bot) >>", textString(d.title)))
+
+	if err := d.font.write(w); err != nil {
+		return nil, errors.Wrap(err, "font.write")
+	}
+
+	resources := fmt.Sprintf("/Font << /F1 %s >>", ref(objFont))
+	if len(d.images) != 0 {
+		images := make([]string, len(d.images))
+		for i, img := range d.images {
+			images[i] = fmt.Sprintf("/Im%d %s", img.id, ref(firstImage+i))
+			if err := img.write(w, firstImage+i); err != nil {
+				return nil, errors.Wrap(err, "image.write")
+			}
+		}
+		resources += fmt.Sprintf(" /XObject << %s >>", strings.Join(images, " "))
+	}
+
+	for i, p := range d.pages {
+		obj := firstPage + 2*i
+		w.object(obj, fmt.Sprintf("<< /Type /Page /Parent %s /MediaBox [0 0 %s %s] /Resources << %s >> /Contents %s >>",
+			ref(objPages), num(PageWidth), num(PageHeight), resources, ref(obj+1)))
+		if err := w.stream(obj+1, "", p.content.Bytes()); err != nil {
+			return nil, errors.Wrap(err, "write page")
+		}
+	}
+
+	return w.finish(objCatalog, objInfo), nil
+}
+
+// writer пишет объекты PDF и запоминает их смещения для таблицы xref
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int // индекс - номер объекта
+}
+
+func newWriter(objects int) *writer {
+	w := &writer{offsets: make([]int, objects+1)}
+	// бинарный комментарий подсказывает программам, что в файле есть не только ASCII
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	return w
+}
+
+func (w *writer) object(n int, body string) {
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream объект-поток, сжатый zlib. dict - дополнительные ключи словаря потока
+func (w *writer) stream(n int, dict string, data []byte) error {
+	compressed := bytes.Buffer{}
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return errors.Wrap(err, "zlib.Write")
+	}
+	if err := zw.Close(); err != nil {
+		return errors.Wrap(err, "zlib.Close")
+	}
+
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", n, dict, compressed.Len())
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func (w *writer) finish(root, info int) []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets))
+	for _, offset := range w.offsets[1:] {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %s /Info %s >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets), ref(root), ref(info), xref)
+	return w.buf.Bytes()
+}
+
+func ref(n int) string {
+	return strconv.Itoa(n) + " 0 R"
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func rgb(c Color) string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+// textString строка PDF в UTF-16BE: в обычных строках PDF нет кириллицы
+func textString(s string) string {
+	res := strings.Builder{}
+	res.WriteString("<FEFF")
+	for _, c := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&res, "%04X", c)
+	}
+	res.WriteString(">")
+	return res.String()
+}
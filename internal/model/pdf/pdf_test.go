@@ -0,0 +1,190 @@
+//go:build test_all || unit_test
+
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	reader "github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wcharczuk/go-chart/v2/roboto"
+)
+
+// parse объекты документа по таблице xref: проверяет, что каждое смещение указывает на начало своего объекта
+func parse(t *testing.T, doc []byte) map[int]string {
+	t.Helper()
+
+	require.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(doc[xref:], []byte("xref\n0 ")))
+
+	lines := strings.Split(string(doc[xref:]), "\n")
+	size, err := strconv.Atoi(strings.TrimPrefix(lines[1], "0 "))
+	require.NoError(t, err)
+
+	objects := make(map[int]string, size-1)
+	for n := 1; n < size; n++ {
+		offset, err := strconv.Atoi(lines[2+n][:10])
+		require.NoError(t, err)
+
+		header := fmt.Sprintf("%d 0 obj\n", n)
+		require.True(t, strings.HasPrefix(string(doc[offset:]), header), "object %d", n)
+		body := string(doc[offset+len(header):])
+		objects[n] = body[:strings.Index(body, "\nendobj\n")]
+	}
+	return objects
+}
+
+// streamData распакованное содержимое объекта-потока
+func streamData(t *testing.T, obj string) []byte {
+	t.Helper()
+
+	start := strings.Index(obj, "stream\n")
+	end := strings.LastIndex(obj, "\nendstream")
+	require.True(t, start >= 0 && end > start)
+
+	zr, err := zlib.NewReader(strings.NewReader(obj[start+len("stream\n") : end]))
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return data
+}
+
+func newDocument(t *testing.T) *Document {
+	t.Helper()
+
+	doc, err := New(roboto.Roboto)
+	require.NoError(t, err)
+	return doc
+}
+
+func Test_Document_Bytes(t *testing.T) {
+	doc := newDocument(t)
+	doc.SetTitle("Отчет")
+	first := doc.AddPage()
+	first.Text(40, 60, 12, Black, "Привет")
+	first.Rect(40, 80, 100, 20, LightGray)
+	doc.AddPage().Line(40, 100, 200, 100, 1, Gray)
+
+	raw, err := doc.Bytes()
+	require.NoError(t, err)
+
+	objects := parse(t, raw)
+	assert.Equal(t, 2, doc.PageCount())
+	assert.Contains(t, objects[objPages], "/Count 2")
+	assert.Contains(t, objects[objInfo], "/Title <FEFF041E0442044704350442>")
+
+	// координата y отсчитывается от нижнего края страницы
+	content := string(streamData(t, objects[objFirstFree+1]))
+	assert.Contains(t, content, "BT /F1 12.00 Tf 0.00 0.00 0.00 rg 40.00 781.89 Td <")
+	assert.Contains(t, content, "0.92 0.92 0.92 rg 40.00 741.89 100.00 20.00 re f")
+	assert.Contains(t, string(streamData(t, objects[objFirstFree+3])), "40.00 741.89 m 200.00 741.89 l S")
+}
+
+func Test_Document_ToUnicode(t *testing.T) {
+	doc := newDocument(t)
+	doc.AddPage().Text(0, 0, 10, Black, "Ё₽")
+
+	raw, err := doc.Bytes()
+	require.NoError(t, err)
+	objects := parse(t, raw)
+
+	// в тексте номера глифов, а по ToUnicode из них восстанавливаются символы
+	yo, rub := doc.font.glyph('Ё'), doc.font.glyph('₽')
+	assert.NotZero(t, yo)
+	assert.NotZero(t, rub)
+	assert.Contains(t, string(streamData(t, objects[objFirstFree+1])), fmt.Sprintf("<%04X%04X> Tj", yo, rub))
+
+	cmap := string(streamData(t, objects[objToUnicode]))
+	assert.Contains(t, cmap, "2 beginbfchar\n")
+	assert.Contains(t, cmap, fmt.Sprintf("<%04X> <0401>\n", yo))
+	assert.Contains(t, cmap, fmt.Sprintf("<%04X> <20BD>\n", rub))
+	assert.Contains(t, objects[objCIDFont], fmt.Sprintf("%d [%d]", yo, doc.font.width(yo)))
+}
+
+func Test_Document_NoPages(t *testing.T) {
+	_, err := newDocument(t).Bytes()
+	assert.Error(t, err)
+}
+
+func Test_TextWidth(t *testing.T) {
+	doc := newDocument(t)
+
+	assert.Zero(t, doc.TextWidth("", 10))
+	assert.Greater(t, doc.TextWidth("Ш", 10), doc.TextWidth("i", 10))
+	assert.InDelta(t, doc.TextWidth("Ш", 10)+doc.TextWidth("i", 10), doc.TextWidth("Шi", 10), 1e-9)
+	assert.InDelta(t, 2*doc.TextWidth("Шi", 10), doc.TextWidth("Шi", 20), 1e-9)
+}
+
+func Test_AddImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	// полупрозрачный черный на белом фоне - серый
+	src.Set(1, 0, color.NRGBA{A: 128})
+	raw := bytes.Buffer{}
+	require.NoError(t, png.Encode(&raw, src))
+
+	doc := newDocument(t)
+	img, err := doc.AddImage(raw.Bytes())
+	require.NoError(t, err)
+	doc.AddPage().Image(img, 10, 20, 200, 100)
+
+	w, h := img.Size()
+	assert.Equal(t, 2, w)
+	assert.Equal(t, 1, h)
+
+	res, err := doc.Bytes()
+	require.NoError(t, err)
+	objects := parse(t, res)
+
+	assert.Contains(t, objects[objFirstFree], "/Width 2 /Height 1")
+	assert.Equal(t, []byte{255, 0, 0, 127, 127, 127}, streamData(t, objects[objFirstFree]))
+	page := objects[objFirstFree+1]
+	assert.Contains(t, page, fmt.Sprintf("/XObject << /Im1 %s >>", ref(objFirstFree)))
+	assert.Contains(t, string(streamData(t, objects[objFirstFree+2])), "q 200.00 0 0 100.00 10.00 721.89 cm /Im1 Do Q")
+
+	_, err = doc.AddImage([]byte("не картинка"))
+	assert.Error(t, err)
+}
+
+// Test_Document_Reader документ открывается сторонней библиотекой чтения PDF, и текст из него извлекается
+func Test_Document_Reader(t *testing.T) {
+	doc := newDocument(t)
+	doc.SetTitle("Отчет")
+	doc.AddPage().Text(40, 60, 12, Black, "Траты с 01.11.2022")
+	second := doc.AddPage()
+	second.Text(40, 60, 12, Black, "Кафе ₽")
+	second.Rect(40, 80, 100, 20, LightGray)
+
+	raw, err := doc.Bytes()
+	require.NoError(t, err)
+
+	r, err := reader.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	require.NoError(t, err)
+	require.Equal(t, 2, r.NumPage())
+
+	for i, want := range []string{"Траты с 01.11.2022", "Кафе ₽"} {
+		page := r.Page(i + 1)
+		require.False(t, page.V.IsNull(), "page %d", i+1)
+
+		text, err := page.GetPlainText(nil)
+		require.NoError(t, err)
+		assert.Equal(t, want, text)
+	}
+}
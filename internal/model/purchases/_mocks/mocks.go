@@ -10,7 +10,9 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	anomaly "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
 	currency "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	i18n "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	purchases "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 )
 
@@ -51,6 +53,20 @@ func (mr *MockRepoMockRecorder) AddCategory(ctx, categoryName interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategory", reflect.TypeOf((*MockRepo)(nil).AddCategory), ctx, categoryName)
 }
 
+// AddCategoryRule mocks base method.
+func (m *MockRepo) AddCategoryRule(ctx context.Context, rule purchases.CategoryRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCategoryRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCategoryRule indicates an expected call of AddCategoryRule.
+func (mr *MockRepoMockRecorder) AddCategoryRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategoryRule", reflect.TypeOf((*MockRepo)(nil).AddCategoryRule), ctx, rule)
+}
+
 // AddCategoryToUser mocks base method.
 func (m *MockRepo) AddCategoryToUser(ctx context.Context, userID int64, catName string) error {
 	m.ctrl.T.Helper()
@@ -93,6 +109,20 @@ func (mr *MockRepoMockRecorder) AddRate(ctx, y, m, d, rates interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRate", reflect.TypeOf((*MockRepo)(nil).AddRate), ctx, y, m, d, rates)
 }
 
+// ChangeConfirmUnusual mocks base method.
+func (m *MockRepo) ChangeConfirmUnusual(ctx context.Context, userID int64, confirm bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeConfirmUnusual", ctx, userID, confirm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeConfirmUnusual indicates an expected call of ChangeConfirmUnusual.
+func (mr *MockRepoMockRecorder) ChangeConfirmUnusual(ctx, userID, confirm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeConfirmUnusual", reflect.TypeOf((*MockRepo)(nil).ChangeConfirmUnusual), ctx, userID, confirm)
+}
+
 // ChangeCurrency mocks base method.
 func (m *MockRepo) ChangeCurrency(ctx context.Context, userID int64, currency currency.Currency) error {
 	m.ctrl.T.Helper()
@@ -107,6 +137,62 @@ func (mr *MockRepoMockRecorder) ChangeCurrency(ctx, userID, currency interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeCurrency", reflect.TypeOf((*MockRepo)(nil).ChangeCurrency), ctx, userID, currency)
 }
 
+// ChangeDigest mocks base method.
+func (m *MockRepo) ChangeDigest(ctx context.Context, userID int64, period string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeDigest", ctx, userID, period)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeDigest indicates an expected call of ChangeDigest.
+func (mr *MockRepoMockRecorder) ChangeDigest(ctx, userID, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeDigest", reflect.TypeOf((*MockRepo)(nil).ChangeDigest), ctx, userID, period)
+}
+
+// ChangeLang mocks base method.
+func (m *MockRepo) ChangeLang(ctx context.Context, userID int64, lang i18n.Lang) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeLang", ctx, userID, lang)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeLang indicates an expected call of ChangeLang.
+func (mr *MockRepoMockRecorder) ChangeLang(ctx, userID, lang interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeLang", reflect.TypeOf((*MockRepo)(nil).ChangeLang), ctx, userID, lang)
+}
+
+// ChangeReminder mocks base method.
+func (m *MockRepo) ChangeReminder(ctx context.Context, userID int64, remindAt int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeReminder", ctx, userID, remindAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeReminder indicates an expected call of ChangeReminder.
+func (mr *MockRepoMockRecorder) ChangeReminder(ctx, userID, remindAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeReminder", reflect.TypeOf((*MockRepo)(nil).ChangeReminder), ctx, userID, remindAt)
+}
+
+// ChangeTimezone mocks base method.
+func (m *MockRepo) ChangeTimezone(ctx context.Context, userID int64, timezone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeTimezone", ctx, userID, timezone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeTimezone indicates an expected call of ChangeTimezone.
+func (mr *MockRepoMockRecorder) ChangeTimezone(ctx, userID, timezone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeTimezone", reflect.TypeOf((*MockRepo)(nil).ChangeTimezone), ctx, userID, timezone)
+}
+
 // ChangeUserLimit mocks base method.
 func (m *MockRepo) ChangeUserLimit(ctx context.Context, userID int64, newLimit float64) error {
 	m.ctrl.T.Helper()
@@ -151,6 +237,36 @@ func (mr *MockRepoMockRecorder) GetCategoryID(ctx, categoryName interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryID", reflect.TypeOf((*MockRepo)(nil).GetCategoryID), ctx, categoryName)
 }
 
+// GetCategoryStats mocks base method.
+func (m *MockRepo) GetCategoryStats(ctx context.Context, userID int64, categoryID uint64) (anomaly.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryStats", ctx, userID, categoryID)
+	ret0, _ := ret[0].(anomaly.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryStats indicates an expected call of GetCategoryStats.
+func (mr *MockRepoMockRecorder) GetCategoryStats(ctx, userID, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryStats", reflect.TypeOf((*MockRepo)(nil).GetCategoryStats), ctx, userID, categoryID)
+}
+
+// GetDigestSubscriptions mocks base method.
+func (m *MockRepo) GetDigestSubscriptions(ctx context.Context) ([]purchases.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigestSubscriptions", ctx)
+	ret0, _ := ret[0].([]purchases.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigestSubscriptions indicates an expected call of GetDigestSubscriptions.
+func (mr *MockRepoMockRecorder) GetDigestSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigestSubscriptions", reflect.TypeOf((*MockRepo)(nil).GetDigestSubscriptions), ctx)
+}
+
 // GetRate mocks base method.
 func (m_2 *MockRepo) GetRate(ctx context.Context, y, m, d int) (bool, currency.RateToRUB, error) {
 	m_2.ctrl.T.Helper()
@@ -167,6 +283,21 @@ func (mr *MockRepoMockRecorder) GetRate(ctx, y, m, d interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRate", reflect.TypeOf((*MockRepo)(nil).GetRate), ctx, y, m, d)
 }
 
+// GetReminders mocks base method.
+func (m *MockRepo) GetReminders(ctx context.Context) ([]purchases.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminders", ctx)
+	ret0, _ := ret[0].([]purchases.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminders indicates an expected call of GetReminders.
+func (mr *MockRepoMockRecorder) GetReminders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminders", reflect.TypeOf((*MockRepo)(nil).GetReminders), ctx)
+}
+
 // GetUserCategories mocks base method.
 func (m *MockRepo) GetUserCategories(ctx context.Context, userID int64) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -182,6 +313,51 @@ func (mr *MockRepoMockRecorder) GetUserCategories(ctx, userID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCategories", reflect.TypeOf((*MockRepo)(nil).GetUserCategories), ctx, userID)
 }
 
+// GetUserCategorizedPurchases mocks base method.
+func (m *MockRepo) GetUserCategorizedPurchases(ctx context.Context, userID int64, limit uint64) ([]purchases.CategorizedPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCategorizedPurchases", ctx, userID, limit)
+	ret0, _ := ret[0].([]purchases.CategorizedPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCategorizedPurchases indicates an expected call of GetUserCategorizedPurchases.
+func (mr *MockRepoMockRecorder) GetUserCategorizedPurchases(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCategorizedPurchases", reflect.TypeOf((*MockRepo)(nil).GetUserCategorizedPurchases), ctx, userID, limit)
+}
+
+// GetUserCategoryRules mocks base method.
+func (m *MockRepo) GetUserCategoryRules(ctx context.Context, userID int64) ([]purchases.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCategoryRules", ctx, userID)
+	ret0, _ := ret[0].([]purchases.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCategoryRules indicates an expected call of GetUserCategoryRules.
+func (mr *MockRepoMockRecorder) GetUserCategoryRules(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCategoryRules", reflect.TypeOf((*MockRepo)(nil).GetUserCategoryRules), ctx, userID)
+}
+
+// GetUserDailySums mocks base method.
+func (m *MockRepo) GetUserDailySums(ctx context.Context, userID int64, from, to time.Time) ([]purchases.DailySum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDailySums", ctx, userID, from, to)
+	ret0, _ := ret[0].([]purchases.DailySum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDailySums indicates an expected call of GetUserDailySums.
+func (mr *MockRepoMockRecorder) GetUserDailySums(ctx, userID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDailySums", reflect.TypeOf((*MockRepo)(nil).GetUserDailySums), ctx, userID, from, to)
+}
+
 // GetUserInfo mocks base method.
 func (m *MockRepo) GetUserInfo(ctx context.Context, userID int64) (purchases.User, error) {
 	m.ctrl.T.Helper()
@@ -227,6 +403,65 @@ func (mr *MockRepoMockRecorder) GetUserPurchasesSumFromMonth(ctx, userID, fromDa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPurchasesSumFromMonth", reflect.TypeOf((*MockRepo)(nil).GetUserPurchasesSumFromMonth), ctx, userID, fromDate)
 }
 
+// GetUserTaggedPurchases mocks base method.
+func (m *MockRepo) GetUserTaggedPurchases(ctx context.Context, userID int64) ([]purchases.TaggedPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTaggedPurchases", ctx, userID)
+	ret0, _ := ret[0].([]purchases.TaggedPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTaggedPurchases indicates an expected call of GetUserTaggedPurchases.
+func (mr *MockRepoMockRecorder) GetUserTaggedPurchases(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTaggedPurchases", reflect.TypeOf((*MockRepo)(nil).GetUserTaggedPurchases), ctx, userID)
+}
+
+// HasUserPurchasesFrom mocks base method.
+func (m *MockRepo) HasUserPurchasesFrom(ctx context.Context, userID int64, fromDate time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUserPurchasesFrom", ctx, userID, fromDate)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUserPurchasesFrom indicates an expected call of HasUserPurchasesFrom.
+func (mr *MockRepoMockRecorder) HasUserPurchasesFrom(ctx, userID, fromDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUserPurchasesFrom", reflect.TypeOf((*MockRepo)(nil).HasUserPurchasesFrom), ctx, userID, fromDate)
+}
+
+// SaveCategoryStats mocks base method.
+func (m *MockRepo) SaveCategoryStats(ctx context.Context, userID int64, categoryID uint64, stats anomaly.Stats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCategoryStats", ctx, userID, categoryID, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCategoryStats indicates an expected call of SaveCategoryStats.
+func (mr *MockRepoMockRecorder) SaveCategoryStats(ctx, userID, categoryID, stats interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCategoryStats", reflect.TypeOf((*MockRepo)(nil).SaveCategoryStats), ctx, userID, categoryID, stats)
+}
+
+// SearchPurchases mocks base method.
+func (m *MockRepo) SearchPurchases(ctx context.Context, filter purchases.SearchFilter, limit, offset uint64) ([]purchases.FoundPurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPurchases", ctx, filter, limit, offset)
+	ret0, _ := ret[0].([]purchases.FoundPurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPurchases indicates an expected call of SearchPurchases.
+func (mr *MockRepoMockRecorder) SearchPurchases(ctx, filter, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPurchases", reflect.TypeOf((*MockRepo)(nil).SearchPurchases), ctx, filter, limit, offset)
+}
+
 // UserCreateIfNotExist mocks base method.
 func (m *MockRepo) UserCreateIfNotExist(ctx context.Context, userID int64) error {
 	m.ctrl.T.Helper()
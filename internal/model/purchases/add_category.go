@@ -30,3 +30,36 @@ func (m *Model) GetAllCategories(ctx context.Context) ([]CategoryRow, error) {
 	}
 	return res, nil
 }
+
+// AddUserCategory добавляет категорию пользователю, при необходимости создавая ее. Повторный вызов ничего не меняет
+func (m *Model) AddUserCategory(ctx context.Context, userID int64, category string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "add user category")
+	defer span.Finish()
+
+	catName := normalize.Category(category)
+	categoryID, err := m.Repo.GetCategoryID(ctx, catName)
+	if err != nil {
+		return errors.Wrap(err, "repo.GetCategoryID")
+	}
+	if categoryID == 0 {
+		if err = m.Repo.AddCategory(ctx, catName); err != nil {
+			return errors.Wrap(err, "repo.AddCategory")
+		}
+		if categoryID, err = m.Repo.GetCategoryID(ctx, catName); err != nil {
+			return errors.Wrap(err, "repo.GetCategoryID")
+		}
+	}
+
+	has, err := m.Repo.UserHasCategory(ctx, userID, categoryID)
+	if err != nil {
+		return errors.Wrap(err, "repo.UserHasCategory")
+	}
+	if has {
+		return nil
+	}
+
+	if err = m.Repo.AddCategoryToUser(ctx, userID, catName); err != nil {
+		return errors.Wrap(err, "repo.AddCategoryToUser")
+	}
+	return nil
+}
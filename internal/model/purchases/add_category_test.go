@@ -0,0 +1,50 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_AddUserCategory(t *testing.T) {
+	t.Run("категории еще нет", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		gomock.InOrder(
+			repo.EXPECT().GetCategoryID(gomock.Any(), "Кафе").Return(uint64(0), nil),
+			repo.EXPECT().AddCategory(gomock.Any(), "Кафе").Return(nil),
+			repo.EXPECT().GetCategoryID(gomock.Any(), "Кафе").Return(uint64(5), nil),
+			repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(5)).Return(false, nil),
+			repo.EXPECT().AddCategoryToUser(gomock.Any(), int64(123), "Кафе").Return(nil),
+		)
+
+		err := model.AddUserCategory(ctx, 123, "Кафе")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("категория уже есть у пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		repo.EXPECT().GetCategoryID(gomock.Any(), "Кафе").Return(uint64(5), nil)
+		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(5)).Return(true, nil)
+
+		err := model.AddUserCategory(ctx, 123, "Кафе")
+
+		assert.NoError(t, err)
+	})
+}
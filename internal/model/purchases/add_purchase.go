@@ -10,14 +10,26 @@ import (
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/normalize"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"go.uber.org/zap"
 )
 
+// defaultCategoryID id категории, в которую попадают траты без указанной категории
+const defaultCategoryID = 1
+
 // AddPurchaseReq тело запроса в Repo для добавления траты
 type AddPurchaseReq struct {
 	UserID     int64
 	Sum        float64
 	CategoryID uint64
 	Date       time.Time
+	// исходный текст, который пользователь указал вместо категории
+	Description string
+	// заметка и хэштеги (без решетки) к трате
+	Note string
+	Tags []string
+	// Currency валюта, в которой пользователь заплатил, сумма Sum уже переведена в рубли
+	Currency currency.Currency
 
 	// коэффициенты валют на момент совершения траты
 	USDRatio float64
@@ -36,15 +48,82 @@ type ExpensesAndLimit struct {
 	Expenses      float64           // сколько он уже потратил за месяц (если лимит установлен) (в выбранной валюте)
 	Currency      currency.Currency // выбранная валюта
 	LimitExceeded bool              // превышен ли лимит
+	// Unusual трата намного больше обычной для своей категории, nil - обычная трата.
+	// Вместе с ErrUnusualPurchase - трата не записана и ждет подтверждения
+	Unusual *UnusualPurchase
+	// Forecast прогноз трат на конец месяца с учетом траты, nil - лимита нет или прогноз не удалось посчитать
+	Forecast *MonthForecast
+}
+
+// AddPurchaseOption дополнительный параметр добавления траты
+type AddPurchaseOption func(*addPurchaseOptions)
+
+type addPurchaseOptions struct {
+	currency    currency.Currency
+	hasCurrency bool
+	tags        []string
+	note        string
+	confirmed   bool
+
+	description    string
+	hasDescription bool
+}
+
+// WithCurrency сумма траты указана в валюте c, а не в выбранной пользователем валюте
+func WithCurrency(c currency.Currency) AddPurchaseOption {
+	return func(o *addPurchaseOptions) {
+		o.currency = c
+		o.hasCurrency = true
+	}
+}
+
+// WithTags хэштеги траты, по ним потом можно строить отчеты
+func WithTags(tags ...string) AddPurchaseOption {
+	return func(o *addPurchaseOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithNote произвольная заметка к трате
+func WithNote(note string) AddPurchaseOption {
+	return func(o *addPurchaseOptions) {
+		o.note = note
+	}
+}
+
+// WithDescription исходный текст траты, когда категория выбрана отдельно от него, например кнопкой.
+// Без этой опции описанием траты становится сам текст category
+func WithDescription(description string) AddPurchaseOption {
+	return func(o *addPurchaseOptions) {
+		o.description = description
+		o.hasDescription = true
+	}
+}
+
+// Confirmed пользователь подтвердил необычно крупную трату, ее можно записать без вопросов
+func Confirmed() AddPurchaseOption {
+	return func(o *addPurchaseOptions) {
+		o.confirmed = true
+	}
 }
 
 // AddPurchase добавляет трату.
 // Если category пустой, трата будет добавлена без категории.
+// Если категории category не существует, она подбирается по правилам пользователя.
+// Текст category всегда сохраняется в описании траты, по нему трату потом можно найти.
 // Если rawDate пустой, для траты будет выставлена текущая дата.
-func (m *Model) AddPurchase(ctx context.Context, userID int64, rawSum, category, rawDate string) (ExpensesAndLimit, error) {
+// По умолчанию сумма считается указанной в выбранной пользователем валюте.
+// Если трата намного больше обычной для категории, а пользователь просил спрашивать о таких тратах,
+// она не записывается: возвращается ErrUnusualPurchase, а в Unusual - обычная сумма траты в категории.
+func (m *Model) AddPurchase(ctx context.Context, userID int64, rawSum, category, rawDate string, opts ...AddPurchaseOption) (ExpensesAndLimit, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "add purchase")
 	defer span.Finish()
 
+	var options addPurchaseOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var (
 		sumCurrency float64
 		date        time.Time
@@ -57,33 +136,26 @@ func (m *Model) AddPurchase(ctx context.Context, userID int64, rawSum, category,
 	}
 
 	// получаем id категории которую выбрал пользователь и проверяем что такая категория существует
-	var categoryID uint64
-	if category != "" {
-		category = strings.ToLower(category)
-		categoryID, err = m.Repo.GetCategoryID(ctx, normalize.Category(category))
-		if err != nil {
-			return ExpensesAndLimit{}, errors.Wrap(err, "repo.GetCategoryID")
-		}
-		if categoryID == 0 {
-			return ExpensesAndLimit{}, ErrCategoryNotExist
-		}
+	categoryID, err := m.userCategoryID(ctx, userID, category, sumCurrency)
+	if err != nil {
+		return ExpensesAndLimit{}, err
+	}
+	description := strings.TrimSpace(category)
+	if options.hasDescription {
+		description = strings.TrimSpace(options.description)
+	}
 
-		// проверяем, создана ли такая категория у юзера
-		has, err := m.Repo.UserHasCategory(ctx, userID, categoryID)
-		if err != nil {
-			return ExpensesAndLimit{}, errors.Wrap(err, "repo.UserHasCategory")
-		}
-		if !has {
-			return ExpensesAndLimit{}, ErrUserHasntCategory
-		}
-	} else {
-		categoryID = 1
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return ExpensesAndLimit{}, errors.Wrap(err, "repo.GetUserInfo")
 	}
+	// даты трат и границы месяца для лимита считаются в часовом поясе пользователя
+	now := m.userNow(info)
 
 	// переводим сумму траты которую он ввел в рубли
 	rates := currency.RateToRUB{}
 	if rawDate != "" {
-		date, err = time.Parse("02.01.2006", rawDate)
+		date, err = time.ParseInLocation("02.01.2006", rawDate, now.Location())
 		if err != nil {
 			return ExpensesAndLimit{}, errors.Wrap(ErrInvalidDate, "parsing err")
 		}
@@ -98,46 +170,120 @@ func (m *Model) AddPurchase(ctx context.Context, userID int64, rawSum, category,
 			return ExpensesAndLimit{}, errors.Wrap(err, "getTodayRates")
 		}
 	} else {
-		date = time.Now()
+		date = now
 		rates = m.ExchangeRatesModel.GetExchangeRateToRUB()
 	}
 
-	info, err := m.Repo.GetUserInfo(ctx, userID)
-	if err != nil {
-		return ExpensesAndLimit{}, errors.Wrap(err, "repo.GetUserInfo")
+	purchaseCurrency := info.Currency
+	if options.hasCurrency {
+		purchaseCurrency = options.currency
 	}
 
-	sumRUB, err := currency.ToRUB(info.Currency, sumCurrency, rates)
+	sumRUB, err := currency.ToRUB(purchaseCurrency, sumCurrency, rates)
 	if err != nil {
 		return ExpensesAndLimit{}, errors.Wrap(err, "toRUB")
 	}
 
+	// для подсчета лимита нужна сумма в валюте пользователя
+	if purchaseCurrency != info.Currency {
+		sumCurrency, err = currency.RubToCurrentCurrency(info.Currency, sumRUB, rates)
+		if err != nil {
+			return ExpensesAndLimit{}, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+	}
+
+	stats, unusual, hasStats := m.checkUnusual(ctx, userID, categoryID, sumRUB, now, info.Currency, rates)
+	if unusual != nil && info.ConfirmUnusual && !options.confirmed {
+		return ExpensesAndLimit{Currency: info.Currency, Unusual: unusual}, ErrUnusualPurchase
+	}
+	if options.confirmed {
+		// пользователь уже видел предупреждение
+		unusual = nil
+	}
+
 	// определяем превышен ли лимит и сколько потрачено за этот календарный месяц
-	expAndLim, err := m.getExpensesAndLimit(ctx, userID, info.Currency, info.Limit, sumCurrency, rates)
+	expAndLim, err := m.getExpensesAndLimit(ctx, userID, now, info.Currency, info.Limit, sumCurrency, rates)
 	if err != nil {
 		return ExpensesAndLimit{}, errors.Wrap(err, "getExpensesAndLimit")
 	}
+	expAndLim.Unusual = unusual
 
 	if err = m.Repo.AddPurchase(ctx, AddPurchaseReq{
-		UserID:     userID,
-		Sum:        sumRUB,
-		CategoryID: categoryID,
-		Date:       date,
-		CNYRatio:   rates.CNY,
-		EURRatio:   rates.EUR,
-		USDRatio:   rates.USD,
+		UserID:      userID,
+		Sum:         sumRUB,
+		CategoryID:  categoryID,
+		Date:        date,
+		Description: description,
+		Note:        options.note,
+		Tags:        options.tags,
+		Currency:    purchaseCurrency,
+		CNYRatio:    rates.CNY,
+		EURRatio:    rates.EUR,
+		USDRatio:    rates.USD,
 	}); err != nil {
 		return ExpensesAndLimit{}, errors.Wrap(err, "repo.AddPurchase")
 	}
+	if hasStats {
+		m.updateStats(ctx, userID, categoryID, stats, sumRUB, date, now)
+	}
 
 	// если не удалить отчет уже устаревший отчет, то при создании отчета нужно будет проверять,
 	// что дата последней совершенной траты не свежее чем дата создания отчета. В таком случае
 	// использование кеша будет абсолютно неэффективным, так как все равно придется сделать запрос в бд
 	m.ReportsStore.Delete(ctx, createKeyForReportsStore(userID)) // nolint: errcheck
 
+	// прогноз показывается вместе с лимитом. Трата уже записана, поэтому без прогноза она все равно добавлена
+	if info.Limit != -1 {
+		todayRates := rates
+		if rawDate != "" {
+			todayRates = m.ExchangeRatesModel.GetExchangeRateToRUB()
+		}
+		f, err := m.monthForecast(ctx, info, now, todayRates)
+		if err != nil {
+			logs.Error("forecast failed", zap.Error(err), zap.Int64("userId", userID))
+		} else {
+			expAndLim.Forecast = &f
+		}
+	}
+
 	return expAndLim, nil
 }
 
+// userCategoryID id категории траты: сама категория category, а если такой нет - подобранная по правилам пользователя.
+// Пустая категория - трата без категории. Ошибка ErrCategoryNotExist, если категорию подобрать
+// не удалось, и ErrUserHasntCategory, если она не добавлена пользователю
+func (m *Model) userCategoryID(ctx context.Context, userID int64, category string, sum float64) (uint64, error) {
+	if category == "" {
+		return defaultCategoryID, nil
+	}
+
+	categoryID, err := m.Repo.GetCategoryID(ctx, normalize.Category(strings.ToLower(category)))
+	if err != nil {
+		return 0, errors.Wrap(err, "repo.GetCategoryID")
+	}
+	if categoryID == 0 {
+		// такой категории нет, пробуем подобрать ее по правилам пользователя
+		categoryID, err = m.categoryIDByRules(ctx, userID, strings.TrimSpace(category), sum)
+		if err != nil {
+			return 0, errors.Wrap(err, "categoryIDByRules")
+		}
+	}
+	if categoryID == 0 {
+		return 0, ErrCategoryNotExist
+	}
+
+	// проверяем, создана ли такая категория у юзера
+	has, err := m.Repo.UserHasCategory(ctx, userID, categoryID)
+	if err != nil {
+		return 0, errors.Wrap(err, "repo.UserHasCategory")
+	}
+	if !has {
+		return 0, ErrUserHasntCategory
+	}
+
+	return categoryID, nil
+}
+
 func RawDateToYMD(rawDate string) (year, month, day int, err error) {
 	t := strings.Split(rawDate, ".")
 	if len(t) != 3 {
@@ -161,10 +307,11 @@ func RawDateToYMD(rawDate string) (year, month, day int, err error) {
 	return int(y1), int(m1), int(d1), nil
 }
 
-func (m *Model) getExpensesAndLimit(ctx context.Context, userID int64, userCurrency currency.Currency, userLimit float64, purchaseSum float64, rates currency.RateToRUB) (ExpensesAndLimit, error) {
+// getExpensesAndLimit считает траты за календарный месяц, в который попадает now (текущее время в часовом поясе пользователя)
+func (m *Model) getExpensesAndLimit(ctx context.Context, userID int64, now time.Time, userCurrency currency.Currency, userLimit float64, purchaseSum float64, rates currency.RateToRUB) (ExpensesAndLimit, error) {
 	expAndLim := ExpensesAndLimit{Limit: userLimit}
 	// получаем траты юзера за текущий месяц в рублях
-	expRUB, err := m.Repo.GetUserPurchasesSumFromMonth(ctx, userID, time.Now())
+	expRUB, err := m.Repo.GetUserPurchasesSumFromMonth(ctx, userID, now)
 	if err != nil {
 		return ExpensesAndLimit{}, errors.Wrap(err, "repo.GetUserPurchasesSumFromMonth")
 	}
@@ -5,10 +5,13 @@ package purchases_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
 )
@@ -123,7 +126,12 @@ func Test_AddPurchase_SumAndCategory(t *testing.T) {
 			Limit:    -1,
 		}, nil)
 		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(100), nil)
-		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req purchases.AddPurchaseReq) error {
+				// исходный текст сохраняется всегда, даже если он совпал с категорией
+				assert.Equal(t, "some category", req.Description)
+				return nil
+			})
 		redis.EXPECT().Delete(gomock.Any(), "123report")
 
 		res, err := model.AddPurchase(ctx, 123, "234.5", "some category", "")
@@ -148,9 +156,83 @@ func Test_AddPurchase_SumAndCategory(t *testing.T) {
 		model := purchases.New(repo, excRateModel, redis, nil)
 
 		repo.EXPECT().GetCategoryID(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+		repo.EXPECT().GetUserCategoryRules(gomock.Any(), int64(123)).Return(nil, nil)
 
 		_, err := model.AddPurchase(ctx, 123, "234.5", "some category", "")
-		assert.Error(t, err, purchases.ErrCategoryNotExist)
+		assert.ErrorIs(t, err, purchases.ErrCategoryNotExist)
+	})
+
+	t.Run("категория не существует, но подобрана по правилу пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+
+		repo.EXPECT().GetCategoryID(gomock.Any(), "Пятёрочка").Return(uint64(0), nil)
+		repo.EXPECT().GetUserCategoryRules(gomock.Any(), int64(123)).Return([]purchases.CategoryRule{
+			{UserID: 123, Type: purchases.RuleTypeSubstring, Pattern: "пятёрочка", CategoryID: 5},
+		}, nil)
+		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(5)).Return(true, nil)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{
+			USD: 1,
+			EUR: 1,
+			CNY: 1,
+		})
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.RUB,
+			Limit:    -1,
+		}, nil)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(0), nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req purchases.AddPurchaseReq) error {
+				assert.Equal(t, uint64(5), req.CategoryID)
+				assert.Equal(t, "Пятёрочка", req.Description)
+				return nil
+			})
+		// статистика обновляется для подобранной категории
+		repo.EXPECT().GetCategoryStats(gomock.Any(), int64(123), uint64(5)).Return(anomaly.Stats{}, nil)
+		repo.EXPECT().SaveCategoryStats(gomock.Any(), int64(123), uint64(5), gomock.Any()).Return(nil)
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		_, err := model.AddPurchase(ctx, 123, "450", "Пятёрочка", "")
+		assert.NoError(t, err)
+	})
+	t.Run("категория выбрана кнопкой, описание - исходный текст", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+
+		repo.EXPECT().GetCategoryID(gomock.Any(), "Кафе").Return(uint64(2), nil)
+		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(2)).Return(true, nil)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{USD: 1, EUR: 1, CNY: 1})
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.RUB,
+			Limit:    -1,
+		}, nil)
+		repo.EXPECT().GetCategoryStats(gomock.Any(), int64(123), uint64(2)).Return(anomaly.Stats{}, nil)
+		repo.EXPECT().SaveCategoryStats(gomock.Any(), int64(123), uint64(2), gomock.Any()).Return(nil)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(0), nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req purchases.AddPurchaseReq) error {
+				assert.Equal(t, uint64(2), req.CategoryID)
+				assert.Equal(t, "кофе старбакс", req.Description)
+				return nil
+			})
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		_, err := model.AddPurchase(ctx, 123, "250", "Кафе", "", purchases.WithDescription("кофе старбакс"))
+		assert.NoError(t, err)
 	})
 }
 
@@ -197,13 +279,37 @@ func Test_AddPurchase_SumAndCategoryAndDate(t *testing.T) {
 
 		repo.EXPECT().GetCategoryID(gomock.Any(), gomock.Any()).Return(uint64(1), nil)
 		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(1)).Return(true, nil)
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.RUB,
+			Limit:    -1,
+		}, nil)
 
 		_, err := model.AddPurchase(ctx, 123, "234.5", "some category", "01-01-2022")
 		assert.Error(t, err, purchases.ErrDateParsing)
 	})
 }
 
+// expectNoDailySums у пользователя нет трат за месяцы, по которым считается прогноз
+func expectNoDailySums(repo *mocks.MockRepo) {
+	repo.EXPECT().GetUserDailySums(gomock.Any(), int64(123), gomock.Any(), gomock.Any()).Return(nil, nil)
+}
+
+// emptyForecast прогноз без трат на day-е число месяца month
+func emptyForecast(month time.Time, day int, limit float64, cy currency.Currency) *purchases.MonthForecast {
+	return &purchases.MonthForecast{
+		Forecast: forecast.Project(forecast.Input{Daily: make([]float64, day), DaysInMonth: month.AddDate(0, 1, -1).Day(), Limit: limit}),
+		Month:    month,
+		Limit:    limit,
+		Currency: cy,
+	}
+}
+
 func Test_AddPurchase_Limits(t *testing.T) {
+	// прогноз считается на 10 января в часовом поясе по умолчанию
+	january := time.Date(2022, 1, 1, 0, 0, 0, 0, purchases.User{}.Location())
+	now := january.AddDate(0, 0, 9).Add(12 * time.Hour)
+
 	t.Run("у юзера не установлен лимит", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -250,6 +356,7 @@ func Test_AddPurchase_Limits(t *testing.T) {
 		redis := mocks.NewMockReportsStore(ctrl)
 
 		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
 
 		repo.EXPECT().GetCategoryID(gomock.Any(), gomock.Any()).Return(uint64(1), nil)
 		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(1)).Return(true, nil)
@@ -264,6 +371,8 @@ func Test_AddPurchase_Limits(t *testing.T) {
 			Limit:    1000,
 		}, nil)
 		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(500), nil)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{USD: 1, EUR: 1, CNY: 1})
+		expectNoDailySums(repo)
 		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
 		redis.EXPECT().Delete(gomock.Any(), "123report")
 
@@ -275,6 +384,7 @@ func Test_AddPurchase_Limits(t *testing.T) {
 			Expenses:      500 + 234.5,
 			Currency:      currency.RUB,
 			LimitExceeded: false,
+			Forecast:      emptyForecast(january, 10, 1000, currency.RUB),
 		}, expAndLim)
 	})
 
@@ -287,6 +397,7 @@ func Test_AddPurchase_Limits(t *testing.T) {
 		redis := mocks.NewMockReportsStore(ctrl)
 
 		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
 
 		repo.EXPECT().GetCategoryID(gomock.Any(), gomock.Any()).Return(uint64(1), nil)
 		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(1)).Return(true, nil)
@@ -301,6 +412,8 @@ func Test_AddPurchase_Limits(t *testing.T) {
 			Limit:    1000,
 		}, nil)
 		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(800), nil)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{USD: 1, EUR: 1, CNY: 1})
+		expectNoDailySums(repo)
 		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
 		redis.EXPECT().Delete(gomock.Any(), "123report")
 
@@ -312,6 +425,7 @@ func Test_AddPurchase_Limits(t *testing.T) {
 			Expenses:      800 + 234.5,
 			Currency:      currency.RUB,
 			LimitExceeded: true,
+			Forecast:      emptyForecast(january, 10, 1000, currency.RUB),
 		}, expAndLim)
 	})
 
@@ -324,6 +438,7 @@ func Test_AddPurchase_Limits(t *testing.T) {
 		redis := mocks.NewMockReportsStore(ctrl)
 
 		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
 
 		repo.EXPECT().GetCategoryID(gomock.Any(), gomock.Any()).Return(uint64(1), nil)
 		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(1)).Return(true, nil)
@@ -338,6 +453,8 @@ func Test_AddPurchase_Limits(t *testing.T) {
 			Limit:    1000,
 		}, nil)
 		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(500), nil)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{USD: 2, EUR: 2, CNY: 2})
+		expectNoDailySums(repo)
 		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
 		redis.EXPECT().Delete(gomock.Any(), "123report")
 
@@ -349,6 +466,169 @@ func Test_AddPurchase_Limits(t *testing.T) {
 			Expenses:      500*2 + 234.5,
 			Currency:      currency.USD,
 			LimitExceeded: false,
+			Forecast:      emptyForecast(january, 10, 1000*2, currency.USD),
 		}, expAndLim)
 	})
 }
+
+func Test_AddPurchase_WithCurrency(t *testing.T) {
+	january := time.Date(2022, 1, 1, 0, 0, 0, 0, purchases.User{}.Location())
+	now := january.AddDate(0, 0, 9).Add(12 * time.Hour)
+
+	t.Run("сумма в валюте, отличной от валюты пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
+
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{
+			USD: 0.5,
+			EUR: 0.25,
+			CNY: 2,
+		})
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.CNY,
+			Limit:    1000,
+		}, nil)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(100), nil)
+		expectNoDailySums(repo)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req purchases.AddPurchaseReq) error {
+				// 10$ по курсу 0.5 - это 20 рублей
+				assert.Equal(t, float64(20), req.Sum)
+				return nil
+			})
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		res, err := model.AddPurchase(ctx, 123, "10", "", "", purchases.WithCurrency(currency.USD))
+
+		assert.NoError(t, err)
+		assert.Equal(t, purchases.ExpensesAndLimit{
+			Limit:         1000 * 2,
+			Expenses:      100*2 + 20*2,
+			Currency:      currency.CNY,
+			LimitExceeded: false,
+			Forecast:      emptyForecast(january, 10, 1000*2, currency.CNY),
+		}, res)
+	})
+
+	t.Run("валюта совпадает с валютой пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
+
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{
+			USD: 0.5,
+			EUR: 0.25,
+			CNY: 2,
+		})
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.USD,
+			Limit:    1000,
+		}, nil)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(100), nil)
+		expectNoDailySums(repo)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		res, err := model.AddPurchase(ctx, 123, "10", "", "", purchases.WithCurrency(currency.USD))
+
+		assert.NoError(t, err)
+		assert.Equal(t, purchases.ExpensesAndLimit{
+			Limit:         1000 * 0.5,
+			Expenses:      100*0.5 + 10,
+			Currency:      currency.USD,
+			LimitExceeded: false,
+			Forecast:      emptyForecast(january, 10, 1000*0.5, currency.USD),
+		}, res)
+	})
+}
+
+func Test_AddPurchase_Timezone(t *testing.T) {
+	vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+	assert.NoError(t, err)
+
+	// 30.11 14:30 по UTC - это уже 1 декабря 00:30 во Владивостоке
+	now := time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC)
+
+	t.Run("трата без даты попадает в день и месяц пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
+
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(currency.RateToRUB{USD: 1, EUR: 1, CNY: 1})
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.RUB,
+			Limit:    1000,
+			Timezone: "Asia/Vladivostok",
+		}, nil)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ int64, date time.Time) (float64, error) {
+				// лимит считается за декабрь, а не за ноябрь
+				assert.Equal(t, time.December, date.Month())
+				assert.Equal(t, vladivostok, date.Location())
+				return 0, nil
+			})
+		expectNoDailySums(repo)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req purchases.AddPurchaseReq) error {
+				assert.True(t, now.Equal(req.Date))
+				assert.Equal(t, "01.12.2022", req.Date.Format("02.01.2006"))
+				return nil
+			})
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		_, err := model.AddPurchase(ctx, 123, "100", "", "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("дата траты - полночь в часовом поясе пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.RUB,
+			Limit:    -1,
+			Timezone: "Asia/Vladivostok",
+		}, nil)
+		repo.EXPECT().GetRate(gomock.Any(), 2022, 12, 1).Return(true, currency.RateToRUB{USD: 1, EUR: 1, CNY: 1}, nil)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(0), nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req purchases.AddPurchaseReq) error {
+				assert.True(t, time.Date(2022, 12, 1, 0, 0, 0, 0, vladivostok).Equal(req.Date))
+				return nil
+			})
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		_, err := model.AddPurchase(ctx, 123, "100", "", "01.12.2022")
+		assert.NoError(t, err)
+	})
+}
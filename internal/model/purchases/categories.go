@@ -0,0 +1,43 @@
+package purchases
+
+import (
+	"strings"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+// StarterCategories категории, с которых предлагается начать. Таблица категорий общая для всех пользователей,
+// поэтому в базе они хранятся под этими названиями на любом языке интерфейса и переводятся только при показе
+var StarterCategories = []string{"Продукты", "Кафе", "Транспорт", "Жилье", "Развлечения", "Здоровье", "Одежда"}
+
+// categoryNames переводы названий стартовых категорий. Названия, созданные пользователями, не переводятся
+var categoryNames = map[i18n.Lang]map[string]string{
+	i18n.EN: {
+		"Продукты":    "Groceries",
+		"Кафе":        "Cafe",
+		"Транспорт":   "Transport",
+		"Жилье":       "Housing",
+		"Развлечения": "Entertainment",
+		"Здоровье":    "Health",
+		"Одежда":      "Clothes",
+	},
+}
+
+// CategoryName название категории для показа на языке lang
+func CategoryName(lang i18n.Lang, category string) string {
+	if res, ok := categoryNames[lang][category]; ok {
+		return res
+	}
+	return category
+}
+
+// CategoryKey название категории, как оно хранится в базе: переведенное название стартовой категории
+// заменяется исходным, остальные названия не меняются
+func CategoryKey(lang i18n.Lang, name string) string {
+	for category, translated := range categoryNames[lang] {
+		if strings.EqualFold(strings.TrimSpace(name), translated) {
+			return category
+		}
+	}
+	return name
+}
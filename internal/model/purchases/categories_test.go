@@ -0,0 +1,24 @@
+//go:build test_all || unit_test
+
+package purchases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+func Test_CategoryName(t *testing.T) {
+	assert.Equal(t, "Groceries", CategoryName(i18n.EN, "Продукты"))
+	assert.Equal(t, "Продукты", CategoryName(i18n.RU, "Продукты"))
+	// свои категории пользователей не переводятся
+	assert.Equal(t, "Кофе", CategoryName(i18n.EN, "Кофе"))
+}
+
+func Test_CategoryKey(t *testing.T) {
+	assert.Equal(t, "Продукты", CategoryKey(i18n.EN, "groceries"))
+	assert.Equal(t, "Кафе", CategoryKey(i18n.EN, "Кафе"))
+	assert.Equal(t, "Coffee", CategoryKey(i18n.EN, "Coffee"))
+	assert.Equal(t, "Groceries", CategoryKey(i18n.RU, "Groceries"))
+}
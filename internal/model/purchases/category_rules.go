@@ -0,0 +1,177 @@
+package purchases
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/normalize"
+)
+
+// RuleType тип правила автоматического подбора категории
+type RuleType string
+
+const (
+	// RuleTypeSubstring описание траты содержит подстроку
+	RuleTypeSubstring RuleType = "substring"
+	// RuleTypeRegexp описание траты подходит под регулярное выражение
+	RuleTypeRegexp RuleType = "regexp"
+	// RuleTypeAmount сумма траты попадает в диапазон
+	RuleTypeAmount RuleType = "amount"
+)
+
+var (
+	// ruleRegexp условие вида /выражение/
+	ruleRegexp = regexp.MustCompile(`^/(.+)/$`)
+	// ruleAmountRange условие вида 100..500
+	ruleAmountRange = regexp.MustCompile(`^(\d+(?:\.\d+)?)\.\.(\d+(?:\.\d+)?)$`)
+	// ruleAmountBound условие вида >5000 или <300
+	ruleAmountBound = regexp.MustCompile(`^([<>])\s*(\d+(?:\.\d+)?)$`)
+)
+
+// CategoryRule правило, по которому описание траты сопоставляется с категорией
+type CategoryRule struct {
+	UserID     int64
+	Type       RuleType
+	Pattern    string  // подстрока или регулярное выражение
+	MinSum     float64 // границы суммы для правил типа amount (в валюте, в которой вводится трата)
+	MaxSum     float64 // 0 значит, что верхней границы нет
+	CategoryID uint64
+
+	// re скомпилированное регулярное выражение для правил типа regexp, см. Compiled
+	re *regexp.Regexp
+}
+
+// ParseRuleCondition разбирает условие правила: /выражение/ - регулярное выражение,
+// 100..500, >5000 или <300 - диапазон суммы, все остальное - подстрока в описании траты
+func ParseRuleCondition(condition string) (CategoryRule, error) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return CategoryRule{}, ErrRuleParsing
+	}
+
+	if res := ruleRegexp.FindStringSubmatch(condition); len(res) == 2 {
+		if _, err := regexp.Compile(res[1]); err != nil {
+			return CategoryRule{}, errors.Wrap(ErrRuleParsing, err.Error())
+		}
+		return CategoryRule{Type: RuleTypeRegexp, Pattern: res[1]}, nil
+	}
+
+	if res := ruleAmountRange.FindStringSubmatch(condition); len(res) == 3 {
+		min, err := strconv.ParseFloat(res[1], 64)
+		if err != nil {
+			return CategoryRule{}, ErrRuleParsing
+		}
+		max, err := strconv.ParseFloat(res[2], 64)
+		if err != nil || max < min {
+			return CategoryRule{}, ErrRuleParsing
+		}
+		return CategoryRule{Type: RuleTypeAmount, MinSum: min, MaxSum: max}, nil
+	}
+
+	if res := ruleAmountBound.FindStringSubmatch(condition); len(res) == 3 {
+		bound, err := strconv.ParseFloat(res[2], 64)
+		if err != nil {
+			return CategoryRule{}, ErrRuleParsing
+		}
+		if res[1] == ">" {
+			return CategoryRule{Type: RuleTypeAmount, MinSum: bound}, nil
+		}
+		return CategoryRule{Type: RuleTypeAmount, MaxSum: bound}, nil
+	}
+
+	return CategoryRule{Type: RuleTypeSubstring, Pattern: strings.ToLower(condition)}, nil
+}
+
+// Compiled правило с заранее скомпилированным регулярным выражением. Вызывается один раз при загрузке правила
+// из хранилища, чтобы не компилировать выражение на каждой трате. Правило с невалидным выражением ни с чем не совпадает
+func (r CategoryRule) Compiled() CategoryRule {
+	if r.Type == RuleTypeRegexp {
+		r.re, _ = regexp.Compile("(?i)" + r.Pattern)
+	}
+	return r
+}
+
+// Match проверяет, подходит ли трата под правило. Регулярное выражение должно быть скомпилировано через Compiled
+func (r CategoryRule) Match(description string, sum float64) bool {
+	switch r.Type {
+	case RuleTypeSubstring:
+		return r.Pattern != "" && strings.Contains(strings.ToLower(description), r.Pattern)
+
+	case RuleTypeRegexp:
+		return r.re != nil && r.re.MatchString(description)
+
+	case RuleTypeAmount:
+		if sum < r.MinSum {
+			return false
+		}
+		return r.MaxSum == 0 || sum <= r.MaxSum
+
+	default:
+		return false
+	}
+}
+
+// matchCategoryRule возвращает id категории из первого подошедшего правила (правила применяются в порядке создания)
+func matchCategoryRule(rules []CategoryRule, description string, sum float64) (uint64, bool) {
+	for _, r := range rules {
+		if r.Match(description, sum) {
+			return r.CategoryID, true
+		}
+	}
+	return 0, false
+}
+
+// AddCategoryRule создает правило, по которому траты с подходящим описанием или суммой
+// будут попадать в указанную категорию
+func (m *Model) AddCategoryRule(ctx context.Context, userID int64, condition, category string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "add category rule")
+	defer span.Finish()
+
+	rule, err := ParseRuleCondition(condition)
+	if err != nil {
+		return errors.Wrap(err, "ParseRuleCondition")
+	}
+
+	catName := normalize.Category(category)
+	categoryID, err := m.Repo.GetCategoryID(ctx, catName)
+	if err != nil {
+		return errors.Wrap(err, "repo.GetCategoryID")
+	}
+	if categoryID == 0 {
+		return ErrCategoryNotExist
+	}
+
+	// правило имеет смысл только для категории, которая есть у пользователя, поэтому сразу добавляем ее
+	has, err := m.Repo.UserHasCategory(ctx, userID, categoryID)
+	if err != nil {
+		return errors.Wrap(err, "repo.UserHasCategory")
+	}
+	if !has {
+		if err = m.Repo.AddCategoryToUser(ctx, userID, catName); err != nil {
+			return errors.Wrap(err, "repo.AddCategoryToUser")
+		}
+	}
+
+	rule.UserID = userID
+	rule.CategoryID = categoryID
+	if err = m.Repo.AddCategoryRule(ctx, rule); err != nil {
+		return errors.Wrap(err, "repo.AddCategoryRule")
+	}
+
+	return nil
+}
+
+// categoryIDByRules подбирает категорию по правилам пользователя. Если ни одно правило не подошло, вернется 0
+func (m *Model) categoryIDByRules(ctx context.Context, userID int64, description string, sum float64) (uint64, error) {
+	rules, err := m.Repo.GetUserCategoryRules(ctx, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "repo.GetUserCategoryRules")
+	}
+
+	categoryID, _ := matchCategoryRule(rules, description, sum)
+	return categoryID, nil
+}
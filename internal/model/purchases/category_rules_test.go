@@ -0,0 +1,114 @@
+//go:build test_all || unit_test
+
+package purchases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseRuleCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		want      CategoryRule
+		wantErr   bool
+	}{
+		{
+			name:      "подстрока приводится к нижнему регистру",
+			condition: "Пятёрочка",
+			want:      CategoryRule{Type: RuleTypeSubstring, Pattern: "пятёрочка"},
+		},
+		{
+			name:      "регулярное выражение",
+			condition: "/^такси.*/",
+			want:      CategoryRule{Type: RuleTypeRegexp, Pattern: "^такси.*"},
+		},
+		{
+			name:      "невалидное регулярное выражение",
+			condition: "/[такси/",
+			wantErr:   true,
+		},
+		{
+			name:      "диапазон суммы",
+			condition: "100..500.5",
+			want:      CategoryRule{Type: RuleTypeAmount, MinSum: 100, MaxSum: 500.5},
+		},
+		{
+			name:      "диапазон суммы с перепутанными границами",
+			condition: "500..100",
+			wantErr:   true,
+		},
+		{
+			name:      "только нижняя граница суммы",
+			condition: ">5000",
+			want:      CategoryRule{Type: RuleTypeAmount, MinSum: 5000},
+		},
+		{
+			name:      "только верхняя граница суммы",
+			condition: "< 300",
+			want:      CategoryRule{Type: RuleTypeAmount, MaxSum: 300},
+		},
+		{
+			name:      "пустое условие",
+			condition: "  ",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParseRuleCondition(tt.condition)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrRuleParsing)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func Test_matchCategoryRule(t *testing.T) {
+	rules := []CategoryRule{
+		{Type: RuleTypeSubstring, Pattern: "пятёрочка", CategoryID: 2},
+		CategoryRule{Type: RuleTypeRegexp, Pattern: "^такси", CategoryID: 3}.Compiled(),
+		{Type: RuleTypeAmount, MinSum: 100, MaxSum: 300, CategoryID: 4},
+		{Type: RuleTypeSubstring, Pattern: "магазин", CategoryID: 5},
+	}
+
+	t.Run("подстрока без учета регистра", func(t *testing.T) {
+		id, ok := matchCategoryRule(rules, "Магазин ПЯТЁРОЧКА", 1000)
+
+		assert.True(t, ok)
+		assert.Equal(t, uint64(2), id)
+	})
+
+	t.Run("регулярное выражение без учета регистра", func(t *testing.T) {
+		id, ok := matchCategoryRule(rules, "Такси до дома", 1000)
+
+		assert.True(t, ok)
+		assert.Equal(t, uint64(3), id)
+	})
+
+	t.Run("диапазон суммы, правила применяются по порядку", func(t *testing.T) {
+		id, ok := matchCategoryRule(rules, "магазин у дома", 250)
+
+		assert.True(t, ok)
+		assert.Equal(t, uint64(4), id)
+	})
+
+	t.Run("нескомпилированное выражение ни с чем не совпадает", func(t *testing.T) {
+		_, ok := matchCategoryRule([]CategoryRule{{Type: RuleTypeRegexp, Pattern: "^такси", CategoryID: 3}}, "такси", 1000)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("ни одно правило не подошло", func(t *testing.T) {
+		_, ok := matchCategoryRule(rules, "кино", 1000)
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,126 @@
+package purchases
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+// digestHour с какого часа первого дня нового периода отправляется отчет за прошедший: не будим пользователя ночью
+const digestHour = 9
+
+// Subscription подписка пользователя на отчет за каждую прошедшую неделю или месяц
+type Subscription struct {
+	UserID   int64
+	Period   Period
+	Lang     i18n.Lang // пустой, если пользователь не выбирал язык
+	Timezone string    // часовой пояс IANA, пустой, если пользователь его не выбирал
+}
+
+// Digest данные отчета по подписке, которых нет в обычном отчете
+type Digest struct {
+	Period string `json:"period"` // week или month
+	// PrevFromDate начало предыдущего периода, с которым сравнивается отчет. Его конец - начало периода отчета
+	PrevFromDate time.Time `json:"prevFromDate"`
+	// Limit месячный лимит в валюте пользователя, -1 - лимита нет
+	Limit float64 `json:"limit"`
+	// MonthExpenses сколько потрачено за месяц, на который приходится конец периода (в валюте пользователя)
+	MonthExpenses float64 `json:"monthExpenses"`
+}
+
+// Location часовой пояс пользователя, как у User
+func (s Subscription) Location() *time.Location {
+	return User{Timezone: s.Timezone}.Location()
+}
+
+// Due пора ли отправить отчет в момент now. Отчет за прошедшую неделю или месяц отправляется в первый день
+// следующего периода начиная с digestHour, в том числе если бот в это время перезапускался.
+// Возвращает конец периода отчета (не включительно) - начало текущей недели или месяца пользователя
+func (s Subscription) Due(now time.Time) (time.Time, bool) {
+	now = now.In(s.Location())
+
+	to, err := periodStart(now, s.Period)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return to, startOfDay(now).Equal(to) && now.Hour() >= digestHour
+}
+
+// ChangeUserDigest подписка на отчет за каждую прошедшую неделю или месяц
+func (m *Model) ChangeUserDigest(ctx context.Context, userID int64, period Period) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "change user digest")
+	defer span.Finish()
+
+	if period != periodWeek && period != periodMonth {
+		return ErrUnknownPeriod
+	}
+
+	if err := m.Repo.ChangeDigest(ctx, userID, period.String()); err != nil {
+		return errors.Wrap(err, "repo.ChangeDigest")
+	}
+	return nil
+}
+
+// RemoveUserDigest отмена подписки на отчеты
+func (m *Model) RemoveUserDigest(ctx context.Context, userID int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "remove user digest")
+	defer span.Finish()
+
+	if err := m.Repo.ChangeDigest(ctx, userID, ""); err != nil {
+		return errors.Wrap(err, "repo.ChangeDigest")
+	}
+	return nil
+}
+
+// GetDigestSubscriptions все подписки на отчеты
+func (m *Model) GetDigestSubscriptions(ctx context.Context) ([]Subscription, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "get digest subscriptions")
+	defer span.Finish()
+
+	subscriptions, err := m.Repo.GetDigestSubscriptions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.GetDigestSubscriptions")
+	}
+	return subscriptions, nil
+}
+
+// digest данные отчета по подписке за период с from по to: начало предыдущего периода
+// и сколько потрачено из месячного лимита
+func (m *Model) digest(ctx context.Context, info User, period Period, from, to time.Time) (*Digest, error) {
+	prevFrom, err := fromTime(from, period)
+	if err != nil {
+		return nil, errors.Wrap(err, "fromTime")
+	}
+
+	digest := Digest{Period: period.String(), PrevFromDate: prevFrom, Limit: -1}
+	if info.Limit != -1 {
+		// лимит считается за месяц последнего дня периода
+		expAndLim, err := m.getExpensesAndLimit(ctx, info.UserID, to.AddDate(0, 0, -1), info.Currency, info.Limit, 0,
+			m.ExchangeRatesModel.GetExchangeRateToRUB())
+		if err != nil {
+			return nil, errors.Wrap(err, "getExpensesAndLimit")
+		}
+		digest.Limit = expAndLim.Limit
+		digest.MonthExpenses = expAndLim.Expenses
+	}
+	return &digest, nil
+}
+
+// periodStart начало недели (с понедельника) или месяца, в которые попадает t, в часовом поясе t
+func periodStart(t time.Time, period Period) (time.Time, error) {
+	switch period {
+	case periodWeek:
+		sinceMonday := (int(t.Weekday()) + 6) % 7
+		return startOfDay(t.AddDate(0, 0, -sinceMonday)), nil
+
+	case periodMonth:
+		y, mon, _ := t.Date()
+		return time.Date(y, mon, 1, 0, 0, 0, 0, t.Location()), nil
+
+	default:
+		return time.Time{}, ErrUnknownPeriod
+	}
+}
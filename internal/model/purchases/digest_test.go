@@ -0,0 +1,157 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_SubscriptionDue(t *testing.T) {
+	week, err := purchases.ParsePeriod("week")
+	assert.NoError(t, err)
+	month, err := purchases.ParsePeriod("month")
+	assert.NoError(t, err)
+
+	monday := time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		period purchases.Period
+		now    time.Time
+		to     time.Time
+		due    bool
+	}{
+		{name: "неделя: понедельник утром", period: week, now: monday.Add(9*time.Hour + 30*time.Minute), to: monday, due: true},
+		{name: "неделя: понедельник ночью", period: week, now: monday.Add(3 * time.Hour), to: monday, due: false},
+		{name: "неделя: вторник", period: week, now: monday.AddDate(0, 0, 1).Add(10 * time.Hour), to: monday, due: false},
+		{name: "неделя: воскресенье", period: week, now: monday.AddDate(0, 0, 6).Add(10 * time.Hour), to: monday, due: false},
+		{name: "месяц: первое число", period: month, now: december.Add(12 * time.Hour), to: december, due: true},
+		{name: "месяц: второе число", period: month, now: december.AddDate(0, 0, 1).Add(12 * time.Hour), to: december, due: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sub := purchases.Subscription{UserID: 123, Period: tt.period, Timezone: "UTC"}
+
+			to, due := sub.Due(tt.now)
+
+			assert.Equal(t, tt.due, due)
+			assert.Equal(t, tt.to, to)
+		})
+	}
+
+	t.Run("день считается в часовом поясе пользователя", func(t *testing.T) {
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+		sub := purchases.Subscription{UserID: 123, Period: month, Timezone: "Asia/Vladivostok"}
+
+		// 30.11 23:30 по UTC - во Владивостоке уже 1 декабря 09:30
+		to, due := sub.Due(time.Date(2022, 11, 30, 23, 30, 0, 0, time.UTC))
+
+		assert.True(t, due)
+		assert.True(t, time.Date(2022, 12, 1, 0, 0, 0, 0, vladivostok).Equal(to))
+	})
+}
+
+func Test_ChangeUserDigest(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockRepo(ctrl)
+	model := purchases.New(repo, nil, nil, nil)
+
+	t.Run("неделя", func(t *testing.T) {
+		week, err := model.ToPeriod("week")
+		assert.NoError(t, err)
+		repo.EXPECT().ChangeDigest(gomock.Any(), int64(123), "week").Return(nil)
+
+		assert.NoError(t, model.ChangeUserDigest(ctx, 123, week))
+	})
+
+	t.Run("на год подписаться нельзя", func(t *testing.T) {
+		year, err := model.ToPeriod("year")
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, model.ChangeUserDigest(ctx, 123, year), purchases.ErrUnknownPeriod)
+	})
+
+	t.Run("отписка", func(t *testing.T) {
+		repo.EXPECT().ChangeDigest(gomock.Any(), int64(123), "").Return(nil)
+
+		assert.NoError(t, model.RemoveUserDigest(ctx, 123))
+	})
+}
+
+func Test_CreateReportRequest_Digest(t *testing.T) {
+	ctx := context.Background()
+	rates := currency.RateToRUB{USD: 0.5, EUR: 1, CNY: 1}
+	to := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	month, err := purchases.ParsePeriod("month")
+	assert.NoError(t, err)
+	opts := purchases.ReportOptions{DigestTo: to}
+
+	modelUp := func(t *testing.T, limit float64) (*purchases.Model, *mocks.MockRepo, *mocks.MockBrokerMsgCreator) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		broker := mocks.NewMockBrokerMsgCreator(ctrl)
+		rateGetter := mocks.NewMockExchangeRateGetter(ctrl)
+		rateGetter.EXPECT().GetExchangeRateToRUB().Return(rates).AnyTimes()
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).
+			Return(purchases.User{UserID: 123, Currency: currency.USD, Limit: limit, Timezone: "UTC"}, nil)
+		return purchases.New(repo, rateGetter, nil, broker), repo, broker
+	}
+
+	t.Run("с лимитом", func(t *testing.T) {
+		model, repo, broker := modelUp(t, 10000)
+
+		// лимит считается за ноябрь - месяц последнего дня периода
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), time.Date(2022, 11, 30, 0, 0, 0, 0, time.UTC)).
+			Return(float64(12000), nil)
+		broker.EXPECT().SendNewMsg("get_report", gomock.Any()).DoAndReturn(func(_, value string) error {
+			var req purchases.ReportRequest
+			assert.NoError(t, json.Unmarshal([]byte(value), &req))
+
+			assert.Equal(t, time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC), req.FromDate)
+			assert.Equal(t, to, req.ToDate)
+			assert.Equal(t, currency.USD, req.Currency)
+			assert.Equal(t, i18n.EN, req.Lang)
+			assert.Equal(t, &purchases.Digest{
+				Period:        "month",
+				PrevFromDate:  time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+				Limit:         5000,
+				MonthExpenses: 6000,
+			}, req.Digest)
+			return nil
+		})
+
+		assert.NoError(t, model.CreateReportRequest(ctx, month, 123, i18n.EN, opts))
+	})
+
+	t.Run("без лимита", func(t *testing.T) {
+		model, _, broker := modelUp(t, -1)
+
+		broker.EXPECT().SendNewMsg("get_report", gomock.Any()).DoAndReturn(func(_, value string) error {
+			var req purchases.ReportRequest
+			assert.NoError(t, json.Unmarshal([]byte(value), &req))
+
+			assert.Equal(t, float64(-1), req.Digest.Limit)
+			return nil
+		})
+
+		assert.NoError(t, model.CreateReportRequest(ctx, month, 123, i18n.EN, opts))
+	})
+}
@@ -0,0 +1,109 @@
+package purchases
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/forecast"
+)
+
+// forecastHistoryMonths за сколько прошлых месяцев берется история трат для прогноза
+const forecastHistoryMonths = 3
+
+// DailySum сумма трат пользователя за день
+type DailySum struct {
+	Day   time.Time // начало дня в часовом поясе пользователя
+	Summa float64
+}
+
+// MonthForecast прогноз трат на конец текущего месяца пользователя
+type MonthForecast struct {
+	forecast.Forecast
+
+	Month    time.Time // первое число месяца в часовом поясе пользователя
+	Limit    float64   // месячный лимит в валюте пользователя, -1 - лимита нет
+	Currency currency.Currency
+}
+
+// LimitDate дата, когда траты превысят лимит или уже превысили его. false, если по прогнозу лимит не будет превышен
+func (f MonthForecast) LimitDate() (time.Time, bool) {
+	if f.LimitDay == 0 {
+		return time.Time{}, false
+	}
+	return f.Month.AddDate(0, 0, f.LimitDay-1), true
+}
+
+// Forecast прогноз трат на конец текущего месяца по тратам за месяц и за прошлые forecastHistoryMonths месяцев.
+// Суммы в валюте пользователя по текущему курсу, как и лимит
+func (m *Model) Forecast(ctx context.Context, userID int64) (MonthForecast, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "forecast")
+	defer span.Finish()
+
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return MonthForecast{}, errors.Wrap(err, "repo.GetUserInfo")
+	}
+
+	return m.monthForecast(ctx, info, m.userNow(info), m.ExchangeRatesModel.GetExchangeRateToRUB())
+}
+
+// monthForecast прогноз по уже загруженным данным пользователя: now - сейчас в часовом поясе пользователя,
+// rates - курсы, по которым суммы переводятся в валюту пользователя
+func (m *Model) monthForecast(ctx context.Context, info User, now time.Time, rates currency.RateToRUB) (MonthForecast, error) {
+	month, err := periodStart(now, periodMonth)
+	if err != nil {
+		return MonthForecast{}, errors.Wrap(err, "periodStart")
+	}
+	historyFrom := month.AddDate(0, -forecastHistoryMonths, 0)
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+
+	sums, err := m.Repo.GetUserDailySums(ctx, info.UserID, historyFrom, tomorrow)
+	if err != nil {
+		return MonthForecast{}, errors.Wrap(err, "repo.GetUserDailySums")
+	}
+
+	in := forecast.Input{
+		Daily:       make([]float64, now.Day()),
+		DaysInMonth: month.AddDate(0, 1, -1).Day(),
+		History:     make([]float64, daysBetween(historyFrom, month)),
+		Limit:       forecast.NoLimit,
+	}
+	for _, s := range sums {
+		sum, err := currency.RubToCurrentCurrency(info.Currency, s.Summa, rates)
+		if err != nil {
+			return MonthForecast{}, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+
+		if s.Day.Before(month) {
+			if i := daysBetween(historyFrom, s.Day); i >= 0 && i < len(in.History) {
+				in.History[i] += sum
+			}
+			continue
+		}
+		if i := daysBetween(month, s.Day); i < len(in.Daily) {
+			in.Daily[i] += sum
+		}
+	}
+
+	res := MonthForecast{Month: month, Limit: -1, Currency: info.Currency}
+	if info.Limit != -1 {
+		if res.Limit, err = currency.RubToCurrentCurrency(info.Currency, info.Limit, rates); err != nil {
+			return MonthForecast{}, errors.Wrap(err, "getting limit from rubToCurrentCurrency")
+		}
+		in.Limit = res.Limit
+	}
+	res.Forecast = forecast.Project(in)
+
+	return res, nil
+}
+
+// daysBetween сколько календарных дней от from до to. Считается по датам, а не по часам: в днях перехода
+// на летнее время их не 24
+func daysBetween(from, to time.Time) int {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	return int(time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC).Sub(time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
@@ -0,0 +1,104 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_Forecast(t *testing.T) {
+	ctx := context.Background()
+	rates := currency.RateToRUB{USD: 0.5, EUR: 1, CNY: 1}
+	// 10 ноября 15:00 в UTC, в ноябре 30 дней
+	now := time.Date(2022, 11, 10, 15, 0, 0, 0, time.UTC)
+	november := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	modelUp := func(t *testing.T, user purchases.User) (*purchases.Model, *mocks.MockRepo) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		rateGetter := mocks.NewMockExchangeRateGetter(ctrl)
+		rateGetter.EXPECT().GetExchangeRateToRUB().Return(rates).AnyTimes()
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(user, nil)
+		model := purchases.New(repo, rateGetter, nil, nil)
+		model.Now = func() time.Time { return now }
+		return model, repo
+	}
+
+	t.Run("без истории, с лимитом", func(t *testing.T) {
+		model, repo := modelUp(t, purchases.User{UserID: 123, Currency: currency.USD, Limit: 3000, Timezone: "UTC"})
+
+		// история за 3 прошлых месяца и текущий месяц по сегодня включительно
+		repo.EXPECT().GetUserDailySums(gomock.Any(), int64(123),
+			time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 11, 11, 0, 0, 0, 0, time.UTC)).
+			Return([]purchases.DailySum{
+				{Day: november, Summa: 600},
+				{Day: november.AddDate(0, 0, 9), Summa: 400},
+			}, nil)
+
+		res, err := model.Forecast(ctx, 123)
+		assert.NoError(t, err)
+
+		assert.Equal(t, november, res.Month)
+		assert.Equal(t, currency.USD, res.Currency)
+		// лимит и траты в долларах
+		assert.Equal(t, float64(1500), res.Limit)
+		assert.Len(t, res.Actual, 10)
+		assert.Len(t, res.Projected, 30)
+		assert.Equal(t, float64(500), res.Actual[9])
+		// 50 в день
+		assert.InDelta(t, 1500, res.Total, 1e-9)
+		// ровно лимит к концу месяца - не превышение
+		_, ok := res.LimitDate()
+		assert.False(t, ok)
+	})
+
+	t.Run("история, лимит будет превышен", func(t *testing.T) {
+		model, repo := modelUp(t, purchases.User{UserID: 123, Currency: currency.RUB, Limit: 2000, Timezone: "UTC"})
+
+		// пользователь начал записывать траты 31 октября
+		repo.EXPECT().GetUserDailySums(gomock.Any(), int64(123), gomock.Any(), gomock.Any()).
+			Return([]purchases.DailySum{
+				{Day: time.Date(2022, 10, 31, 0, 0, 0, 0, time.UTC), Summa: 100},
+				{Day: november.AddDate(0, 0, 4), Summa: 1000},
+			}, nil)
+
+		res, err := model.Forecast(ctx, 123)
+		assert.NoError(t, err)
+
+		// 10/30 * 100 + 20/30 * 100
+		assert.InDelta(t, 100, res.DailyRate, 1e-9)
+		assert.InDelta(t, 3000, res.Total, 1e-9)
+		date, ok := res.LimitDate()
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2022, 11, 21, 0, 0, 0, 0, time.UTC), date)
+	})
+
+	t.Run("месяц в часовом поясе пользователя", func(t *testing.T) {
+		model, repo := modelUp(t, purchases.User{UserID: 123, Currency: currency.RUB, Limit: -1, Timezone: "Asia/Vladivostok"})
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+		// во Владивостоке уже 1 декабря
+		model.Now = func() time.Time { return time.Date(2022, 11, 30, 20, 0, 0, 0, time.UTC) }
+
+		repo.EXPECT().GetUserDailySums(gomock.Any(), int64(123),
+			time.Date(2022, 9, 1, 0, 0, 0, 0, vladivostok), time.Date(2022, 12, 2, 0, 0, 0, 0, vladivostok)).
+			Return(nil, nil)
+
+		res, err := model.Forecast(ctx, 123)
+		assert.NoError(t, err)
+
+		assert.Equal(t, time.Date(2022, 12, 1, 0, 0, 0, 0, vladivostok), res.Month)
+		assert.Len(t, res.Actual, 1)
+		assert.Len(t, res.Projected, 31)
+		assert.Equal(t, float64(-1), res.Limit)
+	})
+}
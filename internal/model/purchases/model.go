@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 )
 
 var (
@@ -17,6 +19,11 @@ var (
 	ErrInvalidDate         = errors.New("invalid date")
 	ErrUserHasntCategory   = errors.New("this user hasn't such category")
 	ErrCreateReportRequest = errors.New("create report request failed")
+	ErrRuleParsing         = errors.New("rule parsing error")
+	ErrSearchQueryParsing  = errors.New("search query parsing error")
+	ErrUnknownTimezone     = errors.New("unknown timezone")
+	ErrReminderParsing     = errors.New("reminder time parsing error")
+	ErrUnusualPurchase     = errors.New("unusual purchase needs confirmation")
 )
 
 // Repo репозиторий
@@ -26,19 +33,37 @@ type Repo interface {
 
 	UserCreateIfNotExist(ctx context.Context, userID int64) error
 	ChangeCurrency(ctx context.Context, userID int64, currency currency.Currency) error
+	ChangeLang(ctx context.Context, userID int64, lang i18n.Lang) error
+	ChangeTimezone(ctx context.Context, userID int64, timezone string) error
 	GetUserInfo(ctx context.Context, userID int64) (User, error)
 	ChangeUserLimit(ctx context.Context, userID int64, newLimit float64) error
 	AddCategoryToUser(ctx context.Context, userID int64, catName string) error
 	UserHasCategory(ctx context.Context, userID int64, categoryID uint64) (bool, error)
 	GetUserCategories(ctx context.Context, userID int64) ([]string, error)
+	ChangeReminder(ctx context.Context, userID int64, remindAt int) error
+	GetReminders(ctx context.Context) ([]Reminder, error)
+	ChangeDigest(ctx context.Context, userID int64, period string) error
+	GetDigestSubscriptions(ctx context.Context) ([]Subscription, error)
+	ChangeConfirmUnusual(ctx context.Context, userID int64, confirm bool) error
 
 	AddPurchase(ctx context.Context, req AddPurchaseReq) error
 	GetUserPurchasesFromDate(ctx context.Context, fromDate time.Time, userID int64) ([]Purchase, error)
+	HasUserPurchasesFrom(ctx context.Context, userID int64, fromDate time.Time) (bool, error)
 	GetUserPurchasesSumFromMonth(ctx context.Context, userID int64, fromDate time.Time) (float64, error)
+	GetUserDailySums(ctx context.Context, userID int64, from, to time.Time) ([]DailySum, error)
+	GetUserCategorizedPurchases(ctx context.Context, userID int64, limit uint64) ([]CategorizedPurchase, error)
+	GetUserTaggedPurchases(ctx context.Context, userID int64) ([]TaggedPurchase, error)
+	SearchPurchases(ctx context.Context, filter SearchFilter, limit, offset uint64) ([]FoundPurchase, error)
+
+	GetCategoryStats(ctx context.Context, userID int64, categoryID uint64) (anomaly.Stats, error)
+	SaveCategoryStats(ctx context.Context, userID int64, categoryID uint64, stats anomaly.Stats) error
 
 	GetCategoryID(ctx context.Context, categoryName string) (uint64, error)
 	AddCategory(ctx context.Context, categoryName string) error
 	GetAllCategories(ctx context.Context) ([]CategoryRow, error)
+
+	AddCategoryRule(ctx context.Context, rule CategoryRule) error
+	GetUserCategoryRules(ctx context.Context, userID int64) ([]CategoryRule, error)
 }
 
 type ExchangeRateGetter interface {
@@ -59,6 +84,9 @@ type Model struct {
 	ExchangeRatesModel ExchangeRateGetter
 	ReportsStore       ReportsStore
 	BrokerMsgCreator   BrokerMsgCreator
+
+	// Now текущее время, подменяется в тестах
+	Now func() time.Time
 }
 
 func New(repo Repo, exchangeRatesModel ExchangeRateGetter, reportsStore ReportsStore, producer BrokerMsgCreator) *Model {
@@ -67,5 +95,6 @@ func New(repo Repo, exchangeRatesModel ExchangeRateGetter, reportsStore ReportsS
 		ExchangeRatesModel: exchangeRatesModel,
 		ReportsStore:       reportsStore,
 		BrokerMsgCreator:   producer,
+		Now:                time.Now,
 	}
 }
@@ -0,0 +1,77 @@
+package purchases
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+)
+
+// PurchasePreview как будет учтена трата, которую еще не добавили
+type PurchasePreview struct {
+	Category string // категория, в которую попадет трата, пустая - трата без категории
+	// Unknown такой категории у пользователя нет, при добавлении траты ее предложат выбрать
+	Unknown       bool
+	MonthExpenses float64 // сколько уже потрачено в этой категории за текущий месяц (в выбранной валюте)
+	Currency      currency.Currency
+}
+
+// PreviewPurchase подбирает категорию для траты на сумму sum так же, как AddPurchase, и считает,
+// сколько в ней уже потрачено за календарный месяц пользователя. Сама трата не добавляется
+func (m *Model) PreviewPurchase(ctx context.Context, userID int64, category string, sum float64) (PurchasePreview, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "preview purchase")
+	defer span.Finish()
+
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return PurchasePreview{}, errors.Wrap(err, "repo.GetUserInfo")
+	}
+	preview := PurchasePreview{Currency: info.Currency}
+
+	categoryID, err := m.userCategoryID(ctx, userID, category, sum)
+	if errors.Is(err, ErrCategoryNotExist) || errors.Is(err, ErrUserHasntCategory) {
+		preview.Unknown = true
+		return preview, nil
+	}
+	if err != nil {
+		return PurchasePreview{}, err
+	}
+
+	categories, err := m.Repo.GetAllCategories(ctx)
+	if err != nil {
+		return PurchasePreview{}, errors.Wrap(err, "repo.GetAllCategories")
+	}
+	var name string
+	for _, c := range categories {
+		if c.ID == categoryID {
+			name = c.Category
+			break
+		}
+	}
+	if categoryID != defaultCategoryID {
+		preview.Category = name
+	}
+
+	// месяц пользователя, как у лимита
+	y, mon, _ := m.userNow(info).Date()
+	from := time.Date(y, mon, 1, 0, 0, 0, 0, info.Location())
+
+	purchases, err := m.Repo.GetUserPurchasesFromDate(ctx, from, userID)
+	if err != nil {
+		return PurchasePreview{}, errors.Wrap(err, "repo.GetUserPurchasesFromDate")
+	}
+	for _, p := range purchases {
+		if p.PurchaseCategory != name {
+			continue
+		}
+		s, err := currency.RubToCurrentCurrency(info.Currency, p.Summa, p.RateToRUB)
+		if err != nil {
+			return PurchasePreview{}, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+		preview.MonthExpenses += s
+	}
+
+	return preview, nil
+}
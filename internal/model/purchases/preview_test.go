@@ -0,0 +1,72 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_PreviewPurchase(t *testing.T) {
+	now := time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC)
+	rates := currency.RateToRUB{USD: 0.5, EUR: 1, CNY: 1}
+
+	modelUp := func(t *testing.T) (*purchases.Model, *mocks.MockRepo) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+
+		model := purchases.New(repo, mocks.NewMockExchangeRateGetter(ctrl), mocks.NewMockReportsStore(ctrl), nil)
+		model.Now = func() time.Time { return now }
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:   123,
+			Currency: currency.USD,
+			Limit:    -1,
+			Timezone: "UTC",
+		}, nil)
+		return model, repo
+	}
+
+	t.Run("траты категории за месяц в валюте пользователя", func(t *testing.T) {
+		ctx := context.Background()
+		model, repo := modelUp(t)
+
+		repo.EXPECT().GetCategoryID(gomock.Any(), "Кафе").Return(uint64(2), nil)
+		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(2)).Return(true, nil)
+		repo.EXPECT().GetAllCategories(gomock.Any()).Return([]purchases.CategoryRow{
+			{ID: 1, Category: "Не заданная категория"},
+			{ID: 2, Category: "Кафе"},
+		}, nil)
+		repo.EXPECT().GetUserPurchasesFromDate(gomock.Any(), time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC), int64(123)).
+			Return([]purchases.Purchase{
+				{PurchaseCategory: "Кафе", Summa: 100, RateToRUB: rates},
+				{PurchaseCategory: "Такси", Summa: 600, RateToRUB: rates},
+				{PurchaseCategory: "Кафе", Summa: 300, RateToRUB: rates},
+			}, nil)
+
+		res, err := model.PreviewPurchase(ctx, 123, "Кафе", 250)
+
+		assert.NoError(t, err)
+		assert.Equal(t, purchases.PurchasePreview{Category: "Кафе", MonthExpenses: 200, Currency: currency.USD}, res)
+	})
+
+	t.Run("категории нет у пользователя", func(t *testing.T) {
+		ctx := context.Background()
+		model, repo := modelUp(t)
+
+		repo.EXPECT().GetCategoryID(gomock.Any(), "Кофе").Return(uint64(0), nil)
+		repo.EXPECT().GetUserCategoryRules(gomock.Any(), int64(123)).Return(nil, nil)
+
+		res, err := model.PreviewPurchase(ctx, 123, "кофе", 250)
+
+		assert.NoError(t, err)
+		assert.Equal(t, purchases.PurchasePreview{Unknown: true, Currency: currency.USD}, res)
+	})
+}
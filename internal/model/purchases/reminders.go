@@ -0,0 +1,96 @@
+package purchases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+// ReminderOff аргумент /remind, который выключает напоминание
+const ReminderOff = "off"
+
+// Reminder ежедневное напоминание записать траты
+type Reminder struct {
+	UserID   int64
+	At       int       // время напоминания в минутах от полуночи в часовом поясе пользователя
+	Lang     i18n.Lang // пустой, если пользователь не выбирал язык
+	Timezone string    // часовой пояс IANA, пустой, если пользователь его не выбирал
+}
+
+// Location часовой пояс пользователя, как у User
+func (r Reminder) Location() *time.Location {
+	return User{Timezone: r.Timezone}.Location()
+}
+
+// Clock время напоминания в формате 21:00
+func (r Reminder) Clock() string {
+	return fmt.Sprintf("%02d:%02d", r.At/60, r.At%60)
+}
+
+// Due пора ли напомнить в момент now, если прошлая проверка была в last. Напоминание отправляется, если его время
+// сегодня попало между проверками: так бот наверстывает тики, пропущенные при перезапуске, но не присылает
+// напоминание, включенное уже после его времени. Возвращает начало дня пользователя, к которому оно относится
+func (r Reminder) Due(last, now time.Time) (time.Time, bool) {
+	now = now.In(r.Location())
+	day := startOfDay(now)
+	at := time.Date(day.Year(), day.Month(), day.Day(), r.At/60, r.At%60, 0, 0, day.Location())
+
+	return day, at.After(last) && !at.After(now)
+}
+
+// ParseReminderTime разбирает время напоминания "21:00" в минуты от полуночи
+func ParseReminderTime(raw string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, ErrReminderParsing
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ChangeUserReminder включает ежедневное напоминание на время rawTime ("21:00") или выключает его (ReminderOff)
+func (m *Model) ChangeUserReminder(ctx context.Context, userID int64, rawTime string) (Reminder, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "change user reminder")
+	defer span.Finish()
+
+	at := -1
+	if !strings.EqualFold(strings.TrimSpace(rawTime), ReminderOff) {
+		var err error
+		if at, err = ParseReminderTime(rawTime); err != nil {
+			return Reminder{}, err
+		}
+	}
+
+	if err := m.Repo.ChangeReminder(ctx, userID, at); err != nil {
+		return Reminder{}, errors.Wrap(err, "repo.ChangeReminder")
+	}
+	return Reminder{UserID: userID, At: at}, nil
+}
+
+// GetReminders все включенные напоминания
+func (m *Model) GetReminders(ctx context.Context) ([]Reminder, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "get reminders")
+	defer span.Finish()
+
+	reminders, err := m.Repo.GetReminders(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.GetReminders")
+	}
+	return reminders, nil
+}
+
+// HasPurchasesFrom добавлял ли пользователь траты начиная с from
+func (m *Model) HasPurchasesFrom(ctx context.Context, userID int64, from time.Time) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "has purchases from")
+	defer span.Finish()
+
+	has, err := m.Repo.HasUserPurchasesFrom(ctx, userID, from)
+	if err != nil {
+		return false, errors.Wrap(err, "repo.HasUserPurchasesFrom")
+	}
+	return has, nil
+}
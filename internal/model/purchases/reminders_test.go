@@ -0,0 +1,111 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_ChangeUserReminder(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawTime string
+		at      int
+	}{
+		{name: "время", rawTime: "21:00", at: 21 * 60},
+		{name: "время с одной цифрой часа", rawTime: "9:05", at: 9*60 + 5},
+		{name: "выключение", rawTime: "OFF", at: -1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockRepo(ctrl)
+			model := purchases.New(repo, nil, nil, nil)
+
+			repo.EXPECT().ChangeReminder(gomock.Any(), int64(123), tt.at).Return(nil)
+
+			res, err := model.ChangeUserReminder(ctx, 123, tt.rawTime)
+
+			assert.NoError(t, err)
+			assert.Equal(t, purchases.Reminder{UserID: 123, At: tt.at}, res)
+		})
+	}
+
+	for _, rawTime := range []string{"25:00", "21:60", "21", "завтра"} {
+		rawTime := rawTime
+		t.Run("неверное время: "+rawTime, func(t *testing.T) {
+			ctx := context.Background()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockRepo(ctrl)
+			model := purchases.New(repo, nil, nil, nil)
+
+			_, err := model.ChangeUserReminder(ctx, 123, rawTime)
+
+			assert.ErrorIs(t, err, purchases.ErrReminderParsing)
+		})
+	}
+}
+
+func Test_ReminderDue(t *testing.T) {
+	// 30 ноября 17:30 UTC - во Владивостоке уже 1 декабря 03:30
+	now := time.Date(2022, 11, 30, 17, 30, 0, 0, time.UTC)
+	last := now.Add(-time.Minute)
+
+	vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+	assert.NoError(t, err)
+
+	t.Run("время напоминания наступило", func(t *testing.T) {
+		r := purchases.Reminder{At: 17*60 + 30, Timezone: "UTC"}
+
+		day, due := r.Due(last, now)
+
+		assert.True(t, due)
+		assert.Equal(t, time.Date(2022, 11, 30, 0, 0, 0, 0, time.UTC), day)
+	})
+
+	t.Run("время напоминания еще не наступило", func(t *testing.T) {
+		r := purchases.Reminder{At: 21 * 60, Timezone: "UTC"}
+
+		_, due := r.Due(last, now)
+
+		assert.False(t, due)
+	})
+
+	t.Run("время прошло до прошлой проверки: напоминание включили позже", func(t *testing.T) {
+		r := purchases.Reminder{At: 17 * 60, Timezone: "UTC"}
+
+		_, due := r.Due(last, now)
+
+		assert.False(t, due)
+	})
+
+	t.Run("бот лежал во время напоминания", func(t *testing.T) {
+		r := purchases.Reminder{At: 17 * 60, Timezone: "UTC"}
+
+		day, due := r.Due(now.Add(-time.Hour), now)
+
+		assert.True(t, due)
+		assert.Equal(t, time.Date(2022, 11, 30, 0, 0, 0, 0, time.UTC), day)
+	})
+
+	t.Run("день считается в часовом поясе пользователя", func(t *testing.T) {
+		r := purchases.Reminder{At: 21 * 60, Timezone: "Asia/Vladivostok"}
+
+		day, due := r.Due(last, now)
+
+		assert.False(t, due)
+		assert.True(t, time.Date(2022, 12, 1, 0, 0, 0, 0, vladivostok).Equal(day))
+	})
+}
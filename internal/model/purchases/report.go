@@ -3,13 +3,13 @@ package purchases
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 )
 
 const keySuffix = "report"
@@ -22,22 +22,39 @@ var (
 	periodWeek  Period = 3
 )
 
+var periodNames = map[Period]string{
+	periodYear:  "year",
+	periodMonth: "month",
+	periodWeek:  "week",
+}
+
 func (m *Model) ToPeriod(str string) (Period, error) {
-	switch str {
-	case "year":
-		return periodYear, nil
-	case "month":
-		return periodMonth, nil
-	case "week":
-		return periodWeek, nil
-	default:
-		return 0, ErrUnknownPeriod
+	return ParsePeriod(str)
+}
+
+// ParsePeriod период по названию: year, month или week
+func ParsePeriod(str string) (Period, error) {
+	for p, name := range periodNames {
+		if name == str {
+			return p, nil
+		}
 	}
+	return 0, ErrUnknownPeriod
+}
+
+// String название периода, обратное ParsePeriod
+func (p Period) String() string {
+	return periodNames[p]
 }
 
 type Purchase struct {
+	Date             time.Time
 	PurchaseCategory string
-	Summa            float64
+	Description      string
+	Summa            float64           // в рублях
+	Currency         currency.Currency // валюта, в которой заплатил пользователь
+	// UnknownCurrency валюта оплаты неизвестна: трата добавлена до того, как валюта стала сохраняться
+	UnknownCurrency bool
 
 	// коэффициенты валют на момент совершения траты
 	currency.RateToRUB
@@ -59,32 +76,95 @@ type Segment struct {
 	Label string
 }
 
+// ReportFormat в каком виде прислать отчет
+type ReportFormat string
+
+const (
+	// ReportFormatText текст и круговая диаграмма
+	ReportFormatText ReportFormat = ""
+	// ReportFormatPDF текст и PDF-документ с таблицами, графиками и списком всех трат
+	ReportFormatPDF ReportFormat = "pdf"
+)
+
+// ReportOptions что и в каком виде показать в отчете
+type ReportOptions struct {
+	Tags   []string // в отчет попадут только траты со всеми этими тегами
+	Format ReportFormat
+	// AllCurrencies показать, сколько потрачено в каждой валюте, в которой платил пользователь
+	AllCurrencies bool
+	// TodayRates пересчитать траты по сегодняшнему курсу, а не по курсу на дату траты
+	TodayRates bool
+	// DigestTo конец периода отчета по подписке (не включительно). Если задан, отчет сравнивается
+	// с предыдущим периодом и показывает, сколько потрачено из месячного лимита
+	DigestTo time.Time
+}
+
 type ReportRequest struct {
 	FromDate time.Time         `json:"fromDate"`
 	UserID   int64             `json:"userId"`
 	Currency currency.Currency `json:"currency"`
+	Tags     []string          `json:"tags,omitempty"`   // в отчет попадут только траты со всеми этими тегами
+	Lang     i18n.Lang         `json:"lang,omitempty"`   // язык, на котором будет отчет
+	Format   ReportFormat      `json:"format,omitempty"` // в старых запросах формата нет - это текст
+	// AllCurrencies показать суммы в каждой валюте оплаты рядом с общей суммой
+	AllCurrencies bool `json:"allCurrencies,omitempty"`
+	// TodayRates курсы на сегодня: если переданы, траты пересчитываются по ним, а не по курсам на дату траты
+	TodayRates *currency.RateToRUB `json:"todayRates,omitempty"`
+	// ToDate конец периода (не включительно), пустой - отчет по сегодняшний день
+	ToDate time.Time `json:"toDate,omitempty"`
+	// Digest отчет по подписке, с ним отчет сравнивается с предыдущим периодом и показывает лимит
+	Digest *Digest `json:"digest,omitempty"`
 }
 
-// CreateReportRequest создание запроса на отчет
-func (m *Model) CreateReportRequest(ctx context.Context, period Period, userID int64) (err error) {
+// CreateReportRequest создание запроса на отчет на языке lang. Если в opts переданы теги, в отчет попадут
+// только траты со всеми этими тегами. Без opts.DigestTo период заканчивается сегодняшним днем
+func (m *Model) CreateReportRequest(ctx context.Context, period Period, userID int64, lang i18n.Lang, opts ReportOptions) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "report")
 	defer span.Finish()
 
-	from, err := fromTime(time.Now(), period)
+	info, err := m.Repo.GetUserInfo(ctx, userID)
 	if err != nil {
-		return errors.Wrap(err, "fromTime")
+		return errors.Wrap(err, "repo.GetUserInfo")
 	}
 
-	info, err := m.Repo.GetUserInfo(ctx, userID)
+	to := m.userNow(info)
+	if !opts.DigestTo.IsZero() {
+		to = opts.DigestTo
+	}
+
+	// начало периода - полночь в часовом поясе пользователя, в запросе оно передается вместе со смещением
+	from, err := fromTime(to, period)
 	if err != nil {
-		return errors.Wrap(err, "repo.GetUserInfo")
+		return errors.Wrap(err, "fromTime")
 	}
 
-	jsonReq, err := json.Marshal(ReportRequest{
-		FromDate: from,
-		UserID:   userID,
-		Currency: info.Currency,
-	})
+	req := ReportRequest{
+		FromDate:      from,
+		UserID:        userID,
+		Currency:      info.Currency,
+		Tags:          opts.Tags,
+		Lang:          lang,
+		Format:        opts.Format,
+		AllCurrencies: opts.AllCurrencies,
+	}
+	if opts.TodayRates {
+		// у сервиса отчетов нет клиента курсов валют, поэтому курсы передаются в запросе
+		rates := m.ExchangeRatesModel.GetExchangeRateToRUB()
+		req.TodayRates = &rates
+	}
+	if !opts.DigestTo.IsZero() {
+		req.ToDate = opts.DigestTo
+		if req.Digest, err = m.digest(ctx, info, period, from, opts.DigestTo); err != nil {
+			return errors.Wrap(err, "digest")
+		}
+	}
+
+	return m.sendReportRequest(req)
+}
+
+// sendReportRequest отправляет запрос на отчет сервису отчетов
+func (m *Model) sendReportRequest(req ReportRequest) error {
+	jsonReq, err := json.Marshal(req)
 	if err != nil {
 		return errors.Wrap(err, "marshalling error")
 	}
@@ -100,20 +180,18 @@ func (m *Model) CreateReportRequest(ctx context.Context, period Period, userID i
 }
 
 // fromTime позволяет получить из переданной даты новую, вычитая из переданной указанный период
-// (учитывая количество дней в месяцах и високосные годы)
+// (учитывая количество дней в месяцах и високосные годы). Результат - начало дня в часовом поясе to
 func fromTime(to time.Time, period Period) (time.Time, error) {
 	var resDate time.Time
 
+	// период отсчитывается в календарных днях: разница в часах между датами дала бы ошибку
+	// на день в часовых поясах с переходом на летнее время
 	switch period {
 	case periodYear:
-		from := to.AddDate(-1, 0, 0)
-		days := to.Sub(from).Hours() / 24
-		resDate = to.AddDate(0, 0, int(-days))
+		resDate = to.AddDate(-1, 0, 0)
 
 	case periodMonth:
-		from := to.AddDate(0, -1, 0)
-		days := to.Sub(from).Hours() / 24
-		resDate = to.AddDate(0, 0, int(-days))
+		resDate = to.AddDate(0, -1, 0)
 
 	case periodWeek:
 		resDate = to.AddDate(0, 0, -7)
@@ -123,13 +201,7 @@ func fromTime(to time.Time, period Period) (time.Time, error) {
 	}
 
 	// этот шаг нужен, чтобы обнулить все составные части кроме даты
-	y, m, d := resDate.Date()
-	result, err := time.Parse("02.01.2006", fmt.Sprintf("%02d.%02d.%d", d, m, y))
-	if err != nil {
-		return time.Time{}, errors.Wrap(err, "time.Parse")
-	}
-
-	return result, nil
+	return startOfDay(resDate), nil
 }
 
 func createKeyForReportsStore(userID int64) string {
@@ -0,0 +1,79 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_CreateReportRequest_Options(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pdf с тегами", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		broker := mocks.NewMockBrokerMsgCreator(ctrl)
+		model := purchases.New(repo, nil, nil, broker)
+		model.Now = func() time.Time { return time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC) }
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).
+			Return(purchases.User{UserID: 123, Currency: currency.USD}, nil)
+		broker.EXPECT().SendNewMsg("get_report", gomock.Any()).DoAndReturn(func(_, value string) error {
+			var req purchases.ReportRequest
+			assert.NoError(t, json.Unmarshal([]byte(value), &req))
+
+			assert.Equal(t, purchases.ReportFormatPDF, req.Format)
+			assert.Equal(t, []string{"командировка"}, req.Tags)
+			assert.Equal(t, i18n.EN, req.Lang)
+			assert.False(t, req.AllCurrencies)
+			assert.Nil(t, req.TodayRates)
+			return nil
+		})
+
+		period, err := model.ToPeriod("week")
+		assert.NoError(t, err)
+
+		err = model.CreateReportRequest(ctx, period, 123, i18n.EN,
+			purchases.ReportOptions{Tags: []string{"командировка"}, Format: purchases.ReportFormatPDF})
+		assert.NoError(t, err)
+	})
+
+	t.Run("все валюты по сегодняшнему курсу", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		broker := mocks.NewMockBrokerMsgCreator(ctrl)
+		model := purchases.New(repo, excRateModel, nil, broker)
+		model.Now = func() time.Time { return time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC) }
+
+		today := currency.RateToRUB{USD: 0.016, EUR: 0.015, CNY: 0.11}
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).
+			Return(purchases.User{UserID: 123, Currency: currency.RUB}, nil)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(today)
+		broker.EXPECT().SendNewMsg("get_report", gomock.Any()).DoAndReturn(func(_, value string) error {
+			var req purchases.ReportRequest
+			assert.NoError(t, json.Unmarshal([]byte(value), &req))
+
+			assert.Equal(t, purchases.ReportFormatText, req.Format)
+			assert.True(t, req.AllCurrencies)
+			assert.Equal(t, &today, req.TodayRates)
+			return nil
+		})
+
+		period, err := model.ToPeriod("month")
+		assert.NoError(t, err)
+
+		err = model.CreateReportRequest(ctx, period, 123, i18n.RU, purchases.ReportOptions{AllCurrencies: true, TodayRates: true})
+		assert.NoError(t, err)
+	})
+}
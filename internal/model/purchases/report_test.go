@@ -99,4 +99,46 @@ func Test_fromTime(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, out, res)
 	})
+	t.Run("месяц через переход на летнее время", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		assert.NoError(t, err)
+
+		// 26.03.2023 в Берлине часы перевели вперед, в этом месяце на час меньше
+		in := time.Date(2023, 4, 1, 10, 0, 0, 0, berlin)
+		out := time.Date(2023, 3, 1, 0, 0, 0, 0, berlin)
+
+		res, err := fromTime(in, periodMonth)
+
+		assert.NoError(t, err)
+		assert.True(t, out.Equal(res), res)
+		assert.Equal(t, berlin, res.Location())
+	})
+
+	t.Run("неделя через переход на зимнее время", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		assert.NoError(t, err)
+
+		// 05.11.2023 в Нью-Йорке часы перевели назад, в этой неделе на час больше
+		in := time.Date(2023, 11, 8, 0, 30, 0, 0, newYork)
+		out := time.Date(2023, 11, 1, 0, 0, 0, 0, newYork)
+
+		res, err := fromTime(in, periodWeek)
+
+		assert.NoError(t, err)
+		assert.True(t, out.Equal(res), res)
+	})
+
+	t.Run("год считается от дня в часовом поясе даты, а не в UTC", func(t *testing.T) {
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+
+		// 01.01.2023 00:30 во Владивостоке - в UTC это еще 31.12.2022
+		in := time.Date(2023, 1, 1, 0, 30, 0, 0, vladivostok)
+		out := time.Date(2022, 1, 1, 0, 0, 0, 0, vladivostok)
+
+		res, err := fromTime(in, periodYear)
+
+		assert.NoError(t, err)
+		assert.True(t, out.Equal(res), res)
+	})
 }
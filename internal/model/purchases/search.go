@@ -0,0 +1,177 @@
+package purchases
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+)
+
+// SearchPageSize сколько трат показывается на одной странице результатов поиска
+const SearchPageSize = 10
+
+var (
+	// searchDateRange условие вида 01.03.2024..31.03.2024
+	searchDateRange = regexp.MustCompile(`^(\d{2}\.\d{2}\.\d{4})\.\.(\d{2}\.\d{2}\.\d{4})$`)
+	// searchDate условие вида 01.03.2024 - траты за один день
+	searchDate = regexp.MustCompile(`^\d{2}\.\d{2}\.\d{4}$`)
+)
+
+// SearchFilter условия поиска трат. Нулевые значения полей значат, что условие не задано
+type SearchFilter struct {
+	UserID int64
+	// Words каждое слово должно встретиться в категории, заметке или описании траты
+	Words []string
+	// MinSum, MaxSum границы суммы в валюте Currency
+	MinSum   float64
+	MaxSum   float64
+	Currency currency.Currency
+	// FromDate, ToDate границы дат включительно (начала дней в часовом поясе пользователя)
+	FromDate time.Time
+	ToDate   time.Time
+}
+
+// FoundPurchase трата из результатов поиска
+type FoundPurchase struct {
+	Date        time.Time
+	Category    string
+	Description string
+	Note        string
+	Tags        []string
+	Summa       float64 // в рублях от репозитория, в валюте пользователя в SearchResult
+
+	// коэффициенты валют на момент совершения траты
+	currency.RateToRUB
+}
+
+// SearchResult одна страница результатов поиска
+type SearchResult struct {
+	Items    []FoundPurchase
+	Currency currency.Currency
+	Page     int // номер страницы, начиная с 0
+	HasNext  bool
+}
+
+// ParseSearchQuery разбирает запрос команды /find. Условия разделяются пробелами и комбинируются через "и":
+// >5000 и <300 - границы суммы, 100..500 - диапазон суммы, 01.03.2024..31.03.2024 - диапазон дат,
+// 01.03.2024 - один день, все остальные слова ищутся в категории, заметке и описании траты
+func ParseSearchQuery(query string) (SearchFilter, error) {
+	var filter SearchFilter
+
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return SearchFilter{}, ErrSearchQueryParsing
+	}
+
+	for _, f := range fields {
+		switch {
+		case searchDateRange.MatchString(f):
+			res := searchDateRange.FindStringSubmatch(f)
+			from, errFrom := time.Parse("02.01.2006", res[1])
+			to, errTo := time.Parse("02.01.2006", res[2])
+			if errFrom != nil || errTo != nil || to.Before(from) {
+				return SearchFilter{}, errors.Wrap(ErrSearchQueryParsing, "invalid date range")
+			}
+			filter.FromDate, filter.ToDate = from, to
+
+		case searchDate.MatchString(f):
+			date, err := time.Parse("02.01.2006", f)
+			if err != nil {
+				return SearchFilter{}, errors.Wrap(ErrSearchQueryParsing, "invalid date")
+			}
+			filter.FromDate, filter.ToDate = date, date
+
+		case ruleAmountRange.MatchString(f):
+			rule, err := ParseRuleCondition(f)
+			if err != nil {
+				return SearchFilter{}, errors.Wrap(ErrSearchQueryParsing, "invalid amount range")
+			}
+			filter.MinSum, filter.MaxSum = rule.MinSum, rule.MaxSum
+
+		case ruleAmountBound.MatchString(f):
+			bound, err := strconv.ParseFloat(strings.TrimSpace(f[1:]), 64)
+			if err != nil {
+				return SearchFilter{}, errors.Wrap(ErrSearchQueryParsing, "invalid amount")
+			}
+			if f[0] == '>' {
+				filter.MinSum = bound
+			} else {
+				filter.MaxSum = bound
+			}
+
+		default:
+			filter.Words = append(filter.Words, strings.ToLower(f))
+		}
+	}
+
+	if filter.MaxSum != 0 && filter.MaxSum < filter.MinSum {
+		return SearchFilter{}, errors.Wrap(ErrSearchQueryParsing, "invalid amount range")
+	}
+
+	return filter, nil
+}
+
+// FindPurchases ищет траты пользователя по запросу команды /find. Суммы в ответе в валюте пользователя,
+// траты идут от новых к старым
+func (m *Model) FindPurchases(ctx context.Context, userID int64, query string, page int) (SearchResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "find purchases")
+	defer span.Finish()
+
+	if page < 0 {
+		page = 0
+	}
+
+	filter, err := ParseSearchQuery(query)
+	if err != nil {
+		return SearchResult{}, errors.Wrap(err, "ParseSearchQuery")
+	}
+
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return SearchResult{}, errors.Wrap(err, "repo.GetUserInfo")
+	}
+	filter.UserID = userID
+	filter.Currency = info.Currency
+
+	// даты из запроса - это дни в часовом поясе пользователя
+	loc := info.Location()
+	filter.FromDate = inLocation(filter.FromDate, loc)
+	filter.ToDate = inLocation(filter.ToDate, loc)
+
+	// берем на одну трату больше, чтобы понять, есть ли следующая страница
+	items, err := m.Repo.SearchPurchases(ctx, filter, SearchPageSize+1, uint64(page*SearchPageSize))
+	if err != nil {
+		return SearchResult{}, errors.Wrap(err, "repo.SearchPurchases")
+	}
+
+	res := SearchResult{Currency: info.Currency, Page: page}
+	if len(items) > SearchPageSize {
+		res.HasNext = true
+		items = items[:SearchPageSize]
+	}
+
+	for i := range items {
+		items[i].Summa, err = currency.RubToCurrentCurrency(info.Currency, items[i].Summa, items[i].RateToRUB)
+		if err != nil {
+			return SearchResult{}, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+		items[i].Date = items[i].Date.In(loc)
+	}
+	res.Items = items
+
+	return res, nil
+}
+
+// inLocation тот же календарный день, что и date, но в часовом поясе loc. Нулевая дата остается нулевой
+func inLocation(date time.Time, loc *time.Location) time.Time {
+	if date.IsZero() {
+		return date
+	}
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
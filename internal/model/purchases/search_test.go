@@ -0,0 +1,190 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_ParseSearchQuery(t *testing.T) {
+	date := func(raw string) time.Time {
+		d, _ := time.Parse("02.01.2006", raw)
+		return d
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  purchases.SearchFilter
+	}{
+		{
+			name:  "текст",
+			query: "Такси",
+			want:  purchases.SearchFilter{Words: []string{"такси"}},
+		},
+		{
+			name:  "несколько слов",
+			query: "такси аэропорт",
+			want:  purchases.SearchFilter{Words: []string{"такси", "аэропорт"}},
+		},
+		{
+			name:  "нижняя граница суммы",
+			query: ">5000",
+			want:  purchases.SearchFilter{MinSum: 5000},
+		},
+		{
+			name:  "верхняя граница суммы",
+			query: "<300.5",
+			want:  purchases.SearchFilter{MaxSum: 300.5},
+		},
+		{
+			name:  "диапазон суммы",
+			query: "100..500",
+			want:  purchases.SearchFilter{MinSum: 100, MaxSum: 500},
+		},
+		{
+			name:  "диапазон дат",
+			query: "01.03.2024..31.03.2024",
+			want:  purchases.SearchFilter{FromDate: date("01.03.2024"), ToDate: date("31.03.2024")},
+		},
+		{
+			name:  "один день",
+			query: "08.03.2024",
+			want:  purchases.SearchFilter{FromDate: date("08.03.2024"), ToDate: date("08.03.2024")},
+		},
+		{
+			name:  "все условия вместе",
+			query: "такси >1000 <3000 01.03.2024..31.03.2024",
+			want: purchases.SearchFilter{
+				Words:    []string{"такси"},
+				MinSum:   1000,
+				MaxSum:   3000,
+				FromDate: date("01.03.2024"),
+				ToDate:   date("31.03.2024"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := purchases.ParseSearchQuery(tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func Test_ParseSearchQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "пустой запрос", query: "  "},
+		{name: "перевернутый диапазон суммы", query: "500..100"},
+		{name: "границы суммы противоречат друг другу", query: ">500 <100"},
+		{name: "перевернутый диапазон дат", query: "31.03.2024..01.03.2024"},
+		{name: "несуществующая дата", query: "31.02.2024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := purchases.ParseSearchQuery(tt.query)
+
+			assert.ErrorIs(t, err, purchases.ErrSearchQueryParsing)
+		})
+	}
+}
+
+func Test_FindPurchases(t *testing.T) {
+	rates := currency.RateToRUB{USD: 0.5, EUR: 0.5, CNY: 0.5}
+
+	page := func(n int) []purchases.FoundPurchase {
+		res := make([]purchases.FoundPurchase, n)
+		for i := range res {
+			res[i] = purchases.FoundPurchase{Category: "такси", Summa: 100, RateToRUB: rates}
+		}
+		return res
+	}
+
+	t.Run("есть следующая страница, суммы в валюте пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{UserID: 123, Currency: currency.USD}, nil)
+		repo.EXPECT().SearchPurchases(gomock.Any(), purchases.SearchFilter{
+			UserID:   123,
+			Words:    []string{"такси"},
+			MinSum:   10,
+			Currency: currency.USD,
+		}, uint64(purchases.SearchPageSize+1), uint64(purchases.SearchPageSize)).Return(page(purchases.SearchPageSize+1), nil)
+
+		res, err := model.FindPurchases(ctx, 123, "такси >10", 1)
+
+		assert.NoError(t, err)
+		assert.True(t, res.HasNext)
+		assert.Equal(t, 1, res.Page)
+		assert.Len(t, res.Items, purchases.SearchPageSize)
+		assert.Equal(t, float64(50), res.Items[0].Summa)
+	})
+
+	t.Run("последняя страница", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{UserID: 123, Currency: currency.RUB}, nil)
+		repo.EXPECT().SearchPurchases(gomock.Any(), gomock.Any(), uint64(purchases.SearchPageSize+1), uint64(0)).
+			Return(page(3), nil)
+
+		res, err := model.FindPurchases(ctx, 123, "такси", 0)
+
+		assert.NoError(t, err)
+		assert.False(t, res.HasNext)
+		assert.Len(t, res.Items, 3)
+		assert.Equal(t, float64(100), res.Items[0].Summa)
+	})
+	t.Run("даты запроса - дни в часовом поясе пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).
+			Return(purchases.User{UserID: 123, Currency: currency.RUB, Timezone: "Asia/Vladivostok"}, nil)
+		repo.EXPECT().SearchPurchases(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, filter purchases.SearchFilter, _, _ uint64) ([]purchases.FoundPurchase, error) {
+				assert.True(t, time.Date(2024, 3, 1, 0, 0, 0, 0, vladivostok).Equal(filter.FromDate))
+				assert.True(t, time.Date(2024, 3, 31, 0, 0, 0, 0, vladivostok).Equal(filter.ToDate))
+				// 29.02 15:00 UTC - это уже 1 марта во Владивостоке
+				return []purchases.FoundPurchase{{
+					Date:      time.Date(2024, 2, 29, 15, 0, 0, 0, time.UTC),
+					Summa:     100,
+					RateToRUB: rates,
+				}}, nil
+			})
+
+		res, err := model.FindPurchases(ctx, 123, "01.03.2024..31.03.2024", 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Items, 1)
+		assert.Equal(t, "01.03.2024 01:00", res.Items[0].Date.Format("02.01.2006 15:04"))
+	})
+}
@@ -0,0 +1,42 @@
+package purchases
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/suggestions"
+)
+
+// suggestionsHistoryLimit сколько последних трат пользователя используется для обучения
+const suggestionsHistoryLimit = 1000
+
+// CategorizedPurchase описание траты и категория, в которую она попала
+type CategorizedPurchase struct {
+	Description  string
+	CategoryName string
+}
+
+// SuggestCategories сортирует категории по вероятности того, что трата с таким описанием относится к ним.
+// Вероятность оценивается классификатором, обученным на истории трат пользователя
+func (m *Model) SuggestCategories(ctx context.Context, userID int64, description string, categories []string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "suggest categories")
+	defer span.Finish()
+
+	history, err := m.Repo.GetUserCategorizedPurchases(ctx, userID, suggestionsHistoryLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.GetUserCategorizedPurchases")
+	}
+
+	samples := make([]suggestions.Sample, len(history))
+	for i, p := range history {
+		// у трат, добавленных сразу с категорией, описания нет, но само название категории тоже подсказка
+		desc := p.Description
+		if desc == "" {
+			desc = p.CategoryName
+		}
+		samples[i] = suggestions.Sample{Description: desc, Category: p.CategoryName}
+	}
+
+	return suggestions.Train(samples).Rank(description, categories), nil
+}
@@ -0,0 +1,79 @@
+package purchases
+
+import (
+	"context"
+	"sort"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+)
+
+// TaggedPurchase трата с одним из ее тегов
+type TaggedPurchase struct {
+	Tag   string
+	Summa float64 // сумма траты в рублях
+
+	// коэффициенты валют на момент совершения траты
+	currency.RateToRUB
+}
+
+// TagTotal сколько всего потрачено с тегом
+type TagTotal struct {
+	Tag   string
+	Summa float64 // в выбранной пользователем валюте
+	Count int     // сколько трат с этим тегом
+}
+
+// GetUserTags возвращает теги пользователя с суммами трат по ним (в выбранной валюте), самые крупные первыми
+func (m *Model) GetUserTags(ctx context.Context, userID int64) ([]TagTotal, currency.Currency, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "get user tags")
+	defer span.Finish()
+
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "repo.GetUserInfo")
+	}
+
+	purchases, err := m.Repo.GetUserTaggedPurchases(ctx, userID)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "repo.GetUserTaggedPurchases")
+	}
+
+	totals, err := sumByTags(purchases, info.Currency)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "sumByTags")
+	}
+
+	return totals, info.Currency, nil
+}
+
+// sumByTags складывает траты по тегам, переводя каждую в валюту по курсу на момент траты
+func sumByTags(purchases []TaggedPurchase, cy currency.Currency) ([]TagTotal, error) {
+	totals := make(map[string]TagTotal)
+	for _, p := range purchases {
+		sum, err := currency.RubToCurrentCurrency(cy, p.Summa, p.RateToRUB)
+		if err != nil {
+			return nil, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+		t := totals[p.Tag]
+		t.Summa += sum
+		t.Count++
+		totals[p.Tag] = t
+	}
+
+	res := make([]TagTotal, 0, len(totals))
+	for tag, t := range totals {
+		t.Tag = tag
+		res = append(res, t)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Summa != res[j].Summa {
+			return res[i].Summa > res[j].Summa
+		}
+		return res[i].Tag < res[j].Tag
+	})
+
+	return res, nil
+}
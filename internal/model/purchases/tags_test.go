@@ -0,0 +1,47 @@
+//go:build test_all || unit_test
+
+package purchases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+)
+
+func Test_sumByTags(t *testing.T) {
+	rates := currency.RateToRUB{USD: 0.5, EUR: 0.25, CNY: 2}
+
+	t.Run("суммы складываются по тегам и сортируются по убыванию", func(t *testing.T) {
+		res, err := sumByTags([]TaggedPurchase{
+			{Tag: "москва", Summa: 100, RateToRUB: rates},
+			{Tag: "командировка", Summa: 100, RateToRUB: rates},
+			{Tag: "командировка", Summa: 300, RateToRUB: rates},
+			{Tag: "отпуск", Summa: 100, RateToRUB: rates},
+		}, currency.RUB)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []TagTotal{
+			{Tag: "командировка", Summa: 400, Count: 2},
+			{Tag: "москва", Summa: 100, Count: 1},
+			{Tag: "отпуск", Summa: 100, Count: 1},
+		}, res)
+	})
+
+	t.Run("перевод в валюту пользователя по курсу на момент траты", func(t *testing.T) {
+		res, err := sumByTags([]TaggedPurchase{
+			{Tag: "отпуск", Summa: 100, RateToRUB: rates},
+			{Tag: "отпуск", Summa: 100, RateToRUB: currency.RateToRUB{USD: 1, EUR: 1, CNY: 1}},
+		}, currency.USD)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []TagTotal{{Tag: "отпуск", Summa: 100*0.5 + 100, Count: 2}}, res)
+	})
+
+	t.Run("нет трат с тегами", func(t *testing.T) {
+		res, err := sumByTags(nil, currency.RUB)
+
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
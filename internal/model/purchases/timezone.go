@@ -0,0 +1,78 @@
+package purchases
+
+import (
+	"context"
+	"strings"
+	"time"
+	// база часовых поясов вшивается в бинарник, чтобы /tz работал одинаково в любом контейнере
+	_ "time/tzdata"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+// DefaultTimezone часовой пояс пользователя, пока он не выбрал свой командой /tz
+const DefaultTimezone = "Europe/Moscow"
+
+// LoadTimezone проверяет название часового пояса из базы IANA (Asia/Vladivostok) и загружает его
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	// пустое название и Local time.LoadLocation понимает как UTC и пояс сервера, пользователю они не нужны
+	if name == "" || name == "Local" {
+		return nil, ErrUnknownTimezone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Wrap(ErrUnknownTimezone, err.Error())
+	}
+	return loc, nil
+}
+
+// Location часовой пояс пользователя. Если пояс не выбран или в базе лежит неизвестный, берется DefaultTimezone
+func (u User) Location() *time.Location {
+	if loc, err := LoadTimezone(u.Timezone); err == nil {
+		return loc
+	}
+	loc, _ := LoadTimezone(DefaultTimezone)
+	return loc
+}
+
+// ChangeUserTimezone выбор часового пояса, в котором считаются даты трат, границы дней, недель и месяцев
+func (m *Model) ChangeUserTimezone(ctx context.Context, userID int64, name string) (*time.Location, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "change user timezone")
+	defer span.Finish()
+
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.Repo.ChangeTimezone(ctx, userID, loc.String()); err != nil {
+		return nil, errors.Wrap(err, "repo.ChangeTimezone")
+	}
+	return loc, nil
+}
+
+// GetUserLocation часовой пояс пользователя
+func (m *Model) GetUserLocation(ctx context.Context, userID int64) (*time.Location, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "get user location")
+	defer span.Finish()
+
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.GetUserInfo")
+	}
+	return info.Location(), nil
+}
+
+// userNow текущее время в часовом поясе пользователя
+func (m *Model) userNow(info User) time.Time {
+	return m.Now().In(info.Location())
+}
+
+// startOfDay начало дня t в часовом поясе t
+func startOfDay(t time.Time) time.Time {
+	y, mon, d := t.Date()
+	return time.Date(y, mon, d, 0, 0, 0, 0, t.Location())
+}
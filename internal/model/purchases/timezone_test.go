@@ -0,0 +1,90 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_ChangeUserTimezone(t *testing.T) {
+	t.Run("часовой пояс из базы IANA сохраняется", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		repo.EXPECT().ChangeTimezone(gomock.Any(), int64(123), "Asia/Vladivostok").Return(nil)
+
+		loc, err := model.ChangeUserTimezone(ctx, 123, "Asia/Vladivostok")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Vladivostok", loc.String())
+	})
+
+	for _, name := range []string{"", "Local", "Moscow", "Asia/Nowhere", "../etc/passwd"} {
+		name := name
+		t.Run("неизвестный часовой пояс: "+name, func(t *testing.T) {
+			ctx := context.Background()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockRepo(ctrl)
+			model := purchases.New(repo, nil, nil, nil)
+
+			_, err := model.ChangeUserTimezone(ctx, 123, name)
+
+			assert.ErrorIs(t, err, purchases.ErrUnknownTimezone)
+		})
+	}
+}
+
+func Test_UserLocation(t *testing.T) {
+	t.Run("пояс не выбран", func(t *testing.T) {
+		assert.Equal(t, purchases.DefaultTimezone, purchases.User{}.Location().String())
+	})
+
+	t.Run("пояс выбран", func(t *testing.T) {
+		assert.Equal(t, "America/New_York", purchases.User{Timezone: "America/New_York"}.Location().String())
+	})
+}
+
+func Test_CreateReportRequest_Timezone(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockRepo(ctrl)
+	broker := mocks.NewMockBrokerMsgCreator(ctrl)
+	model := purchases.New(repo, nil, nil, broker)
+	// 30.11 14:30 по UTC - это уже 1 декабря во Владивостоке
+	model.Now = func() time.Time { return time.Date(2022, 11, 30, 14, 30, 0, 0, time.UTC) }
+
+	vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+	assert.NoError(t, err)
+
+	period, err := model.ToPeriod("month")
+	assert.NoError(t, err)
+
+	repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).
+		Return(purchases.User{UserID: 123, Currency: currency.RUB, Timezone: "Asia/Vladivostok"}, nil)
+	broker.EXPECT().SendNewMsg("get_report", gomock.Any()).DoAndReturn(func(_, value string) error {
+		var req purchases.ReportRequest
+		assert.NoError(t, json.Unmarshal([]byte(value), &req))
+
+		// месяц отсчитывается от 1 декабря по Владивостоку
+		assert.True(t, time.Date(2022, 11, 1, 0, 0, 0, 0, vladivostok).Equal(req.FromDate), req.FromDate)
+		return nil
+	})
+
+	err = model.CreateReportRequest(ctx, period, 123, i18n.RU, purchases.ReportOptions{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,66 @@
+package purchases
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"go.uber.org/zap"
+)
+
+// UnusualPurchase трата намного больше обычной для своей категории
+type UnusualPurchase struct {
+	Typical float64 // обычная сумма траты в категории (медиана за 90 дней) в валюте пользователя
+}
+
+// ChangeUserConfirmUnusual спрашивать ли подтверждение перед записью необычно крупной траты.
+// Без подтверждения такая трата записывается сразу, а в ответе на нее есть предупреждение
+func (m *Model) ChangeUserConfirmUnusual(ctx context.Context, userID int64, confirm bool) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "change user confirm unusual")
+	defer span.Finish()
+
+	if err := m.Repo.ChangeConfirmUnusual(ctx, userID, confirm); err != nil {
+		return errors.Wrap(err, "repo.ChangeConfirmUnusual")
+	}
+	return nil
+}
+
+// checkUnusual сравнивает трату с обычными тратами пользователя в категории. Возвращает статистику категории,
+// чтобы после добавления траты обновить ее без повторного чтения. Траты без категории не проверяются: в ней
+// собраны самые разные траты. Статистика вспомогательная, поэтому ошибка ее чтения не мешает добавить трату
+func (m *Model) checkUnusual(ctx context.Context, userID int64, categoryID uint64, sumRUB float64, now time.Time,
+	userCurrency currency.Currency, rates currency.RateToRUB) (anomaly.Stats, *UnusualPurchase, bool) {
+	if categoryID == defaultCategoryID {
+		return anomaly.Stats{}, nil, false
+	}
+
+	stats, err := m.Repo.GetCategoryStats(ctx, userID, categoryID)
+	if err != nil {
+		logs.Error("get category stats failed", zap.Error(err), zap.Int64("userId", userID))
+		return anomaly.Stats{}, nil, false
+	}
+
+	res := stats.Check(sumRUB, now)
+	if !res.Unusual {
+		return stats, nil, true
+	}
+
+	typical, err := currency.RubToCurrentCurrency(userCurrency, res.Median, rates)
+	if err != nil {
+		logs.Error("typical purchase conversion failed", zap.Error(err), zap.Int64("userId", userID))
+		return stats, nil, true
+	}
+	return stats, &UnusualPurchase{Typical: typical}, true
+}
+
+// updateStats добавляет трату в статистику категории. Трата уже записана, поэтому ошибка только логируется
+func (m *Model) updateStats(ctx context.Context, userID int64, categoryID uint64, stats anomaly.Stats, sumRUB float64, date, now time.Time) {
+	stats = stats.Add(sumRUB, date, now)
+	if err := m.Repo.SaveCategoryStats(ctx, userID, categoryID, stats); err != nil {
+		logs.Error("save category stats failed", zap.Error(err), zap.Int64("userId", userID))
+	}
+}
@@ -0,0 +1,117 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/anomaly"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_AddPurchase_Unusual(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 11, 15, 12, 0, 0, 0, time.UTC)
+	rates := currency.RateToRUB{USD: 0.5, EUR: 1, CNY: 1}
+
+	// обычно в категории тратят около 200 рублей
+	stats := anomaly.Stats{}
+	for _, sum := range []float64{150, 200, 200, 250, 200} {
+		stats = stats.Add(sum, now.AddDate(0, 0, -1), now)
+	}
+
+	modelUp := func(t *testing.T, confirm bool) (*purchases.Model, *mocks.MockRepo, *mocks.MockReportsStore) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		excRateModel := mocks.NewMockExchangeRateGetter(ctrl)
+		redis := mocks.NewMockReportsStore(ctrl)
+		excRateModel.EXPECT().GetExchangeRateToRUB().Return(rates).AnyTimes()
+
+		repo.EXPECT().GetCategoryID(gomock.Any(), "Кафе").Return(uint64(2), nil)
+		repo.EXPECT().UserHasCategory(gomock.Any(), int64(123), uint64(2)).Return(true, nil)
+		repo.EXPECT().GetUserInfo(gomock.Any(), int64(123)).Return(purchases.User{
+			UserID:         123,
+			Currency:       currency.USD,
+			Limit:          -1,
+			Timezone:       "UTC",
+			ConfirmUnusual: confirm,
+		}, nil)
+		repo.EXPECT().GetCategoryStats(gomock.Any(), int64(123), uint64(2)).Return(stats, nil)
+
+		model := purchases.New(repo, excRateModel, redis, nil)
+		model.Now = func() time.Time { return now }
+		return model, repo, redis
+	}
+
+	t.Run("обычная трата", func(t *testing.T) {
+		model, repo, redis := modelUp(t, true)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(0), nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().SaveCategoryStats(gomock.Any(), int64(123), uint64(2), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ int64, _ uint64, s anomaly.Stats) error {
+				assert.Len(t, s.Samples, 6)
+				// 120 долларов в рублях
+				assert.Equal(t, anomaly.Sample{Sum: 240, At: now}, s.Samples[5])
+				return nil
+			})
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		res, err := model.AddPurchase(ctx, 123, "120", "Кафе", "")
+
+		assert.NoError(t, err)
+		assert.Nil(t, res.Unusual)
+	})
+
+	t.Run("крупная трата записывается с предупреждением", func(t *testing.T) {
+		model, repo, redis := modelUp(t, false)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(0), nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().SaveCategoryStats(gomock.Any(), int64(123), uint64(2), gomock.Any()).Return(nil)
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		res, err := model.AddPurchase(ctx, 123, "1000", "Кафе", "")
+
+		assert.NoError(t, err)
+		// обычная трата в долларах
+		assert.Equal(t, &purchases.UnusualPurchase{Typical: 100}, res.Unusual)
+	})
+
+	t.Run("крупная трата ждет подтверждения", func(t *testing.T) {
+		model, _, _ := modelUp(t, true)
+
+		res, err := model.AddPurchase(ctx, 123, "1000", "Кафе", "")
+
+		assert.ErrorIs(t, err, purchases.ErrUnusualPurchase)
+		assert.Equal(t, &purchases.UnusualPurchase{Typical: 100}, res.Unusual)
+		assert.Equal(t, currency.USD, res.Currency)
+	})
+
+	t.Run("подтвержденная крупная трата", func(t *testing.T) {
+		model, repo, redis := modelUp(t, true)
+		repo.EXPECT().GetUserPurchasesSumFromMonth(gomock.Any(), int64(123), gomock.Any()).Return(float64(0), nil)
+		repo.EXPECT().AddPurchase(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().SaveCategoryStats(gomock.Any(), int64(123), uint64(2), gomock.Any()).Return(nil)
+		redis.EXPECT().Delete(gomock.Any(), "123report")
+
+		res, err := model.AddPurchase(ctx, 123, "1000", "Кафе", "", purchases.Confirmed())
+
+		assert.NoError(t, err)
+		assert.Nil(t, res.Unusual)
+	})
+}
+
+func Test_ChangeUserConfirmUnusual(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockRepo(ctrl)
+	model := purchases.New(repo, nil, nil, nil)
+
+	repo.EXPECT().ChangeConfirmUnusual(gomock.Any(), int64(123), true).Return(nil)
+
+	assert.NoError(t, model.ChangeUserConfirmUnusual(context.Background(), 123, true))
+}
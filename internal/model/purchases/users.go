@@ -3,11 +3,11 @@ package purchases
 import (
 	"context"
 	"strconv"
-	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/normalize"
 )
 
@@ -16,6 +16,10 @@ type User struct {
 	Currency   currency.Currency // выбранная пользователем валюта
 	Categories []int64
 	Limit      float64
+	Lang       i18n.Lang // пустой, если пользователь не выбирал язык
+	Timezone   string    // часовой пояс IANA, пустой, если пользователь его не выбирал
+	// ConfirmUnusual спрашивать ли подтверждение перед записью необычно крупной траты
+	ConfirmUnusual bool
 }
 
 func (m *Model) ChangeUserCurrency(ctx context.Context, userID int64, currency currency.Currency) error {
@@ -29,21 +33,53 @@ func (m *Model) ChangeUserCurrency(ctx context.Context, userID int64, currency c
 	return nil
 }
 
+// ChangeUserLang выбор языка интерфейса
+func (m *Model) ChangeUserLang(ctx context.Context, userID int64, lang i18n.Lang) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "change user lang")
+	defer span.Finish()
+
+	if err := m.Repo.ChangeLang(ctx, userID, lang); err != nil {
+		return errors.Wrap(err, "repo.ChangeLang")
+	}
+	return nil
+}
+
+// GetUserLang язык, выбранный пользователем. Пустой, если он его не выбирал
+func (m *Model) GetUserLang(ctx context.Context, userID int64) (i18n.Lang, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "get user lang")
+	defer span.Finish()
+
+	info, err := m.Repo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return "", errors.Wrap(err, "repo.GetUserInfo")
+	}
+	return info.Lang, nil
+}
+
 func (m *Model) ChangeUserLimit(ctx context.Context, userID int64, rawLimit string) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "change user limit")
 	defer span.Finish()
 
 	limitCurrency, err := strconv.ParseFloat(rawLimit, 64)
-	if err != nil {
+	if err != nil || (limitCurrency < 0 && limitCurrency != -1) {
 		return ErrLimitParsing
 	}
 
+	// -1 значит, что лимита нет, в рубли его переводить не нужно
+	if limitCurrency == -1 {
+		if err = m.Repo.ChangeUserLimit(ctx, userID, -1); err != nil {
+			return errors.Wrap(err, "repo.ChangeUserLimit")
+		}
+		return nil
+	}
+
 	info, err := m.Repo.GetUserInfo(ctx, userID)
 	if err != nil {
 		return errors.Wrap(err, "repo.GetUserInfo")
 	}
 
-	y, month, d := time.Now().Date()
+	// лимит переводится в рубли по курсу на сегодняшний день пользователя
+	y, month, d := m.userNow(info).Date()
 	rates, err := m.getTodayRates(ctx, y, int(month), d)
 	if err != nil {
 		return errors.Wrap(err, "getTodayRates")
@@ -0,0 +1,41 @@
+//go:build test_all || unit_test
+
+package purchases_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases/_mocks"
+)
+
+func Test_ChangeUserLimit(t *testing.T) {
+	t.Run("снятие лимита не зависит от валюты пользователя", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		repo.EXPECT().ChangeUserLimit(gomock.Any(), int64(123), float64(-1)).Return(nil)
+
+		err := model.ChangeUserLimit(ctx, 123, "-1")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("отрицательный лимит", func(t *testing.T) {
+		ctx := context.Background()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockRepo(ctrl)
+		model := purchases.New(repo, nil, nil, nil)
+
+		err := model.ChangeUserLimit(ctx, 123, "-100")
+
+		assert.ErrorIs(t, err, purchases.ErrLimitParsing)
+	})
+}
@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/model/ratelimit/limiter.go
+
+// Package mock_ratelimit is a generated GoMock package.
+package mock_ratelimit
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// TakeToken mocks base method.
+func (m *MockStore) TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeToken", ctx, key, rate, burst)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(int)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// TakeToken indicates an expected call of TakeToken.
+func (mr *MockStoreMockRecorder) TakeToken(ctx, key, rate, burst interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeToken", reflect.TypeOf((*MockStore)(nil).TakeToken), ctx, key, rate, burst)
+}
+
+// MockconfigGetter is a mock of configGetter interface.
+type MockconfigGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockconfigGetterMockRecorder
+}
+
+// MockconfigGetterMockRecorder is the mock recorder for MockconfigGetter.
+type MockconfigGetterMockRecorder struct {
+	mock *MockconfigGetter
+}
+
+// NewMockconfigGetter creates a new mock instance.
+func NewMockconfigGetter(ctrl *gomock.Controller) *MockconfigGetter {
+	mock := &MockconfigGetter{ctrl: ctrl}
+	mock.recorder = &MockconfigGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockconfigGetter) EXPECT() *MockconfigGetterMockRecorder {
+	return m.recorder
+}
+
+// RateLimit mocks base method.
+func (m *MockconfigGetter) RateLimit(class string) (float64, int, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimit", class)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// RateLimit indicates an expected call of RateLimit.
+func (mr *MockconfigGetterMockRecorder) RateLimit(class interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimit", reflect.TypeOf((*MockconfigGetter)(nil).RateLimit), class)
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"go.uber.org/zap"
+)
+
+// Классы команд: у каждого свое ограничение частоты запросов
+const (
+	// ClassDefault все, что не требует тяжелой работы: добавление трат, настройки, кнопки
+	ClassDefault = "default"
+	// ClassHeavy команды, которые нагружают базу и сервис отчетов: отчеты, поиск, теги
+	ClassHeavy = "heavy"
+	// ClassInline подсказки в inline-режиме: запрос приходит на каждую набранную букву и считает траты в базе
+	ClassInline = "inline"
+)
+
+// Limit ограничение частоты запросов: в среднем Rate запросов в минуту, подряд - не больше Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultLimits ограничения для классов, которые не заданы в конфиге
+var DefaultLimits = map[string]Limit{
+	ClassDefault: {Rate: 60, Burst: 20},
+	ClassHeavy:   {Rate: 6, Burst: 3},
+	ClassInline:  {Rate: 120, Burst: 30},
+}
+
+// Decision можно ли выполнить запрос пользователя
+type Decision struct {
+	Allowed bool
+	// RetryAfter через сколько можно будет повторить запрос, если он не разрешен
+	RetryAfter time.Duration
+	// Notify первый отказ подряд: пользователю стоит сказать, что он упирается в ограничение. На следующие
+	// отказы отвечать не нужно, иначе флуд пользователя превращается во флуд бота
+	Notify bool
+}
+
+// Store хранилище корзин токенов, общее для всех реплик бота
+type Store interface {
+	// TakeToken забирает токен из корзины key, которая наполняется со скоростью rate токенов в секунду до burst.
+	// Возвращает, получилось ли забрать токен, через сколько появится следующий и сколько отказов было подряд
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, denials int, err error)
+}
+
+type configGetter interface {
+	// RateLimit ограничение класса class из конфига: запросов в минуту и сколько подряд, ok - задано ли оно
+	RateLimit(class string) (rate float64, burst int, ok bool)
+}
+
+// Limiter ограничивает частоту запросов каждого пользователя отдельно по каждому классу команд
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+}
+
+func New(store Store, conf configGetter) *Limiter {
+	limits := make(map[string]Limit, len(DefaultLimits))
+	for class, limit := range DefaultLimits {
+		if rate, burst, ok := conf.RateLimit(class); ok {
+			limit = Limit{Rate: rate, Burst: burst}
+		}
+		limits[class] = limit
+	}
+
+	return &Limiter{store: store, limits: limits}
+}
+
+// Allow тратит один запрос пользователя userID из ограничения класса class. Класс без ограничения не ограничивается.
+// Если хранилище недоступно, запрос разрешается: лучше пропустить флуд, чем отказать всем
+func (l *Limiter) Allow(ctx context.Context, userID int64, class string) Decision {
+	limit, ok := l.limits[class]
+	if !ok || limit.Rate <= 0 || limit.Burst <= 0 {
+		return Decision{Allowed: true}
+	}
+
+	allowed, retryAfter, denials, err := l.store.TakeToken(ctx, key(userID, class), limit.Rate/60, limit.Burst)
+	if err != nil {
+		logs.Error("rate limit check failed", zap.Error(err), zap.Int64("userId", userID), zap.String("class", class))
+		return Decision{Allowed: true}
+	}
+
+	return Decision{
+		Allowed:    allowed,
+		RetryAfter: retryAfter,
+		Notify:     !allowed && denials == 1,
+	}
+}
+
+func key(userID int64, class string) string {
+	return strconv.FormatInt(userID, 10) + class + "limit"
+}
@@ -0,0 +1,84 @@
+//go:build test_all || unit_test
+
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/ratelimit/_mocks"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+)
+
+type config map[string]ratelimit.Limit
+
+func (c config) RateLimit(class string) (float64, int, bool) {
+	limit, ok := c[class]
+	return limit.Rate, limit.Burst, ok
+}
+
+func Test_Allow_UsesConfiguredLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	limiter := ratelimit.New(store, config{ratelimit.ClassHeavy: {Rate: 3, Burst: 2}})
+
+	// 3 запроса в минуту - 0.05 токена в секунду
+	store.EXPECT().TakeToken(gomock.Any(), "123heavylimit", 0.05, 2).Return(true, time.Duration(0), 0, nil)
+
+	assert.Equal(t, ratelimit.Decision{Allowed: true}, limiter.Allow(context.Background(), 123, ratelimit.ClassHeavy))
+}
+
+func Test_Allow_DefaultLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	limiter := ratelimit.New(store, config{})
+
+	def := ratelimit.DefaultLimits[ratelimit.ClassDefault]
+	store.EXPECT().TakeToken(gomock.Any(), "123defaultlimit", def.Rate/60, def.Burst).Return(true, time.Duration(0), 0, nil)
+
+	assert.True(t, limiter.Allow(context.Background(), 123, ratelimit.ClassDefault).Allowed)
+}
+
+func Test_Allow_UnknownClass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	limiter := ratelimit.New(mocks.NewMockStore(ctrl), config{})
+
+	assert.True(t, limiter.Allow(context.Background(), 123, "other").Allowed)
+}
+
+func Test_Allow_DisabledClass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	limiter := ratelimit.New(mocks.NewMockStore(ctrl), config{ratelimit.ClassHeavy: {}})
+
+	assert.True(t, limiter.Allow(context.Background(), 123, ratelimit.ClassHeavy).Allowed)
+}
+
+func Test_Allow_NotifyOnlyOnFirstDenial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	limiter := ratelimit.New(store, config{})
+
+	gomock.InOrder(
+		store.EXPECT().TakeToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(false, 10*time.Second, 1, nil),
+		store.EXPECT().TakeToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(false, 9*time.Second, 2, nil),
+	)
+
+	assert.Equal(t, ratelimit.Decision{RetryAfter: 10 * time.Second, Notify: true}, limiter.Allow(context.Background(), 123, ratelimit.ClassHeavy))
+	assert.Equal(t, ratelimit.Decision{RetryAfter: 9 * time.Second}, limiter.Allow(context.Background(), 123, ratelimit.ClassHeavy))
+}
+
+func Test_Allow_StoreError(t *testing.T) {
+	logs.InitLogger()
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	limiter := ratelimit.New(store, config{})
+
+	store.EXPECT().TakeToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(false, time.Duration(0), 0, errors.New("redis is down"))
+
+	assert.True(t, limiter.Allow(context.Background(), 123, ratelimit.ClassHeavy).Allowed)
+}
@@ -0,0 +1,230 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/model/reminders/scheduler.go
+
+// Package mock_reminders is a generated GoMock package.
+package mock_reminders
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	i18n "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	purchases "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// MockUsersModel is a mock of UsersModel interface.
+type MockUsersModel struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersModelMockRecorder
+}
+
+// MockUsersModelMockRecorder is the mock recorder for MockUsersModel.
+type MockUsersModelMockRecorder struct {
+	mock *MockUsersModel
+}
+
+// NewMockUsersModel creates a new mock instance.
+func NewMockUsersModel(ctrl *gomock.Controller) *MockUsersModel {
+	mock := &MockUsersModel{ctrl: ctrl}
+	mock.recorder = &MockUsersModelMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsersModel) EXPECT() *MockUsersModelMockRecorder {
+	return m.recorder
+}
+
+// CreateReportRequest mocks base method.
+func (m *MockUsersModel) CreateReportRequest(ctx context.Context, period purchases.Period, userID int64, lang i18n.Lang, opts purchases.ReportOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReportRequest", ctx, period, userID, lang, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReportRequest indicates an expected call of CreateReportRequest.
+func (mr *MockUsersModelMockRecorder) CreateReportRequest(ctx, period, userID, lang, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReportRequest", reflect.TypeOf((*MockUsersModel)(nil).CreateReportRequest), ctx, period, userID, lang, opts)
+}
+
+// GetDigestSubscriptions mocks base method.
+func (m *MockUsersModel) GetDigestSubscriptions(ctx context.Context) ([]purchases.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigestSubscriptions", ctx)
+	ret0, _ := ret[0].([]purchases.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigestSubscriptions indicates an expected call of GetDigestSubscriptions.
+func (mr *MockUsersModelMockRecorder) GetDigestSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigestSubscriptions", reflect.TypeOf((*MockUsersModel)(nil).GetDigestSubscriptions), ctx)
+}
+
+// GetReminders mocks base method.
+func (m *MockUsersModel) GetReminders(ctx context.Context) ([]purchases.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminders", ctx)
+	ret0, _ := ret[0].([]purchases.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminders indicates an expected call of GetReminders.
+func (mr *MockUsersModelMockRecorder) GetReminders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminders", reflect.TypeOf((*MockUsersModel)(nil).GetReminders), ctx)
+}
+
+// HasPurchasesFrom mocks base method.
+func (m *MockUsersModel) HasPurchasesFrom(ctx context.Context, userID int64, from time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPurchasesFrom", ctx, userID, from)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPurchasesFrom indicates an expected call of HasPurchasesFrom.
+func (mr *MockUsersModelMockRecorder) HasPurchasesFrom(ctx, userID, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPurchasesFrom", reflect.TypeOf((*MockUsersModel)(nil).HasPurchasesFrom), ctx, userID, from)
+}
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// LastTick mocks base method.
+func (m *MockStore) LastTick(ctx context.Context, name string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastTick", ctx, name)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastTick indicates an expected call of LastTick.
+func (mr *MockStoreMockRecorder) LastTick(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastTick", reflect.TypeOf((*MockStore)(nil).LastTick), ctx, name)
+}
+
+// Lock mocks base method.
+func (m *MockStore) Lock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, name, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockStoreMockRecorder) Lock(ctx, name, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockStore)(nil).Lock), ctx, name, ttl)
+}
+
+// MarkSent mocks base method.
+func (m *MockStore) MarkSent(ctx context.Context, userID int64, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, userID, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockStoreMockRecorder) MarkSent(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockStore)(nil).MarkSent), ctx, userID, key)
+}
+
+// SetLastTick mocks base method.
+func (m *MockStore) SetLastTick(ctx context.Context, name string, t time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLastTick", ctx, name, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLastTick indicates an expected call of SetLastTick.
+func (mr *MockStoreMockRecorder) SetLastTick(ctx, name, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLastTick", reflect.TypeOf((*MockStore)(nil).SetLastTick), ctx, name, t)
+}
+
+// UnmarkSent mocks base method.
+func (m *MockStore) UnmarkSent(ctx context.Context, userID int64, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarkSent", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmarkSent indicates an expected call of UnmarkSent.
+func (mr *MockStoreMockRecorder) UnmarkSent(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarkSent", reflect.TypeOf((*MockStore)(nil).UnmarkSent), ctx, userID, key)
+}
+
+// MockMessageSender is a mock of MessageSender interface.
+type MockMessageSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageSenderMockRecorder
+}
+
+// MockMessageSenderMockRecorder is the mock recorder for MockMessageSender.
+type MockMessageSenderMockRecorder struct {
+	mock *MockMessageSender
+}
+
+// NewMockMessageSender creates a new mock instance.
+func NewMockMessageSender(ctrl *gomock.Controller) *MockMessageSender {
+	mock := &MockMessageSender{ctrl: ctrl}
+	mock.recorder = &MockMessageSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageSender) EXPECT() *MockMessageSenderMockRecorder {
+	return m.recorder
+}
+
+// SendMessage mocks base method.
+func (m *MockMessageSender) SendMessage(text string, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", text, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockMessageSenderMockRecorder) SendMessage(text, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockMessageSender)(nil).SendMessage), text, userID)
+}
@@ -0,0 +1,54 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+)
+
+// maxDigestsPerTick сколько отчетов запрашивается за тик. Отчеты собирает сервис отчетов по одному,
+// поэтому запросы растягиваются на несколько тиков, а отчеты по /report не ждут в очереди за ними
+const maxDigestsPerTick = 100
+
+// sendDigests запрашивает отчеты за прошедший период у подписчиков, для которых он закончился.
+// Готовый отчет сервис отчетов присылает боту, как и отчет по /report
+func (s *Scheduler) sendDigests(ctx context.Context, now time.Time) error {
+	subscriptions, err := s.users.GetDigestSubscriptions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "users.GetDigestSubscriptions")
+	}
+
+	sent := 0
+	for _, sub := range subscriptions {
+		if sent >= maxDigestsPerTick {
+			break
+		}
+
+		to, due := sub.Due(now)
+		if !due {
+			continue
+		}
+		key := digestKey + sub.Period.String() + to.Format(dayLayout)
+
+		marked, err := s.store.MarkSent(ctx, sub.UserID, key)
+		if err != nil {
+			return errors.Wrap(err, "store.MarkSent")
+		}
+		if !marked {
+			continue
+		}
+
+		err = s.users.CreateReportRequest(ctx, sub.Period, sub.UserID, sub.Lang, purchases.ReportOptions{DigestTo: to})
+		if err != nil {
+			s.unmark(ctx, sub.UserID, key)
+			return errors.Wrap(err, "users.CreateReportRequest")
+		}
+		metrics.Digests.Inc()
+		sent++
+	}
+
+	return nil
+}
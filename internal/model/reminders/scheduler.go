@@ -0,0 +1,185 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// tickInterval как часто проверяются напоминания
+	tickInterval = time.Minute
+	// lockTTL блокировка тика истекает чуть раньше следующего тика, поэтому каждый тик обрабатывает ровно одна реплика
+	lockTTL  = tickInterval - 5*time.Second
+	lockName = "reminders"
+
+	// maxPerTick сколько напоминаний ставится в очередь отправки за тик. Очередь отправляет не больше 30 сообщений
+	// в секунду, за минуту она успевает их разослать и не задерживает ответы на команды. Остальные напоминания
+	// уйдут на следующих тиках
+	maxPerTick = 1000
+
+	dayLayout = "2006-01-02"
+	// ключи отметок об отправке: напоминание за день и отчет за период
+	remindKey = "remind"
+	digestKey = "digest"
+)
+
+// UsersModel напоминания и подписки пользователей, их траты и запросы отчетов
+type UsersModel interface {
+	GetReminders(ctx context.Context) ([]purchases.Reminder, error)
+	HasPurchasesFrom(ctx context.Context, userID int64, from time.Time) (bool, error)
+	GetDigestSubscriptions(ctx context.Context) ([]purchases.Subscription, error)
+	CreateReportRequest(ctx context.Context, period purchases.Period, userID int64, lang i18n.Lang, opts purchases.ReportOptions) error
+}
+
+// Store блокировки и отметки об отправленных сообщениях, общие для всех реплик бота
+type Store interface {
+	Lock(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	LastTick(ctx context.Context, name string) (time.Time, error)
+	SetLastTick(ctx context.Context, name string, t time.Time) error
+	MarkSent(ctx context.Context, userID int64, key string) (bool, error)
+	UnmarkSent(ctx context.Context, userID int64, key string) error
+}
+
+// MessageSender отправка сообщений через очередь исходящих сообщений, которая соблюдает ограничения телеграма
+type MessageSender interface {
+	SendMessage(text string, userID int64) error
+}
+
+// Scheduler раз в минуту напоминает записать траты тем, у кого наступило время напоминания, а трат за день нет,
+// и запрашивает отчеты по подпискам за прошедшие недели и месяцы. Отправка отмечается в хранилище, поэтому
+// сообщение уходит один раз, даже если реплик несколько или бот перезапустился. Время последней проверки напоминаний
+// тоже хранится там: если бот лежал во время отправки, напоминание уйдет после запуска в тот же день
+type Scheduler struct {
+	users  UsersModel
+	store  Store
+	sender MessageSender
+
+	now func() time.Time
+}
+
+func New(users UsersModel, store Store, sender MessageSender) *Scheduler {
+	return &Scheduler{
+		users:  users,
+		store:  store,
+		sender: sender,
+		now:    time.Now,
+	}
+}
+
+// Run проверяет напоминания и отчеты, пока не отменен ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx); err != nil {
+			logs.Error("reminders tick failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick отправляет напоминания и отчеты, время которых наступило. Тик пропускается,
+// если его уже обрабатывает другая реплика
+func (s *Scheduler) Tick(ctx context.Context) error {
+	locked, err := s.store.Lock(ctx, lockName, lockTTL)
+	if err != nil {
+		return errors.Wrap(err, "store.Lock")
+	}
+	if !locked {
+		return nil
+	}
+
+	now := s.now()
+	// ошибка в напоминаниях не должна задерживать отчеты и наоборот
+	remindErr := s.remind(ctx, now)
+	if err = s.sendDigests(ctx, now); err != nil {
+		return errors.Wrap(err, "sendDigests")
+	}
+	if remindErr != nil {
+		return errors.Wrap(remindErr, "remind")
+	}
+	return nil
+}
+
+// remind отправляет напоминания, время которых наступило после прошлой полностью обработанной проверки
+func (s *Scheduler) remind(ctx context.Context, now time.Time) error {
+	last, err := s.store.LastTick(ctx, remindKey)
+	if err != nil {
+		return errors.Wrap(err, "store.LastTick")
+	}
+	if last.IsZero() {
+		last = now.Add(-tickInterval)
+	}
+
+	reminders, err := s.users.GetReminders(ctx)
+	if err != nil {
+		return errors.Wrap(err, "users.GetReminders")
+	}
+
+	sent := 0
+	for _, r := range reminders {
+		if sent >= maxPerTick {
+			// время проверки не сдвигается: не отправленные напоминания уйдут на следующем тике
+			return nil
+		}
+
+		from, due := r.Due(last, now)
+		if !due {
+			continue
+		}
+		key := remindKey + from.Format(dayLayout)
+
+		// отметка ставится до отправки: пока одна реплика отправляет, другая этого пользователя пропустит
+		marked, err := s.store.MarkSent(ctx, r.UserID, key)
+		if err != nil {
+			return errors.Wrap(err, "store.MarkSent")
+		}
+		if !marked {
+			continue
+		}
+
+		has, err := s.users.HasPurchasesFrom(ctx, r.UserID, from)
+		if err != nil {
+			s.unmark(ctx, r.UserID, key)
+			return errors.Wrap(err, "users.HasPurchasesFrom")
+		}
+		if has {
+			// за этот день больше не проверяем: траты уже есть
+			metrics.Reminders.WithLabelValues(metrics.ReminderResultSkipped).Inc()
+			continue
+		}
+
+		// очередь переполнена - остальным напомним на следующем тике
+		if err = s.sender.SendMessage(i18n.T(r.Lang, TxtReminder), r.UserID); err != nil {
+			s.unmark(ctx, r.UserID, key)
+			return errors.Wrap(err, "sender.SendMessage")
+		}
+		metrics.Reminders.WithLabelValues(metrics.ReminderResultSent).Inc()
+		sent++
+	}
+
+	if err = s.store.SetLastTick(ctx, remindKey, now); err != nil {
+		return errors.Wrap(err, "store.SetLastTick")
+	}
+	return nil
+}
+
+// unmark снимает отметку, чтобы сообщение повторилось на следующем тике
+func (s *Scheduler) unmark(ctx context.Context, userID int64, key string) {
+	if err := s.store.UnmarkSent(ctx, userID, key); err != nil {
+		logs.Error("unmark sent failed", zap.Int64("user", userID), zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,153 @@
+//go:build test_all || unit_test
+
+package reminders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	mocks "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/reminders/_mocks"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+)
+
+var (
+	now   = time.Date(2022, 11, 30, 21, 30, 0, 0, time.UTC)
+	today = time.Date(2022, 11, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func schedulerUp(t *testing.T) (*Scheduler, *mocks.MockUsersModel, *mocks.MockStore, *mocks.MockMessageSender) {
+	ctrl := gomock.NewController(t)
+	users := mocks.NewMockUsersModel(ctrl)
+	store := mocks.NewMockStore(ctrl)
+	sender := mocks.NewMockMessageSender(ctrl)
+
+	s := New(users, store, sender)
+	s.now = func() time.Time { return now }
+	return s, users, store, sender
+}
+
+func Test_Tick_ShouldRemindUsersWithoutPurchases(t *testing.T) {
+	ctx := context.Background()
+	s, users, store, sender := schedulerUp(t)
+
+	store.EXPECT().Lock(gomock.Any(), lockName, lockTTL).Return(true, nil)
+	// бот лежал с 19:30
+	store.EXPECT().LastTick(gomock.Any(), remindKey).Return(now.Add(-2*time.Hour), nil)
+	users.EXPECT().GetReminders(gomock.Any()).Return([]purchases.Reminder{
+		{UserID: 1, At: 21 * 60, Timezone: "UTC"},
+		{UserID: 2, At: 20 * 60, Lang: i18n.EN, Timezone: "UTC"},
+		// время прошло еще до прошлой проверки: напоминание включили позже
+		{UserID: 6, At: 19 * 60, Timezone: "UTC"},
+		// траты сегодня уже есть
+		{UserID: 3, At: 21 * 60, Timezone: "UTC"},
+		// время еще не наступило
+		{UserID: 4, At: 22 * 60, Timezone: "UTC"},
+		// уже напомнили: другая реплика или до перезапуска
+		{UserID: 5, At: 21 * 60, Timezone: "UTC"},
+	}, nil)
+
+	store.EXPECT().MarkSent(gomock.Any(), int64(1), "remind2022-11-30").Return(true, nil)
+	users.EXPECT().HasPurchasesFrom(gomock.Any(), int64(1), today).Return(false, nil)
+	sender.EXPECT().SendMessage(TxtReminder, int64(1)).Return(nil)
+
+	store.EXPECT().MarkSent(gomock.Any(), int64(2), "remind2022-11-30").Return(true, nil)
+	users.EXPECT().HasPurchasesFrom(gomock.Any(), int64(2), today).Return(false, nil)
+	sender.EXPECT().SendMessage(i18n.T(i18n.EN, TxtReminder), int64(2)).Return(nil)
+
+	store.EXPECT().MarkSent(gomock.Any(), int64(3), "remind2022-11-30").Return(true, nil)
+	users.EXPECT().HasPurchasesFrom(gomock.Any(), int64(3), today).Return(true, nil)
+
+	store.EXPECT().MarkSent(gomock.Any(), int64(5), "remind2022-11-30").Return(false, nil)
+	store.EXPECT().SetLastTick(gomock.Any(), remindKey, now).Return(nil)
+	users.EXPECT().GetDigestSubscriptions(gomock.Any()).Return(nil, nil)
+
+	assert.NoError(t, s.Tick(ctx))
+}
+
+func Test_Tick_ShouldNotRemindLateOnFirstTick(t *testing.T) {
+	ctx := context.Background()
+	s, users, store, _ := schedulerUp(t)
+
+	// проверок еще не было: наверстывать нечего, напоминание на 21:00 в 21:30 уже не отправляется
+	store.EXPECT().Lock(gomock.Any(), lockName, lockTTL).Return(true, nil)
+	store.EXPECT().LastTick(gomock.Any(), remindKey).Return(time.Time{}, nil)
+	users.EXPECT().GetReminders(gomock.Any()).Return([]purchases.Reminder{{UserID: 1, At: 21 * 60, Timezone: "UTC"}}, nil)
+	store.EXPECT().MarkSent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().SetLastTick(gomock.Any(), remindKey, now).Return(nil)
+	users.EXPECT().GetDigestSubscriptions(gomock.Any()).Return(nil, nil)
+
+	assert.NoError(t, s.Tick(ctx))
+}
+
+func Test_Tick_ShouldSkipWhenLockedByOtherReplica(t *testing.T) {
+	ctx := context.Background()
+	s, users, store, _ := schedulerUp(t)
+
+	store.EXPECT().Lock(gomock.Any(), lockName, lockTTL).Return(false, nil)
+	users.EXPECT().GetReminders(gomock.Any()).Times(0)
+
+	assert.NoError(t, s.Tick(ctx))
+}
+
+func Test_Tick_ShouldUnmarkWhenSendFailed(t *testing.T) {
+	logs.InitLogger()
+	ctx := context.Background()
+	s, users, store, sender := schedulerUp(t)
+
+	store.EXPECT().Lock(gomock.Any(), lockName, lockTTL).Return(true, nil)
+	store.EXPECT().LastTick(gomock.Any(), remindKey).Return(now.Add(-time.Hour), nil)
+	users.EXPECT().GetReminders(gomock.Any()).Return([]purchases.Reminder{
+		{UserID: 1, At: 21 * 60, Timezone: "UTC"},
+		{UserID: 2, At: 21 * 60, Timezone: "UTC"},
+	}, nil)
+
+	// очередь отправки переполнена: отметка снимается, остальные ждут следующего тика
+	store.EXPECT().MarkSent(gomock.Any(), int64(1), "remind2022-11-30").Return(true, nil)
+	users.EXPECT().HasPurchasesFrom(gomock.Any(), int64(1), today).Return(false, nil)
+	sender.EXPECT().SendMessage(TxtReminder, int64(1)).Return(errors.New("outbox queue is full"))
+	store.EXPECT().UnmarkSent(gomock.Any(), int64(1), "remind2022-11-30").Return(nil)
+	// время проверки не сдвигается, иначе второму пользователю уже не напомнят
+	store.EXPECT().SetLastTick(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	// отчеты запрашиваются, даже если с напоминаниями что-то не так
+	users.EXPECT().GetDigestSubscriptions(gomock.Any()).Return(nil, nil)
+
+	assert.Error(t, s.Tick(ctx))
+}
+
+func Test_Tick_ShouldRequestDigests(t *testing.T) {
+	ctx := context.Background()
+	s, users, store, _ := schedulerUp(t)
+	// понедельник
+	s.now = func() time.Time { return time.Date(2022, 11, 28, 10, 0, 0, 0, time.UTC) }
+	monday := time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC)
+
+	week, err := purchases.ParsePeriod("week")
+	assert.NoError(t, err)
+	month, err := purchases.ParsePeriod("month")
+	assert.NoError(t, err)
+
+	store.EXPECT().Lock(gomock.Any(), lockName, lockTTL).Return(true, nil)
+	store.EXPECT().LastTick(gomock.Any(), remindKey).Return(time.Time{}, nil)
+	users.EXPECT().GetReminders(gomock.Any()).Return(nil, nil)
+	store.EXPECT().SetLastTick(gomock.Any(), remindKey, gomock.Any()).Return(nil)
+	users.EXPECT().GetDigestSubscriptions(gomock.Any()).Return([]purchases.Subscription{
+		{UserID: 1, Period: week, Timezone: "UTC"},
+		// месяц еще не закончился
+		{UserID: 2, Period: month, Timezone: "UTC"},
+		// отчет уже запрошен
+		{UserID: 3, Period: week, Timezone: "UTC"},
+	}, nil)
+
+	store.EXPECT().MarkSent(gomock.Any(), int64(1), "digestweek2022-11-28").Return(true, nil)
+	users.EXPECT().CreateReportRequest(gomock.Any(), week, int64(1), i18n.Lang(""), purchases.ReportOptions{DigestTo: monday}).Return(nil)
+
+	store.EXPECT().MarkSent(gomock.Any(), int64(3), "digestweek2022-11-28").Return(false, nil)
+
+	assert.NoError(t, s.Tick(ctx))
+}
@@ -0,0 +1,11 @@
+package reminders
+
+import "gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+
+const TxtReminder = "Сегодня вы еще не записали ни одной траты. Добавьте их, например: \"250 кофе\". Выключить напоминание: \"/remind off\""
+
+func init() {
+	i18n.Register(i18n.EN, map[string]string{
+		TxtReminder: "You haven't logged any expenses today. Add them, for example: \"250 coffee\". To turn off the reminder, send \"/remind off\"",
+	})
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+)
+
+// createDigest отчет по подписке: траты за период по категориям в сравнении с предыдущим периодом и лимит на месяц.
+// Такие отчеты не кешируются: у них есть конец периода, а кеш хранит отчет по сегодняшний день
+func (s *service) createDigest(ctx context.Context, req Request) (CreateReportResponse, error) {
+	items, err := s.getPurchasesReportBetween(ctx, req.FromDate, req.ToDate, req.UserID, req.Currency)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "getPurchasesReportBetween")
+	}
+
+	prevItems, err := s.getPurchasesReportBetween(ctx, req.Digest.PrevFromDate, req.FromDate, req.UserID, req.Currency)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "getPurchasesReportBetween")
+	}
+	metrics.InFlightReports.WithLabelValues(metrics.ReportSourceBD).Inc()
+
+	text, err := digestText(req, items, prevItems)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "digestText")
+	}
+
+	labels, err := pieLabels(req)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "pieLabels")
+	}
+
+	resIMG, err := s.Drawer.PieChart(categoryItems(req.Lang, items), labels)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "ChartDrawer.PieChart")
+	}
+
+	return CreateReportResponse{
+		Text:   text,
+		IMG:    resIMG,
+		UserID: req.UserID,
+		Digest: true,
+	}, nil
+}
+
+// getPurchasesReportBetween траты по категориям с from (включительно) до to (не включительно)
+func (s *service) getPurchasesReportBetween(ctx context.Context, from, to time.Time, userID int64, cy currency.Currency) ([]ReportItem, error) {
+	purchases, err := s.repo.GetUserPurchasesBetween(ctx, from, to, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.GetUserPurchasesBetween")
+	}
+
+	reportItems, err := s.packagingByCategory(purchases, cy)
+	if err != nil {
+		return nil, errors.Wrap(err, "packagingByCategory")
+	}
+	return reportItems, nil
+}
+
+// digestRows строки отчета по подписке: категории с тратами в порядке отчета, а за ними категории,
+// в которых трат не было, но были в предыдущем периоде
+func digestRows(items, prevItems []ReportItem) []digestRow {
+	prev := make(map[string]float64, len(prevItems))
+	for _, item := range prevItems {
+		prev[item.PurchaseCategory] = item.Summa
+	}
+
+	rows := make([]digestRow, 0, len(items)+len(prevItems))
+	for _, item := range items {
+		rows = append(rows, digestRow{Category: item.PurchaseCategory, Summa: item.Summa, Prev: prev[item.PurchaseCategory]})
+	}
+	var gone []digestRow
+	for _, item := range prevItems {
+		if !hasCategory(items, item.PurchaseCategory) {
+			gone = append(gone, digestRow{Category: item.PurchaseCategory, Prev: item.Summa})
+		}
+	}
+	sort.SliceStable(gone, func(i, j int) bool {
+		return gone[i].Prev > gone[j].Prev
+	})
+	return append(rows, gone...)
+}
+
+func sum(items []ReportItem) float64 {
+	var res float64
+	for _, item := range items {
+		res += item.Summa
+	}
+	return res
+}
+
+func hasCategory(items []ReportItem, category string) bool {
+	for _, item := range items {
+		if item.PurchaseCategory == category {
+			return true
+		}
+	}
+	return false
+}
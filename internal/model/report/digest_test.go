@@ -0,0 +1,87 @@
+//go:build test_all || unit_test
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+func Test_digestText(t *testing.T) {
+	items := []ReportItem{
+		{PurchaseCategory: "Жилье", Summa: 30000},
+		{PurchaseCategory: "Кафе", Summa: 3000},
+	}
+	prevItems := []ReportItem{
+		{PurchaseCategory: "Жилье", Summa: 30000},
+		{PurchaseCategory: "Такси", Summa: 1500},
+		{PurchaseCategory: "Кафе", Summa: 1500},
+	}
+	req := Request{
+		FromDate: time.Date(2022, 11, 21, 0, 0, 0, 0, time.UTC),
+		ToDate:   time.Date(2022, 11, 28, 0, 0, 0, 0, time.UTC),
+		UserID:   123,
+		Currency: currency.RUB,
+		Digest: &Digest{
+			Period:        "week",
+			PrevFromDate:  time.Date(2022, 11, 14, 0, 0, 0, 0, time.UTC),
+			Limit:         50000,
+			MonthExpenses: 45000,
+		},
+	}
+
+	t.Run("неделя со сравнением и лимитом", func(t *testing.T) {
+		res, err := digestText(req, items, prevItems)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Отчет за неделю 21.11.2022 - 27.11.2022\n"+
+			"Ваша валюта: RUB\n"+
+			"Всего потрачено: 33 000,00\n"+
+			"За предыдущий период: 33 000,00 (+0%)\n"+
+			"Ваш отчет:\n"+
+			"\tЖилье: 30 000,00 (было 30 000,00)\n"+
+			"\tКафе: 3 000,00 (было 1 500,00)\n"+
+			"\tТакси: 0,00 (было 1 500,00)\n"+
+			"Лимит на месяц: потрачено 45 000,00 из 50 000,00\n", res)
+	})
+
+	t.Run("месяц на английском, лимит превышен", func(t *testing.T) {
+		req := req
+		req.Lang = i18n.EN
+		req.Digest = &Digest{Period: "month", Limit: 30000, MonthExpenses: 33000}
+		req.FromDate = time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+		req.ToDate = time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+		res, err := digestText(req, items, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Monthly report Nov 1, 2022 - Nov 30, 2022\n"+
+			"Your currency: RUB\n"+
+			"Total spent: 33,000.00\n"+
+			"No expenses in the previous period\n"+
+			"Your report:\n"+
+			"\tHousing: 30,000.00 (was 0.00)\n"+
+			"\tCafe: 3,000.00 (was 0.00)\n"+
+			"Monthly limit exceeded: spent 33,000.00 of 30,000.00\n", res)
+	})
+
+	t.Run("без лимита", func(t *testing.T) {
+		req := req
+		req.Digest = &Digest{Period: "week", Limit: -1}
+
+		res, err := digestText(req, items[1:], items)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Отчет за неделю 21.11.2022 - 27.11.2022\n"+
+			"Ваша валюта: RUB\n"+
+			"Всего потрачено: 3 000,00\n"+
+			"За предыдущий период: 33 000,00 (-91%)\n"+
+			"Ваш отчет:\n"+
+			"\tКафе: 3 000,00 (было 3 000,00)\n"+
+			"\tЖилье: 0,00 (было 30 000,00)\n", res)
+	})
+}
@@ -5,11 +5,11 @@ import (
 	"encoding/json"
 	"sort"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
 	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
@@ -22,16 +22,41 @@ type Request struct {
 	FromDate time.Time         `json:"fromDate"`
 	UserID   int64             `json:"userId"`
 	Currency currency.Currency `json:"currency"`
+	Tags     []string          `json:"tags,omitempty"` // в отчет попадут только траты со всеми этими тегами
+	Lang     i18n.Lang         `json:"lang,omitempty"` // язык отчета, в старых запросах его нет
+	// Format в каком виде прислать отчет, в старых запросах его нет - это текст
+	Format purchases.ReportFormat `json:"format,omitempty"`
+	// AllCurrencies показать суммы в каждой валюте оплаты
+	AllCurrencies bool `json:"allCurrencies,omitempty"`
+	// TodayRates если переданы, траты пересчитываются по этим курсам, а не по курсам на дату траты
+	TodayRates *currency.RateToRUB `json:"todayRates,omitempty"`
+	// ToDate конец периода (не включительно), пустой - отчет по сегодняшний день
+	ToDate time.Time `json:"toDate,omitempty"`
+	// Digest отчет по подписке: сравнивается с предыдущим периодом и показывает лимит
+	Digest *Digest `json:"digest,omitempty"`
+}
+
+// Digest данные отчета по подписке, которых нет в обычном отчете
+type Digest struct {
+	Period string `json:"period"` // week или month
+	// PrevFromDate начало предыдущего периода, с которым сравнивается отчет. Его конец - начало периода отчета
+	PrevFromDate time.Time `json:"prevFromDate"`
+	// Limit месячный лимит в валюте пользователя, -1 - лимита нет
+	Limit float64 `json:"limit"`
+	// MonthExpenses сколько потрачено за месяц, на который приходится конец периода
+	MonthExpenses float64 `json:"monthExpenses"`
 }
 
 type Report struct {
 	Items    []ReportItem
+	Stats    Stats
 	FromDate time.Time // дата начала выборки данных в отчете
 }
 
 type ReportItem struct {
 	PurchaseCategory string
 	Summa            float64
+	Count            int // количество трат в категории
 }
 
 type Purchase struct {
@@ -43,9 +68,12 @@ type Purchase struct {
 }
 
 type CreateReportResponse struct {
-	Text   string
-	IMG    []byte
-	UserID int64
+	Text         string
+	IMG          []byte
+	Document     []byte // PDF-документ, если отчет запрошен в PDF. Тогда картинки нет: диаграммы есть в документе
+	DocumentName string
+	UserID       int64
+	Digest       bool // отчет по подписке
 }
 
 func (s *service) CreateReport(ctx context.Context, rawReq string) (CreateReportResponse, error) {
@@ -53,94 +81,186 @@ func (s *service) CreateReport(ctx context.Context, rawReq string) (CreateReport
 	if err := json.Unmarshal([]byte(rawReq), &req); err != nil {
 		return CreateReportResponse{}, errors.Wrap(err, "unmarshalling error")
 	}
+	if req.Digest != nil {
+		return s.createDigest(ctx, req)
+	}
+	if req.Format == purchases.ReportFormatPDF {
+		return s.createPDFReport(ctx, req)
+	}
 
-	reportItems, err := s.getPurchasesReportFromDate(ctx, req.FromDate, req.UserID, req.Currency)
+	var report Report
+	var err error
+	switch {
+	case req.TodayRates != nil:
+		report, err = s.getPurchasesReportAtTodayRates(ctx, req)
+	case len(req.Tags) != 0:
+		report, err = s.getPurchasesReportFromDateWithTags(ctx, req.FromDate, req.UserID, req.Currency, req.Tags)
+	default:
+		report, err = s.getPurchasesReportFromDate(ctx, req.FromDate, req.UserID, req.Currency)
+	}
 	if err != nil {
 		return CreateReportResponse{}, errors.Wrap(err, "packagingByCategory")
 	}
 
-	cy, err := currency.CurrencyToStr(req.Currency)
+	text, err := reportText(req, report, s.Now())
 	if err != nil {
-		return CreateReportResponse{}, errors.Wrap(err, "currencyToStr")
+		return CreateReportResponse{}, errors.Wrap(err, "reportText")
 	}
 
-	resStr := strings.Builder{}
-	resStr.WriteString("Ваша валюта: ")
-	resStr.WriteString(cy)
-	resStr.WriteString("\nВаш отчет:\n")
-	for _, item := range reportItems {
-		resStr.WriteString("\t")
-		resStr.WriteString(item.PurchaseCategory)
-		resStr.WriteString(": ")
-		resStr.WriteString(strconv.FormatFloat(item.Summa, 'f', 2, 64))
-		resStr.WriteString("\n")
+	labels, err := pieLabels(req)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "pieLabels")
 	}
 
-	resIMG, err := s.Drawer.PieChart(reportItems)
+	resIMG, err := s.Drawer.PieChart(categoryItems(req.Lang, report.Items), labels)
 	if err != nil {
 		return CreateReportResponse{}, errors.Wrap(err, "ChartDrawer.PieChart")
 	}
 
 	return CreateReportResponse{
-		Text:   resStr.String(),
+		Text:   text,
 		IMG:    resIMG,
 		UserID: req.UserID,
 	}, nil
 }
 
-func (s *service) getPurchasesReportFromDate(ctx context.Context, from time.Time, userID int64, cy currency.Currency) ([]ReportItem, error) {
+func (s *service) getPurchasesReportFromDate(ctx context.Context, from time.Time, userID int64, cy currency.Currency) (Report, error) {
 	report, err := s.reportsStore.GetReport(ctx, createKeyForReportsStore(userID))
 	if err != nil {
 		logs.Error("reports store error", zap.Error(err))
 	}
 	// если в хранилище статусов ничего нет или вернулась ошибка просто идем в репу
 	if err == nil && len(report.Items) != 0 {
-		if report.FromDate == from {
+		if report.FromDate.Equal(from) {
 			metrics.InFlightReports.WithLabelValues(metrics.ReportSourceCache).Inc()
-			return report.Items, nil
+			return report, nil
 		}
 	}
 
 	purchases, err := s.repo.GetUserPurchasesFromDate(ctx, from, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, "repo.GetUserPurchasesFromDate")
+		return Report{}, errors.Wrap(err, "repo.GetUserPurchasesFromDate")
 	}
 
-	reportItems, err := s.packagingByCategory(purchases, cy)
+	report, err = s.packaging(purchases, cy, from)
 	if err != nil {
-		return nil, errors.Wrap(err, "packagingByCategory")
+		return Report{}, errors.Wrap(err, "packaging")
 	}
 
-	err = s.reportsStore.SetReport(ctx, createKeyForReportsStore(userID), Report{Items: reportItems, FromDate: from}) // nolint: errcheck
+	err = s.reportsStore.SetReport(ctx, createKeyForReportsStore(userID), report) // nolint: errcheck
 	if err != nil {
-		return nil, errors.Wrap(err, "reportsStore.SetReport")
+		return Report{}, errors.Wrap(err, "reportsStore.SetReport")
 	}
 	metrics.InFlightReports.WithLabelValues(metrics.ReportSourceBD).Inc()
 
-	logs.Info("REPORT", zap.Any("items", reportItems))
+	logs.Info("REPORT", zap.Any("items", report.Items))
 
-	return reportItems, nil
+	return report, nil
+}
+
+// getPurchasesReportFromDateWithTags отчет только по тратам со всеми переданными тегами.
+// Такие отчеты не кешируются: кеш хранит один отчет на пользователя и он без фильтров
+func (s *service) getPurchasesReportFromDateWithTags(ctx context.Context, from time.Time, userID int64, cy currency.Currency, tags []string) (Report, error) {
+	purchases, err := s.repo.GetUserPurchasesFromDateWithTags(ctx, from, userID, tags)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "repo.GetUserPurchasesFromDateWithTags")
+	}
+
+	report, err := s.packaging(purchases, cy, from)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "packaging")
+	}
+	metrics.InFlightReports.WithLabelValues(metrics.ReportSourceBD).Inc()
+
+	return report, nil
+}
+
+// getPurchasesReportAtTodayRates отчет, в котором траты пересчитаны по сегодняшним курсам из запроса.
+// Такие отчеты не кешируются: курсы меняются каждый день
+func (s *service) getPurchasesReportAtTodayRates(ctx context.Context, req Request) (Report, error) {
+	list, err := s.userPurchases(ctx, req)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "userPurchases")
+	}
+	metrics.InFlightReports.WithLabelValues(metrics.ReportSourceBD).Inc()
+
+	report, _, err := s.packagingAtRates(list, req.Currency, req.FromDate, *req.TodayRates)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "packagingAtRates")
+	}
+	return report, nil
+}
+
+// userPurchases траты пользователя за период запроса, с тегами - только траты со всеми этими тегами
+func (s *service) userPurchases(ctx context.Context, req Request) ([]purchases.Purchase, error) {
+	if len(req.Tags) != 0 {
+		list, err := s.repo.GetUserPurchasesFromDateWithTags(ctx, req.FromDate, req.UserID, req.Tags)
+		return list, errors.Wrap(err, "repo.GetUserPurchasesFromDateWithTags")
+	}
+	list, err := s.repo.GetUserPurchasesFromDate(ctx, req.FromDate, req.UserID)
+	return list, errors.Wrap(err, "repo.GetUserPurchasesFromDate")
+}
+
+// packagingAtRates отчет по тратам, пересчитанным по курсам rates, и сами пересчитанные траты.
+// Сумма по курсам на даты трат остается в статистике, чтобы показать курсовую разницу
+func (s *service) packagingAtRates(list []purchases.Purchase, cy currency.Currency, from time.Time,
+	rates currency.RateToRUB) (Report, []purchases.Purchase, error) {
+	historical, err := collectStats(list, cy, from.Location())
+	if err != nil {
+		return Report{}, nil, errors.Wrap(err, "collectStats")
+	}
+
+	list, err = atRates(list, rates)
+	if err != nil {
+		return Report{}, nil, errors.Wrap(err, "atRates")
+	}
+
+	report, err := s.packaging(list, cy, from)
+	if err != nil {
+		return Report{}, nil, errors.Wrap(err, "packaging")
+	}
+	report.Stats.AtPurchaseRates = historical.Total
+	return report, list, nil
+}
+
+// packaging отчет с начала периода from: траты по категориям и общая статистика. Начало периода приходит
+// в часовом поясе пользователя, в нем же считаются дни недели
+func (s *service) packaging(purchases []purchases.Purchase, cy currency.Currency, from time.Time) (Report, error) {
+	items, err := s.packagingByCategory(purchases, cy)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "packagingByCategory")
+	}
+
+	stats, err := collectStats(purchases, cy, from.Location())
+	if err != nil {
+		return Report{}, errors.Wrap(err, "collectStats")
+	}
+
+	return Report{Items: items, Stats: stats, FromDate: from}, nil
 }
 
 // packagingByCategory получает на вход список трат и формирует из него отчет, переводя все траты в
 // выбранную валюту и складывая их по категориям
 func (s *service) packagingByCategory(purchases []purchases.Purchase, currentCurrency currency.Currency) ([]ReportItem, error) {
-	tempCategoryOnSum := make(map[string]float64, len(purchases))
+	tempCategoryOnSum := make(map[string]*ReportItem, len(purchases))
 	for _, p := range purchases {
 		resSum, err := currency.RubToCurrentCurrency(currentCurrency, p.Summa, p.RateToRUB)
 		if err != nil {
 			return nil, errors.Wrap(err, "rubToCurrentCurrency")
 		}
 
-		tempCategoryOnSum[p.PurchaseCategory] += resSum
+		item, ok := tempCategoryOnSum[p.PurchaseCategory]
+		if !ok {
+			item = &ReportItem{PurchaseCategory: p.PurchaseCategory}
+			tempCategoryOnSum[p.PurchaseCategory] = item
+		}
+		item.Summa += resSum
+		item.Count++
 	}
 
 	res := make([]ReportItem, 0, len(tempCategoryOnSum))
-	for k, v := range tempCategoryOnSum {
-		res = append(res, ReportItem{
-			PurchaseCategory: k,
-			Summa:            v,
-		})
+	for _, item := range tempCategoryOnSum {
+		res = append(res, *item)
 	}
 
 	sort.Slice(res, func(i, j int) bool {
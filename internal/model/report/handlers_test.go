@@ -0,0 +1,194 @@
+//go:build test_all || unit_test
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// fakeRepo отдает траты без фильтра и отдельно траты с тегами, запоминает запрошенные теги
+type fakeRepo struct {
+	all, tagged []purchases.Purchase
+	tags        []string
+}
+
+func (r *fakeRepo) GetUserPurchasesFromDate(_ context.Context, _ time.Time, _ int64) ([]purchases.Purchase, error) {
+	return r.all, nil
+}
+
+func (r *fakeRepo) GetUserPurchasesFromDateWithTags(_ context.Context, _ time.Time, _ int64, tags []string) ([]purchases.Purchase, error) {
+	r.tags = tags
+	return r.tagged, nil
+}
+
+func (r *fakeRepo) GetUserPurchasesBetween(_ context.Context, _, _ time.Time, _ int64) ([]purchases.Purchase, error) {
+	return r.all, nil
+}
+
+func Test_CreateReport_WithTags(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	trip := []purchases.Purchase{
+		{Date: from.Add(time.Hour), PurchaseCategory: "Такси", Summa: 1000},
+		{Date: from.Add(2 * time.Hour), PurchaseCategory: "Кафе", Summa: 500},
+	}
+	repo := &fakeRepo{
+		all:    append([]purchases.Purchase{{Date: from.Add(time.Hour), PurchaseCategory: "Жилье", Summa: 30000}}, trip...),
+		tagged: trip,
+	}
+	// без хранилища: отчеты с тегами не кешируются, обращение к кешу уронит тест
+	s := New(repo, nil, &fakeDrawer{})
+	s.Now = func() time.Time { return from.AddDate(0, 0, 1) }
+
+	raw, err := json.Marshal(Request{FromDate: from, UserID: 123, Currency: currency.RUB, Tags: []string{"командировка"}})
+	require.NoError(t, err)
+
+	res, err := s.CreateReport(context.Background(), string(raw))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"командировка"}, repo.tags)
+	assert.Contains(t, res.Text, "Теги: #командировка\n")
+	assert.Contains(t, res.Text, "Всего потрачено: 1 500,00 (2 траты)\n")
+	assert.NotContains(t, res.Text, "Жилье")
+}
+
+func Test_reportText(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	report := Report{
+		Items: []ReportItem{
+			{PurchaseCategory: "Жилье", Summa: 30000, Count: 1},
+			{PurchaseCategory: "Кафе", Summa: 1234.5, Count: 3},
+		},
+		Stats: Stats{
+			Total: 31234.5,
+			Count: 4,
+			Top: []TopPurchase{
+				{Date: from, Category: "Жилье", Summa: 30000},
+				{Date: from.AddDate(0, 0, 1), Category: "Кафе", Description: "ужин", Summa: 800},
+			},
+			Weekdays: [7]float64{time.Friday: 30000, time.Saturday: 1234.5},
+		},
+		FromDate: from,
+	}
+	req := Request{
+		FromDate: from,
+		UserID:   123,
+		Currency: currency.RUB,
+	}
+	// 10 дней с 1 по 10 марта
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	t.Run("русский по умолчанию", func(t *testing.T) {
+		res, err := reportText(req, report, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Траты с 01.03.2024\nВаша валюта: RUB\n"+
+			"Всего потрачено: 31 234,50 (4 траты)\n"+
+			"В среднем в день: 3 123,45\n"+
+			"Ваш отчет:\n\tЖилье: 30 000,00 (96%, 1 трата)\n\tКафе: 1 234,50 (4%, 3 траты)\n"+
+			"Самые крупные траты:\n\t01.03.2024 Жилье: 30 000,00\n\t02.03.2024 Кафе (ужин): 800,00\n"+
+			"Больше всего потрачено в день недели: пятница (30 000,00)\n", res)
+	})
+
+	t.Run("английский с тегами", func(t *testing.T) {
+		req := req
+		req.Lang = i18n.EN
+		req.Tags = []string{"командировка"}
+
+		res, err := reportText(req, report, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Expenses since Mar 1, 2024\nYour currency: RUB\nTags: #командировка\n"+
+			"Total spent: 31,234.50 (4 purchases)\n"+
+			"Average per day: 3,123.45\n"+
+			"Your report:\n\tHousing: 30,000.00 (96%, 1 purchase)\n\tCafe: 1,234.50 (4%, 3 purchases)\n"+
+			"Biggest purchases:\n\tMar 1, 2024 Housing: 30,000.00\n\tMar 2, 2024 Cafe (ужин): 800.00\n"+
+			"Most spent on: Friday (30,000.00)\n", res)
+	})
+
+	t.Run("по валютам оплаты и по сегодняшнему курсу", func(t *testing.T) {
+		req := req
+		req.AllCurrencies = true
+		req.TodayRates = &currency.RateToRUB{USD: 0.01}
+		report := report
+		report.Stats.Currencies = []CurrencyTotal{
+			{Currency: currency.RUB, Paid: 30000, Summa: 30000},
+			{Currency: currency.USD, Paid: 12.345, Summa: 1234.5},
+		}
+		report.Stats.AtPurchaseRates = 31000
+
+		res, err := reportText(req, report, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Траты с 01.03.2024\nВаша валюта: RUB\n"+
+			"Всего потрачено: 31 234,50 (4 траты)\n"+
+			"В среднем в день: 3 123,45\n"+
+			"Суммы пересчитаны по сегодняшнему курсу. По курсам на даты трат: 31 000,00, курсовая разница: +234,50\n"+
+			"По валютам оплаты:\n\tRUB: 30 000,00 (30 000,00 RUB)\n\tUSD: 12,35 (1 234,50 RUB)\n"+
+			"Ваш отчет:\n\tЖилье: 30 000,00 (96%, 1 трата)\n\tКафе: 1 234,50 (4%, 3 траты)\n"+
+			"Самые крупные траты:\n\t01.03.2024 Жилье: 30 000,00\n\t02.03.2024 Кафе (ужин): 800,00\n"+
+			"Больше всего потрачено в день недели: пятница (30 000,00)\n", res)
+	})
+
+	t.Run("трат нет", func(t *testing.T) {
+		res, err := reportText(req, Report{FromDate: from}, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Траты с 01.03.2024\nВаша валюта: RUB\nТрат за этот период нет\n", res)
+	})
+}
+
+func Test_packagingAtRates(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	then := currency.RateToRUB{USD: 0.02, EUR: 0.02, CNY: 0.1}
+	today := currency.RateToRUB{USD: 0.01, EUR: 0.02, CNY: 0.1}
+	list := []purchases.Purchase{
+		{Date: from, PurchaseCategory: "Кафе", Summa: 500, Currency: currency.USD, RateToRUB: then},
+		{Date: from, PurchaseCategory: "Такси", Summa: 300, Currency: currency.RUB, RateToRUB: then},
+	}
+
+	report, rerated, err := New(nil, nil, nil).packagingAtRates(list, currency.RUB, from, today)
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1300), report.Stats.Total)
+	assert.Equal(t, float64(800), report.Stats.AtPurchaseRates)
+	assert.Equal(t, []ReportItem{{PurchaseCategory: "Кафе", Summa: 1000, Count: 1}, {PurchaseCategory: "Такси", Summa: 300, Count: 1}}, report.Items)
+	assert.Equal(t, []CurrencyTotal{
+		{Currency: currency.USD, Paid: 10, Summa: 1000},
+		{Currency: currency.RUB, Paid: 300, Summa: 300},
+	}, report.Stats.Currencies)
+	assert.Equal(t, float64(1000), rerated[0].Summa)
+}
+
+func Test_reportDays(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, msk)
+
+	assert.Equal(t, 1, reportDays(from, from.Add(time.Hour)))
+	// 22:00 UTC 1 марта - уже 2 марта по Москве
+	assert.Equal(t, 2, reportDays(from, time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 366, reportDays(time.Date(2024, 1, 1, 0, 0, 0, 0, msk), time.Date(2024, 12, 31, 12, 0, 0, 0, msk)))
+}
+
+func Test_pieLabels(t *testing.T) {
+	labels, err := pieLabels(Request{Currency: currency.USD, Lang: i18n.EN})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Other", labels.Other)
+	assert.Equal(t, "No expenses in this period", labels.Empty)
+	assert.Equal(t, "1,234.50 USD", labels.FormatSum(1234.5))
+
+	labels, err = pieLabels(Request{Currency: currency.RUB})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Прочее", labels.Other)
+	assert.Equal(t, "1 234,50 RUB", labels.FormatSum(1234.5))
+}
@@ -0,0 +1,407 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wcharczuk/go-chart/v2/roboto"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/pdf"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/metrics"
+	"go.uber.org/zap"
+)
+
+// Раскладка PDF-отчета, размеры в пунктах
+const (
+	pdfMargin       = 40.0
+	pdfContentWidth = pdf.PageWidth - 2*pdfMargin
+	// pdfBottom ниже этой линии ничего не пишется, там номер страницы
+	pdfBottom      = pdf.PageHeight - pdfMargin
+	pdfCoverTop    = 200.0 // обложка начинается в верхней трети страницы
+	pdfTitleSize   = 22.0
+	pdfHeadingSize = 14.0
+	pdfTextSize    = 11.0
+	pdfTableSize   = 9.0
+	pdfRowHeight   = 16.0
+	pdfCellPadding = 4.0
+	pdfPieWidth    = pdfContentWidth // диаграмма с легендой справа
+)
+
+// createPDFReport отчет в PDF: текст отчета и документ со всеми тратами за период.
+// Такие отчеты не кешируются: в кеше нет списка трат
+func (s *service) createPDFReport(ctx context.Context, req Request) (CreateReportResponse, error) {
+	list, err := s.userPurchases(ctx, req)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "userPurchases")
+	}
+	metrics.InFlightReports.WithLabelValues(metrics.ReportSourceBD).Inc()
+
+	var report Report
+	if req.TodayRates != nil {
+		// в списке трат суммы тоже должны быть по сегодняшнему курсу
+		report, list, err = s.packagingAtRates(list, req.Currency, req.FromDate, *req.TodayRates)
+	} else {
+		report, err = s.packaging(list, req.Currency, req.FromDate)
+	}
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "packaging")
+	}
+
+	now := s.Now()
+	text, err := reportText(req, report, now)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "reportText")
+	}
+
+	doc, err := s.reportPDF(req, report, list, now)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "reportPDF")
+	}
+	if len(doc) > s.MaxDocumentSize {
+		// такой документ не пройдет через gRPC: вместо него отправляем диаграмму, как в обычном отчете,
+		// и объясняем, почему документа нет
+		logs.Error("report document is too large", zap.Int("size", len(doc)), zap.Int64("userId", req.UserID))
+		return s.documentTooLarge(req, report, text)
+	}
+
+	return CreateReportResponse{
+		Text:         text,
+		Document:     doc,
+		DocumentName: fmt.Sprintf("report_%s.pdf", req.FromDate.Format("2006-01-02")),
+		UserID:       req.UserID,
+	}, nil
+}
+
+// documentTooLarge отчет с диаграммой вместо PDF-документа, который получился больше MaxDocumentSize
+func (s *service) documentTooLarge(req Request, report Report, text string) (CreateReportResponse, error) {
+	lang := req.Lang
+	if lang == "" {
+		lang = i18n.Default
+	}
+
+	labels, err := pieLabels(req)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "pieLabels")
+	}
+
+	img, err := s.Drawer.PieChart(categoryItems(req.Lang, report.Items), labels)
+	if err != nil {
+		return CreateReportResponse{}, errors.Wrap(err, "ChartDrawer.PieChart")
+	}
+
+	return CreateReportResponse{
+		Text:   text + i18n.T(lang, txtPDFTooLarge),
+		IMG:    img,
+		UserID: req.UserID,
+	}, nil
+}
+
+// reportPDF документ отчета: обложка с периодом, валютой и итогами, таблица категорий, круговая диаграмма,
+// график трат по дням и список всех трат. Длинные таблицы продолжаются на следующих страницах
+func (s *service) reportPDF(req Request, report Report, list []purchases.Purchase, now time.Time) ([]byte, error) {
+	lang := req.Lang
+	if lang == "" {
+		lang = i18n.Default
+	}
+	loc := req.FromDate.Location()
+
+	cy, err := currency.CurrencyToStr(req.Currency)
+	if err != nil {
+		return nil, errors.Wrap(err, "currencyToStr")
+	}
+
+	doc, err := pdf.New(roboto.Roboto)
+	if err != nil {
+		return nil, errors.Wrap(err, "pdf.New")
+	}
+	doc.SetTitle(i18n.T(lang, txtPDFTitle))
+	l := &pdfLayout{doc: doc}
+
+	l.newPage()
+	l.y = pdfCoverTop
+	l.text(pdfTitleSize, pdf.Black, i18n.T(lang, txtPDFTitle))
+	l.text(pdfHeadingSize, pdf.Gray, fmt.Sprintf("%s - %s", i18n.Date(lang, req.FromDate), i18n.Date(lang, now.In(loc))))
+	l.text(pdfTextSize, pdf.Black, i18n.Sprintf(lang, txtCurrency, cy))
+	if len(req.Tags) != 0 {
+		l.text(pdfTextSize, pdf.Black, i18n.Sprintf(lang, txtTags, freetext.FormatTags(req.Tags)))
+	}
+	l.y += pdfTextSize
+
+	if report.Stats.Count == 0 {
+		l.text(pdfTextSize, pdf.Black, i18n.T(lang, txtNoPurchases))
+		return l.finish(lang)
+	}
+
+	weekday, weekdaySum := report.Stats.BusiestWeekday()
+	l.text(pdfTextSize, pdf.Black, i18n.Sprintf(lang, txtTotal, i18n.Number(lang, report.Stats.Total), purchasesCount(lang, report.Stats.Count)))
+	l.text(pdfTextSize, pdf.Black, i18n.Sprintf(lang, txtPerDay, i18n.Number(lang, report.Stats.Total/float64(reportDays(req.FromDate, now)))))
+	l.text(pdfTextSize, pdf.Black, i18n.Sprintf(lang, txtBusiestWeekday, i18n.T(lang, weekdays[weekday]), i18n.Number(lang, weekdaySum)))
+	if req.TodayRates != nil {
+		l.text(pdfTextSize, pdf.Black, i18n.Sprintf(lang, txtTodayRates, i18n.Number(lang, report.Stats.AtPurchaseRates),
+			signedNumber(lang, report.Stats.Total-report.Stats.AtPurchaseRates)))
+	}
+	if req.AllCurrencies {
+		l.y += pdfTextSize
+		l.text(pdfTextSize, pdf.Black, i18n.T(lang, txtCurrencies))
+		for _, c := range report.Stats.Currencies {
+			code, err := currency.CurrencyToStr(c.Currency)
+			if err != nil {
+				return nil, errors.Wrap(err, "currencyToStr")
+			}
+			l.text(pdfTextSize, pdf.Black, fmt.Sprintf("    %s: %s (%s %s)", code, i18n.Number(lang, c.Paid), i18n.Number(lang, c.Summa), cy))
+		}
+	}
+
+	l.newPage()
+	l.heading(i18n.T(lang, txtPDFCategories))
+	categories := make([][]string, len(report.Items))
+	for i, item := range report.Items {
+		categories[i] = []string{
+			purchases.CategoryName(lang, item.PurchaseCategory),
+			i18n.Number(lang, item.Summa),
+			fmt.Sprintf("%.0f%%", item.Summa/report.Stats.Total*100),
+			strconv.Itoa(item.Count),
+		}
+	}
+	l.table([]pdfColumn{
+		{title: i18n.T(lang, txtPDFCategory), width: pdfContentWidth - 280},
+		{title: i18n.T(lang, txtPDFSum), width: 110, right: true},
+		{title: i18n.T(lang, txtPDFShare), width: 70, right: true},
+		{title: i18n.T(lang, txtPDFCount), width: 100, right: true},
+	}, categories)
+
+	if err = s.pdfCharts(l, lang, req, report, list, now); err != nil {
+		return nil, errors.Wrap(err, "pdfCharts")
+	}
+
+	rows, err := purchaseRows(lang, list, req.Currency, loc)
+	if err != nil {
+		return nil, errors.Wrap(err, "purchaseRows")
+	}
+	l.newPage()
+	l.heading(i18n.T(lang, txtPDFPurchases))
+	l.table([]pdfColumn{
+		{title: i18n.T(lang, txtPDFDate), width: 75},
+		{title: i18n.T(lang, txtPDFCategory), width: 130},
+		{title: i18n.T(lang, txtPDFDescription), width: pdfContentWidth - 315},
+		{title: i18n.T(lang, txtPDFSum), width: 110, right: true},
+	}, rows)
+
+	return l.finish(lang)
+}
+
+// pdfCharts страница с круговой диаграммой категорий и графиком трат по дням. График рисуется,
+// только если в периоде больше одного дня
+func (s *service) pdfCharts(l *pdfLayout, lang i18n.Lang, req Request, report Report, list []purchases.Purchase, now time.Time) error {
+	labels, err := pieLabels(req)
+	if err != nil {
+		return errors.Wrap(err, "pieLabels")
+	}
+
+	pie, err := s.Drawer.PieChart(categoryItems(lang, report.Items), labels)
+	if err != nil {
+		return errors.Wrap(err, "ChartDrawer.PieChart")
+	}
+
+	l.newPage()
+	l.heading(i18n.T(lang, txtPDFPieChart))
+	if err = l.image(pie, pdfPieWidth); err != nil {
+		return errors.Wrap(err, "pie image")
+	}
+
+	days, err := dailyTotals(list, req.Currency, req.FromDate, now)
+	if err != nil {
+		return errors.Wrap(err, "dailyTotals")
+	}
+	if len(days) < 2 {
+		return nil
+	}
+
+	loc := req.FromDate.Location()
+	trend, err := s.Drawer.TrendChart(days, func(t time.Time) string {
+		return i18n.ShortDate(lang, t.In(loc))
+	})
+	if err != nil {
+		return errors.Wrap(err, "ChartDrawer.TrendChart")
+	}
+
+	l.heading(i18n.T(lang, txtPDFTrendChart))
+	if err = l.image(trend, pdfContentWidth); err != nil {
+		return errors.Wrap(err, "trend image")
+	}
+	return nil
+}
+
+// dailyTotals траты в валюте cy по дням с начала периода from по день now включительно.
+// Дни считаются в часовом поясе from, траты задним числом из будущего продлевают период
+func dailyTotals(list []purchases.Purchase, cy currency.Currency, from, now time.Time) ([]DailyTotal, error) {
+	sums := make([]float64, reportDays(from, now))
+	for _, p := range list {
+		sum, err := currency.RubToCurrentCurrency(cy, p.Summa, p.RateToRUB)
+		if err != nil {
+			return nil, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+
+		day := reportDays(from, p.Date) - 1
+		for len(sums) <= day {
+			sums = append(sums, 0)
+		}
+		sums[day] += sum
+	}
+
+	loc := from.Location()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	res := make([]DailyTotal, len(sums))
+	for i, sum := range sums {
+		res[i] = DailyTotal{Day: start.AddDate(0, 0, i), Summa: sum}
+	}
+	return res, nil
+}
+
+// purchaseRows строки списка трат от старых к новым: дата, категория, описание и сумма в валюте cy
+func purchaseRows(lang i18n.Lang, list []purchases.Purchase, cy currency.Currency, loc *time.Location) ([][]string, error) {
+	sorted := append([]purchases.Purchase(nil), list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	rows := make([][]string, len(sorted))
+	for i, p := range sorted {
+		sum, err := currency.RubToCurrentCurrency(cy, p.Summa, p.RateToRUB)
+		if err != nil {
+			return nil, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+		rows[i] = []string{i18n.Date(lang, p.Date.In(loc)), purchases.CategoryName(lang, p.PurchaseCategory), p.Description, i18n.Number(lang, sum)}
+	}
+	return rows, nil
+}
+
+// pdfColumn колонка таблицы
+type pdfColumn struct {
+	title string
+	width float64
+	right bool // выравнивание по правому краю, для чисел
+}
+
+// pdfLayout раскладывает отчет по страницам: y - сколько места сверху уже занято на текущей странице
+type pdfLayout struct {
+	doc   *pdf.Document
+	pages []*pdf.Page
+	page  *pdf.Page
+	y     float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.pages = append(l.pages, l.page)
+	l.y = pdfMargin
+}
+
+// need начинает новую страницу, если на текущей не осталось места высотой h
+func (l *pdfLayout) need(h float64) {
+	if l.y+h > pdfBottom {
+		l.newPage()
+	}
+}
+
+// text строка текста размера size под уже написанным
+func (l *pdfLayout) text(size float64, color pdf.Color, text string) {
+	l.need(size * 1.6)
+	l.page.Text(pdfMargin, l.y+size, size, color, text)
+	l.y += size * 1.6
+}
+
+// heading заголовок раздела
+func (l *pdfLayout) heading(text string) {
+	l.need(pdfHeadingSize*2 + pdfRowHeight)
+	l.text(pdfHeadingSize, pdf.Black, text)
+	l.y += pdfHeadingSize / 2
+}
+
+// image картинка шириной width по центру страницы
+func (l *pdfLayout) image(raw []byte, width float64) error {
+	img, err := l.doc.AddImage(raw)
+	if err != nil {
+		return errors.Wrap(err, "doc.AddImage")
+	}
+	w, h := img.Size()
+	height := width * float64(h) / float64(w)
+
+	l.need(height)
+	l.page.Image(img, pdfMargin+(pdfContentWidth-width)/2, l.y, width, height)
+	l.y += height + pdfHeadingSize
+	return nil
+}
+
+// table таблица с заголовком, который повторяется на каждой странице. Четные строки подкрашиваются,
+// не влезающий в колонку текст обрезается
+func (l *pdfLayout) table(columns []pdfColumn, rows [][]string) {
+	titles := make([]string, len(columns))
+	for i, c := range columns {
+		titles[i] = c.title
+	}
+
+	l.need(2 * pdfRowHeight)
+	l.row(columns, titles, pdf.Gray)
+	for i, row := range rows {
+		if l.y+pdfRowHeight > pdfBottom {
+			l.newPage()
+			l.row(columns, titles, pdf.Gray)
+		}
+		if i%2 == 1 {
+			l.page.Rect(pdfMargin, l.y, pdfContentWidth, pdfRowHeight, pdf.LightGray)
+		}
+		l.row(columns, row, pdf.Black)
+	}
+	l.y += pdfRowHeight
+}
+
+func (l *pdfLayout) row(columns []pdfColumn, cells []string, color pdf.Color) {
+	x := pdfMargin
+	for i, c := range columns {
+		text := l.fit(cells[i], c.width-2*pdfCellPadding)
+		textX := x + pdfCellPadding
+		if c.right {
+			textX = x + c.width - pdfCellPadding - l.doc.TextWidth(text, pdfTableSize)
+		}
+		// базовая линия чуть ниже середины строки
+		l.page.Text(textX, l.y+pdfRowHeight*0.7, pdfTableSize, color, text)
+		x += c.width
+	}
+	l.y += pdfRowHeight
+}
+
+// fit текст, обрезанный до ширины width
+func (l *pdfLayout) fit(text string, width float64) string {
+	if l.doc.TextWidth(text, pdfTableSize) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && l.doc.TextWidth(string(runes)+"…", pdfTableSize) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// finish нумерует страницы и собирает документ
+func (l *pdfLayout) finish(lang i18n.Lang) ([]byte, error) {
+	for i, page := range l.pages {
+		text := i18n.Sprintf(lang, txtPDFPage, i+1, len(l.pages))
+		page.Text(pdf.PageWidth-pdfMargin-l.doc.TextWidth(text, pdfTableSize), pdf.PageHeight-pdfMargin/2, pdfTableSize, pdf.Gray, text)
+	}
+
+	doc, err := l.doc.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "doc.Bytes")
+	}
+	return doc, nil
+}
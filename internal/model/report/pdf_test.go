@@ -0,0 +1,179 @@
+//go:build test_all || unit_test
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	reader "github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wcharczuk/go-chart/v2/roboto"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/pdf"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/utils/logs"
+)
+
+// fakeDrawer рисует вместо диаграмм пустые картинки и запоминает дни графика
+type fakeDrawer struct {
+	days []DailyTotal
+}
+
+func (d *fakeDrawer) PieChart(_ []ReportItem, _ PieLabels) ([]byte, error) {
+	return blankPNG(), nil
+}
+
+func (d *fakeDrawer) TrendChart(days []DailyTotal, _ func(time.Time) string) ([]byte, error) {
+	d.days = days
+	return blankPNG(), nil
+}
+
+func blankPNG() []byte {
+	res := bytes.Buffer{}
+	_ = png.Encode(&res, image.NewRGBA(image.Rect(0, 0, 4, 2)))
+	return res.Bytes()
+}
+
+// pageCount количество страниц документа: документ открывается сторонней библиотекой чтения PDF
+func pageCount(t *testing.T, doc []byte) int {
+	t.Helper()
+
+	r, err := reader.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	require.NoError(t, err)
+	return r.NumPage()
+}
+
+func Test_reportPDF(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	req := Request{FromDate: from, UserID: 123, Currency: currency.RUB, Lang: i18n.EN}
+
+	t.Run("тысячи трат на нескольких страницах", func(t *testing.T) {
+		list := make([]purchases.Purchase, 3000)
+		for i := range list {
+			list[i] = purchases.Purchase{
+				Date:             from.Add(time.Duration(i) * 14 * time.Minute),
+				PurchaseCategory: fmt.Sprintf("Категория %d", i%12),
+				Description:      "очень длинное описание траты, которое не поместится в колонку таблицы целиком",
+				Summa:            float64(100 + i),
+			}
+		}
+
+		drawer := &fakeDrawer{}
+		s := New(nil, nil, drawer)
+		report, err := s.packaging(list, req.Currency, req.FromDate)
+		require.NoError(t, err)
+
+		doc, err := s.reportPDF(req, report, list, now)
+		require.NoError(t, err)
+
+		assert.True(t, bytes.HasPrefix(doc, []byte("%PDF-")))
+		// обложка, категории, диаграммы и не меньше 50 страниц списка трат
+		assert.Greater(t, pageCount(t, doc), 53)
+		assert.Len(t, drawer.days, 31)
+		assert.Less(t, len(doc), MaxDocumentSize)
+	})
+
+	t.Run("без трат только обложка", func(t *testing.T) {
+		s := New(nil, nil, &fakeDrawer{})
+
+		doc, err := s.reportPDF(req, Report{FromDate: from}, nil, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, pageCount(t, doc))
+	})
+
+	t.Run("за один день без графика", func(t *testing.T) {
+		drawer := &fakeDrawer{}
+		s := New(nil, nil, drawer)
+		list := []purchases.Purchase{{Date: from.Add(time.Hour), PurchaseCategory: "Кафе", Summa: 300}}
+		report, err := s.packaging(list, req.Currency, req.FromDate)
+		require.NoError(t, err)
+
+		_, err = s.reportPDF(req, report, list, from.Add(2*time.Hour))
+
+		require.NoError(t, err)
+		assert.Nil(t, drawer.days)
+	})
+}
+
+func Test_createPDFReport_TooLarge(t *testing.T) {
+	logs.InitLogger()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{all: []purchases.Purchase{{Date: from.Add(time.Hour), PurchaseCategory: "Кафе", Summa: 300}}}
+	s := New(repo, nil, &fakeDrawer{})
+	s.Now = func() time.Time { return from.Add(2 * time.Hour) }
+	s.MaxDocumentSize = 1024
+
+	res, err := s.CreateReport(context.Background(),
+		`{"fromDate":"2024-03-01T00:00:00Z","userId":123,"currency":0,"lang":"en","format":"pdf"}`)
+
+	// вместо документа - диаграмма и объяснение
+	require.NoError(t, err)
+	assert.Nil(t, res.Document)
+	assert.Equal(t, blankPNG(), res.IMG)
+	assert.True(t, strings.HasSuffix(res.Text, "\nThe PDF document is too large to send. Choose a shorter period or a report by tags"))
+}
+
+func Test_dailyTotals(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, msk)
+	rates := currency.RateToRUB{USD: 0.5}
+
+	res, err := dailyTotals([]purchases.Purchase{
+		// 22:00 UTC 1 марта - уже 2 марта по Москве
+		{Date: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC), Summa: 100, RateToRUB: rates},
+		{Date: time.Date(2024, 3, 2, 10, 0, 0, 0, msk), Summa: 300, RateToRUB: rates},
+		// трата из будущего продлевает период
+		{Date: time.Date(2024, 3, 5, 10, 0, 0, 0, msk), Summa: 50, RateToRUB: rates},
+	}, currency.USD, from, time.Date(2024, 3, 3, 12, 0, 0, 0, msk))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []DailyTotal{
+		{Day: from, Summa: 0},
+		{Day: from.AddDate(0, 0, 1), Summa: 200},
+		{Day: from.AddDate(0, 0, 2), Summa: 0},
+		{Day: from.AddDate(0, 0, 3), Summa: 0},
+		{Day: from.AddDate(0, 0, 4), Summa: 25},
+	}, res)
+}
+
+func Test_purchaseRows(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	list := []purchases.Purchase{
+		{Date: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), PurchaseCategory: "Такси", Summa: 1500},
+		{Date: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC), PurchaseCategory: "Кафе", Description: "ужин", Summa: 1234.5},
+	}
+
+	res, err := purchaseRows(i18n.RU, list, currency.RUB, msk)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"02.03.2024", "Кафе", "ужин", "1 234,50"},
+		{"02.03.2024", "Такси", "", "1 500,00"},
+	}, res)
+	// исходный список не меняется
+	assert.Equal(t, "Такси", list[0].PurchaseCategory)
+}
+
+func Test_pdfLayout_fit(t *testing.T) {
+	doc, err := pdf.New(roboto.Roboto)
+	require.NoError(t, err)
+	l := &pdfLayout{doc: doc}
+
+	assert.Equal(t, "Кафе", l.fit("Кафе", 100))
+
+	res := l.fit("очень длинное описание траты", 60)
+	assert.NotEqual(t, "очень длинное описание траты", res)
+	assert.Regexp(t, "^очень.*…$", res)
+	assert.LessOrEqual(t, doc.TextWidth(res, pdfTableSize), float64(60))
+}
@@ -0,0 +1,316 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/freetext"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// reportTemplate шаблон текста отчета. txt переводит текст из reportTexts по имени и подставляет аргументы,
+// num, date и weekday форматируют сумму, дату и день недели на языке отчета, signed - сумму со знаком, code - код
+// валюты, category - название категории, purchases - количество трат со словом в нужной форме, share - долю суммы от всех трат
+const reportTemplate = `{{txt "period" (date .FromDate)}}
+{{txt "currency" .Currency}}
+{{with .Tags}}{{txt "tags" .}}
+{{end -}}
+{{if .Stats.Count -}}
+{{txt "total" (num .Stats.Total) (purchases .Stats.Count)}}
+{{txt "perDay" (num .PerDay)}}
+{{if .TodayRates}}{{txt "todayRates" (num .Stats.AtPurchaseRates) (signed .RatesDiff)}}
+{{end -}}
+{{if .AllCurrencies}}{{txt "currencies"}}
+{{range .Stats.Currencies}}	{{code .Currency}}: {{num .Paid}} ({{num .Summa}} {{$.Currency}})
+{{end -}}
+{{end -}}
+{{txt "report"}}
+{{range .Items}}	{{category .PurchaseCategory}}: {{num .Summa}} ({{share .Summa}}, {{purchases .Count}})
+{{end -}}
+{{txt "top"}}
+{{range .Stats.Top}}	{{date .Date}} {{category .Category}}{{with .Description}} ({{.}}){{end}}: {{num .Summa}}
+{{end -}}
+{{txt "weekday" (weekday .Weekday) (num .WeekdaySum)}}
+{{else -}}
+{{txt "empty"}}
+{{end -}}
+`
+
+// digestTemplate шаблон текста отчета по подписке, функции те же, что у reportTemplate
+const digestTemplate = `{{if eq .Period "month"}}{{txt "digestMonth" (date .FromDate) (date .LastDate)}}
+{{- else}}{{txt "digestWeek" (date .FromDate) (date .LastDate)}}{{end}}
+{{txt "currency" .Currency}}
+{{txt "digestTotal" (num .Total)}}
+{{if .PrevTotal}}{{txt "digestPrev" (num .PrevTotal) .Change}}{{else}}{{txt "digestPrevEmpty"}}{{end}}
+{{with .Rows}}{{txt "report"}}
+{{range .}}	{{category .Category}}: {{num .Summa}} {{txt "digestWas" (num .Prev)}}
+{{end -}}
+{{end -}}
+{{with .Limit}}{{if .Exceeded}}{{txt "digestLimitExceeded" (num .Spent) (num .Limit)}}
+{{- else}}{{txt "digestLimit" (num .Spent) (num .Limit)}}{{end}}
+{{end -}}
+`
+
+// reportTexts тексты, доступные в шаблонах отчетов через txt
+var reportTexts = map[string]string{
+	"period":   txtPeriod,
+	"currency": txtCurrency,
+	"tags":     txtTags,
+	"total":    txtTotal,
+	"perDay":   txtPerDay,
+	"report":   txtReport,
+	"top":      txtTopPurchases,
+	"weekday":  txtBusiestWeekday,
+	"empty":    txtNoPurchases,
+
+	"todayRates": txtTodayRates,
+	"currencies": txtCurrencies,
+
+	"digestWeek":          txtDigestWeek,
+	"digestMonth":         txtDigestMonth,
+	"digestTotal":         txtDigestTotal,
+	"digestPrev":          txtDigestPrev,
+	"digestPrevEmpty":     txtDigestPrevEmpty,
+	"digestWas":           txtDigestWas,
+	"digestLimit":         txtDigestLimit,
+	"digestLimitExceeded": txtDigestLimitExceeded,
+}
+
+var (
+	reportTmpl = template.Must(template.New("report").Funcs(reportFuncs(i18n.Default, 0)).Parse(reportTemplate))
+	digestTmpl = template.Must(template.New("digest").Funcs(reportFuncs(i18n.Default, 0)).Parse(digestTemplate))
+)
+
+// reportData данные для шаблона отчета
+type reportData struct {
+	FromDate   time.Time
+	Currency   string
+	Tags       string
+	Items      []ReportItem
+	Stats      Stats
+	PerDay     float64 // в среднем в день
+	Weekday    time.Weekday
+	WeekdaySum float64
+
+	AllCurrencies bool
+	TodayRates    bool
+	RatesDiff     float64 // курсовая разница: насколько по сегодняшнему курсу потрачено больше
+}
+
+// reportText текст отчета на языке пользователя. Среднее в день считается по календарным дням
+// с начала периода по сегодняшний день включительно
+func reportText(req Request, report Report, now time.Time) (string, error) {
+	lang := req.Lang
+	if lang == "" {
+		lang = i18n.Default
+	}
+
+	cy, err := currency.CurrencyToStr(req.Currency)
+	if err != nil {
+		return "", errors.Wrap(err, "currencyToStr")
+	}
+
+	data := reportData{
+		FromDate: req.FromDate,
+		Currency: cy,
+		Items:    report.Items,
+		Stats:    report.Stats,
+		PerDay:   report.Stats.Total / float64(reportDays(req.FromDate, now)),
+
+		AllCurrencies: req.AllCurrencies,
+		TodayRates:    req.TodayRates != nil,
+		RatesDiff:     report.Stats.Total - report.Stats.AtPurchaseRates,
+	}
+	if len(req.Tags) != 0 {
+		data.Tags = freetext.FormatTags(req.Tags)
+	}
+	data.Weekday, data.WeekdaySum = report.Stats.BusiestWeekday()
+
+	tmpl, err := reportTmpl.Clone()
+	if err != nil {
+		return "", errors.Wrap(err, "template.Clone")
+	}
+
+	res := strings.Builder{}
+	if err = tmpl.Funcs(reportFuncs(lang, report.Stats.Total)).Execute(&res, data); err != nil {
+		return "", errors.Wrap(err, "template.Execute")
+	}
+	return res.String(), nil
+}
+
+// digestData данные для шаблона отчета по подписке
+type digestData struct {
+	Period    string // week или month
+	FromDate  time.Time
+	LastDate  time.Time // последний день периода
+	Currency  string
+	Total     float64
+	PrevTotal float64
+	Change    string // изменение по сравнению с предыдущим периодом в процентах
+	Rows      []digestRow
+	Limit     *digestLimit // nil - лимита нет
+}
+
+// digestRow строка отчета по подписке: траты в категории за период и за предыдущий период
+type digestRow struct {
+	Category string
+	Summa    float64
+	Prev     float64
+}
+
+// digestLimit сколько потрачено из месячного лимита
+type digestLimit struct {
+	Spent    float64
+	Limit    float64
+	Exceeded bool
+}
+
+// digestText текст отчета по подписке на языке пользователя
+func digestText(req Request, items, prevItems []ReportItem) (string, error) {
+	lang := req.Lang
+	if lang == "" {
+		lang = i18n.Default
+	}
+
+	cy, err := currency.CurrencyToStr(req.Currency)
+	if err != nil {
+		return "", errors.Wrap(err, "currencyToStr")
+	}
+
+	data := digestData{
+		Period:   req.Digest.Period,
+		FromDate: req.FromDate,
+		// конец периода не включительно, в заголовке - его последний день
+		LastDate:  req.ToDate.AddDate(0, 0, -1),
+		Currency:  cy,
+		Total:     sum(items),
+		PrevTotal: sum(prevItems),
+		Rows:      digestRows(items, prevItems),
+	}
+	if data.PrevTotal != 0 {
+		data.Change = fmt.Sprintf("%+.0f%%", (data.Total-data.PrevTotal)/data.PrevTotal*100)
+	}
+	if req.Digest.Limit != -1 {
+		data.Limit = &digestLimit{
+			Spent:    req.Digest.MonthExpenses,
+			Limit:    req.Digest.Limit,
+			Exceeded: req.Digest.MonthExpenses > req.Digest.Limit,
+		}
+	}
+
+	tmpl, err := digestTmpl.Clone()
+	if err != nil {
+		return "", errors.Wrap(err, "template.Clone")
+	}
+
+	res := strings.Builder{}
+	if err = tmpl.Funcs(reportFuncs(lang, data.Total)).Execute(&res, data); err != nil {
+		return "", errors.Wrap(err, "template.Execute")
+	}
+	return res.String(), nil
+}
+
+// reportFuncs функции шаблона отчета на языке lang, total - сумма всех трат для долей
+func reportFuncs(lang i18n.Lang, total float64) template.FuncMap {
+	return template.FuncMap{
+		"txt": func(name string, args ...any) (string, error) {
+			txt, ok := reportTexts[name]
+			if !ok {
+				return "", errors.Errorf("unknown text %q", name)
+			}
+			return i18n.Sprintf(lang, txt, args...), nil
+		},
+		"num": func(v float64) string {
+			return i18n.Number(lang, v)
+		},
+		"signed": func(v float64) string {
+			return signedNumber(lang, v)
+		},
+		"code": func(cy currency.Currency) (string, error) {
+			return currency.CurrencyToStr(cy)
+		},
+		"date": func(t time.Time) string {
+			return i18n.Date(lang, t)
+		},
+		"category": func(name string) string {
+			return purchases.CategoryName(lang, name)
+		},
+		"weekday": func(d time.Weekday) string {
+			return i18n.T(lang, weekdays[d])
+		},
+		"purchases": func(n int) string {
+			return purchasesCount(lang, n)
+		},
+		"share": func(v float64) string {
+			if total == 0 {
+				return "0%"
+			}
+			return fmt.Sprintf("%.0f%%", v/total*100)
+		},
+	}
+}
+
+// signedNumber сумма со знаком: +100,00 или -100,00
+func signedNumber(lang i18n.Lang, v float64) string {
+	if v > 0 {
+		return "+" + i18n.Number(lang, v)
+	}
+	return i18n.Number(lang, v)
+}
+
+// purchasesCount количество трат со словом в нужной форме: 3 траты
+func purchasesCount(lang i18n.Lang, n int) string {
+	return fmt.Sprintf("%d %s", n, i18n.Plural(lang, n, txtPurchasesPlural))
+}
+
+// pieLabels подписи круговой диаграммы на языке запроса, суммы в легенде - с кодом валюты отчета
+func pieLabels(req Request) (PieLabels, error) {
+	lang := req.Lang
+	if lang == "" {
+		lang = i18n.Default
+	}
+
+	cy, err := currency.CurrencyToStr(req.Currency)
+	if err != nil {
+		return PieLabels{}, errors.Wrap(err, "currencyToStr")
+	}
+
+	return PieLabels{
+		Other: i18n.T(lang, txtPieOther),
+		Empty: i18n.T(lang, txtNoPurchases),
+		FormatSum: func(v float64) string {
+			return i18n.Number(lang, v) + " " + cy
+		},
+	}, nil
+}
+
+// categoryItems строки отчета с названиями категорий на языке lang, например, для подписей диаграммы
+func categoryItems(lang i18n.Lang, items []ReportItem) []ReportItem {
+	res := make([]ReportItem, len(items))
+	for i, item := range items {
+		item.PurchaseCategory = purchases.CategoryName(lang, item.PurchaseCategory)
+		res[i] = item
+	}
+	return res
+}
+
+// reportDays сколько календарных дней с дня from по день now включительно, считая в часовом поясе from
+func reportDays(from, now time.Time) int {
+	loc := from.Location()
+	now = now.In(loc)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	// при переходе на летнее время в сутках бывает не 24 часа, поэтому округляем
+	days := int(math.Round(today.Sub(start).Hours()/24)) + 1
+	if days < 1 {
+		return 1
+	}
+	return days
+}
@@ -10,6 +10,8 @@ import (
 // Repo репозиторий
 type Repo interface {
 	GetUserPurchasesFromDate(ctx context.Context, fromDate time.Time, userID int64) ([]purchases.Purchase, error)
+	GetUserPurchasesFromDateWithTags(ctx context.Context, fromDate time.Time, userID int64, tags []string) ([]purchases.Purchase, error)
+	GetUserPurchasesBetween(ctx context.Context, from, to time.Time, userID int64) ([]purchases.Purchase, error)
 }
 
 type ReportsStore interface {
@@ -20,28 +22,55 @@ type ReportsStore interface {
 
 // ChartDrawer рисовальщик
 type ChartDrawer interface {
-	// PieChart нарисовать круговую диаграмму трат
-	PieChart(data []ReportItem) ([]byte, error)
+	// PieChart нарисовать круговую диаграмму трат, labels - подписи на языке пользователя
+	PieChart(data []ReportItem, labels PieLabels) ([]byte, error)
+	// TrendChart нарисовать график трат по дням, formatDate подписывает дни на оси
+	TrendChart(days []DailyTotal, formatDate func(time.Time) string) ([]byte, error)
 }
 
+// PieLabels подписи круговой диаграммы на языке пользователя
+type PieLabels struct {
+	Other     string                 // сектор, в который собраны мелкие категории
+	Empty     string                 // подпись диаграммы без трат
+	FormatSum func(v float64) string // сумма с валютой для легенды
+}
+
+// MaxDocumentSize самый большой PDF-документ отчета в байтах. Документ передается боту одним сообщением gRPC
+// и до отправки в Telegram хранится в outbox в Redis, поэтому документы больше не отправляются
+const MaxDocumentSize = 8 << 20
+
+// MaxSendReportSize ограничение размера сообщения SendReport в gRPC у сервиса отчетов и у бота:
+// документ и запас на текст отчета
+const MaxSendReportSize = MaxDocumentSize + 1<<20
+
 type service struct {
 	repo         Repo
 	reportsStore ReportsStore
 	Drawer       ChartDrawer
+
+	// Now текущее время, подменяется в тестах
+	Now func() time.Time
+	// MaxDocumentSize самый большой PDF-документ, подменяется в тестах
+	MaxDocumentSize int
 }
 
 func New(repo Repo, store ReportsStore, drawer ChartDrawer) *service {
 	return &service{
-		repo:         repo,
-		reportsStore: store,
-		Drawer:       drawer,
+		repo:            repo,
+		reportsStore:    store,
+		Drawer:          drawer,
+		Now:             time.Now,
+		MaxDocumentSize: MaxDocumentSize,
 	}
 }
 
 type SendReportRequest struct {
-	UserID        int64
-	ReportMessage string
-	ReportIMG     []byte
+	UserID         int64
+	ReportMessage  string
+	ReportIMG      []byte
+	ReportDocument []byte // PDF-документ вместо картинки
+	DocumentName   string
+	Digest         bool // отчет по подписке, а не ответ на /report
 }
 
 type SendReportResponse struct {
@@ -0,0 +1,145 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+// topPurchases сколько самых крупных трат показывать в отчете
+const topPurchases = 3
+
+// Stats общая статистика трат за период в валюте отчета
+type Stats struct {
+	Total    float64
+	Count    int
+	Top      []TopPurchase // самые крупные траты, от большей к меньшей
+	Weekdays [7]float64    // суммы трат по дням недели, индекс - time.Weekday
+	// Currencies суммы по валютам, в которых платил пользователь, от большей к меньшей.
+	// Траты с неизвестной валютой оплаты сюда не попадают
+	Currencies []CurrencyTotal
+	// AtPurchaseRates только в отчете по сегодняшнему курсу: сколько потрачено по курсам на даты трат
+	AtPurchaseRates float64
+}
+
+// CurrencyTotal сколько потрачено в одной валюте оплаты
+type CurrencyTotal struct {
+	Currency currency.Currency
+	Paid     float64 // в валюте оплаты
+	Summa    float64 // в валюте отчета
+}
+
+// TopPurchase одна из самых крупных трат периода
+type TopPurchase struct {
+	Date        time.Time
+	Category    string
+	Description string
+	Summa       float64
+}
+
+// DailyTotal сумма трат за день
+type DailyTotal struct {
+	Day   time.Time
+	Summa float64
+}
+
+// BusiestWeekday день недели, в который потрачено больше всего, и сумма трат в этот день.
+// При равенстве сумм берется более ранний день, неделя начинается с понедельника
+func (s Stats) BusiestWeekday() (time.Weekday, float64) {
+	busiest := time.Monday
+	for i := 1; i < 7; i++ {
+		day := (time.Monday + time.Weekday(i)) % 7
+		if s.Weekdays[day] > s.Weekdays[busiest] {
+			busiest = day
+		}
+	}
+	return busiest, s.Weekdays[busiest]
+}
+
+// collectStats статистика трат в валюте cy. Дни недели считаются в часовом поясе loc - поясе пользователя
+func collectStats(purchases []purchases.Purchase, cy currency.Currency, loc *time.Location) (Stats, error) {
+	var res Stats
+	for _, p := range purchases {
+		sum, err := currency.RubToCurrentCurrency(cy, p.Summa, p.RateToRUB)
+		if err != nil {
+			return Stats{}, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+
+		if !p.UnknownCurrency {
+			paid, err := currency.RubToCurrentCurrency(p.Currency, p.Summa, p.RateToRUB)
+			if err != nil {
+				return Stats{}, errors.Wrap(err, "rubToCurrentCurrency")
+			}
+			res.addCurrency(p.Currency, paid, sum)
+		}
+
+		res.Total += sum
+		res.Count++
+		res.Weekdays[p.Date.In(loc).Weekday()] += sum
+		res.Top = append(res.Top, TopPurchase{
+			Date:        p.Date.In(loc),
+			Category:    p.PurchaseCategory,
+			Description: p.Description,
+			Summa:       sum,
+		})
+	}
+
+	sort.SliceStable(res.Top, func(i, j int) bool {
+		return res.Top[i].Summa > res.Top[j].Summa
+	})
+	if len(res.Top) > topPurchases {
+		res.Top = res.Top[:topPurchases]
+	}
+	sort.SliceStable(res.Currencies, func(i, j int) bool {
+		return res.Currencies[i].Summa > res.Currencies[j].Summa
+	})
+
+	return res, nil
+}
+
+func (s *Stats) addCurrency(cy currency.Currency, paid, sum float64) {
+	for i := range s.Currencies {
+		if s.Currencies[i].Currency == cy {
+			s.Currencies[i].Paid += paid
+			s.Currencies[i].Summa += sum
+			return
+		}
+	}
+	s.Currencies = append(s.Currencies, CurrencyTotal{Currency: cy, Paid: paid, Summa: sum})
+}
+
+// atRates траты, пересчитанные по курсам rates: сумма в валюте оплаты остается той же, а сумма в рублях
+// и курсы траты меняются, поэтому дальше траты переводятся в валюту отчета по новым курсам.
+// Траты с неизвестной валютой оплаты пересчитать нельзя, они остаются по курсам на даты трат
+// и не дают курсовой разницы
+func atRates(list []purchases.Purchase, rates currency.RateToRUB) ([]purchases.Purchase, error) {
+	res := make([]purchases.Purchase, len(list))
+	for i, p := range list {
+		if p.UnknownCurrency {
+			res[i] = p
+			continue
+		}
+
+		paid, err := currency.RubToCurrentCurrency(p.Currency, p.Summa, p.RateToRUB)
+		if err != nil {
+			return nil, errors.Wrap(err, "rubToCurrentCurrency")
+		}
+		rub, err := currency.ToRUB(p.Currency, paid, rates)
+		if err != nil {
+			return nil, errors.Wrap(err, "toRUB")
+		}
+		// нулевой курс - курса валюты нет
+		if math.IsInf(rub, 0) || math.IsNaN(rub) {
+			return nil, errors.Errorf("no rate for currency %d", p.Currency)
+		}
+
+		p.Summa = rub
+		p.RateToRUB = rates
+		res[i] = p
+	}
+	return res, nil
+}
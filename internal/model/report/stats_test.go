@@ -0,0 +1,89 @@
+//go:build test_all || unit_test
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/currency"
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/purchases"
+)
+
+func Test_collectStats(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	rates := currency.RateToRUB{USD: 0.5, EUR: 1, CNY: 1}
+	// пятница 22:00 UTC - суббота по Москве
+	saturday := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	res, err := collectStats([]purchases.Purchase{
+		{Date: saturday, PurchaseCategory: "Кафе", Summa: 100, RateToRUB: rates},
+		{Date: saturday, PurchaseCategory: "Кафе", Summa: 600, RateToRUB: rates},
+		{Date: monday, PurchaseCategory: "Такси", Description: "в аэропорт", Summa: 1000, Currency: currency.EUR, RateToRUB: rates},
+		{Date: monday, PurchaseCategory: "Кафе", Summa: 200, RateToRUB: rates},
+		{Date: monday, PurchaseCategory: "Кафе", Summa: 40, UnknownCurrency: true, RateToRUB: rates},
+	}, currency.USD, msk)
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(970), res.Total)
+	assert.Equal(t, 5, res.Count)
+	assert.Equal(t, []TopPurchase{
+		{Date: monday.In(msk), Category: "Такси", Description: "в аэропорт", Summa: 500},
+		{Date: saturday.In(msk), Category: "Кафе", Summa: 300},
+		{Date: monday.In(msk), Category: "Кафе", Summa: 100},
+	}, res.Top)
+	assert.Equal(t, [7]float64{time.Saturday: 350, time.Monday: 620}, res.Weekdays)
+	// такси оплачено в евро, остальное - в рублях, кроме траты с неизвестной валютой: она в суммы по валютам
+	// не попадает. Валюты - от большей суммы к меньшей
+	assert.Equal(t, []CurrencyTotal{
+		{Currency: currency.EUR, Paid: 1000, Summa: 500},
+		{Currency: currency.RUB, Paid: 900, Summa: 450},
+	}, res.Currencies)
+
+	day, sum := res.BusiestWeekday()
+	assert.Equal(t, time.Monday, day)
+	assert.Equal(t, float64(620), sum)
+}
+
+func Test_BusiestWeekday(t *testing.T) {
+	t.Run("при равенстве - более ранний день недели", func(t *testing.T) {
+		day, _ := Stats{Weekdays: [7]float64{time.Sunday: 100, time.Tuesday: 100}}.BusiestWeekday()
+
+		assert.Equal(t, time.Tuesday, day)
+	})
+
+	t.Run("воскресенье в конце недели", func(t *testing.T) {
+		day, sum := Stats{Weekdays: [7]float64{time.Sunday: 200, time.Monday: 100}}.BusiestWeekday()
+
+		assert.Equal(t, time.Sunday, day)
+		assert.Equal(t, float64(200), sum)
+	})
+}
+
+func Test_atRates(t *testing.T) {
+	then := currency.RateToRUB{USD: 0.02, EUR: 0.02, CNY: 0.1}
+	today := currency.RateToRUB{USD: 0.01, EUR: 0.0125, CNY: 0.1}
+	list := []purchases.Purchase{
+		// 10 долларов по старому курсу - 500 рублей, по новому - 1000
+		{PurchaseCategory: "Кафе", Summa: 500, Currency: currency.USD, RateToRUB: then},
+		{PurchaseCategory: "Такси", Summa: 300, Currency: currency.RUB, RateToRUB: then},
+		// валюта неизвестна - трата остается по старому курсу
+		{PurchaseCategory: "Кино", Summa: 700, UnknownCurrency: true, RateToRUB: then},
+	}
+
+	res, err := atRates(list, today)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []purchases.Purchase{
+		{PurchaseCategory: "Кафе", Summa: 1000, Currency: currency.USD, RateToRUB: today},
+		{PurchaseCategory: "Такси", Summa: 300, Currency: currency.RUB, RateToRUB: today},
+		{PurchaseCategory: "Кино", Summa: 700, UnknownCurrency: true, RateToRUB: then},
+	}, res)
+	// исходные траты не меняются
+	assert.Equal(t, float64(500), list[0].Summa)
+
+	_, err = atRates(list, currency.RateToRUB{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"time"
+
+	"gitlab.ozon.dev/apetrichuk/financial-tg-bot/internal/model/i18n"
+)
+
+var (
+	txtPeriod   = "Траты с %s"
+	txtCurrency = "Ваша валюта: %s"
+	txtTags     = "Теги: %s"
+	txtReport   = "Ваш отчет:"
+
+	txtTotal           = "Всего потрачено: %s (%s)"
+	txtPerDay          = "В среднем в день: %s"
+	txtTopPurchases    = "Самые крупные траты:"
+	txtBusiestWeekday  = "Больше всего потрачено в день недели: %s (%s)"
+	txtNoPurchases     = "Трат за этот период нет"
+	txtPurchasesPlural = "трата|траты|трат"
+	txtCurrencies      = "По валютам оплаты:"
+	txtTodayRates      = "Суммы пересчитаны по сегодняшнему курсу. По курсам на даты трат: %s, курсовая разница: %s"
+	txtPieOther        = "Прочее"
+
+	txtPDFTitle       = "Отчет о тратах"
+	txtPDFCategories  = "Траты по категориям"
+	txtPDFPieChart    = "Доли категорий"
+	txtPDFTrendChart  = "Траты по дням"
+	txtPDFPurchases   = "Все траты"
+	txtPDFCategory    = "Категория"
+	txtPDFSum         = "Сумма"
+	txtPDFShare       = "Доля"
+	txtPDFCount       = "Количество трат"
+	txtPDFDate        = "Дата"
+	txtPDFDescription = "Описание"
+	txtPDFPage        = "Страница %d из %d"
+	txtPDFTooLarge    = "PDF-документ получился слишком большим, чтобы его отправить. Выберите период покороче или отчет по тегам"
+
+	txtDigestWeek          = "Отчет за неделю %s - %s"
+	txtDigestMonth         = "Отчет за месяц %s - %s"
+	txtDigestTotal         = "Всего потрачено: %s"
+	txtDigestPrev          = "За предыдущий период: %s (%s)"
+	txtDigestPrevEmpty     = "За предыдущий период трат не было"
+	txtDigestWas           = "(было %s)"
+	txtDigestLimit         = "Лимит на месяц: потрачено %s из %s"
+	txtDigestLimitExceeded = "Лимит на месяц превышен: потрачено %s из %s"
+)
+
+// weekdays названия дней недели, индекс - time.Weekday
+var weekdays = [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+func init() {
+	i18n.Register(i18n.EN, map[string]string{
+		txtPeriod:   "Expenses since %s",
+		txtCurrency: "Your currency: %s",
+		txtTags:     "Tags: %s",
+		txtReport:   "Your report:",
+
+		txtTotal:           "Total spent: %s (%s)",
+		txtPerDay:          "Average per day: %s",
+		txtTopPurchases:    "Biggest purchases:",
+		txtBusiestWeekday:  "Most spent on: %s (%s)",
+		txtNoPurchases:     "No expenses in this period",
+		txtPurchasesPlural: "purchase|purchases",
+		txtCurrencies:      "By payment currency:",
+		txtTodayRates:      "Amounts are converted at today's rates. At the rates on purchase dates: %s, FX difference: %s",
+		txtPieOther:        "Other",
+
+		txtPDFTitle:       "Expense report",
+		txtPDFCategories:  "Expenses by category",
+		txtPDFPieChart:    "Category shares",
+		txtPDFTrendChart:  "Expenses by day",
+		txtPDFPurchases:   "All purchases",
+		txtPDFCategory:    "Category",
+		txtPDFSum:         "Amount",
+		txtPDFShare:       "Share",
+		txtPDFCount:       "Purchases",
+		txtPDFDate:        "Date",
+		txtPDFDescription: "Description",
+		txtPDFPage:        "Page %d of %d",
+		txtPDFTooLarge:    "The PDF document is too large to send. Choose a shorter period or a report by tags",
+
+		txtDigestWeek:          "Weekly report %s - %s",
+		txtDigestMonth:         "Monthly report %s - %s",
+		txtDigestTotal:         "Total spent: %s",
+		txtDigestPrev:          "Previous period: %s (%s)",
+		txtDigestPrevEmpty:     "No expenses in the previous period",
+		txtDigestWas:           "(was %s)",
+		txtDigestLimit:         "Monthly limit: spent %s of %s",
+		txtDigestLimitExceeded: "Monthly limit exceeded: spent %s of %s",
+	})
+
+	// в английском названия дней недели совпадают с time.Weekday
+	days := make(map[string]string, len(weekdays))
+	for d, name := range weekdays {
+		days[name] = time.Weekday(d).String()
+	}
+	i18n.Register(i18n.EN, days)
+}
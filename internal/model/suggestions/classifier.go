@@ -0,0 +1,130 @@
+package suggestions
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// stemLen длина, до которой обрезаются слова. Грубый стемминг, чтобы "такси" и "таксист" или
+// "продукты" и "продуктовый" считались одним токеном
+const stemLen = 5
+
+// Sample трата из истории пользователя: описание и категория, в которую она в итоге попала
+type Sample struct {
+	Description string
+	Category    string
+}
+
+// Classifier наивный байесовский классификатор описаний трат по категориям.
+// Обучается отдельно для каждого пользователя на его истории трат
+type Classifier struct {
+	docs           int                       // сколько всего трат в обучающей выборке
+	categoryDocs   map[string]int            // сколько трат в каждой категории
+	tokenCounts    map[string]map[string]int // сколько раз токен встречался в категории
+	categoryTokens map[string]int            // сколько всего токенов в категории
+	vocabulary     map[string]struct{}
+}
+
+// Train обучает классификатор на истории трат
+func Train(samples []Sample) *Classifier {
+	c := &Classifier{
+		categoryDocs:   make(map[string]int),
+		tokenCounts:    make(map[string]map[string]int),
+		categoryTokens: make(map[string]int),
+		vocabulary:     make(map[string]struct{}),
+	}
+
+	for _, s := range samples {
+		if s.Category == "" {
+			continue
+		}
+
+		c.docs++
+		c.categoryDocs[s.Category]++
+		if c.tokenCounts[s.Category] == nil {
+			c.tokenCounts[s.Category] = make(map[string]int)
+		}
+
+		for _, token := range Tokenize(s.Description) {
+			c.tokenCounts[s.Category][token]++
+			c.categoryTokens[s.Category]++
+			c.vocabulary[token] = struct{}{}
+		}
+	}
+
+	return c
+}
+
+// Score логарифм ненормированной вероятности того, что трата с таким описанием относится к категории.
+// Используется сглаживание Лапласа, поэтому незнакомые категории и токены не обнуляют вероятность
+func (c *Classifier) Score(description, category string) float64 {
+	categoriesCount := len(c.categoryDocs) + 1 // +1 под незнакомую категорию
+	score := math.Log(float64(c.categoryDocs[category]+1) / float64(c.docs+categoriesCount))
+
+	vocabularySize := len(c.vocabulary) + 1
+	for _, token := range Tokenize(description) {
+		count := c.tokenCounts[category][token]
+		score += math.Log(float64(count+1) / float64(c.categoryTokens[category]+vocabularySize))
+	}
+
+	return score
+}
+
+// Rank сортирует категории от наиболее вероятной к наименее вероятной.
+// При равной вероятности категории сортируются по алфавиту
+func (c *Classifier) Rank(description string, categories []string) []string {
+	scores := make(map[string]float64, len(categories))
+	for _, cat := range categories {
+		scores[cat] = c.Score(description, cat)
+	}
+
+	res := make([]string, len(categories))
+	copy(res, categories)
+	sort.SliceStable(res, func(i, j int) bool {
+		if scores[res[i]] != scores[res[j]] {
+			return scores[res[i]] > scores[res[j]]
+		}
+		return res[i] < res[j]
+	})
+
+	return res
+}
+
+// Predict возвращает наиболее вероятную категорию из тех, что встречались в обучающей выборке
+func (c *Classifier) Predict(description string) (string, bool) {
+	if c.docs == 0 {
+		return "", false
+	}
+
+	categories := make([]string, 0, len(c.categoryDocs))
+	for cat := range c.categoryDocs {
+		categories = append(categories, cat)
+	}
+
+	return c.Rank(description, categories)[0], true
+}
+
+// Tokenize разбивает описание на токены: слова в нижнем регистре, без чисел, обрезанные до stemLen символов
+func Tokenize(description string) []string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.ReplaceAll(w, "ё", "е")
+
+		runes := []rune(w)
+		if len(runes) < 2 {
+			continue
+		}
+		if len(runes) > stemLen {
+			runes = runes[:stemLen]
+		}
+		tokens = append(tokens, string(runes))
+	}
+
+	return tokens
+}
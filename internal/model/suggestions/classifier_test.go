@@ -0,0 +1,104 @@
+//go:build test_all || unit_test
+
+package suggestions
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_Tokenize(t *testing.T) {
+	assert.Equal(t, []string{"пятер", "дома"}, Tokenize("Пятёрочка у дома"))
+	assert.Equal(t, []string{"такси", "до", "аэроп", "чел"}, Tokenize("Такси до аэропорта, 2 чел."))
+	assert.Empty(t, Tokenize("123 - !"))
+}
+
+func Test_Rank(t *testing.T) {
+	c := Train([]Sample{
+		{Description: "пятёрочка", Category: "Продукты"},
+		{Description: "пятерочка молоко", Category: "Продукты"},
+		{Description: "такси", Category: "Транспорт"},
+		{Description: "кофе", Category: "Кафе"},
+		{Description: "кофе с собой", Category: "Кафе"},
+		{Description: "кофейня", Category: "Кафе"},
+	})
+
+	t.Run("категория с совпадающими словами идет первой", func(t *testing.T) {
+		res := c.Rank("такси домой", []string{"Кафе", "Продукты", "Транспорт"})
+		assert.Equal(t, "Транспорт", res[0])
+	})
+
+	t.Run("без совпадений категории сортируются по частоте в истории", func(t *testing.T) {
+		res := c.Rank("кино", []string{"Продукты", "Транспорт", "Кафе"})
+		assert.Equal(t, []string{"Кафе", "Продукты", "Транспорт"}, res)
+	})
+
+	t.Run("незнакомые категории идут после знакомых и сортируются по алфавиту", func(t *testing.T) {
+		res := c.Rank("пятёрочка", []string{"Одежда", "Здоровье", "Продукты"})
+		assert.Equal(t, []string{"Продукты", "Здоровье", "Одежда"}, res)
+	})
+
+	t.Run("пустая история", func(t *testing.T) {
+		res := Train(nil).Rank("кофе", []string{"Б", "А"})
+		assert.Equal(t, []string{"А", "Б"}, res)
+	})
+}
+
+// Test_OfflineEvaluation офлайн-оценка точности классификатора на фикстуре с историей трат:
+// каждая четвертая трата уходит в тестовую выборку, на остальных классификатор обучается
+func Test_OfflineEvaluation(t *testing.T) {
+	raw, err := os.ReadFile("./../../../test_data/suggestions/purchases.yml")
+	assert.NoError(t, err)
+
+	var dataset []struct {
+		Description string `yaml:"description"`
+		Category    string `yaml:"category"`
+	}
+	assert.NoError(t, yaml.Unmarshal(raw, &dataset))
+
+	var train, test []Sample
+	for i, row := range dataset {
+		s := Sample{Description: row.Description, Category: row.Category}
+		if i%4 == 3 {
+			test = append(test, s)
+		} else {
+			train = append(train, s)
+		}
+	}
+
+	c := Train(train)
+
+	var top1, top3 int
+	categories := make(map[string]struct{})
+	for _, s := range train {
+		categories[s.Category] = struct{}{}
+	}
+	allCategories := make([]string, 0, len(categories))
+	for cat := range categories {
+		allCategories = append(allCategories, cat)
+	}
+
+	for _, s := range test {
+		ranked := c.Rank(s.Description, allCategories)
+		if ranked[0] == s.Category {
+			top1++
+		}
+		for _, cat := range ranked[:3] {
+			if cat == s.Category {
+				top3++
+				break
+			}
+		}
+	}
+
+	accuracy := float64(top1) / float64(len(test))
+	accuracyTop3 := float64(top3) / float64(len(test))
+	t.Logf("train: %d, test: %d, accuracy@1: %.2f, accuracy@3: %.2f", len(train), len(test), accuracy, accuracyTop3)
+
+	// пороги чуть ниже текущих значений, чтобы тест ловил заметную деградацию качества
+	assert.GreaterOrEqual(t, accuracy, 0.6)
+	assert.GreaterOrEqual(t, accuracyTop3, 0.75)
+}
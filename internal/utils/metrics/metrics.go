@@ -14,6 +14,16 @@ const (
 
 	ReportSourceBD    = "bd"
 	ReportSourceCache = "cache"
+
+	DropReasonQueueFull     = "queue_full"
+	DropReasonUndeliverable = "undeliverable"
+	DropReasonAttempts      = "attempts"
+
+	RetryReasonRateLimit = "rate_limit"
+	RetryReasonError     = "error"
+
+	ReminderResultSent    = "sent"
+	ReminderResultSkipped = "skipped"
 )
 
 var (
@@ -98,4 +108,77 @@ var (
 		},
 		[]string{"source"},
 	)
+
+	// UpdatesQueueLength сколько апдейтов телеграма ждут обработки в очередях воркеров
+	UpdatesQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tg_bot",
+		Subsystem: "updates",
+		Name:      "queue_length",
+	})
+
+	// UpdatesQueueFull сколько раз очередь воркера была заполнена и прием апдейтов приходилось придерживать
+	UpdatesQueueFull = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "tg_bot",
+		Subsystem: "updates",
+		Name:      "queue_full_total",
+	})
+
+	// HistogramUpdatesQueueWait гистограмма времени ожидания апдейта в очереди
+	HistogramUpdatesQueueWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "tg_bot",
+		Subsystem: "updates",
+		Name:      "histogram_queue_wait_seconds",
+		Buckets:   []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
+	})
+
+	// OutboxQueueLength сколько исходящих сообщений ждут отправки
+	OutboxQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tg_bot",
+		Subsystem: "outbox",
+		Name:      "queue_length",
+	})
+
+	// OutboxDropped сколько исходящих сообщений не было доставлено (по причинам)
+	OutboxDropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tg_bot",
+			Subsystem: "outbox",
+			Name:      "dropped_total",
+		},
+		[]string{"reason"},
+	)
+
+	// OutboxRetries сколько раз отправку сообщения пришлось повторить (по причинам)
+	OutboxRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tg_bot",
+			Subsystem: "outbox",
+			Name:      "retries_total",
+		},
+		[]string{"reason"},
+	)
+
+	// ThrottledRequests сколько запросов пользователей отклонено из-за ограничения частоты (по классам и командам)
+	ThrottledRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tg_bot",
+			Name:      "throttled_requests_total",
+		},
+		[]string{"class", "command"},
+	)
+
+	// Reminders сколько напоминаний о тратах обработано: отправлено или не понадобилось, потому что траты уже есть
+	Reminders = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tg_bot",
+			Name:      "reminders_total",
+		},
+		[]string{"result"},
+	)
+
+	// Digests сколько отчетов по подпискам запрошено
+	Digests = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "tg_bot",
+		Name:      "digests_total",
+	})
 )
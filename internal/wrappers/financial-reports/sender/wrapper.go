@@ -31,9 +31,12 @@ func (w *Wrapper) CreateReport(ctx context.Context, rawReq string) (report.Creat
 	}
 
 	resp, err := w.client.SendReport(ctx, report.SendReportRequest{
-		UserID:        res.UserID,
-		ReportMessage: res.Text,
-		ReportIMG:     res.IMG,
+		UserID:         res.UserID,
+		ReportMessage:  res.Text,
+		ReportIMG:      res.IMG,
+		Digest:         res.Digest,
+		ReportDocument: res.Document,
+		DocumentName:   res.DocumentName,
 	})
 	if err != nil {
 		return report.CreateReportResponse{}, errors.Wrap(err, "sender.SendReport")
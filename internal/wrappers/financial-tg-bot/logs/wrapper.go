@@ -58,14 +58,35 @@ func (m *Wrapper) SendImage(img []byte, userID int64) error {
 	return nil
 }
 
-func (m *Wrapper) SendKeyboard(text string, userID int64, buttonTexts []string) error {
-	err := m.sender.SendKeyboard(text, userID, buttonTexts)
+func (m *Wrapper) SendDocument(doc []byte, name string, userID int64) error {
+	err := m.sender.SendDocument(doc, name, userID)
+	if err != nil {
+		logs.Error(
+			"send document error",
+			zap.Error(err),
+			zap.Int64("userId", userID),
+			zap.String("name", name),
+		)
+		return err
+	}
+
+	logs.Info(
+		"sent document",
+		zap.Int64("userId", userID),
+		zap.String("name", name),
+	)
+
+	return nil
+}
+
+func (m *Wrapper) SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error {
+	err := m.sender.SendKeyboard(text, userID, keyboard)
 	if err != nil {
 		logs.Error(
 			"send keyboard error",
 			zap.Error(err),
 			zap.Int64("userId", userID),
-			zap.Strings("button texts", buttonTexts),
+			zap.Any("keyboard", keyboard),
 		)
 		return err
 	}
@@ -78,6 +99,89 @@ func (m *Wrapper) SendKeyboard(text string, userID int64, buttonTexts []string)
 	return nil
 }
 
+func (m *Wrapper) SendTrackedMessage(text string, userID int64, key string) error {
+	err := m.sender.SendTrackedMessage(text, userID, key)
+	if err != nil {
+		logs.Error(
+			"send tracked message error",
+			zap.Error(err),
+			zap.Int64("userId", userID),
+			zap.String("key", key),
+		)
+		return err
+	}
+
+	logs.Info(
+		"sent tracked message",
+		zap.Int64("userId", userID),
+		zap.String("key", key),
+	)
+
+	return nil
+}
+
+func (m *Wrapper) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	err := m.sender.EditMessage(text, userID, msg)
+	if err != nil {
+		logs.Error(
+			"edit message error",
+			zap.Error(err),
+			zap.Int64("userId", userID),
+			zap.Any("message", msg),
+		)
+		return err
+	}
+
+	logs.Info(
+		"edited message",
+		zap.Int64("userId", userID),
+		zap.Any("message", msg),
+	)
+
+	return nil
+}
+
+func (m *Wrapper) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	err := m.sender.EditKeyboard(text, userID, msg, keyboard)
+	if err != nil {
+		logs.Error(
+			"edit keyboard error",
+			zap.Error(err),
+			zap.Int64("userId", userID),
+			zap.Any("message", msg),
+		)
+		return err
+	}
+
+	logs.Info(
+		"edited keyboard",
+		zap.Int64("userId", userID),
+		zap.Any("message", msg),
+	)
+
+	return nil
+}
+
+func (m *Wrapper) AnswerInlineQuery(queryID string, results []tg.InlineResult) error {
+	err := m.sender.AnswerInlineQuery(queryID, results)
+	if err != nil {
+		logs.Error(
+			"answer inline query error",
+			zap.Error(err),
+			zap.String("queryId", queryID),
+		)
+		return err
+	}
+
+	logs.Info(
+		"answered inline query",
+		zap.String("queryId", queryID),
+		zap.Int("results", len(results)),
+	)
+
+	return nil
+}
+
 func (m *Wrapper) IncomingCallback(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
 	err := m.sender.IncomingCallback(ctx, model, msg)
 	if err != nil {
@@ -114,3 +218,39 @@ func (m *Wrapper) IncomingMessage(ctx context.Context, model tg.MsgModel, msg tg
 
 	return nil
 }
+
+func (m *Wrapper) IncomingInlineQuery(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	if err := m.sender.IncomingInlineQuery(ctx, model, msg); err != nil {
+		logs.Error(
+			"incoming inline query error",
+			zap.Error(err),
+			zap.Int64("userId", msg.InlineQuery.From.ID),
+		)
+		return err
+	}
+
+	logs.Info(
+		"incoming inline query",
+		zap.Int64("userId", msg.InlineQuery.From.ID),
+	)
+
+	return nil
+}
+
+func (m *Wrapper) IncomingChosenInlineResult(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	if err := m.sender.IncomingChosenInlineResult(ctx, model, msg); err != nil {
+		logs.Error(
+			"incoming chosen inline result error",
+			zap.Error(err),
+			zap.Int64("userId", msg.ChosenInlineResult.From.ID),
+		)
+		return err
+	}
+
+	logs.Info(
+		"incoming chosen inline result",
+		zap.Int64("userId", msg.ChosenInlineResult.From.ID),
+	)
+
+	return nil
+}
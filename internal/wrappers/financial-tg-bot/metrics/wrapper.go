@@ -44,8 +44,68 @@ func (m *Wrapper) SendImage(img []byte, userID int64) error {
 	return nil
 }
 
-func (m *Wrapper) SendKeyboard(text string, userID int64, buttonTexts []string) error {
-	err := m.sender.SendKeyboard(text, userID, buttonTexts)
+func (m *Wrapper) SendDocument(doc []byte, name string, userID int64) error {
+	err := m.sender.SendDocument(doc, name, userID)
+	if err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusErr).Inc()
+		return err
+	}
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusOk).Inc()
+
+	return nil
+}
+
+func (m *Wrapper) SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error {
+	err := m.sender.SendKeyboard(text, userID, keyboard)
+	if err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusErr).Inc()
+		return err
+	}
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusOk).Inc()
+
+	return nil
+}
+
+func (m *Wrapper) SendTrackedMessage(text string, userID int64, key string) error {
+	err := m.sender.SendTrackedMessage(text, userID, key)
+	if err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusErr).Inc()
+		return err
+	}
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusOk).Inc()
+
+	return nil
+}
+
+func (m *Wrapper) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	err := m.sender.EditMessage(text, userID, msg)
+	if err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusErr).Inc()
+		return err
+	}
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusOk).Inc()
+
+	return nil
+}
+
+func (m *Wrapper) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	err := m.sender.EditKeyboard(text, userID, msg, keyboard)
+	if err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusErr).Inc()
+		return err
+	}
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusOk).Inc()
+
+	return nil
+}
+
+func (m *Wrapper) AnswerInlineQuery(queryID string, results []tg.InlineResult) error {
+	err := m.sender.AnswerInlineQuery(queryID, results)
 	if err != nil {
 		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeOutgoing, metrics.StatusErr).Inc()
 		return err
@@ -88,3 +148,35 @@ func (m *Wrapper) IncomingMessage(ctx context.Context, model tg.MsgModel, msg tg
 
 	return nil
 }
+
+func (m *Wrapper) IncomingInlineQuery(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	startTime := time.Now()
+	if err := m.sender.IncomingInlineQuery(ctx, model, msg); err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeIncoming, metrics.StatusErr).Inc()
+		return err
+	}
+	duration := time.Since(startTime)
+
+	metrics.SummaryResponseTime.Observe(duration.Seconds())
+	metrics.HistogramResponseTime.Observe(duration.Seconds())
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeIncoming, metrics.StatusOk).Inc()
+
+	return nil
+}
+
+func (m *Wrapper) IncomingChosenInlineResult(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	startTime := time.Now()
+	if err := m.sender.IncomingChosenInlineResult(ctx, model, msg); err != nil {
+		metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeIncoming, metrics.StatusErr).Inc()
+		return err
+	}
+	duration := time.Since(startTime)
+
+	metrics.SummaryResponseTime.Observe(duration.Seconds())
+	metrics.HistogramResponseTime.Observe(duration.Seconds())
+
+	metrics.InFlightTypeMsg.WithLabelValues(metrics.TypeIncoming, metrics.StatusOk).Inc()
+
+	return nil
+}
@@ -10,8 +10,15 @@ import (
 type MsgSender interface {
 	SendMessage(text string, userID int64) error
 	SendImage(img []byte, userID int64) error
-	SendKeyboard(text string, userID int64, buttonTexts []string) error
+	SendDocument(doc []byte, name string, userID int64) error
+	SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error
+	SendTrackedMessage(text string, userID int64, key string) error
+	EditMessage(text string, userID int64, msg tg.MessageRef) error
+	EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error
+	AnswerInlineQuery(queryID string, results []tg.InlineResult) error
 
 	IncomingCallback(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error
 	IncomingMessage(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error
+	IncomingInlineQuery(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error
+	IncomingChosenInlineResult(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error
 }
@@ -42,8 +42,28 @@ func (m *Wrapper) SendImage(img []byte, userID int64) error {
 	return m.sender.SendImage(img, userID)
 }
 
-func (m *Wrapper) SendKeyboard(text string, userID int64, buttonTexts []string) error {
-	return m.sender.SendKeyboard(text, userID, buttonTexts)
+func (m *Wrapper) SendDocument(doc []byte, name string, userID int64) error {
+	return m.sender.SendDocument(doc, name, userID)
+}
+
+func (m *Wrapper) SendKeyboard(text string, userID int64, keyboard tg.Keyboard) error {
+	return m.sender.SendKeyboard(text, userID, keyboard)
+}
+
+func (m *Wrapper) SendTrackedMessage(text string, userID int64, key string) error {
+	return m.sender.SendTrackedMessage(text, userID, key)
+}
+
+func (m *Wrapper) EditMessage(text string, userID int64, msg tg.MessageRef) error {
+	return m.sender.EditMessage(text, userID, msg)
+}
+
+func (m *Wrapper) EditKeyboard(text string, userID int64, msg tg.MessageRef, keyboard tg.Keyboard) error {
+	return m.sender.EditKeyboard(text, userID, msg, keyboard)
+}
+
+func (m *Wrapper) AnswerInlineQuery(queryID string, results []tg.InlineResult) error {
+	return m.sender.AnswerInlineQuery(queryID, results)
 }
 
 func (m *Wrapper) IncomingCallback(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
@@ -59,3 +79,17 @@ func (m *Wrapper) IncomingMessage(ctx context.Context, model tg.MsgModel, msg tg
 
 	return m.sender.IncomingMessage(ctx, model, msg)
 }
+
+func (m *Wrapper) IncomingInlineQuery(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "incoming inline query")
+	defer span.Finish()
+
+	return m.sender.IncomingInlineQuery(ctx, model, msg)
+}
+
+func (m *Wrapper) IncomingChosenInlineResult(ctx context.Context, model tg.MsgModel, msg tgbotapi.Update) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "incoming chosen inline result")
+	defer span.Finish()
+
+	return m.sender.IncomingChosenInlineResult(ctx, model, msg)
+}
@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- правила автоматического подбора категории по описанию траты
+CREATE TABLE category_rules
+(
+    id          bigserial PRIMARY KEY NOT NULL, -- уникальный id, по нему же определяется порядок применения правил
+    user_id     bigint                NOT NULL,
+    rule_type   text                  NOT NULL, -- substring, regexp или amount
+    pattern     text                  NOT NULL DEFAULT '', -- подстрока или регулярное выражение
+    min_sum     numeric               NOT NULL DEFAULT 0,  -- границы суммы для правил типа amount
+    max_sum     numeric               NOT NULL DEFAULT 0,
+    category_id bigint                NOT NULL
+);
+
+-- правила всегда достаются для конкретного юзера, b-tree подходит
+CREATE INDEX category_rules_idx ON category_rules (user_id);
+
+-- исходный текст, который пользователь ввел вместо категории, нужен для последующего поиска
+ALTER TABLE purchases ADD COLUMN description text NOT NULL DEFAULT '';
+
+-- +goose Down
+
+ALTER TABLE purchases DROP COLUMN description;
+DROP TABLE category_rules;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- заметка и хэштеги, которые пользователь указал при добавлении траты
+ALTER TABLE purchases ADD COLUMN note text NOT NULL DEFAULT '';
+ALTER TABLE purchases ADD COLUMN tags text[] NOT NULL DEFAULT '{}';
+
+-- фильтрация по тегам идет через оператор @>, для массивов подходит gin
+CREATE INDEX purchases_tags_idx ON purchases USING gin (tags);
+
+-- +goose Down
+
+DROP INDEX purchases_tags_idx;
+ALTER TABLE purchases DROP COLUMN tags;
+ALTER TABLE purchases DROP COLUMN note;
@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- почти все выборки трат идут по юзеру и дате (отчеты, лимиты, поиск), а индекс был только по категории и дате
+CREATE INDEX purchases_user_ts_idx ON purchases (user_id, ts);
+
+-- +goose Down
+
+DROP INDEX purchases_user_ts_idx;
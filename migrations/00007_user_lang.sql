@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- язык, выбранный командой /lang. NULL - не выбран, тогда язык берется из настроек телеграма
+ALTER TABLE users ADD COLUMN lang text;
+
+-- +goose Down
+
+ALTER TABLE users DROP COLUMN lang;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- часовой пояс пользователя в формате IANA (Asia/Vladivostok), выбирается командой /tz.
+-- NULL - не выбран, тогда используется часовой пояс по умолчанию (Europe/Moscow)
+ALTER TABLE users ADD COLUMN tz text;
+
+-- время трат хранится с часовым поясом, чтобы границы дней и месяцев можно было считать в поясе пользователя.
+-- До этого время записывалось по часам сервера, который работает в UTC
+ALTER TABLE purchases ALTER COLUMN ts TYPE timestamptz USING ts AT TIME ZONE 'UTC';
+
+-- +goose Down
+
+ALTER TABLE purchases ALTER COLUMN ts TYPE timestamp USING ts AT TIME ZONE 'UTC';
+
+ALTER TABLE users DROP COLUMN tz;
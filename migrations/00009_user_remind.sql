@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- время ежедневного напоминания о тратах в минутах от полуночи в часовом поясе пользователя, задается командой /remind.
+-- NULL - напоминание выключено
+ALTER TABLE users ADD COLUMN remind_at smallint;
+
+-- +goose Down
+
+ALTER TABLE users DROP COLUMN remind_at;
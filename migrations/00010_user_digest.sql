@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- подписка на отчет за каждую прошедшую неделю (week) или месяц (month), оформляется командой /subscribe.
+-- NULL - подписки нет
+ALTER TABLE users ADD COLUMN digest text;
+
+-- +goose Down
+
+ALTER TABLE users DROP COLUMN digest;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- последние траты пользователя в каждой категории за 90 дней, по ним ищутся необычно крупные траты.
+-- Обновляется при каждом добавлении траты, чтобы не читать всю историю
+CREATE TABLE category_stats
+(
+    user_id     bigint NOT NULL,
+    category_id bigint NOT NULL,
+    samples     jsonb  NOT NULL DEFAULT '[]', -- [{"sum": сумма в рублях, "at": время траты}] в порядке добавления
+    PRIMARY KEY (user_id, category_id)
+);
+
+-- статистика по уже добавленным тратам
+INSERT INTO category_stats (user_id, category_id, samples)
+SELECT user_id, category_id, jsonb_agg(jsonb_build_object('sum', sum, 'at', ts) ORDER BY ts)
+FROM purchases
+WHERE ts >= NOW() - INTERVAL '90 days'
+GROUP BY user_id, category_id;
+
+-- спрашивать ли подтверждение перед записью необычно крупной траты, включается командой /confirm
+ALTER TABLE users ADD COLUMN confirm_unusual boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+
+ALTER TABLE users DROP COLUMN confirm_unusual;
+DROP TABLE category_stats;
@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- валюта, в которой пользователь заплатил. Сумма по-прежнему хранится в рублях, сумма в валюте оплаты
+-- восстанавливается по курсу на момент траты. Для уже добавленных трат валюта неизвестна и остается NULL:
+-- такие траты не попадают в суммы по валютам оплаты и в курсовую разницу
+ALTER TABLE purchases ADD COLUMN curr currency;
+
+-- +goose Down
+
+ALTER TABLE purchases DROP COLUMN curr;